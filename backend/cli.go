@@ -0,0 +1,45 @@
+package main
+
+import (
+    "fmt"
+
+    "hyprlnk/internal/browserdb"
+)
+
+// runCommand runs a one-off command against DATA_DIR instead of serving
+// the API. Stop the server first; both would write the same files.
+func (app *App) runCommand(name string, args []string) error {
+    switch name {
+    case "import-browser-db":
+        return app.importBrowserDB(args)
+    default:
+        return fmt.Errorf("unknown command %q (available: import-browser-db)", name)
+    }
+}
+
+// importBrowserDB imports copied Chrome History/Bookmarks files or Firefox
+// places.sqlite files given as arguments
+func (app *App) importBrowserDB(paths []string) error {
+    if len(paths) == 0 {
+        return fmt.Errorf("usage: hyprlnk import-browser-db <History|Bookmarks|places.sqlite>...")
+    }
+
+    for _, path := range paths {
+        data, err := browserdb.ReadFile(path)
+        if err != nil {
+            return fmt.Errorf("%s: %w", path, err)
+        }
+
+        result, err := app.service.ImportBrowserHistory(data.Bookmarks, data.History, data.Visits)
+        if err != nil {
+            return fmt.Errorf("%s: %w", path, err)
+        }
+
+        fmt.Printf("%s (%s): %d/%d bookmarks, %d/%d history entries, %d/%d visits imported\n",
+            path, data.Browser,
+            result.BookmarksImported, len(data.Bookmarks),
+            result.HistoryImported, len(data.History),
+            result.VisitsImported, len(data.Visits))
+    }
+    return nil
+}
@@ -0,0 +1,147 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"hyprlnk/internal/fetch"
+)
+
+// pixel is a 1x1 transparent GIF
+var pixel = []byte("GIF89a\x01\x00\x01\x00\x80\x00\x00\x00\x00\x00\xff\xff\xff!\xf9\x04\x01\x00\x00\x00\x00,\x00\x00\x00\x00\x01\x00\x01\x00\x00\x02\x02D\x01\x00;")
+
+const articlePage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="windows-1252">
+  <title>Archived article</title>
+  <link rel="stylesheet" href="/css/site.css">
+  <link rel="preload" href="/js/app.js" as="script">
+  <script src="/js/app.js"></script>
+</head>
+<body onload="track()">
+  <nav class="menu"><a href="/">Home</a> <a href="/blog">Blog</a> <a href="/about">About</a></nav>
+  <article class="post-content">
+    <h1>Why links rot</h1>
+    <p>Pages move, domains lapse, and sites get redesigned, so a bookmark saved today may point nowhere next year.</p>
+    <p>Keeping a copy, with its images and styles, means the reference survives even when the original does not.</p>
+    <img src="/img/chart.gif" srcset="/img/chart-2x.gif 2x" alt="Chart">
+    <p><a href="details.html" onclick="steal()">Read more</a></p>
+  </article>
+  <div class="sidebar-related"><p>Related: ten other posts you might enjoy reading, all linked from here.</p></div>
+  <footer>Copyright, all rights reserved, no really.</footer>
+</body>
+</html>`
+
+func newSite(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/post/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, articlePage)
+	})
+	mux.HandleFunc("/css/site.css", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/css")
+		fmt.Fprint(w, `@import "print.css" print; body { background: url(../img/bg.gif) }`)
+	})
+	mux.HandleFunc("/css/print.css", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/css")
+		fmt.Fprint(w, `nav { display: none }`)
+	})
+	mux.HandleFunc("/img/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/gif")
+		w.Write(pixel)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTake_InlinesResourcesAndStripsScripts(t *testing.T) {
+	server := newSite(t)
+	fetcher := fetch.New(fetch.Config{HostInterval: time.Millisecond})
+	ctx := context.Background()
+
+	resp, err := fetcher.Get(ctx, server.URL+"/post/links")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := Take(ctx, fetcher, resp.Body, resp.URL, resp.Header.Get("Content-Type"), Limits{})
+	if err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	page := string(snapshot.HTML)
+
+	for _, unwanted := range []string{"<script", "onload", "onclick", "preload", "srcset", "windows-1252", "/img/chart.gif", "url(../img/bg.gif)"} {
+		if strings.Contains(page, unwanted) {
+			t.Errorf("Expected %q to be gone from the snapshot", unwanted)
+		}
+	}
+	for _, wanted := range []string{
+		`<meta charset="utf-8"/>`,
+		`<img src="data:image/gif;base64,`,
+		`background: url("data:image/gif;base64,`,
+		"@media print {\nnav { display: none }\n}",
+		`href="` + server.URL + `/post/details.html"`,
+	} {
+		if !strings.Contains(page, wanted) {
+			t.Errorf("Expected the snapshot to contain %q", wanted)
+		}
+	}
+
+	// site.css, print.css and both images
+	if snapshot.Inlined != 4 || snapshot.Skipped != 0 {
+		t.Errorf("Expected 4 inlined and 0 skipped, got %d and %d", snapshot.Inlined, snapshot.Skipped)
+	}
+}
+
+func TestTake_LeavesResourcesOverTheLimitAsLinks(t *testing.T) {
+	server := newSite(t)
+	fetcher := fetch.New(fetch.Config{HostInterval: time.Millisecond})
+	ctx := context.Background()
+
+	resp, err := fetcher.Get(ctx, server.URL+"/post/links")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := Take(ctx, fetcher, resp.Body, resp.URL, "", Limits{MaxResources: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(snapshot.HTML), `src="`+server.URL+`/img/chart.gif"`) {
+		t.Error("Expected the image past the limit to link to the original")
+	}
+	if snapshot.Inlined != 1 || snapshot.Skipped != 3 {
+		t.Errorf("Expected 1 inlined and 3 skipped, got %d and %d", snapshot.Inlined, snapshot.Skipped)
+	}
+}
+
+func TestReadable_KeepsTheArticle(t *testing.T) {
+	text := Readable([]byte(articlePage), "text/html")
+
+	want := "Why links rot\n\n" +
+		"Pages move, domains lapse, and sites get redesigned, so a bookmark saved today may point nowhere next year.\n\n" +
+		"Keeping a copy, with its images and styles, means the reference survives even when the original does not.\n\n" +
+		"Read more"
+	if text != want {
+		t.Fatalf("Unexpected text:\n%s", text)
+	}
+}
+
+func TestReadable_FormatsListsAndBreaks(t *testing.T) {
+	text := Readable([]byte(`<body><main>
+		<p>First line<br>second   line, with a comma, or two, to score well</p>
+		<ul><li>one</li>
+		<li>two <b>bold</b></li></ul>
+	</main></body>`), "")
+
+	want := "First line\nsecond line, with a comma, or two, to score well\n\n- one\n- two bold"
+	if text != want {
+		t.Fatalf("Unexpected text:\n%q", text)
+	}
+}
@@ -0,0 +1,261 @@
+package archive
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// unlikely class names and IDs mark page furniture rather than content
+	unlikely = regexp.MustCompile(`(?i)comment|sidebar|footer|masthead|menu|nav|share|social|related|sponsor|advert|promo|cookie|banner|popup|modal|subscribe|newsletter|breadcrumb`)
+	likely   = regexp.MustCompile(`(?i)article|content|main|post|entry|story|body|text|prose`)
+)
+
+// minParagraph is the length below which a block is too short to count
+const minParagraph = 25
+
+// Readable extracts the main text of a page, the way browser reader modes
+// do: blocks are scored by how much prose they hold, and the text of the
+// best-scoring container is kept. Paragraphs are separated by blank lines.
+func Readable(body []byte, contentType string) string {
+	doc, err := parse(body, contentType)
+	if err != nil {
+		return ""
+	}
+	root := find(doc, atom.Body)
+	if root == nil {
+		return ""
+	}
+	prune(root)
+
+	scores := make(map[*html.Node]float64)
+	var score func(n *html.Node)
+	score = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode {
+				score(child)
+			}
+		}
+		if !isParagraph(n) {
+			return
+		}
+		text := strings.Join(strings.Fields(textContent(n)), " ")
+		if len(text) < minParagraph {
+			return
+		}
+		points := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+		if parent := n.Parent; parent != nil {
+			scores[parent] += points
+			if grandparent := parent.Parent; grandparent != nil {
+				scores[grandparent] += points / 2
+			}
+		}
+	}
+	score(root)
+
+	var best *html.Node
+	bestScore := 0.0
+	for n, points := range scores {
+		points = (points + classWeight(n)) * (1 - linkDensity(n))
+		if points > bestScore || (points == bestScore && best != nil && contains(best, n)) {
+			best, bestScore = n, points
+		}
+	}
+	if best == nil {
+		best = root
+	}
+
+	var w textWriter
+	w.node(best, false)
+	return w.String()
+}
+
+// prune removes elements that are never part of the main text
+func prune(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.ElementNode {
+			switch child.DataAtom {
+			case atom.Script, atom.Style, atom.Noscript, atom.Iframe, atom.Form, atom.Nav, atom.Header, atom.Footer,
+				atom.Aside, atom.Svg, atom.Button, atom.Select, atom.Input, atom.Template, atom.Dialog:
+				n.RemoveChild(child)
+				child = next
+				continue
+			}
+			names := attr(child, "class") + " " + attr(child, "id")
+			if child.DataAtom != atom.Body && child.DataAtom != atom.Article && child.DataAtom != atom.Main &&
+				unlikely.MatchString(names) && !likely.MatchString(names) {
+				n.RemoveChild(child)
+				child = next
+				continue
+			}
+			prune(child)
+		}
+		child = next
+	}
+}
+
+// isParagraph is true for elements that hold prose directly: paragraphs,
+// and divs used as paragraphs
+func isParagraph(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		return true
+	case atom.Div:
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode && isBlock(child.DataAtom) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func classWeight(n *html.Node) float64 {
+	weight := 0.0
+	switch n.DataAtom {
+	case atom.Article, atom.Main:
+		weight += 10
+	}
+	names := attr(n, "class") + " " + attr(n, "id")
+	if likely.MatchString(names) {
+		weight += 25
+	}
+	if unlikely.MatchString(names) {
+		weight -= 25
+	}
+	return weight
+}
+
+// linkDensity is the share of a node's text that is link text
+func linkDensity(n *html.Node) float64 {
+	total := len(strings.Join(strings.Fields(textContent(n)), " "))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	var count func(n *html.Node)
+	count = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode && child.DataAtom == atom.A {
+				links += len(strings.Join(strings.Fields(textContent(child)), " "))
+			} else {
+				count(child)
+			}
+		}
+	}
+	count(n)
+	return math.Min(float64(links)/float64(total), 1)
+}
+
+func contains(ancestor, n *html.Node) bool {
+	for ; n != nil; n = n.Parent {
+		if n == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+func isBlock(a atom.Atom) bool {
+	switch a {
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Ul, atom.Ol, atom.Li, atom.Dl, atom.Dt, atom.Dd, atom.Pre, atom.Blockquote, atom.Table, atom.Tr,
+		atom.Figure, atom.Figcaption, atom.Hr, atom.Header, atom.Footer:
+		return true
+	}
+	return false
+}
+
+// textWriter renders text with whitespace collapsed, blank lines between
+// blocks and line breaks for <br>
+type textWriter struct {
+	out    strings.Builder
+	breaks int  // newlines owed before the next text
+	space  bool // a space is owed before the next text
+}
+
+func (w *textWriter) node(n *html.Node, pre bool) {
+	switch n.Type {
+	case html.TextNode:
+		if pre {
+			w.write(n.Data)
+			return
+		}
+		words := strings.Fields(n.Data)
+		if len(words) == 0 {
+			w.space = w.space || n.Data != ""
+			return
+		}
+		if strings.TrimLeft(n.Data, " \t\r\n") != n.Data {
+			w.space = true
+		}
+		w.write(strings.Join(words, " "))
+		w.space = strings.TrimRight(n.Data, " \t\r\n") != n.Data
+		return
+	case html.ElementNode:
+		if n.DataAtom == atom.Br {
+			w.lineBreak(1)
+			return
+		}
+	default:
+		return
+	}
+
+	// List items go on their own lines, without blank lines between them
+	breaks := 0
+	if n.DataAtom == atom.Li {
+		breaks = 1
+		w.lineBreak(breaks)
+		w.write("-")
+		w.space = true
+	} else if isBlock(n.DataAtom) {
+		breaks = 2
+		w.lineBreak(breaks)
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		w.node(child, pre || n.DataAtom == atom.Pre)
+	}
+	w.lineBreak(breaks)
+}
+
+func (w *textWriter) lineBreak(n int) {
+	if n > w.breaks {
+		w.breaks = n
+	}
+}
+
+func (w *textWriter) write(text string) {
+	if text == "" {
+		return
+	}
+	if w.out.Len() > 0 {
+		if w.breaks > 0 {
+			w.out.WriteString(strings.Repeat("\n", w.breaks))
+		} else if w.space {
+			w.out.WriteByte(' ')
+		}
+	}
+	w.breaks, w.space = 0, false
+	w.out.WriteString(text)
+}
+
+func (w *textWriter) String() string {
+	return strings.TrimSpace(w.out.String())
+}
@@ -0,0 +1,367 @@
+// Package archive keeps offline copies of web pages: a self-contained HTML
+// snapshot with stylesheets and images inlined, and the page's readable
+// text for search.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+
+	"hyprlnk/internal/fetch"
+)
+
+// Limits bound what a snapshot inlines; zero values pick the defaults.
+// Resources past a limit are left as links to the original site.
+type Limits struct {
+	MaxResources     int   // fetched per page, default 100
+	MaxResourceBytes int64 // per resource, default 2MB
+	MaxTotalBytes    int64 // inlined per page, default 20MB
+}
+
+// Snapshot is a page with everything it needs to render inlined
+type Snapshot struct {
+	HTML    []byte
+	Inlined int // resources embedded as data: URIs
+	Skipped int // resources left as external links
+}
+
+// maxImportDepth bounds nested stylesheet @imports
+const maxImportDepth = 2
+
+// Take builds a snapshot of a fetched page. Scripts, frames and event
+// handlers are removed, so the snapshot is safe to serve and renders the
+// same without network access.
+func Take(ctx context.Context, fetcher *fetch.Fetcher, body []byte, base *url.URL, contentType string, limits Limits) (*Snapshot, error) {
+	if limits.MaxResources <= 0 {
+		limits.MaxResources = 100
+	}
+	if limits.MaxResourceBytes <= 0 {
+		limits.MaxResourceBytes = 2 << 20
+	}
+	if limits.MaxTotalBytes <= 0 {
+		limits.MaxTotalBytes = 20 << 20
+	}
+
+	doc, err := parse(body, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &snapshotter{
+		ctx:       ctx,
+		fetcher:   fetcher,
+		limits:    limits,
+		resources: make(map[string]string),
+		snapshot:  &Snapshot{},
+	}
+	s.walk(doc, base)
+	addCharset(doc)
+
+	// After the doctype, which must come first
+	if root := find(doc, atom.Html); root != nil {
+		doc.InsertBefore(&html.Node{
+			Type: html.CommentNode,
+			Data: fmt.Sprintf(" Archived from %s on %s ", base, time.Now().UTC().Format(time.RFC3339)),
+		}, root)
+	}
+
+	var out bytes.Buffer
+	if err := html.Render(&out, doc); err != nil {
+		return nil, err
+	}
+	s.snapshot.HTML = out.Bytes()
+	return s.snapshot, nil
+}
+
+// parse decodes the page to UTF-8 and parses it with scripting disabled,
+// so <noscript> fallbacks (often the only real images) become markup
+func parse(body []byte, contentType string) (*html.Node, error) {
+	var reader io.Reader = bytes.NewReader(body)
+	if decoded, err := charset.NewReader(reader, contentType); err == nil {
+		reader = decoded
+	}
+	return html.ParseWithOptions(reader, html.ParseOptionEnableScripting(false))
+}
+
+type snapshotter struct {
+	ctx       context.Context
+	fetcher   *fetch.Fetcher
+	limits    Limits
+	resources map[string]string // resource URL to what replaced it
+	fetched   int
+	total     int64
+	snapshot  *Snapshot
+}
+
+func (s *snapshotter) walk(n *html.Node, base *url.URL) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		switch {
+		case child.Type == html.CommentNode, child.Type == html.ElementNode && drop(child):
+			n.RemoveChild(child)
+		case child.Type == html.ElementNode:
+			s.rewrite(child, base)
+			s.walk(child, base)
+		}
+		child = next
+	}
+}
+
+// drop is true for elements that run code, load other documents or would
+// change how the snapshot is interpreted
+func drop(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Script, atom.Iframe, atom.Frame, atom.Frameset, atom.Object, atom.Embed, atom.Applet, atom.Base:
+		return true
+	case atom.Link:
+		rels := strings.Fields(strings.ToLower(attr(n, "rel")))
+		for _, rel := range rels {
+			if rel == "stylesheet" || rel == "icon" {
+				return false
+			}
+		}
+		return true
+	case atom.Meta:
+		// addCharset declares the encoding the snapshot is actually in
+		return attr(n, "charset") != "" || attr(n, "http-equiv") != ""
+	case atom.Source:
+		// <picture> falls back to its <img>, which gets inlined
+		return n.Parent != nil && n.Parent.DataAtom == atom.Picture
+	}
+	return false
+}
+
+func (s *snapshotter) rewrite(n *html.Node, base *url.URL) {
+	kept := n.Attr[:0]
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		value := strings.ToLower(strings.TrimSpace(a.Val))
+		if strings.HasPrefix(key, "on") || strings.HasPrefix(value, "javascript:") {
+			continue
+		}
+		switch key {
+		case "integrity", "nonce", "crossorigin", "srcset", "sizes", "loading":
+			continue
+		case "style":
+			a.Val = s.inlineCSS(a.Val, base, 0)
+		}
+		kept = append(kept, a)
+	}
+	n.Attr = kept
+
+	switch n.DataAtom {
+	case atom.Img:
+		src := attr(n, "src")
+		if lazy := attr(n, "data-src"); lazy != "" && (src == "" || strings.HasPrefix(src, "data:")) {
+			src = lazy
+		}
+		if src != "" {
+			setAttr(n, "src", s.resource(src, base))
+		}
+	case atom.Link:
+		href := attr(n, "href")
+		if strings.Contains(strings.ToLower(attr(n, "rel")), "stylesheet") {
+			css, cssBase, ok := s.stylesheet(href, base)
+			if !ok {
+				setAttr(n, "href", absolute(href, base))
+				return
+			}
+			// Turn the link into an equivalent <style> element
+			media := attr(n, "media")
+			n.DataAtom, n.Data, n.Attr = atom.Style, "style", nil
+			if media != "" {
+				setAttr(n, "media", media)
+			}
+			n.AppendChild(&html.Node{Type: html.TextNode, Data: s.inlineCSS(css, cssBase, 1)})
+			return
+		}
+		setAttr(n, "href", s.resource(href, base))
+	case atom.Style:
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.TextNode {
+				child.Data = s.inlineCSS(child.Data, base, 0)
+			}
+		}
+	case atom.A, atom.Area, atom.Form, atom.Video, atom.Audio, atom.Source, atom.Track:
+		// Links keep pointing at the live site; media is too large to inline
+		for _, key := range []string{"href", "action", "src", "poster"} {
+			if value := attr(n, key); value != "" {
+				setAttr(n, key, absolute(value, base))
+			}
+		}
+	case atom.Image: // inside <svg>
+		for _, key := range []string{"href", "xlink:href"} {
+			if value := attr(n, key); value != "" {
+				setAttr(n, key, s.resource(value, base))
+			}
+		}
+	}
+}
+
+// resource returns ref as a data: URI, or as an absolute link when it
+// can't be fetched or is over the limits
+func (s *snapshotter) resource(ref string, base *url.URL) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "#") {
+		return ref
+	}
+	target := absolute(ref, base)
+	if replaced, ok := s.resources[target]; ok {
+		return replaced
+	}
+
+	replaced := target
+	if data, contentType, ok := s.fetch(target); ok {
+		replaced = "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+		s.snapshot.Inlined++
+	} else {
+		s.snapshot.Skipped++
+	}
+	s.resources[target] = replaced
+	return replaced
+}
+
+// stylesheet fetches a linked or imported stylesheet and returns its text
+// and the URL its own references are relative to
+func (s *snapshotter) stylesheet(ref string, base *url.URL) (string, *url.URL, bool) {
+	target := absolute(ref, base)
+	data, _, ok := s.fetch(target)
+	if !ok {
+		s.snapshot.Skipped++
+		return "", nil, false
+	}
+	cssBase, err := url.Parse(target)
+	if err != nil {
+		return "", nil, false
+	}
+	s.snapshot.Inlined++
+	return string(data), cssBase, true
+}
+
+func (s *snapshotter) fetch(target string) ([]byte, string, bool) {
+	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		return nil, "", false
+	}
+	if s.fetched >= s.limits.MaxResources || s.total >= s.limits.MaxTotalBytes || s.ctx.Err() != nil {
+		return nil, "", false
+	}
+	s.fetched++
+
+	resp, err := s.fetcher.GetLimit(s.ctx, target, s.limits.MaxResourceBytes)
+	if err != nil || resp.StatusCode != http.StatusOK || resp.Truncated || len(resp.Body) == 0 {
+		return nil, "", false
+	}
+	if s.total+int64(len(resp.Body)) > s.limits.MaxTotalBytes {
+		return nil, "", false
+	}
+	s.total += int64(len(resp.Body))
+
+	contentType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || contentType == "application/octet-stream" {
+		contentType, _, _ = mime.ParseMediaType(http.DetectContentType(resp.Body))
+	}
+	return resp.Body, contentType, true
+}
+
+var (
+	cssImport = regexp.MustCompile(`@import\s+(?:url\(\s*)?["']?([^"')\s;]+)["']?\s*\)?([^;]*);`)
+	cssURL    = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)"'\s]*))\s*\)`)
+)
+
+// inlineCSS embeds a stylesheet's imports, images and fonts. Imports are
+// replaced by placeholders first, so their url()s resolve against their
+// own location rather than the importing sheet's.
+func (s *snapshotter) inlineCSS(css string, base *url.URL, depth int) string {
+	var imported []string
+	css = cssImport.ReplaceAllStringFunc(css, func(rule string) string {
+		match := cssImport.FindStringSubmatch(rule)
+		text := "@import url(\"" + absolute(match[1], base) + "\")" + match[2] + ";"
+		if depth < maxImportDepth {
+			if sheet, sheetBase, ok := s.stylesheet(match[1], base); ok {
+				text = s.inlineCSS(sheet, sheetBase, depth+1)
+				if media := strings.TrimSpace(match[2]); media != "" {
+					text = "@media " + media + " {\n" + text + "\n}"
+				}
+			}
+		}
+		imported = append(imported, text)
+		return fmt.Sprintf("\x00%d\x00", len(imported)-1)
+	})
+
+	css = cssURL.ReplaceAllStringFunc(css, func(ref string) string {
+		match := cssURL.FindStringSubmatch(ref)
+		target := match[1] + match[2] + match[3]
+		if target == "" {
+			return ref
+		}
+		return `url("` + s.resource(target, base) + `")`
+	})
+
+	for i, text := range imported {
+		css = strings.Replace(css, fmt.Sprintf("\x00%d\x00", i), text, 1)
+	}
+	return css
+}
+
+// addCharset declares UTF-8, which html.Render always writes
+func addCharset(doc *html.Node) {
+	head := find(doc, atom.Head)
+	if head == nil {
+		return
+	}
+	meta := &html.Node{Type: html.ElementNode, DataAtom: atom.Meta, Data: "meta"}
+	setAttr(meta, "charset", "utf-8")
+	head.InsertBefore(meta, head.FirstChild)
+}
+
+func find(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := find(child, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func absolute(ref string, base *url.URL) string {
+	parsed, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil || base == nil {
+		return ref
+	}
+	return base.ResolveReference(parsed).String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(n *html.Node, key, value string) {
+	for i, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			n.Attr[i].Val = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: value})
+}
@@ -0,0 +1,168 @@
+// Package autotag applies user-defined rules that tag bookmarks and file
+// them into collections, such as "domain matches *.atlassian.net → work".
+package autotag
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"hyprlnk/internal/models"
+)
+
+// Engine evaluates a set of rules, in order
+type Engine struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	rule       models.AutoTagRule
+	conditions []condition
+}
+
+type condition struct {
+	field string
+	match func(value string) bool
+}
+
+// New compiles the enabled rules
+func New(rules []models.AutoTagRule) (*Engine, error) {
+	engine := &Engine{}
+	for _, rule := range rules {
+		if rule.Disabled {
+			continue
+		}
+		compiled, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+	return engine, nil
+}
+
+// Validate reports what is wrong with a rule, if anything
+func Validate(rule models.AutoTagRule) error {
+	if len(rule.Tags) == 0 && rule.CollectionID == 0 {
+		return fmt.Errorf("a rule needs tags to add or a collection to file into")
+	}
+	_, err := compile(rule)
+	return err
+}
+
+func compile(rule models.AutoTagRule) (compiledRule, error) {
+	if len(rule.Conditions) == 0 {
+		return compiledRule{}, fmt.Errorf("a rule needs at least one condition")
+	}
+
+	compiled := compiledRule{rule: rule}
+	for _, c := range rule.Conditions {
+		switch c.Field {
+		case models.RuleFieldDomain, models.RuleFieldURL, models.RuleFieldTitle, models.RuleFieldDescription:
+		default:
+			return compiledRule{}, fmt.Errorf("unknown field %q (use domain, url, title or description)", c.Field)
+		}
+		if c.Value == "" {
+			return compiledRule{}, fmt.Errorf("condition on %s has no value", c.Field)
+		}
+
+		value := strings.ToLower(c.Value)
+		var match func(string) bool
+		switch c.Op {
+		case models.RuleOpContains:
+			match = func(field string) bool { return strings.Contains(field, value) }
+		case models.RuleOpEquals:
+			match = func(field string) bool { return field == value }
+		case models.RuleOpGlob:
+			pattern := regexp.MustCompile("^" + strings.ReplaceAll(strings.ReplaceAll(regexp.QuoteMeta(value), `\*`, ".*"), `\?`, ".") + "$")
+			match = pattern.MatchString
+		case models.RuleOpRegex:
+			pattern, err := regexp.Compile("(?i)" + c.Value)
+			if err != nil {
+				return compiledRule{}, fmt.Errorf("invalid regex %q: %w", c.Value, err)
+			}
+			match = pattern.MatchString
+		default:
+			return compiledRule{}, fmt.Errorf("unknown op %q (use contains, equals, glob or regex)", c.Op)
+		}
+		compiled.conditions = append(compiled.conditions, condition{field: c.Field, match: match})
+	}
+	return compiled, nil
+}
+
+// Apply adds the tags of every matching rule to bookmark, and files it into
+// the collection of the first matching rule that has one if it isn't in a
+// collection yet. It returns what changed, or nil if nothing did.
+func (e *Engine) Apply(bookmark *models.Bookmark) *models.RuleChange {
+	fields := map[string]string{
+		models.RuleFieldDomain:      domainOf(bookmark.URL),
+		models.RuleFieldURL:         strings.ToLower(bookmark.URL),
+		models.RuleFieldTitle:       strings.ToLower(bookmark.Title),
+		models.RuleFieldDescription: strings.ToLower(bookmark.Description),
+	}
+
+	var change *models.RuleChange
+	for _, rule := range e.rules {
+		if !rule.matches(fields) {
+			continue
+		}
+
+		added := addTags(&bookmark.Tags, rule.rule.Tags)
+		filed := rule.rule.CollectionID != 0 && bookmark.CollectionID == 0
+		if filed {
+			bookmark.CollectionID = rule.rule.CollectionID
+		}
+		if len(added) == 0 && !filed {
+			continue
+		}
+
+		if change == nil {
+			change = &models.RuleChange{BookmarkID: bookmark.ID, URL: bookmark.URL, Title: bookmark.Title}
+		}
+		change.AddedTags = append(change.AddedTags, added...)
+		if filed {
+			change.CollectionID = bookmark.CollectionID
+		}
+		change.RuleIDs = append(change.RuleIDs, rule.rule.ID)
+	}
+	return change
+}
+
+func (r compiledRule) matches(fields map[string]string) bool {
+	for _, c := range r.conditions {
+		if !c.match(fields[c.field]) {
+			return false
+		}
+	}
+	return true
+}
+
+// addTags appends the tags bookmark doesn't have yet, comparing
+// case-insensitively, and returns them
+func addTags(tags *[]string, extra []string) []string {
+	have := make(map[string]bool, len(*tags))
+	for _, tag := range *tags {
+		have[strings.ToLower(strings.TrimSpace(tag))] = true
+	}
+
+	var added []string
+	for _, tag := range extra {
+		key := strings.ToLower(strings.TrimSpace(tag))
+		if key == "" || have[key] {
+			continue
+		}
+		have[key] = true
+		*tags = append(*tags, tag)
+		added = append(added, tag)
+	}
+	return added
+}
+
+func domainOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
@@ -0,0 +1,137 @@
+package autotag
+
+import (
+	"reflect"
+	"testing"
+
+	"hyprlnk/internal/models"
+)
+
+func rule(id int64, field, op, value string, tags ...string) models.AutoTagRule {
+	return models.AutoTagRule{
+		ID:         id,
+		Name:       value,
+		Conditions: []models.RuleCondition{{Field: field, Op: op, Value: value}},
+		Tags:       tags,
+	}
+}
+
+func TestApply_Ops(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  models.AutoTagRule
+		url   string
+		title string
+		want  bool
+	}{
+		{"glob on domain", rule(1, models.RuleFieldDomain, models.RuleOpGlob, "*.atlassian.net"), "https://acme.atlassian.net/wiki", "", true},
+		{"glob is anchored", rule(1, models.RuleFieldDomain, models.RuleOpGlob, "*.atlassian.net"), "https://atlassian.net.evil.com/", "", false},
+		{"glob needs the dot", rule(1, models.RuleFieldDomain, models.RuleOpGlob, "*.atlassian.net"), "https://atlassian.net/", "", false},
+		{"glob single character", rule(1, models.RuleFieldDomain, models.RuleOpGlob, "go?.dev"), "https://gox.dev/", "", true},
+		{"glob treats dots literally", rule(1, models.RuleFieldDomain, models.RuleOpGlob, "go.dev"), "https://goxdev/", "", false},
+		{"regex ignores case", rule(1, models.RuleFieldTitle, models.RuleOpRegex, `^issue #\d+`), "https://example.com", "ISSUE #42: crash", true},
+		{"regex is unanchored", rule(1, models.RuleFieldURL, models.RuleOpRegex, `/pull/\d+`), "https://github.com/a/b/pull/7/files", "", true},
+		{"regex no match", rule(1, models.RuleFieldURL, models.RuleOpRegex, `/pull/\d+`), "https://github.com/a/b/issues/7", "", false},
+		{"equals ignores case", rule(1, models.RuleFieldDomain, models.RuleOpEquals, "GitHub.com"), "https://github.com/golang/go", "", true},
+		{"equals is exact", rule(1, models.RuleFieldDomain, models.RuleOpEquals, "github.com"), "https://gist.github.com/x", "", false},
+		{"contains", rule(1, models.RuleFieldTitle, models.RuleOpContains, "Recipe"), "https://example.com", "Bread recipe", true},
+		{"domain drops the port", rule(1, models.RuleFieldDomain, models.RuleOpEquals, "localhost"), "http://localhost:8080/app", "", true},
+		{"domain is only the host", rule(1, models.RuleFieldDomain, models.RuleOpContains, "wiki"), "https://example.com/wiki", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Tags = []string{"matched"}
+			engine, err := New([]models.AutoTagRule{tt.rule})
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			bookmark := models.Bookmark{URL: tt.url, Title: tt.title, Tags: []string{}}
+			change := engine.Apply(&bookmark)
+			if got := change != nil; got != tt.want {
+				t.Errorf("Expected match %v, got change %+v", tt.want, change)
+			}
+		})
+	}
+}
+
+func TestApply_TagsAndChange(t *testing.T) {
+	engine, err := New([]models.AutoTagRule{
+		rule(1, models.RuleFieldDomain, models.RuleOpEquals, "github.com", "Code", "dev"),
+		rule(2, models.RuleFieldURL, models.RuleOpContains, "/golang/", "go", "DEV"),
+		{ID: 3, Name: "off", Disabled: true, Conditions: []models.RuleCondition{{Field: models.RuleFieldDomain, Op: models.RuleOpEquals, Value: "github.com"}}, Tags: []string{"never"}},
+		// Every condition must match
+		{ID: 4, Name: "both", Conditions: []models.RuleCondition{
+			{Field: models.RuleFieldDomain, Op: models.RuleOpEquals, Value: "github.com"},
+			{Field: models.RuleFieldTitle, Op: models.RuleOpContains, Value: "rust"},
+		}, Tags: []string{"rust"}},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	bookmark := models.Bookmark{ID: 7, URL: "https://github.com/golang/go", Title: "Go", Tags: []string{"code"}}
+	change := engine.Apply(&bookmark)
+	if change == nil {
+		t.Fatal("Expected a change")
+	}
+	// Tags already there are compared case-insensitively and not added twice
+	if want := []string{"code", "dev", "go"}; !reflect.DeepEqual(bookmark.Tags, want) {
+		t.Errorf("Expected tags %v, got %v", want, bookmark.Tags)
+	}
+	if !reflect.DeepEqual(change.AddedTags, []string{"dev", "go"}) || !reflect.DeepEqual(change.RuleIDs, []int64{1, 2}) || change.BookmarkID != 7 {
+		t.Errorf("Unexpected change %+v", change)
+	}
+
+	// Applying again finds nothing to add
+	if change := engine.Apply(&bookmark); change != nil {
+		t.Errorf("Expected no change the second time, got %+v", change)
+	}
+}
+
+func TestApply_Collection(t *testing.T) {
+	filing := rule(1, models.RuleFieldDomain, models.RuleOpGlob, "*.atlassian.net")
+	filing.CollectionID = 100
+	second := rule(2, models.RuleFieldDomain, models.RuleOpGlob, "*.net")
+	second.CollectionID = 200
+	engine, err := New([]models.AutoTagRule{filing, second})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	unfiled := models.Bookmark{URL: "https://acme.atlassian.net/"}
+	change := engine.Apply(&unfiled)
+	if unfiled.CollectionID != 100 || change == nil || change.CollectionID != 100 {
+		t.Errorf("Expected the first matching rule to file the bookmark into 100, got %d (%+v)", unfiled.CollectionID, change)
+	}
+
+	filed := models.Bookmark{URL: "https://acme.atlassian.net/", CollectionID: 5}
+	if change := engine.Apply(&filed); filed.CollectionID != 5 || change != nil {
+		t.Errorf("Expected a bookmark already in a collection to stay put, got %d (%+v)", filed.CollectionID, change)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    models.AutoTagRule
+		wantErr bool
+	}{
+		{"valid", rule(1, models.RuleFieldDomain, models.RuleOpGlob, "*.example.com", "work"), false},
+		{"collection only", models.AutoTagRule{Conditions: []models.RuleCondition{{Field: models.RuleFieldURL, Op: models.RuleOpContains, Value: "x"}}, CollectionID: 1}, false},
+		{"nothing to do", rule(1, models.RuleFieldDomain, models.RuleOpEquals, "example.com"), true},
+		{"no conditions", models.AutoTagRule{Tags: []string{"x"}}, true},
+		{"unknown field", rule(1, "path", models.RuleOpEquals, "x", "tag"), true},
+		{"unknown op", rule(1, models.RuleFieldURL, "startswith", "x", "tag"), true},
+		{"empty value", rule(1, models.RuleFieldURL, models.RuleOpContains, "", "tag"), true},
+		{"bad regex", rule(1, models.RuleFieldURL, models.RuleOpRegex, "(", "tag"), true},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.rule); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate returned %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if _, err := New([]models.AutoTagRule{rule(1, models.RuleFieldURL, models.RuleOpRegex, "(", "tag")}); err == nil {
+		t.Error("Expected New to reject an invalid rule")
+	}
+}
@@ -0,0 +1,175 @@
+// Package browserdb reads browsing data straight from browser profile files:
+// Chrome's History database and Bookmarks file, and Firefox's places.sqlite.
+// Point it at a copy; browsers keep their live databases locked.
+package browserdb
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // pure Go, so builds keep working with CGO_ENABLED=0
+
+	"hyprlnk/internal/models"
+)
+
+const (
+	BrowserChrome  = "chrome"
+	BrowserFirefox = "firefox"
+)
+
+// Click types for imported visits, alongside the extension's
+// external_link, internal_link and form_submit
+const (
+	VisitTyped    = "typed"
+	VisitBookmark = "bookmark"
+	VisitRedirect = "redirect"
+	VisitReload   = "reload"
+	VisitOther    = "other"
+
+	// visitLink marks a followed link until visitsToClicks knows whether
+	// it stayed on the same host
+	visitLink = "link"
+)
+
+// Data is everything read from one profile file. History has one entry per
+// URL; Visits has one LinkClick per page visit, with the referring page as
+// the source URL when the browser recorded one and the browser as Source.
+type Data struct {
+	Browser   string                    `json:"browser"`
+	Bookmarks []models.ImportedBookmark `json:"bookmarks"`
+	History   []models.HistoryEntry     `json:"history"`
+	Visits    []models.LinkClick        `json:"visits"`
+}
+
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// ReadFile detects what kind of profile file path is and reads it
+func ReadFile(path string) (*Data, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, len(sqliteHeader))
+	n, err := io.ReadFull(file, header)
+	file.Close()
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read file header: %w", err)
+	}
+
+	if n == len(sqliteHeader) && bytes.Equal(header, sqliteHeader) {
+		return readDatabase(path)
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(header[:n]), []byte("{")) {
+		return readChromeBookmarks(path)
+	}
+	return nil, fmt.Errorf("not a browser database or Chrome Bookmarks file")
+}
+
+func readDatabase(path string) (*Data, error) {
+	db, err := sql.Open("sqlite", "file:"+url.PathEscape(path)+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	switch {
+	case hasTable(db, "moz_places"):
+		return readFirefox(db)
+	case hasTable(db, "urls") && hasTable(db, "visits"):
+		return readChrome(db)
+	default:
+		return nil, fmt.Errorf("not a Chrome History or Firefox places.sqlite database")
+	}
+}
+
+func hasTable(db *sql.DB, name string) bool {
+	var found string
+	err := db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&found)
+	return err == nil
+}
+
+// chromeEpochOffset is the number of seconds between 1601-01-01, the
+// Windows epoch Chrome counts from, and 1970-01-01
+const chromeEpochOffset = 11644473600
+
+// chromeTime converts Chrome's microseconds since 1601-01-01 UTC
+func chromeTime(micros int64) time.Time {
+	if micros <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(micros - chromeEpochOffset*1e6).UTC()
+}
+
+// firefoxTime converts Firefox's microseconds since the Unix epoch
+func firefoxTime(micros int64) time.Time {
+	if micros <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(micros).UTC()
+}
+
+// importable reports whether a URL is a web page worth keeping, as opposed
+// to browser-internal pages, place: queries or bookmarklets
+func importable(rawURL string) bool {
+	scheme, _, ok := strings.Cut(rawURL, ":")
+	if !ok {
+		return false
+	}
+	scheme = strings.ToLower(scheme)
+	return scheme == "http" || scheme == "https"
+}
+
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// visit is a page visit before it's resolved into a LinkClick
+type visit struct {
+	url       string
+	title     string
+	time      time.Time
+	fromVisit int64
+	clickType string
+}
+
+// visitsToClicks resolves each visit's referrer and returns the visits as
+// LinkClicks from browser, oldest first. Link visits become internal_link
+// or external_link depending on whether they stayed on the referrer's host.
+func visitsToClicks(browser string, visits map[int64]visit, order []int64) []models.LinkClick {
+	clicks := make([]models.LinkClick, 0, len(order))
+	for _, id := range order {
+		v := visits[id]
+		click := models.LinkClick{
+			DestinationURL:   v.url,
+			DestinationTitle: v.title,
+			ClickType:        v.clickType,
+			Source:           browser,
+			Timestamp:        v.time,
+		}
+
+		if source, ok := visits[v.fromVisit]; ok && v.fromVisit != 0 {
+			click.SourceURL = source.url
+			click.SourceTitle = source.title
+			click.Domain = hostOf(source.url)
+		}
+
+		if click.ClickType == visitLink {
+			click.ClickType = "external_link"
+			if click.Domain != "" && click.Domain == hostOf(v.url) {
+				click.ClickType = "internal_link"
+			}
+		}
+		clicks = append(clicks, click)
+	}
+	return clicks
+}
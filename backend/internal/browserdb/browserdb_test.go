@@ -0,0 +1,227 @@
+package browserdb
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newFixtureDB creates a SQLite database from the given statements and
+// returns its path. The directory name has a space and a question mark in
+// it, which ReadFile has to escape when it opens the database.
+func newFixtureDB(t *testing.T, name string, statements ...string) string {
+	t.Helper()
+	tmp := t.TempDir()
+	built := filepath.Join(tmp, "fixture.sqlite")
+	db, err := sql.Open("sqlite", built)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(tmp, "Application Support?copy")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.Rename(built, path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestChromeTime(t *testing.T) {
+	if got := chromeTime(0); !got.IsZero() {
+		t.Errorf("chromeTime(0) = %v, want zero time", got)
+	}
+	want := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := chromeTime((want.Unix() + chromeEpochOffset) * 1e6); !got.Equal(want) {
+		t.Errorf("chromeTime = %v, want %v", got, want)
+	}
+}
+
+func TestFirefoxTime(t *testing.T) {
+	if got := firefoxTime(0); !got.IsZero() {
+		t.Errorf("firefoxTime(0) = %v, want zero time", got)
+	}
+	want := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := firefoxTime(want.UnixMicro()); !got.Equal(want) {
+		t.Errorf("firefoxTime = %v, want %v", got, want)
+	}
+}
+
+func TestReadFile_Chrome(t *testing.T) {
+	base := (time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Unix() + chromeEpochOffset) * 1e6
+	path := newFixtureDB(t, "History",
+		`CREATE TABLE urls (id INTEGER PRIMARY KEY, url TEXT, title TEXT, visit_count INTEGER, last_visit_time INTEGER)`,
+		`CREATE TABLE visits (id INTEGER PRIMARY KEY, url INTEGER, visit_time INTEGER, from_visit INTEGER, transition INTEGER)`,
+		`INSERT INTO urls VALUES
+			(1, 'https://example.com/', 'Example', 3, 13253760003000000),
+			(2, 'https://example.com/about', 'About', 1, 0),
+			(3, 'https://other.org/', 'Other', 1, 0),
+			(4, 'chrome://settings/', 'Settings', 1, 0),
+			(5, 'https://ads.example.net/frame', 'Ad', 1, 0)`,
+		// Typed with a redirect qualifier in the high bits, then a link on
+		// the same host, a link off it, a subframe and a browser page
+		`INSERT INTO visits VALUES
+			(1, 1, `+itoa(base+1)+`, 0, `+itoa(chromeTyped|0x10000000)+`),
+			(2, 2, `+itoa(base+2)+`, 1, `+itoa(chromeLink)+`),
+			(3, 3, `+itoa(base+3)+`, 2, `+itoa(chromeLink)+`),
+			(4, 5, `+itoa(base+4)+`, 3, `+itoa(chromeAutoSubframe)+`),
+			(5, 4, `+itoa(base+5)+`, 0, `+itoa(chromeTyped)+`)`,
+	)
+
+	data, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if data.Browser != BrowserChrome {
+		t.Errorf("Browser = %q, want %q", data.Browser, BrowserChrome)
+	}
+	if len(data.History) != 1 || data.History[0].URL != "https://example.com/" || data.History[0].VisitCount != 3 {
+		t.Errorf("History = %+v, want only the visited example.com page", data.History)
+	}
+
+	wantTypes := []string{VisitTyped, "internal_link", "external_link"}
+	if len(data.Visits) != len(wantTypes) {
+		t.Fatalf("got %d visits, want %d: %+v", len(data.Visits), len(wantTypes), data.Visits)
+	}
+	for i, click := range data.Visits {
+		if click.ClickType != wantTypes[i] {
+			t.Errorf("visit %d ClickType = %q, want %q", i, click.ClickType, wantTypes[i])
+		}
+		if click.Source != BrowserChrome {
+			t.Errorf("visit %d Source = %q, want %q", i, click.Source, BrowserChrome)
+		}
+	}
+	if got := data.Visits[2]; got.SourceURL != "https://example.com/about" || got.Domain != "example.com" {
+		t.Errorf("external link source = %q on %q, want the about page", got.SourceURL, got.Domain)
+	}
+	if got := data.Visits[0].Timestamp; !got.Equal(chromeTime(base + 1)) {
+		t.Errorf("visit timestamp = %v, want %v", got, chromeTime(base+1))
+	}
+}
+
+func TestChromeClickType(t *testing.T) {
+	tests := []struct {
+		transition int64
+		want       string
+		keep       bool
+	}{
+		{chromeLink, visitLink, true},
+		{chromeTyped, VisitTyped, true},
+		{chromeKeyword, VisitTyped, true},
+		{chromeAutoBookmark, VisitBookmark, true},
+		{chromeFormSubmit, "form_submit", true},
+		{chromeReload, VisitReload, true},
+		{chromeAutoToplevel, VisitOther, true},
+		{chromeAutoSubframe, "", false},
+		{chromeManualSubframe | 0x20000000, "", false},
+	}
+	for _, tt := range tests {
+		got, keep := chromeClickType(tt.transition)
+		if got != tt.want || keep != tt.keep {
+			t.Errorf("chromeClickType(%#x) = %q, %v, want %q, %v", tt.transition, got, keep, tt.want, tt.keep)
+		}
+	}
+}
+
+func TestReadFile_Firefox(t *testing.T) {
+	base := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).UnixMicro()
+	path := newFixtureDB(t, "places.sqlite",
+		`CREATE TABLE moz_places (id INTEGER PRIMARY KEY, url TEXT, title TEXT, visit_count INTEGER, last_visit_date INTEGER)`,
+		`CREATE TABLE moz_historyvisits (id INTEGER PRIMARY KEY, place_id INTEGER, visit_date INTEGER, from_visit INTEGER, visit_type INTEGER)`,
+		`CREATE TABLE moz_bookmarks (id INTEGER PRIMARY KEY, type INTEGER, fk INTEGER, parent INTEGER, position INTEGER, title TEXT, dateAdded INTEGER, guid TEXT)`,
+		`INSERT INTO moz_places VALUES
+			(1, 'https://example.com/', 'Example', 2, `+itoa(base+2)+`),
+			(2, 'https://go.dev/', NULL, 1, `+itoa(base+3)+`),
+			(3, 'place:tag=go', NULL, 0, NULL),
+			(4, 'https://example.com/file.zip', NULL, 1, `+itoa(base+4)+`)`,
+		`INSERT INTO moz_historyvisits VALUES
+			(1, 1, `+itoa(base+1)+`, 0, `+itoa(firefoxTyped)+`),
+			(2, 2, `+itoa(base+2)+`, 1, `+itoa(firefoxLink)+`),
+			(3, 4, `+itoa(base+3)+`, 1, `+itoa(firefoxDownload)+`),
+			(4, 1, `+itoa(base+4)+`, 0, `+itoa(firefoxRedirectTemporary)+`)`,
+		`INSERT INTO moz_bookmarks VALUES
+			(1, 2, NULL, 0, 0, '', 0, 'root________'),
+			(2, 2, NULL, 1, 0, 'menu', 0, 'menu________'),
+			(3, 2, NULL, 1, 1, 'tags', 0, 'tags________'),
+			(4, 2, NULL, 2, 0, 'Dev', 0, 'folder_dev__'),
+			(5, 1, 2, 4, 0, 'The Go site', `+itoa(base)+`, 'bookmark_go_'),
+			(6, 1, 1, 2, 0, 'Example', `+itoa(base)+`, 'bookmark_ex_'),
+			(7, 1, 3, 4, 1, 'Go tag query', 0, 'bookmark_q__'),
+			(8, 2, NULL, 3, 0, 'golang', 0, 'tag_golang__'),
+			(9, 1, 2, 8, 0, NULL, 0, 'tagged_go___'),
+			(10, 2, NULL, 3, 1, 'lang', 0, 'tag_lang____'),
+			(11, 1, 2, 10, 0, NULL, 0, 'tagged_go2__')`,
+	)
+
+	data, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if data.Browser != BrowserFirefox {
+		t.Errorf("Browser = %q, want %q", data.Browser, BrowserFirefox)
+	}
+	if len(data.History) != 3 {
+		t.Errorf("got %d history entries, want 3 (place: queries skipped): %+v", len(data.History), data.History)
+	}
+
+	wantTypes := []string{VisitTyped, "external_link", VisitRedirect}
+	if len(data.Visits) != len(wantTypes) {
+		t.Fatalf("got %d visits, want %d: %+v", len(data.Visits), len(wantTypes), data.Visits)
+	}
+	for i, click := range data.Visits {
+		if click.ClickType != wantTypes[i] {
+			t.Errorf("visit %d ClickType = %q, want %q", i, click.ClickType, wantTypes[i])
+		}
+		if click.Source != BrowserFirefox {
+			t.Errorf("visit %d Source = %q, want %q", i, click.Source, BrowserFirefox)
+		}
+	}
+
+	// Tag folders become tags on the bookmark, not bookmarks of their own
+	if len(data.Bookmarks) != 2 {
+		t.Fatalf("got %d bookmarks, want 2: %+v", len(data.Bookmarks), data.Bookmarks)
+	}
+	byURL := make(map[string]int)
+	for i, bookmark := range data.Bookmarks {
+		byURL[bookmark.URL] = i
+	}
+	goBookmark := data.Bookmarks[byURL["https://go.dev/"]]
+	if !slices.Equal(goBookmark.FolderPath, []string{"Bookmarks Menu", "Dev"}) {
+		t.Errorf("FolderPath = %v, want [Bookmarks Menu Dev]", goBookmark.FolderPath)
+	}
+	if !slices.Equal(goBookmark.Tags, []string{"golang", "lang"}) {
+		t.Errorf("Tags = %v, want [golang lang]", goBookmark.Tags)
+	}
+	if !goBookmark.AddedDate.Equal(firefoxTime(base)) {
+		t.Errorf("AddedDate = %v, want %v", goBookmark.AddedDate, firefoxTime(base))
+	}
+	if example := data.Bookmarks[byURL["https://example.com/"]]; len(example.Tags) != 0 {
+		t.Errorf("untagged bookmark got tags %v", example.Tags)
+	}
+}
+
+func TestReadFile_NotABrowserDatabase(t *testing.T) {
+	path := newFixtureDB(t, "other.sqlite", `CREATE TABLE notes (id INTEGER PRIMARY KEY)`)
+	if _, err := ReadFile(path); err == nil {
+		t.Error("ReadFile accepted a database that is neither Chrome nor Firefox")
+	}
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
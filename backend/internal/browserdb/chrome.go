@@ -0,0 +1,179 @@
+package browserdb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"hyprlnk/internal/models"
+)
+
+// Chrome page transition core types, the low byte of visits.transition
+const (
+	chromeLink             = 0
+	chromeTyped            = 1
+	chromeAutoBookmark     = 2
+	chromeAutoSubframe     = 3
+	chromeManualSubframe   = 4
+	chromeGenerated        = 5
+	chromeAutoToplevel     = 6
+	chromeFormSubmit       = 7
+	chromeReload           = 8
+	chromeKeyword          = 9
+	chromeKeywordGenerated = 10
+
+	// The high bits are qualifiers such as redirect chain markers
+	chromeCoreMask = 0xFF
+)
+
+// readChrome reads a Chrome (or Edge, Brave, Vivaldi...) History database.
+// Bookmarks live in the separate Bookmarks JSON file.
+func readChrome(db *sql.DB) (*Data, error) {
+	data := &Data{Browser: BrowserChrome}
+
+	rows, err := db.Query(`SELECT id, url, title, visit_count, last_visit_time FROM urls`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read urls: %w", err)
+	}
+	defer rows.Close()
+
+	type page struct{ url, title string }
+	pages := make(map[int64]page)
+	for rows.Next() {
+		var id, visitCount, lastVisit int64
+		var rawURL, title string
+		if err := rows.Scan(&id, &rawURL, &title, &visitCount, &lastVisit); err != nil {
+			return nil, fmt.Errorf("failed to read urls: %w", err)
+		}
+		if !importable(rawURL) {
+			continue
+		}
+		pages[id] = page{url: rawURL, title: title}
+		if lastVisit > 0 {
+			data.History = append(data.History, models.HistoryEntry{
+				URL:           rawURL,
+				Title:         title,
+				VisitCount:    int(visitCount),
+				LastVisitTime: chromeTime(lastVisit),
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read urls: %w", err)
+	}
+
+	visitRows, err := db.Query(`SELECT id, url, visit_time, from_visit, transition FROM visits ORDER BY visit_time, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read visits: %w", err)
+	}
+	defer visitRows.Close()
+
+	visits := make(map[int64]visit)
+	var order []int64
+	for visitRows.Next() {
+		var id, urlID, visitTime, fromVisit, transition int64
+		if err := visitRows.Scan(&id, &urlID, &visitTime, &fromVisit, &transition); err != nil {
+			return nil, fmt.Errorf("failed to read visits: %w", err)
+		}
+		p, ok := pages[urlID]
+		if !ok {
+			continue
+		}
+		clickType, keep := chromeClickType(transition)
+		if !keep {
+			continue
+		}
+		visits[id] = visit{url: p.url, title: p.title, time: chromeTime(visitTime), fromVisit: fromVisit, clickType: clickType}
+		order = append(order, id)
+	}
+	if err := visitRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read visits: %w", err)
+	}
+
+	data.Visits = visitsToClicks(BrowserChrome, visits, order)
+	return data, nil
+}
+
+// chromeClickType maps a Chrome transition onto a click type. Subframe
+// navigations are page furniture, not visits, so they are dropped.
+func chromeClickType(transition int64) (string, bool) {
+	switch transition & chromeCoreMask {
+	case chromeLink:
+		return visitLink, true
+	case chromeTyped, chromeGenerated, chromeKeyword, chromeKeywordGenerated:
+		return VisitTyped, true
+	case chromeAutoBookmark:
+		return VisitBookmark, true
+	case chromeFormSubmit:
+		return "form_submit", true
+	case chromeReload:
+		return VisitReload, true
+	case chromeAutoSubframe, chromeManualSubframe:
+		return "", false
+	default:
+		return VisitOther, true
+	}
+}
+
+// chromeBookmarkNode is a node of Chrome's Bookmarks JSON file
+type chromeBookmarkNode struct {
+	Type      string               `json:"type"` // "url" or "folder"
+	Name      string               `json:"name"`
+	URL       string               `json:"url"`
+	DateAdded string               `json:"date_added"` // microseconds since 1601, as a string
+	Children  []chromeBookmarkNode `json:"children"`
+}
+
+// readChromeBookmarks reads the Bookmarks file next to History in a Chrome
+// profile directory
+func readChromeBookmarks(path string) (*Data, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Roots map[string]json.RawMessage `json:"roots"`
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse Chrome Bookmarks file: %w", err)
+	}
+
+	data := &Data{Browser: BrowserChrome}
+	// Fixed order, so collections are created the way Chrome shows them
+	for _, name := range []string{"bookmark_bar", "other", "synced"} {
+		raw, ok := file.Roots[name]
+		if !ok {
+			continue
+		}
+		var root chromeBookmarkNode
+		if err := json.Unmarshal(raw, &root); err != nil {
+			return nil, fmt.Errorf("failed to parse Chrome Bookmarks root %q: %w", name, err)
+		}
+		data.Bookmarks = appendChromeBookmarks(data.Bookmarks, root, []string{root.Name})
+	}
+	return data, nil
+}
+
+func appendChromeBookmarks(bookmarks []models.ImportedBookmark, node chromeBookmarkNode, path []string) []models.ImportedBookmark {
+	for _, child := range node.Children {
+		switch child.Type {
+		case "url":
+			if !importable(child.URL) {
+				continue
+			}
+			micros, _ := strconv.ParseInt(child.DateAdded, 10, 64)
+			bookmarks = append(bookmarks, models.ImportedBookmark{
+				URL:        child.URL,
+				Title:      child.Name,
+				FolderPath: append([]string(nil), path...),
+				AddedDate:  chromeTime(micros),
+			})
+		case "folder":
+			bookmarks = appendChromeBookmarks(bookmarks, child, append(path, child.Name))
+		}
+	}
+	return bookmarks
+}
@@ -0,0 +1,222 @@
+package browserdb
+
+import (
+	"database/sql"
+	"fmt"
+
+	"hyprlnk/internal/models"
+)
+
+// Firefox visit types from moz_historyvisits.visit_type
+const (
+	firefoxLink              = 1
+	firefoxTyped             = 2
+	firefoxBookmark          = 3
+	firefoxEmbed             = 4
+	firefoxRedirectPermanent = 5
+	firefoxRedirectTemporary = 6
+	firefoxDownload          = 7
+	firefoxFramedLink        = 8
+	firefoxReload            = 9
+)
+
+// firefoxBookmarkItem is the moz_bookmarks type of a bookmark, as opposed
+// to a folder or separator
+const firefoxBookmarkItem = 1
+
+// Folder names for Firefox's built-in bookmark roots, keyed by their fixed GUIDs
+var firefoxRootNames = map[string]string{
+	"menu________": "Bookmarks Menu",
+	"toolbar_____": "Bookmarks Toolbar",
+	"unfiled_____": "Other Bookmarks",
+	"mobile______": "Mobile Bookmarks",
+}
+
+const (
+	firefoxRootGUID = "root________"
+	firefoxTagsGUID = "tags________"
+)
+
+// readFirefox reads a Firefox places.sqlite database
+func readFirefox(db *sql.DB) (*Data, error) {
+	data := &Data{Browser: BrowserFirefox}
+
+	rows, err := db.Query(`SELECT id, url, COALESCE(title, ''), visit_count, COALESCE(last_visit_date, 0) FROM moz_places`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read moz_places: %w", err)
+	}
+	defer rows.Close()
+
+	type page struct{ url, title string }
+	pages := make(map[int64]page)
+	for rows.Next() {
+		var id, visitCount, lastVisit int64
+		var rawURL, title string
+		if err := rows.Scan(&id, &rawURL, &title, &visitCount, &lastVisit); err != nil {
+			return nil, fmt.Errorf("failed to read moz_places: %w", err)
+		}
+		if !importable(rawURL) {
+			continue
+		}
+		pages[id] = page{url: rawURL, title: title}
+		if lastVisit > 0 {
+			data.History = append(data.History, models.HistoryEntry{
+				URL:           rawURL,
+				Title:         title,
+				VisitCount:    int(visitCount),
+				LastVisitTime: firefoxTime(lastVisit),
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read moz_places: %w", err)
+	}
+
+	visitRows, err := db.Query(`SELECT id, place_id, visit_date, from_visit, visit_type FROM moz_historyvisits ORDER BY visit_date, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read moz_historyvisits: %w", err)
+	}
+	defer visitRows.Close()
+
+	visits := make(map[int64]visit)
+	var order []int64
+	for visitRows.Next() {
+		var id, placeID, visitDate, fromVisit, visitType int64
+		if err := visitRows.Scan(&id, &placeID, &visitDate, &fromVisit, &visitType); err != nil {
+			return nil, fmt.Errorf("failed to read moz_historyvisits: %w", err)
+		}
+		p, ok := pages[placeID]
+		if !ok {
+			continue
+		}
+		clickType, keep := firefoxClickType(visitType)
+		if !keep {
+			continue
+		}
+		visits[id] = visit{url: p.url, title: p.title, time: firefoxTime(visitDate), fromVisit: fromVisit, clickType: clickType}
+		order = append(order, id)
+	}
+	if err := visitRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read moz_historyvisits: %w", err)
+	}
+	data.Visits = visitsToClicks(BrowserFirefox, visits, order)
+
+	bookmarks, err := readFirefoxBookmarks(db)
+	if err != nil {
+		return nil, err
+	}
+	data.Bookmarks = bookmarks
+	return data, nil
+}
+
+// firefoxClickType maps a Firefox visit type onto a click type. Embedded
+// and framed loads aren't page visits and downloads aren't pages.
+func firefoxClickType(visitType int64) (string, bool) {
+	switch visitType {
+	case firefoxLink:
+		return visitLink, true
+	case firefoxTyped:
+		return VisitTyped, true
+	case firefoxBookmark:
+		return VisitBookmark, true
+	case firefoxRedirectPermanent, firefoxRedirectTemporary:
+		return VisitRedirect, true
+	case firefoxReload:
+		return VisitReload, true
+	case firefoxEmbed, firefoxFramedLink, firefoxDownload:
+		return "", false
+	default:
+		return VisitOther, true
+	}
+}
+
+// readFirefoxBookmarks rebuilds folder paths from moz_bookmarks. Firefox
+// stores tags as folders under the tags root holding one bookmark per
+// tagged place, so those become Tags rather than bookmarks.
+func readFirefoxBookmarks(db *sql.DB) ([]models.ImportedBookmark, error) {
+	rows, err := db.Query(`
+		SELECT b.id, b.type, COALESCE(b.fk, 0), COALESCE(b.parent, 0), COALESCE(b.title, ''),
+		       COALESCE(b.dateAdded, 0), COALESCE(b.guid, ''), COALESCE(p.url, '')
+		FROM moz_bookmarks b
+		LEFT JOIN moz_places p ON p.id = b.fk
+		ORDER BY b.parent, b.position`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read moz_bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	type item struct {
+		itemType  int64
+		placeID   int64
+		parent    int64
+		title     string
+		dateAdded int64
+		guid      string
+		url       string
+	}
+	items := make(map[int64]item)
+	var order []int64
+	for rows.Next() {
+		var id int64
+		var it item
+		if err := rows.Scan(&id, &it.itemType, &it.placeID, &it.parent, &it.title, &it.dateAdded, &it.guid, &it.url); err != nil {
+			return nil, fmt.Errorf("failed to read moz_bookmarks: %w", err)
+		}
+		items[id] = it
+		order = append(order, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read moz_bookmarks: %w", err)
+	}
+
+	var tagsRoot int64 = -1
+	for id, it := range items {
+		if it.guid == firefoxTagsGUID {
+			tagsRoot = id
+		}
+	}
+
+	// folderPath walks up to the root, naming built-in roots readably
+	var folderPath func(id int64) []string
+	folderPath = func(id int64) []string {
+		folder, ok := items[id]
+		if !ok || folder.guid == firefoxRootGUID {
+			return nil
+		}
+		name := folder.title
+		if rootName, ok := firefoxRootNames[folder.guid]; ok {
+			name = rootName
+		}
+		return append(folderPath(folder.parent), name)
+	}
+
+	tagsByPlace := make(map[int64][]string)
+	var bookmarks []models.ImportedBookmark
+	var bookmarkPlaces []int64
+	for _, id := range order {
+		it := items[id]
+		if it.itemType != firefoxBookmarkItem || !importable(it.url) {
+			continue
+		}
+		if parent, ok := items[it.parent]; ok && parent.parent == tagsRoot {
+			tagsByPlace[it.placeID] = append(tagsByPlace[it.placeID], parent.title)
+			continue
+		}
+		if it.parent == tagsRoot {
+			continue
+		}
+
+		bookmarks = append(bookmarks, models.ImportedBookmark{
+			URL:        it.url,
+			Title:      it.title,
+			FolderPath: folderPath(it.parent),
+			AddedDate:  firefoxTime(it.dateAdded),
+		})
+		bookmarkPlaces = append(bookmarkPlaces, it.placeID)
+	}
+
+	for i, placeID := range bookmarkPlaces {
+		bookmarks[i].Tags = tagsByPlace[placeID]
+	}
+	return bookmarks, nil
+}
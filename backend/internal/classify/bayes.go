@@ -0,0 +1,280 @@
+package classify
+
+import (
+	"context"
+	"crypto/sha256"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"hyprlnk/internal/models"
+)
+
+// Bayes suggests tags with a multinomial naive Bayes model trained on the
+// tags already on bookmarks. It needs no external service. Before each use
+// it catches up with the bookmarks source, training on new and changed
+// bookmarks and forgetting deleted ones, so it never retrains from scratch.
+type Bayes struct {
+	source func() ([]models.Bookmark, error)
+
+	mutex     sync.Mutex
+	trained   map[int64]trainedBookmark
+	tagDocs   map[string]int            // bookmarks per tag
+	tagTokens map[string]map[string]int // token counts per tag
+	tagTotals map[string]int            // total tokens per tag
+	vocab     map[string]int            // bookmarks containing each token, across tags
+	docs      int
+}
+
+// trainedBookmark is what the model learned from one bookmark, so it can be
+// unlearned when the bookmark changes
+type trainedBookmark struct {
+	fingerprint [sha256.Size]byte
+	tokens      []string
+	tags        []string
+}
+
+const (
+	// bayesMinScore drops suggestions the model is unsure of
+	bayesMinScore = 0.05
+	// bayesClassifyTags caps the tags Classify applies
+	bayesClassifyTags = 3
+	// bayesClassifyScore is how sure Classify must be to apply a tag
+	bayesClassifyScore = 0.2
+)
+
+func NewBayes(source func() ([]models.Bookmark, error)) *Bayes {
+	return &Bayes{
+		source:    source,
+		trained:   make(map[int64]trainedBookmark),
+		tagDocs:   make(map[string]int),
+		tagTokens: make(map[string]map[string]int),
+		tagTotals: make(map[string]int),
+		vocab:     make(map[string]int),
+	}
+}
+
+// Suggest ranks tags for bookmark, best first, leaving out tags it already
+// has. Scores are probabilities across all known tags.
+func (b *Bayes) Suggest(bookmark models.Bookmark, limit int) ([]models.TagSuggestion, error) {
+	if err := b.sync(); err != nil {
+		return nil, err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.suggest(bookmark, limit), nil
+}
+
+// Classify tags each bookmark with its most likely tags, so the model can
+// drive BulkSegmentBookmarks
+func (b *Bayes) Classify(ctx context.Context, bookmarks []models.Bookmark) ([]models.AISegmentation, error) {
+	if err := b.sync(); err != nil {
+		return nil, err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	results := make([]models.AISegmentation, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		suggestions := b.suggest(bookmark, bayesClassifyTags)
+		for _, suggestion := range suggestions {
+			if suggestion.Score < bayesClassifyScore {
+				break
+			}
+			results[i].Tags = append(results[i].Tags, suggestion.Tag)
+		}
+		if len(results[i].Tags) > 0 {
+			results[i].Category = results[i].Tags[0]
+			results[i].Confidence = suggestions[0].Score
+		}
+	}
+	return results, nil
+}
+
+func (b *Bayes) suggest(bookmark models.Bookmark, limit int) []models.TagSuggestion {
+	existing := make(map[string]bool, len(bookmark.Tags))
+	for _, tag := range bookmark.Tags {
+		existing[strings.ToLower(tag)] = true
+	}
+
+	suggestions := []models.TagSuggestion{}
+	for _, suggestion := range b.score(tokenize(bookmark)) {
+		if len(suggestions) == limit || suggestion.Score < bayesMinScore {
+			break
+		}
+		if !existing[strings.ToLower(suggestion.Tag)] {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	return suggestions
+}
+
+// sync trains on bookmarks added or changed since the last call and
+// forgets deleted ones
+func (b *Bayes) sync() error {
+	bookmarks, err := b.source()
+	if err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	current := make(map[int64]bool, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		current[bookmark.ID] = true
+		fingerprint := fingerprintBookmark(bookmark)
+		previous, known := b.trained[bookmark.ID]
+		if known && previous.fingerprint == fingerprint {
+			continue
+		}
+		if known {
+			b.learn(previous, -1)
+			delete(b.trained, bookmark.ID)
+		}
+		if len(bookmark.Tags) == 0 {
+			continue
+		}
+
+		// A tag listed twice is still one bookmark for that tag
+		learned := trainedBookmark{fingerprint: fingerprint, tokens: tokenize(bookmark), tags: unique(bookmark.Tags)}
+		b.learn(learned, 1)
+		b.trained[bookmark.ID] = learned
+	}
+
+	for id, previous := range b.trained {
+		if !current[id] {
+			b.learn(previous, -1)
+			delete(b.trained, id)
+		}
+	}
+	return nil
+}
+
+// learn adds one bookmark's counts to the model, or removes them when
+// sign is -1
+func (b *Bayes) learn(bookmark trainedBookmark, sign int) {
+	b.docs += sign
+	for _, token := range unique(bookmark.tokens) {
+		b.vocab[token] += sign
+		if b.vocab[token] <= 0 {
+			delete(b.vocab, token)
+		}
+	}
+
+	for _, tag := range bookmark.tags {
+		b.tagDocs[tag] += sign
+		if b.tagTokens[tag] == nil {
+			b.tagTokens[tag] = make(map[string]int)
+		}
+		for _, token := range bookmark.tokens {
+			b.tagTokens[tag][token] += sign
+			if b.tagTokens[tag][token] <= 0 {
+				delete(b.tagTokens[tag], token)
+			}
+		}
+		b.tagTotals[tag] += sign * len(bookmark.tokens)
+
+		if b.tagDocs[tag] <= 0 {
+			delete(b.tagDocs, tag)
+			delete(b.tagTokens, tag)
+			delete(b.tagTotals, tag)
+		}
+	}
+}
+
+// score returns every known tag with its posterior probability for
+// tokens, best first. Tokens the model has never seen carry no evidence.
+func (b *Bayes) score(tokens []string) []models.TagSuggestion {
+	if b.docs == 0 {
+		return nil
+	}
+
+	vocabSize := float64(len(b.vocab) + 1)
+	logScores := make(map[string]float64, len(b.tagDocs))
+	best := math.Inf(-1)
+	for tag, docs := range b.tagDocs {
+		// Laplace-smoothed multinomial likelihood
+		logScore := math.Log(float64(docs) / float64(b.docs))
+		denominator := float64(b.tagTotals[tag]) + vocabSize
+		for _, token := range tokens {
+			if _, known := b.vocab[token]; !known {
+				continue
+			}
+			logScore += math.Log((float64(b.tagTokens[tag][token]) + 1) / denominator)
+		}
+		logScores[tag] = logScore
+		best = math.Max(best, logScore)
+	}
+
+	// Normalise in log space to avoid underflow
+	total := 0.0
+	for _, logScore := range logScores {
+		total += math.Exp(logScore - best)
+	}
+	suggestions := make([]models.TagSuggestion, 0, len(logScores))
+	for tag, logScore := range logScores {
+		suggestions = append(suggestions, models.TagSuggestion{Tag: tag, Score: math.Exp(logScore-best) / total})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Tag < suggestions[j].Tag
+	})
+	return suggestions
+}
+
+// fingerprintBookmark changes whenever anything the model learns from does
+func fingerprintBookmark(bookmark models.Bookmark) [sha256.Size]byte {
+	return sha256.Sum256([]byte(bookmark.URL + "\x00" + bookmark.Title + "\x00" + bookmark.Description + "\x00" + strings.Join(bookmark.Tags, "\x00")))
+}
+
+// tokenize splits a bookmark's title, description, host and path into
+// lowercase words, dropping stop words, numbers and single letters
+func tokenize(bookmark models.Bookmark) []string {
+	var tokens []string
+	text := bookmark.Title + " " + bookmark.Description
+	if parsed, err := url.Parse(bookmark.URL); err == nil && parsed.Hostname() != "" {
+		host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+		// The whole host is a strong signal on its own ("github.com")
+		tokens = append(tokens, host)
+		text += " " + host + " " + parsed.Path
+	}
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) < 2 || stopWords[word] || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// unique drops repeats, keeping the first occurrence of each value
+func unique(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var kept []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"com": true, "for": true, "from": true, "how": true, "html": true, "htm": true, "http": true,
+	"https": true, "in": true, "index": true, "is": true, "it": true, "of": true, "on": true,
+	"or": true, "org": true, "net": true, "php": true, "that": true, "the": true, "this": true,
+	"to": true, "with": true, "www": true, "you": true, "your": true, "what": true, "why": true,
+}
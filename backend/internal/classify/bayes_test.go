@@ -0,0 +1,160 @@
+package classify
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"hyprlnk/internal/models"
+)
+
+// bookmarkSource is an editable stand-in for the bookmark repository
+type bookmarkSource struct {
+	bookmarks []models.Bookmark
+}
+
+func (s *bookmarkSource) all() ([]models.Bookmark, error) {
+	return append([]models.Bookmark{}, s.bookmarks...), nil
+}
+
+func trainingSet() []models.Bookmark {
+	return []models.Bookmark{
+		{ID: 1, URL: "https://go.dev/doc/effective_go", Title: "Effective Go", Description: "golang programming style", Tags: []string{"go", "programming"}},
+		{ID: 2, URL: "https://pkg.go.dev/net/http", Title: "net/http package", Description: "golang http server", Tags: []string{"go"}},
+		{ID: 3, URL: "https://www.seriouseats.com/bread", Title: "Bread recipe", Description: "baking sourdough", Tags: []string{"cooking"}},
+		{ID: 4, URL: "https://cooking.nytimes.com/pasta", Title: "Pasta recipe", Description: "dinner cooking", Tags: []string{"cooking"}},
+		{ID: 5, URL: "https://doc.rust-lang.org/book", Title: "The Rust book", Description: "rust programming language", Tags: []string{"rust", "programming"}},
+	}
+}
+
+// modelState is everything the model has counted, for comparing models
+func modelState(b *Bayes) []interface{} {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return []interface{}{b.docs, b.tagDocs, b.tagTokens, b.tagTotals, b.vocab}
+}
+
+// freshModel trains a new model on bookmarks
+func freshModel(t *testing.T, bookmarks []models.Bookmark) *Bayes {
+	t.Helper()
+	source := &bookmarkSource{bookmarks: bookmarks}
+	model := NewBayes(source.all)
+	if err := model.sync(); err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+func TestBayes_Suggest(t *testing.T) {
+	source := &bookmarkSource{bookmarks: trainingSet()}
+	model := NewBayes(source.all)
+
+	suggestions, err := model.Suggest(models.Bookmark{URL: "https://go.dev/blog", Title: "The Go blog", Description: "golang news"}, 3)
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+	if len(suggestions) == 0 || suggestions[0].Tag != "go" {
+		t.Fatalf("Expected go as the top suggestion, got %+v", suggestions)
+	}
+	for i := 1; i < len(suggestions); i++ {
+		if suggestions[i].Score > suggestions[i-1].Score {
+			t.Errorf("Expected suggestions best first, got %+v", suggestions)
+		}
+	}
+
+	suggestions, _ = model.Suggest(models.Bookmark{URL: "https://example.com/soup", Title: "Soup recipe", Description: "cooking dinner"}, 1)
+	if len(suggestions) != 1 || suggestions[0].Tag != "cooking" {
+		t.Errorf("Expected cooking as the only suggestion, got %+v", suggestions)
+	}
+}
+
+func TestBayes_SuggestExcludesExistingTags(t *testing.T) {
+	model := freshModel(t, trainingSet())
+
+	bookmark := models.Bookmark{URL: "https://go.dev/blog", Title: "The Go blog", Description: "golang programming", Tags: []string{"Go"}}
+	suggestions, err := model.Suggest(bookmark, 5)
+	if err != nil {
+		t.Fatalf("Suggest failed: %v", err)
+	}
+	if len(suggestions) == 0 {
+		t.Fatal("Expected suggestions besides the existing tag")
+	}
+	for _, suggestion := range suggestions {
+		if suggestion.Tag == "go" {
+			t.Errorf("Expected the existing tag to be left out, regardless of case, got %+v", suggestions)
+		}
+	}
+	if suggestions[0].Tag != "programming" {
+		t.Errorf("Expected programming next, got %+v", suggestions)
+	}
+}
+
+func TestBayes_EditAndDeleteUnlearn(t *testing.T) {
+	source := &bookmarkSource{bookmarks: trainingSet()}
+	model := NewBayes(source.all)
+	if err := model.sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Retag one bookmark, untag another and delete a third
+	source.bookmarks[1].Tags = []string{"web"}
+	source.bookmarks[1].Title = "HTTP servers"
+	source.bookmarks[3].Tags = nil
+	source.bookmarks = append(source.bookmarks[:4], source.bookmarks[5:]...)
+	if err := model.sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := modelState(model), modelState(freshModel(t, source.bookmarks)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the counts of a freshly trained model\n got %v\nwant %v", got, want)
+	}
+
+	// Deleting everything leaves nothing behind
+	source.bookmarks = nil
+	if err := model.sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := modelState(model), modelState(NewBayes(source.all)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected an empty model, got %v", got)
+	}
+	if suggestions, _ := model.Suggest(models.Bookmark{Title: "golang"}, 3); len(suggestions) != 0 {
+		t.Errorf("Expected no suggestions from an empty model, got %+v", suggestions)
+	}
+}
+
+func TestBayes_DuplicateTags(t *testing.T) {
+	bookmarks := trainingSet()
+	duplicated := append([]models.Bookmark{}, bookmarks...)
+	duplicated[0].Tags = []string{"go", "programming", "go"}
+
+	model := freshModel(t, duplicated)
+	if docs := model.tagDocs["go"]; docs != 2 {
+		t.Errorf("Expected 2 bookmarks tagged go, got %d", docs)
+	}
+
+	// The fingerprint differs, so compare against the counts alone
+	if got, want := modelState(model), modelState(freshModel(t, bookmarks)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected a repeated tag to count once\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestBayes_Classify(t *testing.T) {
+	model := freshModel(t, trainingSet())
+
+	results, err := model.Classify(context.Background(), []models.Bookmark{
+		{URL: "https://example.com/cake", Title: "Cake recipe", Description: "baking"},
+		{URL: "https://go.dev/tour", Title: "A tour of Go", Description: "golang"},
+	})
+	if err != nil {
+		t.Fatalf("Classify failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if got := results[0]; got.Category != "cooking" || len(got.Tags) == 0 || got.Tags[0] != "cooking" || got.Confidence < bayesClassifyScore {
+		t.Errorf("Unexpected result %+v", got)
+	}
+	if got := results[1]; got.Category != "go" {
+		t.Errorf("Expected results in bookmark order, got %+v", got)
+	}
+}
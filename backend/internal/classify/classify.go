@@ -0,0 +1,50 @@
+// Package classify suggests a category, tags and a description for
+// bookmarks. Implementations range from keyword rules to a language model.
+package classify
+
+import (
+	"context"
+	"fmt"
+
+	"hyprlnk/internal/models"
+)
+
+// Classifier suggests a segmentation for each bookmark
+type Classifier interface {
+	// Classify returns one segmentation per bookmark, in the same order
+	Classify(ctx context.Context, bookmarks []models.Bookmark) ([]models.AISegmentation, error)
+}
+
+const (
+	KindKeywords = "keywords" // built-in keyword rules
+	KindRules    = "rules"    // keyword rules from a user-edited JSON file
+	KindLLM      = "llm"      // an OpenAI-compatible chat completions endpoint
+	KindBayes    = "bayes"    // a model trained on the tags already on bookmarks
+)
+
+// Config selects and configures a classifier
+type Config struct {
+	Kind      string
+	RulesFile string // for KindRules
+	LLM       LLMConfig
+	Bayes     *Bayes // for KindBayes, shared with tag suggestions
+}
+
+// New builds the classifier config asks for, defaulting to keywords
+func New(config Config) (Classifier, error) {
+	switch config.Kind {
+	case "", KindKeywords:
+		return NewRules(DefaultRules()), nil
+	case KindRules:
+		return NewFileRules(config.RulesFile)
+	case KindLLM:
+		return NewLLM(config.LLM)
+	case KindBayes:
+		if config.Bayes == nil {
+			return nil, fmt.Errorf("the bayes classifier needs a model")
+		}
+		return config.Bayes, nil
+	default:
+		return nil, fmt.Errorf("unknown classifier %q (use %s, %s, %s or %s)", config.Kind, KindKeywords, KindRules, KindLLM, KindBayes)
+	}
+}
@@ -0,0 +1,285 @@
+package classify
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"hyprlnk/internal/models"
+)
+
+// LLMConfig points at an OpenAI-compatible chat completions API, such as
+// OpenAI itself or a local Ollama (http://localhost:11434/v1)
+type LLMConfig struct {
+	BaseURL    string
+	Model      string
+	APIKey     string        // optional; Ollama needs none
+	BatchSize  int           // bookmarks per request, default 20
+	Timeout    time.Duration // per request, default 60s
+	MaxRetries int           // after the first attempt, default 2; negative disables
+	CacheSize  int           // remembered classifications, default 10000
+}
+
+// LLM asks a language model to classify bookmarks. Requests carry a batch
+// of bookmarks each, are retried on rate limits, server errors and network
+// failures, and answers are cached by bookmark content.
+type LLM struct {
+	config  LLMConfig
+	client  *http.Client
+	backoff time.Duration // first retry delay, doubling after each attempt
+	cache   *segmentationCache
+}
+
+func NewLLM(config LLMConfig) (*LLM, error) {
+	if config.BaseURL == "" || config.Model == "" {
+		return nil, fmt.Errorf("the llm classifier needs a base URL and a model")
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	if config.BatchSize <= 0 {
+		config.BatchSize = 20
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 60 * time.Second
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	} else if config.MaxRetries == 0 {
+		config.MaxRetries = 2
+	}
+	if config.CacheSize <= 0 {
+		config.CacheSize = 10000
+	}
+
+	return &LLM{
+		config:  config,
+		client:  &http.Client{},
+		backoff: time.Second,
+		cache:   newSegmentationCache(config.CacheSize),
+	}, nil
+}
+
+const llmSystemPrompt = `You organise a personal bookmark collection. You are given numbered bookmarks.
+Reply with only a JSON object of this shape, one entry per bookmark:
+{"bookmarks": [{"id": <number>, "category": "<one lowercase word>", "tags": ["<up to 5 lowercase tags>"], "description": "<one sentence>", "confidence": <0 to 1>}]}`
+
+func (l *LLM) Classify(ctx context.Context, bookmarks []models.Bookmark) ([]models.AISegmentation, error) {
+	results := make([]models.AISegmentation, len(bookmarks))
+
+	var pending []int
+	for i, bookmark := range bookmarks {
+		if cached, ok := l.cache.get(l.cacheKey(bookmark)); ok {
+			results[i] = cached
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	for start := 0; start < len(pending); start += l.config.BatchSize {
+		batch := pending[start:min(start+l.config.BatchSize, len(pending))]
+
+		var prompt strings.Builder
+		for n, i := range batch {
+			bookmark := bookmarks[i]
+			fmt.Fprintf(&prompt, "%d.\nTitle: %s\nURL: %s\nDescription: %s\n\n", n+1, bookmark.Title, bookmark.URL, bookmark.Description)
+		}
+
+		reply, err := l.completeWithRetries(ctx, prompt.String())
+		if err != nil {
+			return nil, err
+		}
+		segmentations, err := parseSegmentations(reply, len(batch))
+		if err != nil {
+			return nil, err
+		}
+
+		for n, i := range batch {
+			results[i] = segmentations[n]
+			// A bookmark the model skipped gets another chance next time
+			if len(segmentations[n].Tags) > 0 || segmentations[n].Category != "" {
+				l.cache.put(l.cacheKey(bookmarks[i]), segmentations[n])
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// cacheKey identifies a bookmark's content as seen by the model
+func (l *LLM) cacheKey(bookmark models.Bookmark) string {
+	sum := sha256.Sum256([]byte(l.config.Model + "\x00" + bookmark.URL + "\x00" + bookmark.Title + "\x00" + bookmark.Description))
+	return hex.EncodeToString(sum[:])
+}
+
+// retryableError marks failures worth another attempt, optionally with
+// the delay the server asked for
+type retryableError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+func (l *LLM) completeWithRetries(ctx context.Context, prompt string) (string, error) {
+	delay := l.backoff
+	for attempt := 0; ; attempt++ {
+		reply, err := l.complete(ctx, prompt)
+		var retryable *retryableError
+		if err == nil || !errors.As(err, &retryable) || attempt >= l.config.MaxRetries {
+			return reply, err
+		}
+
+		wait := delay
+		if retryable.after > 0 {
+			wait = retryable.after
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// complete sends one chat completion request and returns the reply text
+func (l *LLM) complete(ctx context.Context, prompt string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": l.config.Model,
+		"messages": []map[string]string{
+			{"role": "system", "content": llmSystemPrompt},
+			{"role": "user", "content": prompt},
+		},
+		"temperature":     0,
+		"response_format": map[string]string{"type": "json_object"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, l.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.config.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if l.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+l.config.APIKey)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("llm request failed: %w", err)
+		if ctx.Err() != nil && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", err // cancelled by the caller
+		}
+		return "", &retryableError{err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("llm request failed: %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			return "", &retryableError{err: err, after: time.Duration(seconds) * time.Second}
+		}
+		return "", err
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return "", &retryableError{err: fmt.Errorf("failed to decode llm response: %w", err)}
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("llm response has no choices")
+	}
+	return completion.Choices[0].Message.Content, nil
+}
+
+// parseSegmentations reads the JSON object in a model reply, tolerating
+// markdown code fences and text around it. Bookmarks the model left out
+// get an empty segmentation.
+func parseSegmentations(reply string, count int) ([]models.AISegmentation, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("llm reply is not JSON: %.80q", reply)
+	}
+
+	var parsed struct {
+		Bookmarks []struct {
+			ID int `json:"id"`
+			models.AISegmentation
+		} `json:"bookmarks"`
+	}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("llm reply is not a list of segmentations: %w", err)
+	}
+
+	segmentations := make([]models.AISegmentation, count)
+	for _, entry := range parsed.Bookmarks {
+		if entry.ID < 1 || entry.ID > count {
+			continue
+		}
+		segmentation := entry.AISegmentation
+		segmentation.Category = strings.ToLower(strings.TrimSpace(segmentation.Category))
+		for i, tag := range segmentation.Tags {
+			segmentation.Tags[i] = strings.ToLower(strings.TrimSpace(tag))
+		}
+		segmentations[entry.ID-1] = segmentation
+	}
+	return segmentations, nil
+}
+
+// segmentationCache remembers classifications, forgetting the oldest
+// once full
+type segmentationCache struct {
+	mutex   sync.Mutex
+	size    int
+	entries map[string]models.AISegmentation
+	order   []string
+}
+
+func newSegmentationCache(size int) *segmentationCache {
+	return &segmentationCache{size: size, entries: make(map[string]models.AISegmentation)}
+}
+
+func (c *segmentationCache) get(key string) (models.AISegmentation, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	segmentation, ok := c.entries[key]
+	return segmentation, ok
+}
+
+func (c *segmentationCache) put(key string, segmentation models.AISegmentation) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.entries[key]; !ok {
+		if len(c.order) >= c.size {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = segmentation
+}
@@ -0,0 +1,242 @@
+package classify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hyprlnk/internal/models"
+)
+
+// fakeLLM stands in for a chat completions endpoint. reply answers each
+// request given its user prompt; the request number starts at 1.
+func fakeLLM(t *testing.T, reply func(n int32, prompt string, w http.ResponseWriter) string) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Expected /v1/chat/completions, got %s", r.URL.Path)
+		}
+
+		var request struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		if request.Model != "test-model" || len(request.Messages) != 2 {
+			t.Errorf("Unexpected request: %+v", request)
+		}
+
+		content := reply(n, request.Messages[1].Content, w)
+		if content == "" {
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// tagEveryBookmark answers with one tag per bookmark in the prompt, named
+// after its position
+func tagEveryBookmark(prompt string) string {
+	var entries []string
+	for id := 1; strings.Contains(prompt, fmt.Sprintf("%d.\nTitle:", id)); id++ {
+		entries = append(entries, fmt.Sprintf(`{"id": %d, "category": "Dev", "tags": ["Tag%d"], "description": "d", "confidence": 0.8}`, id, id))
+	}
+	return "```json\n{\"bookmarks\": [" + strings.Join(entries, ",") + "]}\n```"
+}
+
+func newTestLLM(t *testing.T, server *httptest.Server, config LLMConfig) *LLM {
+	config.BaseURL = server.URL + "/v1/"
+	config.Model = "test-model"
+	llm, err := NewLLM(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	llm.backoff = time.Millisecond
+	return llm
+}
+
+func testBookmarks(n int) []models.Bookmark {
+	bookmarks := make([]models.Bookmark, n)
+	for i := range bookmarks {
+		bookmarks[i] = models.Bookmark{
+			URL:         fmt.Sprintf("https://example.com/%d", i),
+			Title:       fmt.Sprintf("Example %d", i),
+			Description: fmt.Sprintf("About %d", i),
+		}
+	}
+	return bookmarks
+}
+
+func TestLLM_ClassifiesInBatches(t *testing.T) {
+	server, requests := fakeLLM(t, func(n int32, prompt string, w http.ResponseWriter) string {
+		if !strings.Contains(prompt, "Title: Example 0\nURL: https://example.com/0\nDescription: About 0") && n == 1 {
+			t.Errorf("Prompt is missing the bookmark: %q", prompt)
+		}
+		return tagEveryBookmark(prompt)
+	})
+	llm := newTestLLM(t, server, LLMConfig{BatchSize: 2})
+
+	results, err := llm.Classify(context.Background(), testBookmarks(5))
+	if err != nil {
+		t.Fatalf("Classify failed: %v", err)
+	}
+
+	if *requests != 3 {
+		t.Fatalf("Expected 3 requests for 5 bookmarks in batches of 2, got %d", *requests)
+	}
+	// Positions restart in every batch: 1, 2 | 1, 2 | 1
+	expected := []string{"tag1", "tag2", "tag1", "tag2", "tag1"}
+	for i, result := range results {
+		if result.Category != "dev" || len(result.Tags) != 1 || result.Tags[0] != expected[i] {
+			t.Errorf("Bookmark %d: unexpected segmentation %+v", i, result)
+		}
+	}
+}
+
+func TestLLM_CachesAnswers(t *testing.T) {
+	server, requests := fakeLLM(t, func(n int32, prompt string, w http.ResponseWriter) string {
+		return tagEveryBookmark(prompt)
+	})
+	llm := newTestLLM(t, server, LLMConfig{})
+
+	bookmarks := testBookmarks(2)
+	if _, err := llm.Classify(context.Background(), bookmarks); err != nil {
+		t.Fatal(err)
+	}
+	bookmarks = append(bookmarks, testBookmarks(3)[2])
+	results, err := llm.Classify(context.Background(), bookmarks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *requests != 2 {
+		t.Fatalf("Expected the second call to request only the new bookmark, got %d requests", *requests)
+	}
+	if results[1].Tags[0] != "tag2" || results[2].Tags[0] != "tag1" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+}
+
+func TestLLM_RetriesServerErrors(t *testing.T) {
+	server, requests := fakeLLM(t, func(n int32, prompt string, w http.ResponseWriter) string {
+		switch n {
+		case 1:
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return ""
+		case 2:
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return ""
+		}
+		return tagEveryBookmark(prompt)
+	})
+	llm := newTestLLM(t, server, LLMConfig{MaxRetries: 2})
+
+	results, err := llm.Classify(context.Background(), testBookmarks(1))
+	if err != nil {
+		t.Fatalf("Classify failed: %v", err)
+	}
+	if *requests != 3 || results[0].Tags[0] != "tag1" {
+		t.Fatalf("Expected success on the third attempt, got %d requests and %+v", *requests, results)
+	}
+}
+
+func TestLLM_GivesUpAfterMaxRetries(t *testing.T) {
+	server, requests := fakeLLM(t, func(n int32, prompt string, w http.ResponseWriter) string {
+		http.Error(w, "down", http.StatusBadGateway)
+		return ""
+	})
+	llm := newTestLLM(t, server, LLMConfig{MaxRetries: 1})
+
+	if _, err := llm.Classify(context.Background(), testBookmarks(1)); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("Expected a 502 error, got %v", err)
+	}
+	if *requests != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", *requests)
+	}
+}
+
+func TestLLM_DoesNotRetryClientErrors(t *testing.T) {
+	server, requests := fakeLLM(t, func(n int32, prompt string, w http.ResponseWriter) string {
+		http.Error(w, "unknown model", http.StatusBadRequest)
+		return ""
+	})
+	llm := newTestLLM(t, server, LLMConfig{})
+
+	if _, err := llm.Classify(context.Background(), testBookmarks(1)); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if *requests != 1 {
+		t.Fatalf("Expected a single attempt, got %d", *requests)
+	}
+}
+
+func TestLLM_TimesOut(t *testing.T) {
+	server, requests := fakeLLM(t, func(n int32, prompt string, w http.ResponseWriter) string {
+		if n == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return tagEveryBookmark(prompt)
+	})
+	llm := newTestLLM(t, server, LLMConfig{Timeout: 50 * time.Millisecond, MaxRetries: 1})
+
+	results, err := llm.Classify(context.Background(), testBookmarks(1))
+	if err != nil {
+		t.Fatalf("Expected the retry after a timeout to succeed, got %v", err)
+	}
+	if *requests != 2 || results[0].Tags[0] != "tag1" {
+		t.Fatalf("Unexpected %d requests and %+v", *requests, results)
+	}
+}
+
+func TestLLM_SkippedBookmarksAreNotCached(t *testing.T) {
+	server, requests := fakeLLM(t, func(n int32, prompt string, w http.ResponseWriter) string {
+		if n == 1 {
+			return `{"bookmarks": [{"id": 2, "tags": ["second"]}]}`
+		}
+		return tagEveryBookmark(prompt)
+	})
+	llm := newTestLLM(t, server, LLMConfig{})
+
+	results, err := llm.Classify(context.Background(), testBookmarks(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results[0].Tags) != 0 || results[1].Tags[0] != "second" {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+
+	results, err = llm.Classify(context.Background(), testBookmarks(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *requests != 2 || results[0].Tags[0] != "tag1" || results[1].Tags[0] != "second" {
+		t.Fatalf("Expected only the skipped bookmark to be asked again, got %d requests and %+v", *requests, results)
+	}
+}
+
+func TestLLM_RejectsNonJSONReply(t *testing.T) {
+	server, _ := fakeLLM(t, func(n int32, prompt string, w http.ResponseWriter) string {
+		return "I cannot help with that."
+	})
+	llm := newTestLLM(t, server, LLMConfig{})
+
+	if _, err := llm.Classify(context.Background(), testBookmarks(1)); err == nil {
+		t.Fatal("Expected an error for a reply without JSON")
+	}
+}
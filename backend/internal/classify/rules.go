@@ -0,0 +1,177 @@
+package classify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"hyprlnk/internal/models"
+)
+
+// Rule tags bookmarks whose Field contains Match, case-insensitively
+type Rule struct {
+	Match    string   `json:"match"`
+	Field    string   `json:"field,omitempty"` // url, title or description; empty matches any
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags"`
+}
+
+// RuleSet is the contents of a rules file
+type RuleSet struct {
+	Rules    []Rule   `json:"rules"`
+	Fallback []string `json:"fallback_tags"` // tags for bookmarks no rule matches
+}
+
+// DefaultRules are the built-in keyword rules
+func DefaultRules() RuleSet {
+	return RuleSet{
+		Rules: []Rule{
+			{Match: "github.com", Tags: []string{"development"}},
+			{Match: "stackoverflow", Tags: []string{"development"}},
+			{Match: "youtube.com", Tags: []string{"video"}},
+			{Match: "medium.com", Tags: []string{"article"}},
+			{Match: "news", Tags: []string{"news"}},
+			{Match: "blog", Tags: []string{"blog"}},
+			{Match: "tutorial", Tags: []string{"learning"}},
+			{Match: "documentation", Tags: []string{"docs"}},
+			{Match: "api", Tags: []string{"development"}},
+			{Match: "react", Tags: []string{"frontend"}},
+			{Match: "javascript", Tags: []string{"development"}},
+			{Match: "python", Tags: []string{"development"}},
+			{Match: "golang", Tags: []string{"development"}},
+			{Match: "design", Tags: []string{"design"}},
+			{Match: "tool", Tags: []string{"tools"}},
+		},
+		Fallback: []string{"uncategorized"},
+	}
+}
+
+// Rules classifies with a fixed rule set
+type Rules struct {
+	set RuleSet
+}
+
+func NewRules(set RuleSet) *Rules {
+	return &Rules{set: set}
+}
+
+func (r *Rules) Classify(ctx context.Context, bookmarks []models.Bookmark) ([]models.AISegmentation, error) {
+	results := make([]models.AISegmentation, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		results[i] = r.set.classify(bookmark)
+	}
+	return results, nil
+}
+
+// classify applies every matching rule. The first match sets the category;
+// confidence grows with the number of matches.
+func (set RuleSet) classify(bookmark models.Bookmark) models.AISegmentation {
+	var result models.AISegmentation
+	seen := make(map[string]bool)
+	matches := 0
+
+	for _, rule := range set.Rules {
+		if rule.Match == "" || !strings.Contains(ruleField(bookmark, rule.Field), strings.ToLower(rule.Match)) {
+			continue
+		}
+		matches++
+		if result.Category == "" {
+			result.Category = rule.Category
+			if result.Category == "" && len(rule.Tags) > 0 {
+				result.Category = rule.Tags[0]
+			}
+		}
+		for _, tag := range rule.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				result.Tags = append(result.Tags, tag)
+			}
+		}
+	}
+
+	if matches == 0 {
+		result.Tags = append([]string{}, set.Fallback...)
+		return result
+	}
+	result.Confidence = min(1, 0.4+0.2*float64(matches))
+	return result
+}
+
+func ruleField(bookmark models.Bookmark, field string) string {
+	switch field {
+	case "url":
+		return strings.ToLower(bookmark.URL)
+	case "title":
+		return strings.ToLower(bookmark.Title)
+	case "description":
+		return strings.ToLower(bookmark.Description)
+	default:
+		return strings.ToLower(bookmark.Title + " " + bookmark.Description + " " + bookmark.URL)
+	}
+}
+
+// FileRules classifies with rules read from a JSON file, re-reading it
+// whenever it changes so edits apply without a restart
+type FileRules struct {
+	path string
+
+	mutex   sync.Mutex
+	modTime time.Time
+	set     RuleSet
+}
+
+// NewFileRules loads path. A missing file is created with the default
+// rules as a starting point for editing.
+func NewFileRules(path string) (*FileRules, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		data, _ := json.MarshalIndent(DefaultRules(), "", "  ")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to create rules file: %w", err)
+		}
+	}
+
+	rules := &FileRules{path: path}
+	if _, err := rules.current(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *FileRules) Classify(ctx context.Context, bookmarks []models.Bookmark) ([]models.AISegmentation, error) {
+	set, err := r.current()
+	if err != nil {
+		return nil, err
+	}
+	return NewRules(set).Classify(ctx, bookmarks)
+}
+
+// current returns the rule set, reloading the file if it was modified
+func (r *FileRules) current() (RuleSet, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return r.set, fmt.Errorf("failed to read rules file: %w", err)
+	}
+	if info.ModTime().Equal(r.modTime) {
+		return r.set, nil
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return r.set, fmt.Errorf("failed to read rules file: %w", err)
+	}
+	var set RuleSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return r.set, fmt.Errorf("failed to parse rules file %s: %w", r.path, err)
+	}
+
+	r.set = set
+	r.modTime = info.ModTime()
+	return set, nil
+}
@@ -0,0 +1,167 @@
+package classify
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"hyprlnk/internal/models"
+)
+
+func TestRules_Classify(t *testing.T) {
+	rules := NewRules(RuleSet{
+		Rules: []Rule{
+			{Match: "GitHub.com", Field: "url", Tags: []string{"code", "dev"}},
+			{Match: "golang", Category: "Go", Tags: []string{"go", "dev"}},
+			{Match: "recipe", Field: "title", Tags: []string{"cooking"}},
+			{Match: "", Tags: []string{"never"}},
+		},
+		Fallback: []string{"uncategorized"},
+	})
+
+	bookmarks := []models.Bookmark{
+		{URL: "https://github.com/golang/go", Title: "The Go repo"},
+		{URL: "https://example.com/recipe", Title: "Nothing to see"},
+		{URL: "https://example.com", Title: "Bread Recipe"},
+	}
+	results, err := rules.Classify(context.Background(), bookmarks)
+	if err != nil {
+		t.Fatalf("Classify failed: %v", err)
+	}
+	if len(results) != len(bookmarks) {
+		t.Fatalf("Expected %d results, got %d", len(bookmarks), len(results))
+	}
+
+	// Both rules match: the first sets the category, tags are deduplicated
+	if got := results[0]; got.Category != "code" || !reflect.DeepEqual(got.Tags, []string{"code", "dev", "go"}) || got.Confidence != 0.8 {
+		t.Errorf("Unexpected result for two matches: %+v", got)
+	}
+	// The title rule doesn't look at the URL, so the fallback applies
+	if got := results[1]; got.Category != "" || !reflect.DeepEqual(got.Tags, []string{"uncategorized"}) || got.Confidence != 0 {
+		t.Errorf("Expected the fallback, got %+v", got)
+	}
+	if got := results[2]; got.Category != "cooking" || !reflect.DeepEqual(got.Tags, []string{"cooking"}) {
+		t.Errorf("Expected a case-insensitive title match, got %+v", got)
+	}
+}
+
+func TestRules_CategoryFromRule(t *testing.T) {
+	rules := NewRules(RuleSet{Rules: []Rule{{Match: "golang", Category: "Go", Tags: []string{"dev"}}}})
+	results, _ := rules.Classify(context.Background(), []models.Bookmark{{URL: "https://golang.org"}})
+	if results[0].Category != "Go" {
+		t.Errorf("Expected the rule's category, got %q", results[0].Category)
+	}
+}
+
+func writeRules(t *testing.T, path string, set RuleSet, modTime time.Time) {
+	t.Helper()
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Set the time explicitly; two writes can land within the clock's resolution
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileRules_CreatesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	rules, err := NewFileRules(path)
+	if err != nil {
+		t.Fatalf("NewFileRules failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected the rules file to be created: %v", err)
+	}
+	var set RuleSet
+	if err := json.Unmarshal(data, &set); err != nil || !reflect.DeepEqual(set, DefaultRules()) {
+		t.Errorf("Expected the default rules to be written, got %s", data)
+	}
+
+	results, _ := rules.Classify(context.Background(), []models.Bookmark{{URL: "https://youtube.com/watch"}})
+	if !reflect.DeepEqual(results[0].Tags, []string{"video"}) {
+		t.Errorf("Expected the default rules to apply, got %v", results[0].Tags)
+	}
+}
+
+func TestFileRules_ReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	start := time.Now().Add(-time.Hour)
+	writeRules(t, path, RuleSet{Rules: []Rule{{Match: "example", Tags: []string{"first"}}}}, start)
+
+	rules, err := NewFileRules(path)
+	if err != nil {
+		t.Fatalf("NewFileRules failed: %v", err)
+	}
+	bookmark := []models.Bookmark{{URL: "https://example.com"}}
+	if results, _ := rules.Classify(context.Background(), bookmark); !reflect.DeepEqual(results[0].Tags, []string{"first"}) {
+		t.Fatalf("Expected the file's rules, got %v", results[0].Tags)
+	}
+
+	writeRules(t, path, RuleSet{Rules: []Rule{{Match: "example", Tags: []string{"second"}}}}, start.Add(time.Minute))
+	if results, _ := rules.Classify(context.Background(), bookmark); !reflect.DeepEqual(results[0].Tags, []string{"second"}) {
+		t.Errorf("Expected the edited rules to apply, got %v", results[0].Tags)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, start.Add(2*time.Minute), start.Add(2*time.Minute))
+	if _, err := rules.Classify(context.Background(), bookmark); err == nil {
+		t.Error("Expected an error for a malformed rules file")
+	}
+}
+
+func TestNewFileRules_Malformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileRules(path); err == nil {
+		t.Error("Expected an error for a rules file that isn't a rule set")
+	}
+}
+
+func TestNew(t *testing.T) {
+	bayes := NewBayes(func() ([]models.Bookmark, error) { return nil, nil })
+	tests := []struct {
+		config  Config
+		want    string
+		wantErr bool
+	}{
+		{Config{}, "*classify.Rules", false},
+		{Config{Kind: KindKeywords}, "*classify.Rules", false},
+		{Config{Kind: KindRules, RulesFile: filepath.Join(t.TempDir(), "rules.json")}, "*classify.FileRules", false},
+		{Config{Kind: KindLLM, LLM: LLMConfig{BaseURL: "http://localhost:11434", Model: "llama3"}}, "*classify.LLM", false},
+		{Config{Kind: KindLLM}, "", true},
+		{Config{Kind: KindBayes, Bayes: bayes}, "*classify.Bayes", false},
+		{Config{Kind: KindBayes}, "", true},
+		{Config{Kind: "magic"}, "", true},
+	}
+	for _, tt := range tests {
+		classifier, err := New(tt.config)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("New(%+v): expected an error", tt.config)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("New(%+v) failed: %v", tt.config, err)
+			continue
+		}
+		if got := reflect.TypeOf(classifier).String(); got != tt.want {
+			t.Errorf("New(%+v) = %s, want %s", tt.config, got, tt.want)
+		}
+	}
+}
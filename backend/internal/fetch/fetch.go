@@ -0,0 +1,365 @@
+// Package fetch retrieves web pages politely: it honours robots.txt,
+// spaces out requests to the same host and caps how much it downloads.
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDisallowed is returned for URLs robots.txt asks crawlers to avoid
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// Config tunes a Fetcher; zero values pick the defaults
+type Config struct {
+	UserAgent    string        // default "hyprlnk/1.0 (+https://hyprlnk.app)"
+	Timeout      time.Duration // per request, default 20s
+	MaxBytes     int64         // body size cap, default 2MB
+	HostInterval time.Duration // minimum gap between requests to a host, default 1s
+}
+
+// Response is a fetched page. Non-2xx responses are returned too, so
+// callers can tell a dead link from a network failure.
+type Response struct {
+	URL        *url.URL // after redirects
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Truncated  bool       // the body was cut off at MaxBytes
+	Redirects  []Redirect // the redirects followed, in order
+}
+
+// Redirect is one hop of a redirect chain
+type Redirect struct {
+	URL        string // the URL that redirected
+	StatusCode int
+	Location   string // where it redirected to
+}
+
+// Fetcher is safe for concurrent use
+type Fetcher struct {
+	config Config
+	client *http.Client
+
+	mutex   sync.Mutex
+	next    map[string]time.Time // earliest time the next request to a host may start
+	robots  map[string]*robots
+	pending map[string]chan struct{} // robots.txt fetches in flight
+}
+
+const (
+	// robotsTTL is how long a host's robots.txt is trusted
+	robotsTTL = 24 * time.Hour
+	// maxCrawlDelay caps a Crawl-delay, so one host can't stall its bookmarks for hours
+	maxCrawlDelay = time.Minute
+)
+
+func New(config Config) *Fetcher {
+	if config.UserAgent == "" {
+		config.UserAgent = "hyprlnk/1.0 (+https://hyprlnk.app)"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 20 * time.Second
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = 2 << 20
+	}
+	if config.HostInterval <= 0 {
+		config.HostInterval = time.Second
+	}
+
+	return &Fetcher{
+		config:  config,
+		client:  &http.Client{},
+		next:    make(map[string]time.Time),
+		robots:  make(map[string]*robots),
+		pending: make(map[string]chan struct{}),
+	}
+}
+
+// Get fetches rawURL, after checking robots.txt and waiting for its host's
+// turn. Only http and https URLs are fetched.
+func (f *Fetcher) Get(ctx context.Context, rawURL string) (*Response, error) {
+	return f.do(ctx, http.MethodGet, rawURL, f.config.MaxBytes)
+}
+
+// GetLimit is Get with a different body size cap
+func (f *Fetcher) GetLimit(ctx context.Context, rawURL string, maxBytes int64) (*Response, error) {
+	return f.do(ctx, http.MethodGet, rawURL, maxBytes)
+}
+
+// Head is Get without the body
+func (f *Fetcher) Head(ctx context.Context, rawURL string) (*Response, error) {
+	return f.do(ctx, http.MethodHead, rawURL, 0)
+}
+
+func (f *Fetcher) do(ctx context.Context, method, rawURL string, maxBytes int64) (*Response, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, fmt.Errorf("can't fetch %s URLs", target.Scheme)
+	}
+
+	rules, err := f.robotsFor(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if !rules.allowed(target.EscapedPath()) {
+		return nil, ErrDisallowed
+	}
+
+	return f.request(ctx, method, target, maxBytes, rules.crawlDelay)
+}
+
+// request waits for the host's turn and performs one request
+func (f *Fetcher) request(ctx context.Context, method string, target *url.URL, maxBytes int64, crawlDelay time.Duration) (*Response, error) {
+	if err := f.wait(ctx, target.Host, crawlDelay); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.config.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	truncated := int64(len(body)) > maxBytes
+	if truncated {
+		body = body[:maxBytes]
+	}
+
+	return &Response{
+		URL:        resp.Request.URL,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+		Truncated:  truncated,
+		Redirects:  redirects(resp),
+	}, nil
+}
+
+// redirects walks back from the final response through the responses
+// that redirected to it
+func redirects(resp *http.Response) []Redirect {
+	var chain []Redirect
+	for req := resp.Request; req.Response != nil; req = req.Response.Request {
+		chain = append(chain, Redirect{
+			URL:        req.Response.Request.URL.String(),
+			StatusCode: req.Response.StatusCode,
+			Location:   req.URL.String(),
+		})
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
+
+// wait reserves the host's next request slot and sleeps until it comes
+func (f *Fetcher) wait(ctx context.Context, host string, crawlDelay time.Duration) error {
+	interval := f.config.HostInterval
+	if crawlDelay > interval {
+		interval = crawlDelay
+	}
+
+	f.mutex.Lock()
+	now := time.Now()
+	slot := f.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	f.next[host] = slot.Add(interval)
+	f.mutex.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// robotsFor returns the host's robots.txt rules, fetching them at most
+// once at a time. A missing or unreadable robots.txt allows everything.
+func (f *Fetcher) robotsFor(ctx context.Context, target *url.URL) (*robots, error) {
+	key := target.Scheme + "://" + target.Host
+	for {
+		f.mutex.Lock()
+		if rules, ok := f.robots[key]; ok && time.Since(rules.fetched) < robotsTTL {
+			f.mutex.Unlock()
+			return rules, nil
+		}
+		if done, ok := f.pending[key]; ok {
+			f.mutex.Unlock()
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-done:
+			}
+			continue
+		}
+		done := make(chan struct{})
+		f.pending[key] = done
+		f.mutex.Unlock()
+
+		rules := &robots{fetched: time.Now()}
+		robotsURL := &url.URL{Scheme: target.Scheme, Host: target.Host, Path: "/robots.txt"}
+		resp, err := f.request(ctx, http.MethodGet, robotsURL, 512<<10, 0)
+		if err == nil && resp.StatusCode == http.StatusOK {
+			rules = parseRobots(string(resp.Body), f.config.UserAgent)
+		}
+
+		f.mutex.Lock()
+		delete(f.pending, key)
+		// A cancelled fetch says nothing about the host; let the next caller retry
+		if ctx.Err() == nil {
+			f.robots[key] = rules
+		}
+		f.mutex.Unlock()
+		close(done)
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return rules, nil
+	}
+}
+
+// robots holds the robots.txt rules that apply to us
+type robots struct {
+	fetched    time.Time
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	prefix string
+	allow  bool
+}
+
+// allowed applies the longest matching rule; Allow wins ties
+func (r *robots) allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	best := -1
+	allow := true
+	for _, rule := range r.rules {
+		if !strings.HasPrefix(path, rule.prefix) {
+			continue
+		}
+		if len(rule.prefix) > best || (len(rule.prefix) == best && rule.allow) {
+			best = len(rule.prefix)
+			allow = rule.allow
+		}
+	}
+	return allow
+}
+
+// parseRobots reads the group for our user agent, falling back to "*".
+// Wildcards inside paths aren't supported; such rules match as prefixes
+// up to the first "*".
+func parseRobots(text, userAgent string) *robots {
+	product := strings.ToLower(strings.SplitN(userAgent, "/", 2)[0])
+
+	type group struct {
+		agents []string
+		rules  []robotsRule
+		delay  time.Duration
+	}
+	var groups []*group
+	var current *group
+	inAgents := false
+
+	for _, line := range strings.Split(text, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				current = &group{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			inAgents = true
+			continue
+		case "allow", "disallow":
+			if current != nil {
+				if i := strings.Index(value, "*"); i >= 0 {
+					value = value[:i]
+				}
+				value = strings.TrimSuffix(value, "$")
+				// An empty Disallow allows everything
+				if value != "" || key == "allow" {
+					current.rules = append(current.rules, robotsRule{prefix: value, allow: key == "allow"})
+				}
+			}
+		case "crawl-delay":
+			if current != nil {
+				var seconds float64
+				if _, err := fmt.Sscanf(value, "%g", &seconds); err == nil && seconds > 0 {
+					current.delay = min(time.Duration(seconds*float64(time.Second)), maxCrawlDelay)
+				}
+			}
+		}
+		inAgents = false
+	}
+
+	var matched, wildcard *group
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			if agent == "*" && wildcard == nil {
+				wildcard = g
+			} else if agent != "*" && strings.Contains(product, agent) && matched == nil {
+				matched = g
+			}
+		}
+	}
+	if matched == nil {
+		matched = wildcard
+	}
+
+	rules := &robots{fetched: time.Now()}
+	if matched != nil {
+		rules.rules = matched.rules
+		rules.crawlDelay = matched.delay
+	}
+	return rules
+}
@@ -0,0 +1,206 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newSite(t *testing.T, robotsTxt string, handler http.HandlerFunc) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		if robotsTxt == "" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, robotsTxt)
+	})
+	mux.HandleFunc("/", handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetcher_GetsPage(t *testing.T) {
+	var userAgent string
+	server := newSite(t, "", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		userAgent = r.UserAgent()
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<title>New</title>")
+	})
+
+	fetcher := New(Config{UserAgent: "hyprlnk-test/1.0", HostInterval: time.Millisecond})
+	resp, err := fetcher.Get(context.Background(), server.URL+"/old")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || string(resp.Body) != "<title>New</title>" {
+		t.Fatalf("Unexpected response %d %q", resp.StatusCode, resp.Body)
+	}
+	if resp.URL.Path != "/new" {
+		t.Errorf("Expected the final URL after redirects, got %s", resp.URL)
+	}
+	if userAgent != "hyprlnk-test/1.0" {
+		t.Errorf("Expected our User-Agent, got %q", userAgent)
+	}
+}
+
+func TestFetcher_ReturnsErrorStatuses(t *testing.T) {
+	server := newSite(t, "", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	resp, err := New(Config{HostInterval: time.Millisecond}).Get(context.Background(), server.URL+"/gone")
+	if err != nil {
+		t.Fatalf("Expected a response for a 404, got %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestFetcher_HonoursRobots(t *testing.T) {
+	var requests []string
+	var mutex sync.Mutex
+	server := newSite(t, `
+# comments are ignored
+User-agent: otherbot
+Disallow: /
+
+User-agent: *
+Disallow: /private
+Allow: /private/open
+`, func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests = append(requests, r.URL.Path)
+		mutex.Unlock()
+		fmt.Fprint(w, "ok")
+	})
+
+	fetcher := New(Config{HostInterval: time.Millisecond})
+	ctx := context.Background()
+
+	if _, err := fetcher.Get(ctx, server.URL+"/private/page"); !errors.Is(err, ErrDisallowed) {
+		t.Fatalf("Expected ErrDisallowed, got %v", err)
+	}
+	if _, err := fetcher.Get(ctx, server.URL+"/private/open/page"); err != nil {
+		t.Fatalf("Expected the longer Allow rule to win, got %v", err)
+	}
+	if _, err := fetcher.Get(ctx, server.URL+"/public"); err != nil {
+		t.Fatalf("Expected /public to be allowed, got %v", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if strings.Join(requests, ",") != "/private/open/page,/public" {
+		t.Errorf("Unexpected requests: %v", requests)
+	}
+}
+
+func TestFetcher_UsesOwnRobotsGroup(t *testing.T) {
+	server := newSite(t, "User-agent: *\nDisallow: /\n\nUser-agent: hyprlnk\nDisallow: /admin\n", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+
+	fetcher := New(Config{HostInterval: time.Millisecond})
+	if _, err := fetcher.Get(context.Background(), server.URL+"/page"); err != nil {
+		t.Fatalf("Expected our own group to override *, got %v", err)
+	}
+	if _, err := fetcher.Get(context.Background(), server.URL+"/admin"); !errors.Is(err, ErrDisallowed) {
+		t.Fatalf("Expected ErrDisallowed for /admin, got %v", err)
+	}
+}
+
+func TestFetcher_FetchesRobotsOnce(t *testing.T) {
+	var robotsRequests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&robotsRequests, 1)
+		fmt.Fprint(w, "User-agent: *\nDisallow:\n")
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := New(Config{HostInterval: time.Millisecond})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := fetcher.Get(context.Background(), fmt.Sprintf("%s/page/%d", server.URL, i)); err != nil {
+				t.Errorf("Get failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if robotsRequests != 1 {
+		t.Fatalf("Expected robots.txt to be fetched once, got %d", robotsRequests)
+	}
+}
+
+func TestFetcher_CapsBodySize(t *testing.T) {
+	server := newSite(t, "", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("x", 1000))
+	})
+
+	resp, err := New(Config{MaxBytes: 100, HostInterval: time.Millisecond}).Get(context.Background(), server.URL+"/big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Body) != 100 || !resp.Truncated {
+		t.Fatalf("Expected 100 truncated bytes, got %d (truncated=%v)", len(resp.Body), resp.Truncated)
+	}
+}
+
+func TestFetcher_SpacesOutRequestsToAHost(t *testing.T) {
+	var mutex sync.Mutex
+	var times []time.Time
+	server := newSite(t, "", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		times = append(times, time.Now())
+		mutex.Unlock()
+	})
+
+	fetcher := New(Config{HostInterval: 50 * time.Millisecond})
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fetcher.Get(context.Background(), fmt.Sprintf("%s/%d", server.URL, i))
+		}(i)
+	}
+	wg.Wait()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(times) != 3 {
+		t.Fatalf("Expected 3 page requests, got %d", len(times))
+	}
+	// robots.txt took the first slot, so three pages need at least three gaps
+	if spread := times[2].Sub(times[0]); spread < 90*time.Millisecond {
+		t.Fatalf("Expected requests spaced 50ms apart, first to last took %v", spread)
+	}
+}
+
+func TestFetcher_RejectsOtherSchemes(t *testing.T) {
+	if _, err := New(Config{}).Get(context.Background(), "file:///etc/passwd"); err == nil {
+		t.Fatal("Expected file URLs to be refused")
+	}
+}
@@ -0,0 +1,62 @@
+package formats
+
+import (
+	"io"
+	"strings"
+
+	"hyprlnk/internal/models"
+)
+
+// WriteAnnotationsMarkdown writes notes and highlights as Markdown, one
+// section per page under the bookmark's title: highlights as quotes
+// followed by their comments, notes as they were written
+func WriteAnnotationsMarkdown(w io.Writer, annotations []models.Annotation, bookmarks []models.Bookmark) error {
+	out := &errWriter{w: w}
+	out.printf("# Annotations\n")
+
+	titles := make(map[int64]string, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		titles[bookmark.ID] = bookmark.Title
+	}
+
+	var pages []string
+	byPage := make(map[string][]models.Annotation)
+	for _, annotation := range annotations {
+		if _, ok := byPage[annotation.URL]; !ok {
+			pages = append(pages, annotation.URL)
+		}
+		byPage[annotation.URL] = append(byPage[annotation.URL], annotation)
+	}
+
+	for _, page := range pages {
+		group := byPage[page]
+		title := ""
+		for _, annotation := range group {
+			if title = titles[annotation.BookmarkID]; title != "" {
+				break
+			}
+		}
+		if title == "" {
+			title = page
+		}
+		out.printf("\n## [%s](<%s>)\n", markdownEscaper.Replace(title), page)
+
+		for _, annotation := range group {
+			out.printf("\n")
+			if annotation.Highlight != nil {
+				text := strings.TrimSpace(annotation.Highlight.Text)
+				out.printf("> %s\n", strings.ReplaceAll(text, "\n", "\n> "))
+				if body := strings.TrimSpace(annotation.Body); body != "" {
+					out.printf("\n%s\n", body)
+				}
+				continue
+			}
+			out.printf("%s\n", strings.TrimSpace(annotation.Body))
+		}
+	}
+
+	return out.err
+}
+
+// markdownEscaper keeps page titles from breaking the link around them
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`)
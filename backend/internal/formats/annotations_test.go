@@ -0,0 +1,45 @@
+package formats
+
+import (
+	"bytes"
+	"testing"
+
+	"hyprlnk/internal/models"
+)
+
+func TestWriteAnnotationsMarkdown(t *testing.T) {
+	bookmarks := []models.Bookmark{{ID: 1, Title: "Go [docs]"}}
+	annotations := []models.Annotation{
+		{ID: 1, BookmarkID: 1, URL: "https://go.dev", Type: models.AnnotationHighlight, Body: "  Worth remembering ",
+			Highlight: &models.Highlight{Text: "Line one\nLine two"}},
+		{ID: 2, URL: "https://example.com/page", Type: models.AnnotationNote, Body: "A note on an unsaved page\n"},
+		{ID: 3, BookmarkID: 1, URL: "https://go.dev", Type: models.AnnotationNote, Body: "Second note"},
+		{ID: 4, BookmarkID: 1, URL: "https://go.dev", Type: models.AnnotationHighlight, Highlight: &models.Highlight{Text: "Bare quote"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAnnotationsMarkdown(&buf, annotations, bookmarks); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# Annotations
+
+## [Go \[docs\]](<https://go.dev>)
+
+> Line one
+> Line two
+
+Worth remembering
+
+Second note
+
+> Bare quote
+
+## [https://example.com/page](<https://example.com/page>)
+
+A note on an unsaved page
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Unexpected Markdown:\n%s\nwant:\n%s", got, want)
+	}
+}
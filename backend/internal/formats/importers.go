@@ -0,0 +1,123 @@
+package formats
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"hyprlnk/internal/models"
+)
+
+// Import is what an importer read from an export file
+type Import struct {
+	Bookmarks []models.ImportedBookmark
+	Sessions  []models.Session
+	Errors    []models.ItemError // skipped entries; Row is the line number, or the item index for JSON
+}
+
+// Importer parses one export format
+type Importer func(r io.Reader) (*Import, error)
+
+// Importers maps format names to their importers
+var Importers = map[string]Importer{
+	"netscape": importNetscape,
+	"pocket":   ParsePocket,
+	"raindrop": ParseRaindrop,
+	"pinboard": ParsePinboard,
+	"onetab":   ParseOneTab,
+}
+
+// ImporterNames lists the supported format names, sorted
+func ImporterNames() []string {
+	names := make([]string, 0, len(Importers))
+	for name := range Importers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func importNetscape(r io.Reader) (*Import, error) {
+	bookmarks, err := ParseNetscape(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Import{Bookmarks: bookmarks}, nil
+}
+
+// checkURL rejects values that can't be bookmarked, such as blank cells
+// or relative paths
+func checkURL(raw string) error {
+	if raw == "" {
+		return fmt.Errorf("missing URL")
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %v", raw, err)
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Errorf("invalid URL %q: not absolute", raw)
+	}
+	return nil
+}
+
+// csvRow gives access to a CSV record's cells by header name
+type csvRow struct {
+	columns map[string]int
+	record  []string
+}
+
+func (row csvRow) get(column string) string {
+	i, ok := row.columns[column]
+	if !ok || i >= len(row.record) {
+		return ""
+	}
+	return strings.TrimSpace(row.record[i])
+}
+
+// readCSV calls handle for every record after the header. Records that fail
+// to parse or that handle rejects are recorded as errors instead of
+// aborting the import; a missing required column does abort it.
+func readCSV(r io.Reader, required []string, handle func(row csvRow) error) ([]models.ItemError, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV has no %q column", name)
+		}
+	}
+
+	var rowErrors []models.ItemError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rowErrors, nil
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			if parseErr, ok := err.(*csv.ParseError); ok {
+				rowErrors = append(rowErrors, models.ItemError{Row: parseErr.StartLine, Message: parseErr.Err.Error()})
+				continue
+			}
+			return rowErrors, err
+		}
+		if err := handle(csvRow{columns: columns, record: record}); err != nil {
+			rowErrors = append(rowErrors, models.ItemError{Row: line, Message: err.Error()})
+		}
+	}
+}
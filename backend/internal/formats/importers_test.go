@@ -0,0 +1,197 @@
+package formats
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hyprlnk/internal/models"
+)
+
+func TestParsePocket_CSV(t *testing.T) {
+	input := "title,url,time_added,tags,status\n" +
+		"Go,https://go.dev,1700000000,lang|google,unread\n" +
+		"Missing URL,,1700000000,,unread\n" +
+		"Relative,/docs,1700000000,,archive\n" +
+		"\"Quoted, title\",https://example.com,,,archive\n"
+
+	result, err := ParsePocket(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(result.Bookmarks) != 2 {
+		t.Fatalf("Expected 2 bookmarks, got %d: %+v", len(result.Bookmarks), result.Bookmarks)
+	}
+
+	goBookmark := result.Bookmarks[0]
+	if goBookmark.URL != "https://go.dev" || goBookmark.Title != "Go" || !reflect.DeepEqual(goBookmark.Tags, []string{"lang", "google"}) {
+		t.Errorf("Unexpected bookmark %+v", goBookmark)
+	}
+	if !goBookmark.AddedDate.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Expected time_added to be read, got %v", goBookmark.AddedDate)
+	}
+	if got := result.Bookmarks[1]; got.Title != "Quoted, title" || !got.AddedDate.IsZero() {
+		t.Errorf("Unexpected bookmark %+v", got)
+	}
+
+	assertRows(t, result.Errors, 3, 4)
+}
+
+func TestParsePocket_HTML(t *testing.T) {
+	input := `<!DOCTYPE html>
+<html><body>
+<h1>Unread</h1>
+<ul>
+<li><a href="https://go.dev" time_added="1700000000" tags="lang,google">Go</a></li>
+<li><a href="https://example.com/untitled" time_added="1700000000">https://example.com/untitled</a></li>
+<li><a href="javascript:void(0)">Bookmarklet</a></li>
+</ul>
+</body></html>
+`
+	result, err := ParsePocket(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(result.Bookmarks) != 2 {
+		t.Fatalf("Expected 2 bookmarks, got %d: %+v", len(result.Bookmarks), result.Bookmarks)
+	}
+	if got := result.Bookmarks[0]; got.Title != "Go" || !reflect.DeepEqual(got.Tags, []string{"lang", "google"}) {
+		t.Errorf("Unexpected bookmark %+v", got)
+	}
+	if got := result.Bookmarks[1]; got.Title != "" {
+		t.Errorf("Expected the URL placeholder title to be dropped, got %q", got.Title)
+	}
+	assertRows(t, result.Errors, 7)
+}
+
+func TestParseRaindrop(t *testing.T) {
+	input := "id,title,note,excerpt,url,folder,tags,created\n" +
+		"1,Go,My note,An excerpt,https://go.dev,Dev,\"lang, google\",2023-11-14T22:13:20Z\n" +
+		"2,Example,,Only an excerpt,https://example.com,Unsorted,,\n" +
+		"3,Bad date,,,https://example.org,Dev,,yesterday\n" +
+		"4,No URL,,,,Dev,,\n"
+
+	result, err := ParseRaindrop(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(result.Bookmarks) != 2 {
+		t.Fatalf("Expected 2 bookmarks, got %d: %+v", len(result.Bookmarks), result.Bookmarks)
+	}
+
+	goBookmark := result.Bookmarks[0]
+	if goBookmark.Description != "My note" || goBookmark.Folder != "Dev" || !reflect.DeepEqual(goBookmark.Tags, []string{"lang", "google"}) {
+		t.Errorf("Unexpected bookmark %+v", goBookmark)
+	}
+	if !goBookmark.AddedDate.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Expected created to be read, got %v", goBookmark.AddedDate)
+	}
+	if got := result.Bookmarks[1]; got.Description != "Only an excerpt" || got.Folder != "" {
+		t.Errorf("Expected the excerpt and no folder for Unsorted, got %+v", got)
+	}
+
+	assertRows(t, result.Errors, 4, 5)
+}
+
+func TestParseRaindrop_MissingURLColumn(t *testing.T) {
+	if _, err := ParseRaindrop(strings.NewReader("id,title\n1,Go\n")); err == nil {
+		t.Error("Expected an error for a CSV without a url column")
+	}
+}
+
+func TestParsePinboard(t *testing.T) {
+	input := `[
+		{"href": "https://go.dev", "description": "Go", "extended": "Notes", "time": "2023-11-14T22:13:20Z", "tags": "lang  google"},
+		{"href": "", "description": "No URL"},
+		"not an object",
+		{"href": "https://example.com", "description": "Bad date", "time": "14/11/2023"},
+		{"href": "https://example.org", "description": "Untagged"}
+	]`
+
+	result, err := ParsePinboard(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(result.Bookmarks) != 2 {
+		t.Fatalf("Expected 2 bookmarks, got %d: %+v", len(result.Bookmarks), result.Bookmarks)
+	}
+
+	want := models.ImportedBookmark{
+		URL:         "https://go.dev",
+		Title:       "Go",
+		Description: "Notes",
+		Tags:        []string{"lang", "google"},
+		AddedDate:   time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC),
+	}
+	if got := result.Bookmarks[0]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if got := result.Bookmarks[1]; got.Tags != nil || !got.AddedDate.IsZero() {
+		t.Errorf("Unexpected bookmark %+v", got)
+	}
+
+	assertRows(t, result.Errors, 2, 3, 4)
+}
+
+func TestParsePinboard_NotAnArray(t *testing.T) {
+	if _, err := ParsePinboard(strings.NewReader(`{"href": "https://go.dev"}`)); err == nil {
+		t.Error("Expected an error for an export that isn't a JSON array")
+	}
+}
+
+func TestParseOneTab(t *testing.T) {
+	input := "https://go.dev | Go\n" +
+		"https://example.com | Title | with a pipe\n" +
+		"\n" +
+		"\n" +
+		"not a url | Broken\n" +
+		"https://example.org\n"
+
+	result, err := ParseOneTab(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(result.Sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d: %+v", len(result.Sessions), result.Sessions)
+	}
+
+	first := result.Sessions[0]
+	wantTabs := []models.Tab{
+		{URL: "https://go.dev", Title: "Go", Index: 0},
+		{URL: "https://example.com", Title: "Title | with a pipe", Index: 1},
+	}
+	if first.Name != "OneTab group 1" || !reflect.DeepEqual(first.Tabs, wantTabs) {
+		t.Errorf("Unexpected first session %+v", first)
+	}
+
+	second := result.Sessions[1]
+	if second.Name != "OneTab group 2" || len(second.Tabs) != 1 || second.Tabs[0].URL != "https://example.org" || second.Tabs[0].Index != 0 {
+		t.Errorf("Unexpected second session %+v", second)
+	}
+
+	assertRows(t, result.Errors, 5)
+}
+
+func TestImporterNames(t *testing.T) {
+	want := []string{"netscape", "onetab", "pinboard", "pocket", "raindrop"}
+	if got := ImporterNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// assertRows checks that exactly the given rows were skipped, each with a reason
+func assertRows(t *testing.T, errors []models.ItemError, rows ...int) {
+	t.Helper()
+	if len(errors) != len(rows) {
+		t.Fatalf("Expected %d skipped rows, got %d: %+v", len(rows), len(errors), errors)
+	}
+	for i, row := range rows {
+		if errors[i].Row != row {
+			t.Errorf("Expected skipped row %d, got %d (%s)", row, errors[i].Row, errors[i].Message)
+		}
+		if errors[i].Message == "" {
+			t.Errorf("Skipped row %d has no message", errors[i].Row)
+		}
+	}
+}
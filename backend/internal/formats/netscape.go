@@ -0,0 +1,248 @@
+// Package formats converts between HyprLnk's models and the bookmark export
+// formats of browsers and third-party services.
+package formats
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"hyprlnk/internal/models"
+)
+
+// ParseNetscape reads a Netscape Bookmark File, the HTML format every
+// browser and most bookmark services export. Folders become FolderPath,
+// ADD_DATE becomes AddedDate, TAGS become Tags and <DD> text the Description.
+func ParseNetscape(r io.Reader) ([]models.ImportedBookmark, error) {
+	tokenizer := nethtml.NewTokenizer(r)
+
+	var bookmarks []models.ImportedBookmark
+	var folders []string // one entry per open <DL>; "" for lists that aren't a folder
+	var pendingFolder *string
+
+	var current *models.ImportedBookmark // bookmark whose <A> is open
+	var describing *models.ImportedBookmark
+	describable := -1 // index of the bookmark a <DD> describes; -1 after a folder's <H3>
+	var inFolderTitle bool
+	var folderTitle strings.Builder
+
+	folderPath := func() []string {
+		var path []string
+		for _, name := range folders {
+			if name != "" {
+				path = append(path, name)
+			}
+		}
+		return path
+	}
+
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case nethtml.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return bookmarks, fmt.Errorf("failed to parse bookmark file: %w", err)
+			}
+			return bookmarks, nil
+
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.H3:
+				inFolderTitle = true
+				folderTitle.Reset()
+				describing = nil
+				describable = -1
+			case atom.Dl:
+				name := ""
+				if pendingFolder != nil {
+					name = *pendingFolder
+					pendingFolder = nil
+				}
+				folders = append(folders, name)
+				describing = nil
+				describable = -1
+			case atom.A:
+				describable = -1
+				href := attr(token, "href")
+				if href == "" || strings.HasPrefix(strings.ToLower(href), "place:") {
+					continue
+				}
+				current = &models.ImportedBookmark{
+					URL:        href,
+					FolderPath: folderPath(),
+					Tags:       splitTags(attr(token, "tags"), ","),
+					AddedDate:  parseUnixAttr(attr(token, "add_date")),
+				}
+				current.Folder = strings.Join(current.FolderPath, "/")
+				describing = nil
+			case atom.Dt:
+				describing = nil
+			case atom.Dd:
+				// A folder's description has nowhere to go
+				if describable >= 0 {
+					describing = &bookmarks[describable]
+				}
+			}
+
+		case nethtml.EndTagToken:
+			token := tokenizer.Token()
+			switch token.DataAtom {
+			case atom.H3:
+				inFolderTitle = false
+				name := strings.TrimSpace(folderTitle.String())
+				pendingFolder = &name
+			case atom.Dl:
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+				describing = nil
+				describable = -1
+			case atom.A:
+				if current != nil {
+					current.Title = strings.TrimSpace(current.Title)
+					bookmarks = append(bookmarks, *current)
+					describable = len(bookmarks) - 1
+					current = nil
+				}
+			}
+
+		case nethtml.TextToken:
+			text := string(tokenizer.Text())
+			switch {
+			case inFolderTitle:
+				folderTitle.WriteString(text)
+			case current != nil:
+				current.Title += text
+			case describing != nil:
+				describing.Description = strings.TrimSpace(describing.Description + " " + strings.TrimSpace(text))
+			}
+		}
+	}
+}
+
+// WriteNetscape writes bookmarks as a Netscape Bookmark File that browsers
+// can import, nesting them inside their collections
+func WriteNetscape(w io.Writer, bookmarks []models.Bookmark, collections []models.Collection) error {
+	out := &errWriter{w: w}
+
+	out.printf("<!DOCTYPE NETSCAPE-Bookmark-file-1>\n")
+	out.printf("<!-- This is an automatically generated file.\n     It will be read and overwritten.\n     DO NOT EDIT! -->\n")
+	out.printf("<META HTTP-EQUIV=\"Content-Type\" CONTENT=\"text/html; charset=UTF-8\">\n")
+	out.printf("<TITLE>Bookmarks</TITLE>\n")
+	out.printf("<H1>Bookmarks</H1>\n")
+
+	children := make(map[int64][]models.Collection)
+	known := make(map[int64]bool)
+	for _, collection := range collections {
+		children[collection.ParentID] = append(children[collection.ParentID], collection)
+		known[collection.ID] = true
+	}
+	for parentID := range children {
+		siblings := children[parentID]
+		sort.SliceStable(siblings, func(i, j int) bool { return siblings[i].Position < siblings[j].Position })
+	}
+
+	filed := make(map[int64][]models.Bookmark)
+	for _, bookmark := range bookmarks {
+		collectionID := bookmark.CollectionID
+		if !known[collectionID] {
+			collectionID = 0
+		}
+		filed[collectionID] = append(filed[collectionID], bookmark)
+	}
+	for collectionID := range filed {
+		group := filed[collectionID]
+		sort.SliceStable(group, func(i, j int) bool { return group[i].CreatedAt.Before(group[j].CreatedAt) })
+	}
+
+	var writeList func(parentID int64, depth int)
+	writeList = func(parentID int64, depth int) {
+		indent := strings.Repeat("    ", depth)
+		out.printf("%s<DL><p>\n", indent)
+
+		for _, collection := range children[parentID] {
+			out.printf("%s    <DT><H3 ADD_DATE=\"%d\" LAST_MODIFIED=\"%d\">%s</H3>\n",
+				indent, collection.CreatedAt.Unix(), collection.UpdatedAt.Unix(), html.EscapeString(collection.Name))
+			writeList(collection.ID, depth+1)
+		}
+
+		for _, bookmark := range filed[parentID] {
+			title := bookmark.Title
+			if title == "" {
+				title = bookmark.URL
+			}
+			out.printf("%s    <DT><A HREF=\"%s\" ADD_DATE=\"%d\" LAST_MODIFIED=\"%d\"",
+				indent, html.EscapeString(bookmark.URL), bookmark.CreatedAt.Unix(), bookmark.UpdatedAt.Unix())
+			if len(bookmark.Tags) > 0 {
+				out.printf(" TAGS=\"%s\"", html.EscapeString(strings.Join(bookmark.Tags, ",")))
+			}
+			out.printf(">%s</A>\n", html.EscapeString(title))
+			if bookmark.Description != "" {
+				out.printf("%s    <DD>%s\n", indent, html.EscapeString(bookmark.Description))
+			}
+		}
+
+		out.printf("%s</DL><p>\n", indent)
+	}
+	writeList(0, 0)
+
+	return out.err
+}
+
+// errWriter remembers the first write error so callers can check once
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) printf(format string, args ...interface{}) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = fmt.Fprintf(e.w, format, args...)
+}
+
+func attr(token nethtml.Token, name string) string {
+	for _, a := range token.Attr {
+		if strings.EqualFold(a.Key, name) {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+// splitTags splits a tag list on sep, trimming and dropping blanks
+func splitTags(value, sep string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, sep) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// parseUnixAttr parses an ADD_DATE value. It is seconds since the epoch,
+// though some exporters write milliseconds or microseconds.
+func parseUnixAttr(value string) time.Time {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	}
+	switch {
+	case n > 1e15:
+		return time.UnixMicro(n)
+	case n > 1e12:
+		return time.UnixMilli(n)
+	default:
+		return time.Unix(n, 0)
+	}
+}
@@ -0,0 +1,120 @@
+package formats
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hyprlnk/internal/models"
+)
+
+func TestParseNetscape(t *testing.T) {
+	input := `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<DL><p>
+    <DT><A HREF="https://top.example" ADD_DATE="1700000000" TAGS="a, b">Top</A>
+    <DD>Top description
+    <DT><H3>Work</H3>
+    <DL><p>
+        <DT><H3>Go</H3>
+        <DL><p>
+            <DT><A HREF="https://go.dev" ADD_DATE="1700000000000">Go</A>
+        </DL><p>
+        <DT><A HREF="https://work.example">Work &amp; stuff</A>
+    </DL><p>
+    <DT><A HREF="place:sort=8">Smart folder</A>
+    <DT><A HREF="">No URL</A>
+</DL><p>
+`
+	bookmarks, err := ParseNetscape(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(bookmarks) != 3 {
+		t.Fatalf("Expected 3 bookmarks, got %d: %+v", len(bookmarks), bookmarks)
+	}
+
+	top := bookmarks[0]
+	if top.Title != "Top" || top.Description != "Top description" || !reflect.DeepEqual(top.Tags, []string{"a", "b"}) {
+		t.Errorf("Unexpected top-level bookmark %+v", top)
+	}
+	if !top.AddedDate.Equal(time.Unix(1700000000, 0)) || len(top.FolderPath) != 0 {
+		t.Errorf("Unexpected date or folder %v %v", top.AddedDate, top.FolderPath)
+	}
+
+	if goDev := bookmarks[1]; goDev.Folder != "Work/Go" || !reflect.DeepEqual(goDev.FolderPath, []string{"Work", "Go"}) {
+		t.Errorf("Expected nested folder Work/Go, got %q %v", goDev.Folder, goDev.FolderPath)
+	} else if !goDev.AddedDate.Equal(time.UnixMilli(1700000000000)) {
+		t.Errorf("Expected a millisecond ADD_DATE to be understood, got %v", goDev.AddedDate)
+	}
+
+	if work := bookmarks[2]; work.Title != "Work & stuff" || work.Folder != "Work" {
+		t.Errorf("Expected to be back in Work after the nested list, got %q in %q", work.Title, work.Folder)
+	}
+}
+
+func TestParseNetscape_FolderDescription(t *testing.T) {
+	input := `<DL><p>
+    <DT><A HREF="https://a.example">A</A>
+    <DT><H3>Folder</H3>
+    <DD>Folder description
+    <DL><p>
+        <DT><A HREF="https://b.example">B</A>
+    </DL><p>
+</DL><p>
+`
+	bookmarks, err := ParseNetscape(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(bookmarks) != 2 {
+		t.Fatalf("Expected 2 bookmarks, got %d", len(bookmarks))
+	}
+	for _, bookmark := range bookmarks {
+		if bookmark.Description != "" {
+			t.Errorf("Expected the folder's description to stay off %s, got %q", bookmark.URL, bookmark.Description)
+		}
+	}
+}
+
+func TestNetscape_RoundTrip(t *testing.T) {
+	created := time.Unix(1700000000, 0)
+	collections := []models.Collection{
+		{ID: 1, Name: "Reading"},
+		{ID: 2, Name: "Papers & notes", ParentID: 1},
+	}
+	bookmarks := []models.Bookmark{
+		{URL: "https://a.example/?q=1&r=2", Title: "A <tag>", Description: "About A", Tags: []string{"x", "y"}, CreatedAt: created},
+		{URL: "https://b.example", Title: "B", CollectionID: 1, Tags: []string{}, CreatedAt: created.Add(time.Second)},
+		{URL: "https://c.example", Title: "", Description: "Only a description", CollectionID: 2, Tags: []string{}, CreatedAt: created.Add(2 * time.Second)},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteNetscape(&buf, bookmarks, collections); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	parsed, err := ParseNetscape(&buf)
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if len(parsed) != len(bookmarks) {
+		t.Fatalf("Expected %d bookmarks back, got %d", len(bookmarks), len(parsed))
+	}
+
+	byURL := make(map[string]models.ImportedBookmark)
+	for _, bookmark := range parsed {
+		byURL[bookmark.URL] = bookmark
+	}
+	a := byURL["https://a.example/?q=1&r=2"]
+	if a.Title != "A <tag>" || a.Description != "About A" || !reflect.DeepEqual(a.Tags, []string{"x", "y"}) || !a.AddedDate.Equal(created) {
+		t.Errorf("Bookmark A didn't round-trip: %+v", a)
+	}
+	if b := byURL["https://b.example"]; b.Folder != "Reading" || b.Description != "" {
+		t.Errorf("Bookmark B didn't round-trip: %+v", b)
+	}
+	c := byURL["https://c.example"]
+	if c.Folder != "Reading/Papers & notes" || c.Title != "https://c.example" || c.Description != "Only a description" {
+		t.Errorf("Bookmark C didn't round-trip: %+v", c)
+	}
+}
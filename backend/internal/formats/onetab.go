@@ -0,0 +1,58 @@
+package formats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"hyprlnk/internal/models"
+)
+
+// ParseOneTab reads OneTab's "Export URLs" text: one "URL | title" line per
+// tab, with blank lines between tab groups. Each group becomes a session.
+func ParseOneTab(r io.Reader) (*Import, error) {
+	result := &Import{}
+	var tabs []models.Tab
+
+	flush := func() {
+		if len(tabs) == 0 {
+			return
+		}
+		result.Sessions = append(result.Sessions, models.Session{
+			Name: fmt.Sprintf("OneTab group %d", len(result.Sessions)+1),
+			Tabs: tabs,
+		})
+		tabs = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // data: URLs can be long
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			flush()
+			continue
+		}
+
+		rawURL, title, _ := strings.Cut(text, " | ")
+		rawURL = strings.TrimSpace(rawURL)
+		if err := checkURL(rawURL); err != nil {
+			result.Errors = append(result.Errors, models.ItemError{Row: line, Message: err.Error()})
+			continue
+		}
+		tabs = append(tabs, models.Tab{
+			URL:   rawURL,
+			Title: strings.TrimSpace(title),
+			Index: len(tabs),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("failed to read OneTab export: %w", err)
+	}
+	flush()
+
+	return result, nil
+}
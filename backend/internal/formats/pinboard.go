@@ -0,0 +1,60 @@
+package formats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"hyprlnk/internal/models"
+)
+
+// pinboardPost is one item of Pinboard's JSON export. Pinboard calls the
+// title "description" and the notes "extended".
+type pinboardPost struct {
+	Href        string `json:"href"`
+	Description string `json:"description"`
+	Extended    string `json:"extended"`
+	Time        string `json:"time"`
+	Tags        string `json:"tags"` // space-separated
+}
+
+// ParsePinboard reads a Pinboard JSON export (pinboard_export.json)
+func ParsePinboard(r io.Reader) (*Import, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("failed to parse Pinboard export: %w", err)
+	}
+
+	result := &Import{}
+	for i, raw := range items {
+		bookmark, err := parsePinboardPost(raw)
+		if err != nil {
+			result.Errors = append(result.Errors, models.ItemError{Row: i + 1, Message: err.Error()})
+			continue
+		}
+		result.Bookmarks = append(result.Bookmarks, bookmark)
+	}
+	return result, nil
+}
+
+func parsePinboardPost(raw json.RawMessage) (models.ImportedBookmark, error) {
+	var post pinboardPost
+	if err := json.Unmarshal(raw, &post); err != nil {
+		return models.ImportedBookmark{}, fmt.Errorf("not a Pinboard bookmark: %s", raw)
+	}
+	if err := checkURL(post.Href); err != nil {
+		return models.ImportedBookmark{}, err
+	}
+	added, err := parseISOTime(post.Time)
+	if err != nil {
+		return models.ImportedBookmark{}, err
+	}
+
+	return models.ImportedBookmark{
+		URL:         post.Href,
+		Title:       post.Description,
+		Description: post.Extended,
+		Tags:        splitTags(post.Tags, " "),
+		AddedDate:   added,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package formats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"hyprlnk/internal/models"
+)
+
+// ParsePocket reads a Pocket export, either the older ril_export.html or
+// the CSV export (title, url, time_added, tags, status)
+func ParsePocket(r io.Reader) (*Import, error) {
+	buffered := bufio.NewReader(r)
+	start, _ := buffered.Peek(512)
+	if strings.HasPrefix(strings.TrimSpace(string(start)), "<") {
+		return parsePocketHTML(buffered)
+	}
+	return parsePocketCSV(buffered)
+}
+
+func parsePocketCSV(r io.Reader) (*Import, error) {
+	result := &Import{}
+	rowErrors, err := readCSV(r, []string{"url"}, func(row csvRow) error {
+		rawURL := row.get("url")
+		if err := checkURL(rawURL); err != nil {
+			return err
+		}
+		result.Bookmarks = append(result.Bookmarks, models.ImportedBookmark{
+			URL:       rawURL,
+			Title:     row.get("title"),
+			Tags:      splitTags(row.get("tags"), "|"),
+			AddedDate: parseUnixAttr(row.get("time_added")),
+		})
+		return nil
+	})
+	result.Errors = rowErrors
+	return result, err
+}
+
+// parsePocketHTML reads ril_export.html: <a href time_added tags> links in
+// lists under "Unread" and "Read Archive" headings
+func parsePocketHTML(r io.Reader) (*Import, error) {
+	result := &Import{}
+	tokenizer := nethtml.NewTokenizer(r)
+
+	var current *models.ImportedBookmark
+	var title strings.Builder
+	line := 1 // tracked for error reporting
+
+	for {
+		tokenType := tokenizer.Next()
+		line += strings.Count(string(tokenizer.Raw()), "\n")
+
+		switch tokenType {
+		case nethtml.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return result, fmt.Errorf("failed to parse Pocket export: %w", err)
+			}
+			return result, nil
+
+		case nethtml.StartTagToken:
+			token := tokenizer.Token()
+			if token.DataAtom != atom.A {
+				continue
+			}
+			href := attr(token, "href")
+			if err := checkURL(href); err != nil {
+				result.Errors = append(result.Errors, models.ItemError{Row: line, Message: err.Error()})
+				continue
+			}
+			current = &models.ImportedBookmark{
+				URL:       href,
+				Tags:      splitTags(attr(token, "tags"), ","),
+				AddedDate: parseUnixAttr(attr(token, "time_added")),
+			}
+			title.Reset()
+
+		case nethtml.TextToken:
+			if current != nil {
+				title.Write(tokenizer.Text())
+			}
+
+		case nethtml.EndTagToken:
+			name, _ := tokenizer.TagName()
+			if current != nil && atom.Lookup(name) == atom.A {
+				current.Title = strings.TrimSpace(title.String())
+				if current.Title == current.URL {
+					current.Title = "" // Pocket falls back to the URL for untitled items
+				}
+				result.Bookmarks = append(result.Bookmarks, *current)
+				current = nil
+			}
+		}
+	}
+}
+
+// parseISOTime parses the RFC 3339 timestamps used by Raindrop and
+// Pinboard; an empty value is the zero time
+func parseISOTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return parsed, nil
+}
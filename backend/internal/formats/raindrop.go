@@ -0,0 +1,48 @@
+package formats
+
+import (
+	"io"
+
+	"hyprlnk/internal/models"
+)
+
+// raindropUnsorted is the collection Raindrop files uncategorized items in
+const raindropUnsorted = "Unsorted"
+
+// ParseRaindrop reads a Raindrop.io CSV export (id, title, note, excerpt,
+// url, folder, tags, created, ...). The folder becomes a collection and the
+// note, or the excerpt when there is none, becomes the description.
+func ParseRaindrop(r io.Reader) (*Import, error) {
+	result := &Import{}
+	rowErrors, err := readCSV(r, []string{"url"}, func(row csvRow) error {
+		rawURL := row.get("url")
+		if err := checkURL(rawURL); err != nil {
+			return err
+		}
+		created, err := parseISOTime(row.get("created"))
+		if err != nil {
+			return err
+		}
+
+		description := row.get("note")
+		if description == "" {
+			description = row.get("excerpt")
+		}
+		folder := row.get("folder")
+		if folder == raindropUnsorted {
+			folder = ""
+		}
+
+		result.Bookmarks = append(result.Bookmarks, models.ImportedBookmark{
+			URL:         rawURL,
+			Title:       row.get("title"),
+			Description: description,
+			Tags:        splitTags(row.get("tags"), ","),
+			Folder:      folder,
+			AddedDate:   created,
+		})
+		return nil
+	})
+	result.Errors = rowErrors
+	return result, err
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/services"
+)
+
+type AnnotationHandler struct {
+    service services.HyprLinkService
+}
+
+func NewAnnotationHandler(service services.HyprLinkService) *AnnotationHandler {
+    return &AnnotationHandler{service: service}
+}
+
+// GetAll lists notes and highlights; ?url= narrows to a page, ?type= to
+// note or highlight, and ?q= searches their text
+func (h *AnnotationHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+    query := r.URL.Query()
+    annotations, err := h.service.GetAnnotations(0, query.Get("url"), query.Get("type"), query.Get("q"))
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(annotations)
+}
+
+// GetForBookmark lists a bookmark's notes and highlights; ?type= and ?q=
+// work as for GetAll
+func (h *AnnotationHandler) GetForBookmark(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+        return
+    }
+
+    query := r.URL.Query()
+    annotations, err := h.service.GetAnnotations(id, "", query.Get("type"), query.Get("q"))
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(annotations)
+}
+
+func (h *AnnotationHandler) Get(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid annotation ID", http.StatusBadRequest)
+        return
+    }
+
+    annotation, err := h.service.GetAnnotation(id)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(annotation)
+}
+
+func (h *AnnotationHandler) Create(w http.ResponseWriter, r *http.Request) {
+    var annotation models.Annotation
+    if err := json.NewDecoder(r.Body).Decode(&annotation); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    if err := h.service.CreateAnnotation(&annotation); err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(annotation)
+}
+
+func (h *AnnotationHandler) Update(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid annotation ID", http.StatusBadRequest)
+        return
+    }
+
+    var annotation models.Annotation
+    if err := json.NewDecoder(r.Body).Decode(&annotation); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    annotation.ID = id
+    if err := h.service.UpdateAnnotation(&annotation); err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(annotation)
+}
+
+func (h *AnnotationHandler) Delete(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid annotation ID", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.service.DeleteAnnotation(id); err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
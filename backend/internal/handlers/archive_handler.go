@@ -0,0 +1,82 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+    "hyprlnk/internal/services"
+)
+
+type ArchiveHandler struct {
+    service services.HyprLinkService
+}
+
+func NewArchiveHandler(service services.HyprLinkService) *ArchiveHandler {
+    return &ArchiveHandler{service: service}
+}
+
+// Get returns a bookmark's archive: its record as JSON by default, the
+// HTML snapshot with ?format=html or the readable text with ?format=text
+func (h *ArchiveHandler) Get(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+        return
+    }
+
+    switch format := r.URL.Query().Get("format"); format {
+    case "", "json":
+        archive, err := h.service.GetBookmarkArchive(id)
+        if err != nil {
+            writeServiceError(w, err)
+            return
+        }
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(archive)
+
+    case "html":
+        snapshot, err := h.service.GetArchiveSnapshot(id)
+        if err != nil {
+            writeServiceError(w, err)
+            return
+        }
+        w.Header().Set("Content-Type", "text/html; charset=utf-8")
+        // Snapshots are other sites' pages: everything they need is inlined,
+        // so forbid scripts and any request back out
+        w.Header().Set("Content-Security-Policy", "default-src 'none'; img-src data:; style-src 'unsafe-inline' data:; font-src data:; sandbox")
+        w.Header().Set("X-Content-Type-Options", "nosniff")
+        w.Write(snapshot)
+
+    case "text":
+        text, err := h.service.GetArchiveText(id)
+        if err != nil {
+            writeServiceError(w, err)
+            return
+        }
+        w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+        w.Write([]byte(text))
+
+    default:
+        http.Error(w, "Unknown format "+strconv.Quote(format)+", expected json, html or text", http.StatusBadRequest)
+    }
+}
+
+// Create archives a bookmark's page now and returns the record
+func (h *ArchiveHandler) Create(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+        return
+    }
+
+    archive, err := h.service.ArchiveBookmark(id)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(archive)
+}
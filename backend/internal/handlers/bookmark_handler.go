@@ -2,6 +2,7 @@ package handlers
 
 import (
     "encoding/json"
+    "fmt"
     "net/http"
     "strconv"
 
@@ -18,8 +19,16 @@ func NewBookmarkHandler(service services.HyprLinkService) *BookmarkHandler {
     return &BookmarkHandler{service: service}
 }
 
+// GetAll lists bookmarks; ?tag= narrows to a tag and the tags nested under it
 func (h *BookmarkHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-    bookmarks, err := h.service.GetAllBookmarks()
+    var bookmarks []models.Bookmark
+    var err error
+
+    if tag := r.URL.Query().Get("tag"); tag != "" {
+        bookmarks, err = h.service.GetBookmarksByTag(tag)
+    } else {
+        bookmarks, err = h.service.GetAllBookmarks()
+    }
     if err != nil {
         http.Error(w, err.Error(), http.StatusInternalServerError)
         return
@@ -29,6 +38,35 @@ func (h *BookmarkHandler) GetAll(w http.ResponseWriter, r *http.Request) {
     json.NewEncoder(w).Encode(bookmarks)
 }
 
+func (h *BookmarkHandler) Get(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+        return
+    }
+
+    bookmark, err := h.service.GetBookmark(id)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(bookmark)
+}
+
+// Lookup finds the bookmark for ?url=, matching by canonical URL
+func (h *BookmarkHandler) Lookup(w http.ResponseWriter, r *http.Request) {
+    bookmark, err := h.service.LookupBookmark(r.URL.Query().Get("url"))
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(bookmark)
+}
+
 func (h *BookmarkHandler) Create(w http.ResponseWriter, r *http.Request) {
     var bookmark models.Bookmark
     if err := json.NewDecoder(r.Body).Decode(&bookmark); err != nil {
@@ -36,13 +74,17 @@ func (h *BookmarkHandler) Create(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    if err := h.service.CreateBookmark(&bookmark); err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+    created, err := h.service.CreateBookmark(&bookmark)
+    if err != nil {
+        writeServiceError(w, err)
         return
     }
 
+    // Saving a URL that is already bookmarked returns the existing bookmark
     w.Header().Set("Content-Type", "application/json")
-    w.WriteHeader(http.StatusCreated)
+    if created {
+        w.WriteHeader(http.StatusCreated)
+    }
     json.NewEncoder(w).Encode(bookmark)
 }
 
@@ -62,7 +104,7 @@ func (h *BookmarkHandler) Update(w http.ResponseWriter, r *http.Request) {
 
     bookmark.ID = id
     if err := h.service.UpdateBookmark(&bookmark); err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeServiceError(w, err)
         return
     }
 
@@ -70,6 +112,7 @@ func (h *BookmarkHandler) Update(w http.ResponseWriter, r *http.Request) {
     json.NewEncoder(w).Encode(bookmark)
 }
 
+// Delete moves a bookmark to the trash; ?permanent=true purges it instead
 func (h *BookmarkHandler) Delete(w http.ResponseWriter, r *http.Request) {
     vars := mux.Vars(r)
     id, err := strconv.ParseInt(vars["id"], 10, 64)
@@ -78,6 +121,15 @@ func (h *BookmarkHandler) Delete(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    if r.URL.Query().Get("permanent") == "true" {
+        if err := h.service.PurgeItem(models.ItemBookmark, id); err != nil {
+            writeServiceError(w, err)
+            return
+        }
+        w.WriteHeader(http.StatusNoContent)
+        return
+    }
+
     if err := h.service.DeleteBookmark(id); err != nil {
         http.Error(w, err.Error(), http.StatusInternalServerError)
         return
@@ -86,6 +138,97 @@ func (h *BookmarkHandler) Delete(w http.ResponseWriter, r *http.Request) {
     w.WriteHeader(http.StatusNoContent)
 }
 
+// Move files a bookmark into "collection_id"; 0 removes it from its collection
+func (h *BookmarkHandler) Move(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.ParseInt(vars["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+        return
+    }
+
+    var moveRequest struct {
+        CollectionID int64 `json:"collection_id"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&moveRequest); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    bookmark, err := h.service.MoveBookmark(id, moveRequest.CollectionID)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(bookmark)
+}
+
+// SuggestTags proposes tags for a saved bookmark from the model trained on
+// existing tags; ?limit= caps the list (default 5)
+func (h *BookmarkHandler) SuggestTags(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.ParseInt(vars["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+        return
+    }
+
+    limit, err := suggestionLimit(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    suggestions, err := h.service.SuggestBookmarkTags(id, limit)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(suggestions)
+}
+
+// SuggestTagsForNew proposes tags for an unsaved bookmark given as JSON
+// (url, title, description and any tags it already has)
+func (h *BookmarkHandler) SuggestTagsForNew(w http.ResponseWriter, r *http.Request) {
+    var bookmark models.Bookmark
+    if err := json.NewDecoder(r.Body).Decode(&bookmark); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    limit, err := suggestionLimit(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    suggestions, err := h.service.SuggestTags(bookmark, limit)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(suggestions)
+}
+
+func suggestionLimit(r *http.Request) (int, error) {
+    value := r.URL.Query().Get("limit")
+    if value == "" {
+        return 5, nil
+    }
+    limit, err := strconv.Atoi(value)
+    if err != nil || limit < 1 {
+        return 0, fmt.Errorf("limit must be a positive number")
+    }
+    return limit, nil
+}
+
 func (h *BookmarkHandler) Search(w http.ResponseWriter, r *http.Request) {
     query := r.URL.Query().Get("q")
     if query == "" {
@@ -101,4 +244,81 @@ func (h *BookmarkHandler) Search(w http.ResponseWriter, r *http.Request) {
 
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(results)
-}
\ No newline at end of file
+}
+
+func (h *BookmarkHandler) GetDuplicates(w http.ResponseWriter, r *http.Request) {
+    groups, err := h.service.FindDuplicateBookmarks()
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    if groups == nil {
+        groups = []models.DuplicateGroup{}
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(groups)
+}
+
+// Merge merges the bookmarks in "ids" into "target_id" (default: the oldest),
+// or every duplicate group at once when "all" is set
+func (h *BookmarkHandler) Merge(w http.ResponseWriter, r *http.Request) {
+    var mergeRequest struct {
+        IDs      []int64 `json:"ids"`
+        TargetID int64   `json:"target_id"`
+        All      bool    `json:"all"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&mergeRequest); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    var merged []models.Bookmark
+    if mergeRequest.All {
+        bookmarks, err := h.service.MergeAllDuplicates()
+        if err != nil {
+            writeServiceError(w, err)
+            return
+        }
+        merged = bookmarks
+    } else {
+        bookmark, err := h.service.MergeBookmarks(mergeRequest.IDs, mergeRequest.TargetID)
+        if err != nil {
+            writeServiceError(w, err)
+            return
+        }
+        merged = []models.Bookmark{*bookmark}
+    }
+
+    response := map[string]interface{}{
+        "merged_count": len(merged),
+        "bookmarks":    merged,
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(response)
+}
+
+// Bulk applies one operation to many bookmarks, picked by "ids" or "query"
+func (h *BookmarkHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+    var bulkRequest models.BulkRequest
+    if err := json.NewDecoder(r.Body).Decode(&bulkRequest); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    result, err := h.service.BulkBookmarks(bulkRequest)
+    if result == nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    if err != nil {
+        // Failed partway; the result says which bookmarks changed
+        w.WriteHeader(http.StatusInternalServerError)
+    }
+    json.NewEncoder(w).Encode(result)
+}
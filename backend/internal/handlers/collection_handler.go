@@ -0,0 +1,153 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/services"
+)
+
+type CollectionHandler struct {
+    service services.HyprLinkService
+}
+
+func NewCollectionHandler(service services.HyprLinkService) *CollectionHandler {
+    return &CollectionHandler{service: service}
+}
+
+func (h *CollectionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+    collections, err := h.service.GetAllCollections()
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(collections)
+}
+
+func (h *CollectionHandler) GetTree(w http.ResponseWriter, r *http.Request) {
+    tree, err := h.service.GetCollectionTree()
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(tree)
+}
+
+// GetBookmarks lists a collection's bookmarks; ?recursive=true includes nested collections
+func (h *CollectionHandler) GetBookmarks(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.ParseInt(vars["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+        return
+    }
+
+    recursive := r.URL.Query().Get("recursive") == "true"
+
+    bookmarks, err := h.service.GetCollectionBookmarks(id, recursive)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(bookmarks)
+}
+
+func (h *CollectionHandler) Create(w http.ResponseWriter, r *http.Request) {
+    var collection models.Collection
+    if err := json.NewDecoder(r.Body).Decode(&collection); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    if err := h.service.CreateCollection(&collection); err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(collection)
+}
+
+func (h *CollectionHandler) Update(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.ParseInt(vars["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+        return
+    }
+
+    var collection models.Collection
+    if err := json.NewDecoder(r.Body).Decode(&collection); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    collection.ID = id
+    if err := h.service.UpdateCollection(&collection); err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(collection)
+}
+
+func (h *CollectionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.ParseInt(vars["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.service.DeleteCollection(id); err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// Move re-parents a collection; an omitted position appends it at the end
+func (h *CollectionHandler) Move(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.ParseInt(vars["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+        return
+    }
+
+    var moveRequest struct {
+        ParentID int64 `json:"parent_id"`
+        Position *int  `json:"position"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&moveRequest); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    position := -1
+    if moveRequest.Position != nil {
+        position = *moveRequest.Position
+    }
+
+    collection, err := h.service.MoveCollection(id, moveRequest.ParentID, position)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(collection)
+}
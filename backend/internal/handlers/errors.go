@@ -0,0 +1,22 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+
+    "hyprlnk/internal/services"
+)
+
+// writeServiceError maps a service error to an HTTP status
+func writeServiceError(w http.ResponseWriter, err error) {
+    status := http.StatusInternalServerError
+    switch {
+    case errors.Is(err, services.ErrInvalidInput):
+        status = http.StatusBadRequest
+    case errors.Is(err, services.ErrNotFound):
+        status = http.StatusNotFound
+    case errors.Is(err, services.ErrConflict):
+        status = http.StatusConflict
+    }
+    http.Error(w, err.Error(), status)
+}
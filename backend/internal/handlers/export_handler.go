@@ -0,0 +1,71 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+
+    "hyprlnk/internal/formats"
+    "hyprlnk/internal/services"
+)
+
+type ExportHandler struct {
+    service services.HyprLinkService
+}
+
+func NewExportHandler(service services.HyprLinkService) *ExportHandler {
+    return &ExportHandler{service: service}
+}
+
+// ExportBookmarksHTML downloads all bookmarks as a Netscape Bookmark File
+// that browsers and bookmark services can import
+func (h *ExportHandler) ExportBookmarksHTML(w http.ResponseWriter, r *http.Request) {
+    bookmarks, err := h.service.GetAllBookmarks()
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    collections, err := h.service.GetAllCollections()
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "text/html; charset=utf-8")
+    w.Header().Set("Content-Disposition", `attachment; filename="bookmarks.html"`)
+    formats.WriteNetscape(w, bookmarks, collections)
+}
+
+// ExportAnnotationsMarkdown downloads all notes and highlights as Markdown,
+// grouped by page
+func (h *ExportHandler) ExportAnnotationsMarkdown(w http.ResponseWriter, r *http.Request) {
+    annotations, err := h.service.GetAnnotations(0, "", "", "")
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    bookmarks, err := h.service.GetAllBookmarks()
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+    w.Header().Set("Content-Disposition", `attachment; filename="annotations.md"`)
+    formats.WriteAnnotationsMarkdown(w, annotations, bookmarks)
+}
+
+// ExportAnnotationsJSON downloads all notes and highlights with their
+// highlight positions, for re-importing elsewhere
+func (h *ExportHandler) ExportAnnotationsJSON(w http.ResponseWriter, r *http.Request) {
+    annotations, err := h.service.GetAnnotations(0, "", "", "")
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("Content-Disposition", `attachment; filename="annotations.json"`)
+    json.NewEncoder(w).Encode(annotations)
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
     "encoding/json"
+    "fmt"
     "net/http"
+    "time"
 
     "hyprlnk/internal/models"
     "hyprlnk/internal/services"
@@ -16,8 +18,43 @@ func NewHistoryHandler(service services.HyprLinkService) *HistoryHandler {
     return &HistoryHandler{service: service}
 }
 
+// GetAll returns all history, or only entries within ?from=&to= when either
+// bound is given. Bounds are dates (2006-01-02) or RFC3339 timestamps; dates
+// are calendar days in ?tz= (default: the user's timezone setting) and `to`
+// is inclusive of its whole day.
 func (h *HistoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-    history, err := h.service.GetAllHistory()
+    query := r.URL.Query()
+    fromParam, toParam := query.Get("from"), query.Get("to")
+
+    var history []models.HistoryEntry
+    var err error
+
+    if fromParam == "" && toParam == "" && query.Get("tz") == "" {
+        history, err = h.service.GetAllHistory()
+    } else {
+        loc, locErr := h.service.HistoryLocation(query.Get("tz"))
+        if locErr != nil {
+            writeServiceError(w, locErr)
+            return
+        }
+
+        from, parseErr := parseHistoryBound(fromParam, loc, false)
+        if parseErr != nil {
+            http.Error(w, parseErr.Error(), http.StatusBadRequest)
+            return
+        }
+        to, parseErr := parseHistoryBound(toParam, loc, true)
+        if parseErr != nil {
+            http.Error(w, parseErr.Error(), http.StatusBadRequest)
+            return
+        }
+        if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+            http.Error(w, "'from' must be before 'to'", http.StatusBadRequest)
+            return
+        }
+
+        history, err = h.service.GetHistoryRange(from, to)
+    }
     if err != nil {
         http.Error(w, err.Error(), http.StatusInternalServerError)
         return
@@ -102,4 +139,25 @@ func (h *HistoryHandler) Sync(w http.ResponseWriter, r *http.Request) {
 
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+}
+
+// parseHistoryBound parses a from/to query value in loc. A bare date used as
+// an upper bound is pushed to the following midnight so the day is included.
+func parseHistoryBound(value string, loc *time.Location, upper bool) (time.Time, error) {
+    if value == "" {
+        return time.Time{}, nil
+    }
+
+    if day, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+        if upper {
+            return day.AddDate(0, 0, 1), nil
+        }
+        return day, nil
+    }
+
+    if t, err := time.Parse(time.RFC3339, value); err == nil {
+        return t, nil
+    }
+
+    return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD or RFC3339", value)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/services"
+)
+
+func TestParseHistoryBound(t *testing.T) {
+    berlin, err := time.LoadLocation("Europe/Berlin")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        value string
+        upper bool
+        want  time.Time
+    }{
+        {"", false, time.Time{}},
+        {"2026-01-15", false, time.Date(2026, 1, 15, 0, 0, 0, 0, berlin)},
+        {"2026-01-15", true, time.Date(2026, 1, 16, 0, 0, 0, 0, berlin)},
+        // Clocks go forward on Mar 29, so that day is 23 hours long
+        {"2026-03-29", true, time.Date(2026, 3, 30, 0, 0, 0, 0, berlin)},
+        {"2026-03-31", true, time.Date(2026, 4, 1, 0, 0, 0, 0, berlin)},
+        {"2026-01-15T10:00:00Z", true, time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)},
+        {"2026-01-15T10:00:00+05:30", false, time.Date(2026, 1, 15, 4, 30, 0, 0, time.UTC)},
+    }
+    for _, tt := range tests {
+        got, err := parseHistoryBound(tt.value, berlin, tt.upper)
+        if err != nil {
+            t.Errorf("parseHistoryBound(%q): %v", tt.value, err)
+            continue
+        }
+        if !got.Equal(tt.want) {
+            t.Errorf("parseHistoryBound(%q, upper %v) = %s, want %s", tt.value, tt.upper, got, tt.want)
+        }
+    }
+
+    day, _ := parseHistoryBound("2026-03-29", berlin, false)
+    next, _ := parseHistoryBound("2026-03-29", berlin, true)
+    if length := next.Sub(day); length != 23*time.Hour {
+        t.Errorf("Expected the day clocks change to last 23 hours, got %s", length)
+    }
+
+    for _, value := range []string{"yesterday", "2026-13-01", "15/01/2026", "2026-01-15 10:00"} {
+        if _, err := parseHistoryBound(value, berlin, false); err == nil {
+            t.Errorf("Expected %q rejected", value)
+        }
+    }
+}
+
+// rangeService records the history range it was asked for
+type rangeService struct {
+    services.HyprLinkService
+    from, to time.Time
+}
+
+func (s *rangeService) HistoryLocation(tz string) (*time.Location, error) {
+    if tz == "" {
+        tz = "UTC" // the setting
+    }
+    loc, err := time.LoadLocation(tz)
+    if err != nil {
+        return nil, fmt.Errorf("%w: unknown timezone %q", services.ErrInvalidInput, tz)
+    }
+    return loc, nil
+}
+
+func (s *rangeService) GetHistoryRange(from, to time.Time) ([]models.HistoryEntry, error) {
+    s.from, s.to = from, to
+    return []models.HistoryEntry{}, nil
+}
+
+func TestHistoryHandler_GetAllRange(t *testing.T) {
+    tokyo, _ := time.LoadLocation("Asia/Tokyo")
+    tests := []struct {
+        query    string
+        status   int
+        from, to time.Time
+    }{
+        {"from=2026-03-01&to=2026-03-31&tz=Asia/Tokyo", http.StatusOK,
+            time.Date(2026, 3, 1, 0, 0, 0, 0, tokyo), time.Date(2026, 4, 1, 0, 0, 0, 0, tokyo)},
+        {"from=2026-03-01", http.StatusOK, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Time{}},
+        {"to=2026-03-01T12:00:00Z", http.StatusOK, time.Time{}, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+        {"from=2026-03-01&tz=Nowhere/Special", http.StatusBadRequest, time.Time{}, time.Time{}},
+        {"from=last-week", http.StatusBadRequest, time.Time{}, time.Time{}},
+        {"from=2026-03-02&to=2026-03-01", http.StatusBadRequest, time.Time{}, time.Time{}},
+    }
+    for _, tt := range tests {
+        service := &rangeService{}
+        handler := NewHistoryHandler(service)
+        recorder := httptest.NewRecorder()
+        handler.GetAll(recorder, httptest.NewRequest(http.MethodGet, "/api/history?"+tt.query, nil))
+
+        if recorder.Code != tt.status {
+            t.Errorf("%s: expected status %d, got %d (%s)", tt.query, tt.status, recorder.Code, recorder.Body)
+            continue
+        }
+        if tt.status == http.StatusOK && (!service.from.Equal(tt.from) || !service.to.Equal(tt.to)) {
+            t.Errorf("%s: expected [%s, %s), got [%s, %s)", tt.query, tt.from, tt.to, service.from, service.to)
+        }
+    }
+}
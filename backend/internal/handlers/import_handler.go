@@ -2,12 +2,27 @@ package handlers
 
 import (
     "encoding/json"
+    "fmt"
+    "io"
     "net/http"
+    "os"
+    "strings"
 
+    "github.com/gorilla/mux"
+
+    "hyprlnk/internal/browserdb"
+    "hyprlnk/internal/formats"
     "hyprlnk/internal/models"
     "hyprlnk/internal/services"
 )
 
+// maxImportUploadSize caps uploaded bookmark files
+const maxImportUploadSize = 64 << 20
+
+// maxBrowserDBUploadSize caps uploaded browser databases; years of Chrome
+// or Firefox history run to hundreds of megabytes
+const maxBrowserDBUploadSize = 2 << 30
+
 type ImportHandler struct {
     service services.HyprLinkService
 }
@@ -21,6 +36,8 @@ func (h *ImportHandler) ImportBrowserData(w http.ResponseWriter, r *http.Request
         Bookmarks []models.ImportedBookmark `json:"bookmarks"`
         History   []models.HistoryEntry     `json:"history"`
         UseAI     bool                      `json:"use_ai"`
+        Mode      string                    `json:"mode"`
+        DryRun    bool                      `json:"dry_run"`
     }
 
     if err := json.NewDecoder(r.Body).Decode(&importRequest); err != nil {
@@ -28,22 +45,161 @@ func (h *ImportHandler) ImportBrowserData(w http.ResponseWriter, r *http.Request
         return
     }
 
-    importedCount, err := h.service.ImportBrowserData(importRequest.Bookmarks, importRequest.History, importRequest.UseAI)
+    h.submitImport(w, &models.ImportBatch{
+        Source:    "browser",
+        Mode:      importRequest.Mode,
+        Bookmarks: importRequest.Bookmarks,
+        History:   importRequest.History,
+        UseAI:     importRequest.UseAI,
+    }, nil, importRequest.DryRun)
+}
+
+// ImportFile imports an export file uploaded as the multipart field "file".
+// The {format} path variable picks the parser: netscape (bookmarks.html),
+// pocket, raindrop, pinboard or onetab. Entries that can't be read are
+// reported in the job's errors rather than failing the import.
+func (h *ImportHandler) ImportFile(w http.ResponseWriter, r *http.Request) {
+    format := mux.Vars(r)["format"]
+    importer, ok := formats.Importers[format]
+    if !ok {
+        http.Error(w, fmt.Sprintf("unknown import format %q (supported: %s)", format, strings.Join(formats.ImporterNames(), ", ")), http.StatusNotFound)
+        return
+    }
+
+    upload, err := openUpload(w, r)
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        http.Error(w, err.Error(), http.StatusBadRequest)
         return
     }
+    defer upload.Close()
 
-    allBookmarks, _ := h.service.GetAllBookmarks()
+    parsed, err := importer(upload)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
 
-    response := map[string]interface{}{
-        "imported_count": importedCount,
-        "total_count":    len(allBookmarks),
-        "ai_processed":   importRequest.UseAI,
+    h.submitImport(w, &models.ImportBatch{
+        Source:    format,
+        Mode:      r.URL.Query().Get("mode"),
+        Bookmarks: parsed.Bookmarks,
+        Sessions:  parsed.Sessions,
+    }, parsed.Errors, r.URL.Query().Get("dry_run") == "true")
+}
+
+// openUpload returns the multipart "file" field of an upload request
+func openUpload(w http.ResponseWriter, r *http.Request) (io.ReadCloser, error) {
+    r.Body = http.MaxBytesReader(w, r.Body, maxImportUploadSize)
+
+    file, _, err := r.FormFile("file")
+    if err != nil {
+        return nil, fmt.Errorf("expected a multipart upload with a 'file' field: %w", err)
+    }
+    return file, nil
+}
+
+// ImportBrowserDB imports a copied Chrome History database, Chrome
+// Bookmarks file or Firefox places.sqlite. Several files may be uploaded at
+// once, each as a multipart "file" field.
+func (h *ImportHandler) ImportBrowserDB(w http.ResponseWriter, r *http.Request) {
+    r.Body = http.MaxBytesReader(w, r.Body, maxBrowserDBUploadSize)
+
+    reader, err := r.MultipartReader()
+    if err != nil {
+        http.Error(w, "expected a multipart upload with 'file' fields: "+err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    var bookmarks []models.ImportedBookmark
+    var history []models.HistoryEntry
+    var visits []models.LinkClick
+    var browsers []string
+
+    for {
+        part, err := reader.NextPart()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            http.Error(w, err.Error(), http.StatusBadRequest)
+            return
+        }
+        if part.FormName() != "file" {
+            continue
+        }
+
+        // SQLite needs a real file to open
+        data, err := readUploadedBrowserFile(part)
+        if err != nil {
+            http.Error(w, fmt.Sprintf("%s: %v", part.FileName(), err), http.StatusBadRequest)
+            return
+        }
+        bookmarks = append(bookmarks, data.Bookmarks...)
+        history = append(history, data.History...)
+        visits = append(visits, data.Visits...)
+        browsers = append(browsers, data.Browser)
+    }
+
+    if len(browsers) == 0 {
+        http.Error(w, "no 'file' field in upload", http.StatusBadRequest)
+        return
+    }
+
+    h.submitImport(w, &models.ImportBatch{
+        Source:    "browser-db:" + strings.Join(browsers, ","),
+        Mode:      r.URL.Query().Get("mode"),
+        Bookmarks: bookmarks,
+        History:   history,
+        Visits:    visits,
+    }, nil, r.URL.Query().Get("dry_run") == "true")
+}
+
+// submitImport queues batch as a background job and answers 202 Accepted
+// with the job; clients poll GET /api/jobs/{id} for progress. A dry run
+// (mode=dry_run, or dry_run alongside another mode) instead answers at once
+// with the bookmarks that would be created, updated and skipped.
+func (h *ImportHandler) submitImport(w http.ResponseWriter, batch *models.ImportBatch, parseErrors []models.ItemError, dryRun bool) {
+    if dryRun || batch.Mode == models.ImportDryRun {
+        diff, err := h.service.PreviewImport(batch)
+        if err != nil {
+            writeServiceError(w, err)
+            return
+        }
+        diff.Errors = parseErrors
+
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(diff)
+        return
+    }
+
+    job, err := h.service.SubmitImport(batch, parseErrors)
+    if err != nil {
+        writeServiceError(w, err)
+        return
     }
 
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(response)
+    w.Header().Set("Location", fmt.Sprintf("/api/jobs/%d", job.ID))
+    w.WriteHeader(http.StatusAccepted)
+    json.NewEncoder(w).Encode(job)
+}
+
+func readUploadedBrowserFile(upload io.Reader) (*browserdb.Data, error) {
+    tmp, err := os.CreateTemp("", "hyprlnk-browserdb-*")
+    if err != nil {
+        return nil, err
+    }
+    defer os.Remove(tmp.Name())
+
+    _, err = io.Copy(tmp, upload)
+    if closeErr := tmp.Close(); err == nil {
+        err = closeErr
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    return browserdb.ReadFile(tmp.Name())
 }
 
 func (h *ImportHandler) BulkSegmentBookmarks(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+
+    "hyprlnk/internal/services"
+)
+
+type JobHandler struct {
+    service services.HyprLinkService
+}
+
+func NewJobHandler(service services.HyprLinkService) *JobHandler {
+    return &JobHandler{service: service}
+}
+
+// GetAll lists background jobs, newest first
+func (h *JobHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(h.service.GetJobs())
+}
+
+// Get reports a job's status, progress, counts and per-item errors
+func (h *JobHandler) Get(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid job ID", http.StatusBadRequest)
+        return
+    }
+
+    job, err := h.service.GetJob(id)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(job)
+}
+
+// Cancel stops a queued or running job; what it already stored is kept
+func (h *JobHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid job ID", http.StatusBadRequest)
+        return
+    }
+
+    job, err := h.service.CancelJob(id)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(job)
+}
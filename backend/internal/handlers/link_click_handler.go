@@ -17,8 +17,10 @@ func NewLinkClickHandler(service services.HyprLinkService) *LinkClickHandler {
     return &LinkClickHandler{service: service}
 }
 
+// GetAll lists the extension's clicks; ?source=chrome, firefox or all
+// includes visits imported from browser profiles
 func (h *LinkClickHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-    clicks, err := h.service.GetAllLinkClicks()
+    clicks, err := h.service.GetAllLinkClicks(r.URL.Query().Get("source"))
     if err != nil {
         http.Error(w, err.Error(), http.StatusInternalServerError)
         return
@@ -70,7 +72,7 @@ func (h *LinkClickHandler) Sync(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    allClicks, _ := h.service.GetAllLinkClicks()
+    allClicks, _ := h.service.GetAllLinkClicks("")
 
     response := map[string]interface{}{
         "synced_count": syncedCount,
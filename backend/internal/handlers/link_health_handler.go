@@ -0,0 +1,93 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/services"
+)
+
+type LinkHealthHandler struct {
+    service services.HyprLinkService
+}
+
+func NewLinkHealthHandler(service services.HyprLinkService) *LinkHealthHandler {
+    return &LinkHealthHandler{service: service}
+}
+
+// GetAll lists checked bookmarks with counts per status; ?status=dead,
+// moved, unknown or healthy narrows the list
+func (h *LinkHealthHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+    report, err := h.service.GetLinkHealth(r.URL.Query().Get("status"))
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(report)
+}
+
+// Get returns a bookmark's link health with its check history
+func (h *LinkHealthHandler) Get(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+        return
+    }
+
+    health, err := h.service.GetBookmarkHealth(id)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(health)
+}
+
+// Check checks a bookmark's link now and returns the result
+func (h *LinkHealthHandler) Check(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+        return
+    }
+
+    health, err := h.service.CheckBookmarkLink(id)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(health)
+}
+
+// ApplyRedirects updates moved bookmarks to their new URLs. The body
+// {"ids": [...]} picks bookmarks; {} updates every moved one.
+func (h *LinkHealthHandler) ApplyRedirects(w http.ResponseWriter, r *http.Request) {
+    var applyRequest struct {
+        IDs []int64 `json:"ids"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&applyRequest); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    updated, err := h.service.ApplyRedirects(applyRequest.IDs)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+    if updated == nil {
+        updated = []models.Bookmark{}
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(updated)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+    "hyprlnk/internal/services"
+)
+
+type MetadataHandler struct {
+    service services.HyprLinkService
+}
+
+func NewMetadataHandler(service services.HyprLinkService) *MetadataHandler {
+    return &MetadataHandler{service: service}
+}
+
+// Get returns what fetching a bookmark's page found
+func (h *MetadataHandler) Get(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+        return
+    }
+
+    metadata, err := h.service.GetBookmarkMetadata(id)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(metadata)
+}
+
+// Refresh fetches a bookmark's page now and returns the result
+func (h *MetadataHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+        return
+    }
+
+    metadata, err := h.service.RefreshBookmarkMetadata(id)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(metadata)
+}
+
+// Favicon serves the stored icon of a bookmarked host
+func (h *MetadataHandler) Favicon(w http.ResponseWriter, r *http.Request) {
+    data, contentType, err := h.service.GetFavicon(mux.Vars(r)["host"])
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", contentType)
+    w.Header().Set("Cache-Control", "public, max-age=86400")
+    // Icons are fetched from other sites; never let an SVG run scripts here
+    w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+    w.Header().Set("X-Content-Type-Options", "nosniff")
+    w.Write(data)
+}
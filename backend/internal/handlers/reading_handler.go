@@ -0,0 +1,80 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+    "hyprlnk/internal/services"
+)
+
+type ReadingHandler struct {
+    service services.HyprLinkService
+}
+
+func NewReadingHandler(service services.HyprLinkService) *ReadingHandler {
+    return &ReadingHandler{service: service}
+}
+
+// GetAll lists the reading list. ?status= picks unread, reading, read,
+// archived or all (default: unread and reading); ?sort= orders by newest
+// (default), oldest, title, progress or read.
+func (h *ReadingHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+    query := r.URL.Query()
+    bookmarks, err := h.service.GetReadingList(query.Get("status"), query.Get("sort"))
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(bookmarks)
+}
+
+// Update sets a bookmark's reading state from {"status": ..., "progress": ...};
+// either may be left out
+func (h *ReadingHandler) Update(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+        return
+    }
+
+    var readingRequest struct {
+        Status   string `json:"status"`
+        Progress *int   `json:"progress"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&readingRequest); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    bookmark, err := h.service.SetReadingState(id, readingRequest.Status, readingRequest.Progress)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(bookmark)
+}
+
+// Delete takes a bookmark off the reading list without deleting it
+func (h *ReadingHandler) Delete(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid bookmark ID", http.StatusBadRequest)
+        return
+    }
+
+    bookmark, err := h.service.RemoveFromReadingList(id)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(bookmark)
+}
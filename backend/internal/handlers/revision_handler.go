@@ -0,0 +1,126 @@
+package handlers
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/services"
+)
+
+type RevisionHandler struct {
+    service services.HyprLinkService
+}
+
+func NewRevisionHandler(service services.HyprLinkService) *RevisionHandler {
+    return &RevisionHandler{service: service}
+}
+
+func (h *RevisionHandler) GetBookmarkRevisions(w http.ResponseWriter, r *http.Request) {
+    h.getRevisions(w, r, models.ItemBookmark)
+}
+
+func (h *RevisionHandler) RestoreBookmarkRevision(w http.ResponseWriter, r *http.Request) {
+    h.restoreRevision(w, r, models.ItemBookmark)
+}
+
+func (h *RevisionHandler) GetSessionRevisions(w http.ResponseWriter, r *http.Request) {
+    h.getRevisions(w, r, models.ItemSession)
+}
+
+func (h *RevisionHandler) RestoreSessionRevision(w http.ResponseWriter, r *http.Request) {
+    h.restoreRevision(w, r, models.ItemSession)
+}
+
+func (h *RevisionHandler) getRevisions(w http.ResponseWriter, r *http.Request, itemType string) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Invalid %s ID", itemType), http.StatusBadRequest)
+        return
+    }
+
+    revisions, err := h.service.GetRevisions(itemType, id)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(revisions)
+}
+
+// restoreRevision responds with the restored item
+func (h *RevisionHandler) restoreRevision(w http.ResponseWriter, r *http.Request, itemType string) {
+    vars := mux.Vars(r)
+    id, err := strconv.ParseInt(vars["id"], 10, 64)
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Invalid %s ID", itemType), http.StatusBadRequest)
+        return
+    }
+    rev, err := strconv.Atoi(vars["rev"])
+    if err != nil {
+        http.Error(w, "Invalid revision", http.StatusBadRequest)
+        return
+    }
+
+    item, err := h.service.RestoreRevision(itemType, id, rev)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.Write(item)
+}
+
+// GetOperations lists recent operations, newest first; ?limit= caps the
+// list (default 50)
+func (h *RevisionHandler) GetOperations(w http.ResponseWriter, r *http.Request) {
+    limit := 50
+    if value := r.URL.Query().Get("limit"); value != "" {
+        parsed, err := strconv.Atoi(value)
+        if err != nil || parsed < 1 {
+            http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+            return
+        }
+        limit = parsed
+    }
+
+    operations, err := h.service.GetOperations(limit)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(operations)
+}
+
+// Undo reverts the last {"count": N} operations; an empty body undoes one
+func (h *RevisionHandler) Undo(w http.ResponseWriter, r *http.Request) {
+    var undoRequest struct {
+        Count int `json:"count"`
+    }
+    if r.ContentLength != 0 {
+        if err := json.NewDecoder(r.Body).Decode(&undoRequest); err != nil {
+            http.Error(w, err.Error(), http.StatusBadRequest)
+            return
+        }
+    }
+
+    undone, err := h.service.Undo(undoRequest.Count)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    response := map[string]interface{}{
+        "undone_count": len(undone),
+        "operations":   undone,
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(response)
+}
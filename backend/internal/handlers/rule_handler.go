@@ -0,0 +1,106 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/services"
+)
+
+type RuleHandler struct {
+    service services.HyprLinkService
+}
+
+func NewRuleHandler(service services.HyprLinkService) *RuleHandler {
+    return &RuleHandler{service: service}
+}
+
+func (h *RuleHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+    rules, err := h.service.GetRules()
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(rules)
+}
+
+func (h *RuleHandler) Create(w http.ResponseWriter, r *http.Request) {
+    var rule models.AutoTagRule
+    if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    if err := h.service.CreateRule(&rule); err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(rule)
+}
+
+func (h *RuleHandler) Update(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.ParseInt(vars["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+        return
+    }
+
+    var rule models.AutoTagRule
+    if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    rule.ID = id
+    if err := h.service.UpdateRule(&rule); err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(rule)
+}
+
+func (h *RuleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.ParseInt(vars["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.service.DeleteRule(id); err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// Apply runs the rules over all existing bookmarks and lists what changed;
+// ?dry_run=true only reports what would change
+func (h *RuleHandler) Apply(w http.ResponseWriter, r *http.Request) {
+    dryRun := r.URL.Query().Get("dry_run") == "true"
+
+    changes, err := h.service.ApplyRules(dryRun)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "dry_run": dryRun,
+        "changed": len(changes),
+        "changes": changes,
+    })
+}
@@ -37,7 +37,7 @@ func (h *SessionHandler) Create(w http.ResponseWriter, r *http.Request) {
     }
 
     if err := h.service.CreateSession(&session); err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeServiceError(w, err)
         return
     }
 
@@ -62,7 +62,7 @@ func (h *SessionHandler) Update(w http.ResponseWriter, r *http.Request) {
 
     session.ID = id
     if err := h.service.UpdateSession(&session); err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeServiceError(w, err)
         return
     }
 
@@ -70,6 +70,7 @@ func (h *SessionHandler) Update(w http.ResponseWriter, r *http.Request) {
     json.NewEncoder(w).Encode(session)
 }
 
+// Delete moves a session to the trash; ?permanent=true purges it instead
 func (h *SessionHandler) Delete(w http.ResponseWriter, r *http.Request) {
     vars := mux.Vars(r)
     id, err := strconv.ParseInt(vars["id"], 10, 64)
@@ -78,6 +79,15 @@ func (h *SessionHandler) Delete(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    if r.URL.Query().Get("permanent") == "true" {
+        if err := h.service.PurgeItem(models.ItemSession, id); err != nil {
+            writeServiceError(w, err)
+            return
+        }
+        w.WriteHeader(http.StatusNoContent)
+        return
+    }
+
     if err := h.service.DeleteSession(id); err != nil {
         http.Error(w, err.Error(), http.StatusInternalServerError)
         return
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gorilla/mux"
+    "hyprlnk/internal/models"
+)
+
+// GetVersions lists a session's saved versions, newest first
+func (h *SessionHandler) GetVersions(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid session ID", http.StatusBadRequest)
+        return
+    }
+
+    versions, err := h.service.GetSessionVersions(id)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(versions)
+}
+
+func (h *SessionHandler) GetVersion(w http.ResponseWriter, r *http.Request) {
+    id, version, ok := parseSessionVersion(w, r)
+    if !ok {
+        return
+    }
+
+    found, err := h.service.GetSessionVersion(id, version)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(found)
+}
+
+// GetVersionAt returns the version that was current at ?time=, an RFC3339
+// timestamp or a local time such as 2006-01-02T15:04 in ?tz= (default:
+// the user's timezone setting). A bare date means the end of that day.
+func (h *SessionHandler) GetVersionAt(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid session ID", http.StatusBadRequest)
+        return
+    }
+
+    query := r.URL.Query()
+    loc, err := h.service.HistoryLocation(query.Get("tz"))
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+    at, err := parseSessionTime(query.Get("time"), loc)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    version, err := h.service.GetSessionVersionAt(id, at)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(version)
+}
+
+// RestoreVersion makes an earlier version the session's current state and
+// responds with the session
+func (h *SessionHandler) RestoreVersion(w http.ResponseWriter, r *http.Request) {
+    id, version, ok := parseSessionVersion(w, r)
+    if !ok {
+        return
+    }
+
+    session, err := h.service.RestoreSessionVersion(id, version)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(session)
+}
+
+// AutoSave records the open tabs the extension posts every few minutes
+func (h *SessionHandler) AutoSave(w http.ResponseWriter, r *http.Request) {
+    var request models.AutoSaveRequest
+    if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    result, err := h.service.AutoSaveSession(request)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(result)
+}
+
+// parseSessionVersion reads the session ID and version number from the
+// path, answering 400 when either is invalid
+func parseSessionVersion(w http.ResponseWriter, r *http.Request) (int64, int, bool) {
+    vars := mux.Vars(r)
+    id, err := strconv.ParseInt(vars["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid session ID", http.StatusBadRequest)
+        return 0, 0, false
+    }
+    version, err := strconv.Atoi(vars["version"])
+    if err != nil {
+        http.Error(w, "Invalid version", http.StatusBadRequest)
+        return 0, 0, false
+    }
+    return id, version, true
+}
+
+// parseSessionTime parses a point in time given as RFC3339, or as a local
+// date and time in loc
+func parseSessionTime(value string, loc *time.Location) (time.Time, error) {
+    if value == "" {
+        return time.Time{}, fmt.Errorf("time is required")
+    }
+
+    if t, err := time.Parse(time.RFC3339, value); err == nil {
+        return t, nil
+    }
+    for _, layout := range []string{"2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02 15:04:05"} {
+        if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+            return t, nil
+        }
+    }
+    if day, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+        return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+    }
+
+    return time.Time{}, fmt.Errorf("invalid time %q: use RFC3339, YYYY-MM-DDTHH:MM or YYYY-MM-DD", value)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+
+    "hyprlnk/internal/services"
+)
+
+type SettingsHandler struct {
+    service services.HyprLinkService
+}
+
+func NewSettingsHandler(service services.HyprLinkService) *SettingsHandler {
+    return &SettingsHandler{service: service}
+}
+
+func (h *SettingsHandler) Get(w http.ResponseWriter, r *http.Request) {
+    settings, err := h.service.GetSettings()
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(settings)
+}
+
+func (h *SettingsHandler) Update(w http.ResponseWriter, r *http.Request) {
+    settings, err := h.service.GetSettings()
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    // Decode over the current settings so omitted fields keep their values
+    updated := *settings
+    if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    if err := h.service.UpdateSettings(&updated); err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(updated)
+}
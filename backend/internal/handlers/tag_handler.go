@@ -0,0 +1,119 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+
+    "github.com/gorilla/mux"
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/services"
+)
+
+type TagHandler struct {
+    service services.HyprLinkService
+}
+
+func NewTagHandler(service services.HyprLinkService) *TagHandler {
+    return &TagHandler{service: service}
+}
+
+func (h *TagHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+    tags, err := h.service.GetTags()
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(tags)
+}
+
+func (h *TagHandler) Rename(w http.ResponseWriter, r *http.Request) {
+    var renameRequest struct {
+        From string `json:"from"`
+        To   string `json:"to"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&renameRequest); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    updatedCount, err := h.service.RenameTag(renameRequest.From, renameRequest.To)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    response := map[string]interface{}{
+        "updated_count": updatedCount,
+        "message":       "Tag renamed successfully",
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(response)
+}
+
+func (h *TagHandler) Merge(w http.ResponseWriter, r *http.Request) {
+    var mergeRequest struct {
+        Sources []string `json:"sources"`
+        Target  string   `json:"target"`
+    }
+
+    if err := json.NewDecoder(r.Body).Decode(&mergeRequest); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    updatedCount, err := h.service.MergeTags(mergeRequest.Sources, mergeRequest.Target)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    response := map[string]interface{}{
+        "updated_count": updatedCount,
+        "message":       "Tags merged successfully",
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(response)
+}
+
+// Update stores a tag's color and description
+func (h *TagHandler) Update(w http.ResponseWriter, r *http.Request) {
+    var info models.TagInfo
+    if err := json.NewDecoder(r.Body).Decode(&info); err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    info.Name = mux.Vars(r)["name"]
+    if err := h.service.UpdateTagInfo(&info); err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(info)
+}
+
+// Delete removes a tag from all bookmarks; ?recursive=true removes its children too
+func (h *TagHandler) Delete(w http.ResponseWriter, r *http.Request) {
+    name := mux.Vars(r)["name"]
+    recursive := r.URL.Query().Get("recursive") == "true"
+
+    updatedCount, err := h.service.DeleteTag(name, recursive)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    response := map[string]interface{}{
+        "updated_count": updatedCount,
+        "message":       "Tag deleted successfully",
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "github.com/gorilla/mux"
+    "hyprlnk/internal/services"
+)
+
+type TrashHandler struct {
+    service services.HyprLinkService
+}
+
+func NewTrashHandler(service services.HyprLinkService) *TrashHandler {
+    return &TrashHandler{service: service}
+}
+
+func (h *TrashHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+    items, err := h.service.GetTrash()
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(items)
+}
+
+// Restore puts a bookmark or session back and responds with it
+func (h *TrashHandler) Restore(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.ParseInt(vars["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid item ID", http.StatusBadRequest)
+        return
+    }
+
+    item, err := h.service.RestoreFromTrash(vars["type"], id)
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    w.Write(item)
+}
+
+// Delete purges one item from the trash for good
+func (h *TrashHandler) Delete(w http.ResponseWriter, r *http.Request) {
+    vars := mux.Vars(r)
+    id, err := strconv.ParseInt(vars["id"], 10, 64)
+    if err != nil {
+        http.Error(w, "Invalid item ID", http.StatusBadRequest)
+        return
+    }
+
+    if err := h.service.PurgeItem(vars["type"], id); err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
+// Empty purges everything in the trash
+func (h *TrashHandler) Empty(w http.ResponseWriter, r *http.Request) {
+    purged, err := h.service.EmptyTrash()
+    if err != nil {
+        writeServiceError(w, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]int{"purged_count": purged})
+}
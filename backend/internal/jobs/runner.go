@@ -0,0 +1,374 @@
+// Package jobs runs long operations such as imports in the background.
+// Jobs and their input are persisted, so queued and interrupted jobs pick
+// up where they left off after a restart.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"hyprlnk/internal/models"
+)
+
+var (
+	ErrNotFound = errors.New("job not found")
+	ErrFinished = errors.New("job already finished")
+)
+
+const (
+	// maxErrors bounds the per-item errors kept on a job
+	maxErrors = 1000
+
+	// retention is how long finished jobs stay listed
+	retention = 7 * 24 * time.Hour
+)
+
+// Store persists jobs and the payloads they work from
+type Store interface {
+	GetAll() ([]models.Job, error)
+	Save(job *models.Job) error
+	Delete(ids ...int64) error
+	SavePayload(id int64, payload []byte) error
+	LoadPayload(id int64) ([]byte, error)
+	DeletePayload(id int64) error
+}
+
+// Func does the work of one job type. It decodes its input from payload,
+// reports through p, and returns ctx.Err() promptly once ctx is cancelled.
+// A resumed job finds the progress it had checkpointed in p.Job().Processed.
+type Func func(ctx context.Context, payload []byte, p *Progress) error
+
+// Runner runs jobs one at a time, in submission order
+type Runner struct {
+	store Store
+	funcs map[string]Func
+
+	mutex   sync.Mutex
+	jobs    map[int64]*models.Job
+	pending []int64
+	cancel  context.CancelFunc // cancels the running job
+	running int64
+	wake    chan struct{}
+}
+
+func NewRunner(store Store) *Runner {
+	return &Runner{
+		store: store,
+		funcs: make(map[string]Func),
+		jobs:  make(map[int64]*models.Job),
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// Register sets the function that runs jobs of jobType. Register every
+// type before Start so resumed jobs find theirs.
+func (r *Runner) Register(jobType string, fn Func) {
+	r.funcs[jobType] = fn
+}
+
+// Start loads stored jobs, requeues unfinished ones, drops old finished
+// ones and starts the worker
+func (r *Runner) Start() error {
+	stored, err := r.store.GetAll()
+	if err != nil {
+		return err
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].CreatedAt.Before(stored[j].CreatedAt) })
+
+	r.mutex.Lock()
+	var expired []int64
+	for i := range stored {
+		job := stored[i]
+		if job.Finished() && time.Since(job.FinishedAt) > retention {
+			expired = append(expired, job.ID)
+			continue
+		}
+		if !job.Finished() {
+			job.Status = models.JobQueued
+			r.pending = append(r.pending, job.ID)
+		}
+		r.jobs[job.ID] = &job
+	}
+	r.mutex.Unlock()
+
+	if len(expired) > 0 {
+		if err := r.store.Delete(expired...); err != nil {
+			return err
+		}
+	}
+
+	go r.work()
+	r.signal()
+	return nil
+}
+
+// Submit persists a new job and queues it. counts seeds the job's tallies
+// and errs are item errors found before it runs, such as unparseable rows
+// of an uploaded file.
+func (r *Runner) Submit(jobType, title string, payload []byte, counts map[string]int, errs []models.ItemError) (*models.Job, error) {
+	if _, ok := r.funcs[jobType]; !ok {
+		return nil, fmt.Errorf("unknown job type %q", jobType)
+	}
+
+	job := &models.Job{
+		ID:        time.Now().UnixNano(),
+		Type:      jobType,
+		Title:     title,
+		Status:    models.JobQueued,
+		Counts:    counts,
+		Errors:    capErrors(nil, errs),
+		CreatedAt: time.Now(),
+	}
+	if job.Counts == nil {
+		job.Counts = map[string]int{}
+	}
+
+	if err := r.store.SavePayload(job.ID, payload); err != nil {
+		return nil, err
+	}
+	if err := r.store.Save(job); err != nil {
+		r.deletePayload(job.ID)
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	r.jobs[job.ID] = job
+	r.pending = append(r.pending, job.ID)
+	snapshot := clone(job)
+	r.mutex.Unlock()
+
+	r.signal()
+	return &snapshot, nil
+}
+
+// Get returns a snapshot of a job
+func (r *Runner) Get(id int64) (*models.Job, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	snapshot := clone(job)
+	return &snapshot, nil
+}
+
+// List returns snapshots of all jobs, newest first
+func (r *Runner) List() []models.Job {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	list := make([]models.Job, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		list = append(list, clone(job))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// Cancel stops a queued or running job. Work a running job already
+// checkpointed is kept.
+func (r *Runner) Cancel(id int64) (*models.Job, error) {
+	r.mutex.Lock()
+	job, ok := r.jobs[id]
+	if !ok {
+		r.mutex.Unlock()
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	if job.Finished() {
+		r.mutex.Unlock()
+		return nil, fmt.Errorf("%w: %d is %s", ErrFinished, id, job.Status)
+	}
+
+	if r.running == id {
+		// The worker records the cancellation once the job returns
+		r.cancel()
+		snapshot := clone(job)
+		r.mutex.Unlock()
+		return &snapshot, nil
+	}
+
+	for i, pendingID := range r.pending {
+		if pendingID == id {
+			r.pending = append(r.pending[:i], r.pending[i+1:]...)
+			break
+		}
+	}
+	job.Status = models.JobCancelled
+	job.FinishedAt = time.Now()
+	snapshot := clone(job)
+	r.mutex.Unlock()
+
+	if err := r.store.Save(&snapshot); err != nil {
+		return nil, err
+	}
+	r.deletePayload(id)
+	return &snapshot, nil
+}
+
+func (r *Runner) signal() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Runner) work() {
+	for range r.wake {
+		for r.runNext() {
+		}
+	}
+}
+
+// runNext runs the oldest pending job, reporting false if there was none
+func (r *Runner) runNext() bool {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Dequeue and mark running in one step, so Cancel always finds the job
+	// either pending or running
+	r.mutex.Lock()
+	if len(r.pending) == 0 {
+		r.mutex.Unlock()
+		return false
+	}
+	id := r.pending[0]
+	r.pending = r.pending[1:]
+	job := r.jobs[id]
+	job.Status = models.JobRunning
+	if job.StartedAt.IsZero() {
+		job.StartedAt = time.Now()
+	}
+	r.running = id
+	r.cancel = cancel
+	snapshot := clone(job)
+	r.mutex.Unlock()
+	if err := r.store.Save(&snapshot); err != nil {
+		log.Printf("jobs: job %d: %v", id, err)
+	}
+
+	err := r.execute(ctx, job.Type, id)
+
+	r.mutex.Lock()
+	r.running = 0
+	r.cancel = nil
+	switch {
+	case err == nil:
+		job.Status = models.JobCompleted
+	case ctx.Err() != nil:
+		job.Status = models.JobCancelled
+	default:
+		job.Status = models.JobFailed
+		job.Error = err.Error()
+	}
+	job.FinishedAt = time.Now()
+	snapshot = clone(job)
+	r.mutex.Unlock()
+
+	// Until the outcome is stored the job still reads as unfinished, and a
+	// restart resumes it from its last checkpoint, so it needs its input
+	if err := r.store.Save(&snapshot); err != nil {
+		log.Printf("jobs: job %d finished but wasn't saved: %v", id, err)
+		return true
+	}
+	r.deletePayload(id)
+	return true
+}
+
+func (r *Runner) deletePayload(id int64) {
+	if err := r.store.DeletePayload(id); err != nil {
+		log.Printf("jobs: job %d: %v", id, err)
+	}
+}
+
+func (r *Runner) execute(ctx context.Context, jobType string, id int64) error {
+	fn, ok := r.funcs[jobType]
+	if !ok {
+		return fmt.Errorf("unknown job type %q", jobType)
+	}
+	payload, err := r.store.LoadPayload(id)
+	if err != nil {
+		return fmt.Errorf("failed to load job input: %w", err)
+	}
+	return fn(ctx, payload, &Progress{runner: r, id: id})
+}
+
+// Progress is how a running job reports back
+type Progress struct {
+	runner *Runner
+	id     int64
+}
+
+// Job returns a snapshot of the job being run
+func (p *Progress) Job() models.Job {
+	p.runner.mutex.Lock()
+	defer p.runner.mutex.Unlock()
+	return clone(p.runner.jobs[p.id])
+}
+
+// SetTotal records how many items the job will process
+func (p *Progress) SetTotal(total int) error {
+	return p.update(func(job *models.Job) {
+		job.Total = total
+	})
+}
+
+// Advance checkpoints n more processed items, adding counts to the job's
+// tallies. Call it after the items' effects are stored, so a resumed job
+// can skip them.
+func (p *Progress) Advance(n int, counts map[string]int) error {
+	return p.update(func(job *models.Job) {
+		job.Processed += n
+		for key, count := range counts {
+			job.Counts[key] += count
+		}
+	})
+}
+
+// AddErrors records items the job had to skip
+func (p *Progress) AddErrors(errs ...models.ItemError) error {
+	return p.update(func(job *models.Job) {
+		job.Errors = capErrors(job.Errors, errs)
+	})
+}
+
+func (p *Progress) update(change func(job *models.Job)) error {
+	p.runner.mutex.Lock()
+	job := p.runner.jobs[p.id]
+	change(job)
+	snapshot := clone(job)
+	p.runner.mutex.Unlock()
+
+	return p.runner.store.Save(&snapshot)
+}
+
+func capErrors(existing, added []models.ItemError) []models.ItemError {
+	if existing == nil {
+		existing = []models.ItemError{}
+	}
+	room := maxErrors - len(existing)
+	if room <= 0 {
+		return existing
+	}
+	if len(added) > room {
+		added = added[:room]
+	}
+	return append(existing, added...)
+}
+
+// clone copies a job so callers can't race with the worker
+func clone(job *models.Job) models.Job {
+	copied := *job
+	copied.Counts = make(map[string]int, len(job.Counts))
+	for key, count := range job.Counts {
+		copied.Counts[key] = count
+	}
+	copied.Errors = append([]models.ItemError{}, job.Errors...)
+	return copied
+}
@@ -0,0 +1,322 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"hyprlnk/internal/models"
+)
+
+// memoryStore is a Store in memory. failSaves makes Save fail for jobs
+// that have finished, as a full disk would.
+type memoryStore struct {
+	mutex     sync.Mutex
+	jobs      map[int64]models.Job
+	payloads  map[int64][]byte
+	failSaves bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{jobs: make(map[int64]models.Job), payloads: make(map[int64][]byte)}
+}
+
+func (s *memoryStore) GetAll() ([]models.Job, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var all []models.Job
+	for _, job := range s.jobs {
+		all = append(all, job)
+	}
+	return all, nil
+}
+
+func (s *memoryStore) Save(job *models.Job) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.failSaves && job.Finished() {
+		return errors.New("disk full")
+	}
+	s.jobs[job.ID] = clone(job)
+	return nil
+}
+
+func (s *memoryStore) Delete(ids ...int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, id := range ids {
+		delete(s.jobs, id)
+	}
+	return nil
+}
+
+func (s *memoryStore) SavePayload(id int64, payload []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.payloads[id] = payload
+	return nil
+}
+
+func (s *memoryStore) LoadPayload(id int64) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	payload, ok := s.payloads[id]
+	if !ok {
+		return nil, errors.New("no payload")
+	}
+	return payload, nil
+}
+
+func (s *memoryStore) DeletePayload(id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.payloads, id)
+	return nil
+}
+
+func (s *memoryStore) stored(id int64) (models.Job, bool, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	job, ok := s.jobs[id]
+	_, hasPayload := s.payloads[id]
+	return job, ok, hasPayload
+}
+
+// waitFinished polls until the runner reports the job finished
+func waitFinished(t *testing.T, r *Runner, id int64) models.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := r.Get(id)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if job.Finished() {
+			return *job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Job %d didn't finish", id)
+	return models.Job{}
+}
+
+// waitStored polls until the stored job is finished, since the store is
+// written after the runner's own copy
+func waitStored(t *testing.T, store *memoryStore, id int64) models.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, ok, _ := store.stored(id); ok && job.Finished() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Job %d wasn't stored as finished", id)
+	return models.Job{}
+}
+
+func TestRunner_Completes(t *testing.T) {
+	store := newMemoryStore()
+	runner := NewRunner(store)
+	runner.Register("count", func(ctx context.Context, payload []byte, p *Progress) error {
+		if err := p.SetTotal(len(payload)); err != nil {
+			return err
+		}
+		return p.Advance(len(payload), map[string]int{"items": len(payload)})
+	})
+	if err := runner.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	job, err := runner.Submit("count", "Count", []byte("abc"), nil, []models.ItemError{{Row: 1, Message: "bad row"}})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	waitFinished(t, runner, job.ID)
+
+	stored := waitStored(t, store, job.ID)
+	if stored.Status != models.JobCompleted || stored.Processed != 3 || stored.Counts["items"] != 3 || len(stored.Errors) != 1 {
+		t.Errorf("Unexpected stored job %+v", stored)
+	}
+	if _, _, hasPayload := store.stored(job.ID); hasPayload {
+		t.Error("Expected the payload of a finished job to be deleted")
+	}
+
+	if _, err := runner.Submit("unknown", "Nope", nil, nil, nil); err == nil {
+		t.Error("Expected an error submitting an unregistered job type")
+	}
+}
+
+func TestRunner_Fails(t *testing.T) {
+	store := newMemoryStore()
+	runner := NewRunner(store)
+	runner.Register("fail", func(ctx context.Context, payload []byte, p *Progress) error {
+		return errors.New("boom")
+	})
+	runner.Start()
+
+	job, _ := runner.Submit("fail", "Fail", []byte("x"), nil, nil)
+	if finished := waitFinished(t, runner, job.ID); finished.Status != models.JobFailed || finished.Error != "boom" {
+		t.Errorf("Expected a failed job with its error, got %+v", finished)
+	}
+}
+
+func TestRunner_ResumesFromCheckpoint(t *testing.T) {
+	store := newMemoryStore()
+	interrupted := models.Job{
+		ID:        1,
+		Type:      "count",
+		Status:    models.JobRunning,
+		Total:     10,
+		Processed: 4,
+		Counts:    map[string]int{"items": 4},
+		CreatedAt: time.Now().Add(-time.Minute),
+		StartedAt: time.Now().Add(-time.Minute),
+	}
+	store.Save(&interrupted)
+	store.SavePayload(1, []byte("0123456789"))
+
+	var resumedAt int
+	runner := NewRunner(store)
+	runner.Register("count", func(ctx context.Context, payload []byte, p *Progress) error {
+		resumedAt = p.Job().Processed
+		rest := len(payload) - resumedAt
+		return p.Advance(rest, map[string]int{"items": rest})
+	})
+	if err := runner.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	finished := waitFinished(t, runner, 1)
+	if resumedAt != 4 {
+		t.Errorf("Expected the job to resume at its checkpoint 4, got %d", resumedAt)
+	}
+	if finished.Status != models.JobCompleted || finished.Processed != 10 || finished.Counts["items"] != 10 {
+		t.Errorf("Unexpected resumed job %+v", finished)
+	}
+	if !finished.StartedAt.Equal(interrupted.StartedAt) {
+		t.Errorf("Expected StartedAt to be kept, got %v", finished.StartedAt)
+	}
+}
+
+func TestRunner_CancelRunning(t *testing.T) {
+	store := newMemoryStore()
+	runner := NewRunner(store)
+	started := make(chan struct{})
+	runner.Register("block", func(ctx context.Context, payload []byte, p *Progress) error {
+		if err := p.Advance(1, nil); err != nil {
+			return err
+		}
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	runner.Start()
+
+	job, _ := runner.Submit("block", "Block", []byte("x"), nil, nil)
+	<-started
+	if _, err := runner.Cancel(job.ID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+
+	finished := waitFinished(t, runner, job.ID)
+	if finished.Status != models.JobCancelled || finished.Processed != 1 {
+		t.Errorf("Expected a cancelled job keeping its checkpoint, got %+v", finished)
+	}
+	waitStored(t, store, job.ID)
+	if _, err := runner.Cancel(job.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("Expected ErrFinished cancelling a finished job, got %v", err)
+	}
+	if _, err := runner.Cancel(12345); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound cancelling an unknown job, got %v", err)
+	}
+}
+
+func TestRunner_CancelQueued(t *testing.T) {
+	store := newMemoryStore()
+	runner := NewRunner(store)
+	release := make(chan struct{})
+	var ran []string
+	var mutex sync.Mutex
+	runner.Register("wait", func(ctx context.Context, payload []byte, p *Progress) error {
+		<-release
+		mutex.Lock()
+		ran = append(ran, string(payload))
+		mutex.Unlock()
+		return nil
+	})
+	runner.Start()
+
+	first, _ := runner.Submit("wait", "First", []byte("first"), nil, nil)
+	second, _ := runner.Submit("wait", "Second", []byte("second"), nil, nil)
+	cancelled, err := runner.Cancel(second.ID)
+	if err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if cancelled.Status != models.JobCancelled {
+		t.Errorf("Expected the queued job to be cancelled at once, got %s", cancelled.Status)
+	}
+	if stored, _, hasPayload := store.stored(second.ID); stored.Status != models.JobCancelled || hasPayload {
+		t.Errorf("Expected the cancellation stored and the payload deleted, got %+v (payload %v)", stored, hasPayload)
+	}
+
+	close(release)
+	waitFinished(t, runner, first.ID)
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(ran) != 1 || ran[0] != "first" {
+		t.Errorf("Expected only the first job to run, ran %v", ran)
+	}
+}
+
+func TestRunner_Retention(t *testing.T) {
+	store := newMemoryStore()
+	old := models.Job{ID: 1, Type: "count", Status: models.JobCompleted, CreatedAt: time.Now().Add(-10 * 24 * time.Hour), FinishedAt: time.Now().Add(-8 * 24 * time.Hour)}
+	recent := models.Job{ID: 2, Type: "count", Status: models.JobFailed, CreatedAt: time.Now().Add(-2 * 24 * time.Hour), FinishedAt: time.Now().Add(-24 * time.Hour)}
+	store.Save(&old)
+	store.Save(&recent)
+
+	runner := NewRunner(store)
+	runner.Register("count", func(ctx context.Context, payload []byte, p *Progress) error { return nil })
+	if err := runner.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	if _, err := runner.Get(1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a job finished over a week ago to be dropped, got %v", err)
+	}
+	if _, ok, _ := store.stored(1); ok {
+		t.Error("Expected a job finished over a week ago to be deleted from the store")
+	}
+	if list := runner.List(); len(list) != 1 || list[0].ID != 2 {
+		t.Errorf("Expected only the recent job to be listed, got %+v", list)
+	}
+}
+
+func TestRunner_KeepsPayloadUntilSaved(t *testing.T) {
+	store := newMemoryStore()
+	store.failSaves = true
+	runner := NewRunner(store)
+	runner.Register("count", func(ctx context.Context, payload []byte, p *Progress) error { return nil })
+	runner.Start()
+
+	job, err := runner.Submit("count", "Count", []byte("abc"), nil, nil)
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	waitFinished(t, runner, job.ID)
+
+	// The worker saves after updating its own copy; give it time to try
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, _, hasPayload := store.stored(job.ID); !hasPayload {
+			t.Fatal("Expected the payload to be kept when the finished job couldn't be saved")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stored, _, _ := store.stored(job.ID); stored.Finished() {
+		t.Errorf("Expected the stored job to still be unfinished, got %s", stored.Status)
+	}
+}
@@ -0,0 +1,222 @@
+// Package linkcheck finds dead and moved bookmarks: it requests their URLs
+// and keeps a history of what came back.
+package linkcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"hyprlnk/internal/fetch"
+	"hyprlnk/internal/models"
+)
+
+// Error kinds recorded on failed checks
+const (
+	ErrorDNS         = "dns"
+	ErrorTLS         = "tls"
+	ErrorTimeout     = "timeout"
+	ErrorConnection  = "connection"
+	ErrorRobots      = "robots"
+	ErrorUnsupported = "unsupported"
+)
+
+const (
+	// deadAfter is how many consecutive failures make a link dead, unless
+	// the server said outright that the page is gone
+	deadAfter = 3
+	// historySize is how many checks are kept per bookmark
+	historySize = 30
+	// getBytes caps the body read when HEAD has to be retried as GET
+	getBytes = 64 << 10
+)
+
+// Config tunes a Checker; zero values pick the defaults
+type Config struct {
+	UserAgent    string
+	Workers      int           // URLs checked in parallel, default 4
+	Interval     time.Duration // how often each URL is checked, default 24h
+	HostInterval time.Duration // minimum gap between requests to a host, default 2s
+	Timeout      time.Duration // per request, default 15s
+}
+
+// Checker is safe for concurrent use
+type Checker struct {
+	config  Config
+	fetcher *fetch.Fetcher
+}
+
+func New(config Config) *Checker {
+	if config.Workers <= 0 {
+		config.Workers = 4
+	}
+	if config.Interval <= 0 {
+		config.Interval = 24 * time.Hour
+	}
+	if config.HostInterval <= 0 {
+		config.HostInterval = 2 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 15 * time.Second
+	}
+
+	return &Checker{
+		config: config,
+		fetcher: fetch.New(fetch.Config{
+			UserAgent:    config.UserAgent,
+			Timeout:      config.Timeout,
+			HostInterval: config.HostInterval,
+		}),
+	}
+}
+
+// Workers is how many URLs to check in parallel
+func (c *Checker) Workers() int {
+	return c.config.Workers
+}
+
+// Interval is how often each URL should be checked
+func (c *Checker) Interval() time.Duration {
+	return c.config.Interval
+}
+
+// Check requests rawURL with HEAD, retrying with GET for servers that
+// refuse or mishandle HEAD
+func (c *Checker) Check(ctx context.Context, rawURL string) models.LinkCheck {
+	start := time.Now()
+	check := models.LinkCheck{CheckedAt: start, URL: rawURL, Method: http.MethodHead}
+
+	resp, err := c.fetcher.Head(ctx, rawURL)
+	if err == nil && resp.StatusCode >= 400 {
+		check.Method = http.MethodGet
+		resp, err = c.fetcher.GetLimit(ctx, rawURL, getBytes)
+	}
+	check.DurationMs = time.Since(start).Milliseconds()
+
+	if err != nil {
+		check.Error = err.Error()
+		check.ErrorKind = errorKind(err)
+		return check
+	}
+	check.StatusCode = resp.StatusCode
+	check.FinalURL = resp.URL.String()
+	for _, hop := range resp.Redirects {
+		check.Redirects = append(check.Redirects, models.LinkRedirect{
+			URL:        hop.URL,
+			StatusCode: hop.StatusCode,
+			Location:   hop.Location,
+		})
+	}
+	return check
+}
+
+func errorKind(err error) string {
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	var record tls.RecordHeaderError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, fetch.ErrDisallowed):
+		return ErrorRobots
+	case errors.As(err, &dnsErr):
+		return ErrorDNS
+	case errors.As(err, &certErr), errors.As(err, &unknownAuthority), errors.As(err, &hostname),
+		errors.As(err, &invalid), errors.As(err, &record), strings.Contains(err.Error(), "tls: "):
+		return ErrorTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	case strings.HasPrefix(err.Error(), "can't fetch"):
+		return ErrorUnsupported
+	}
+	return ErrorConnection
+}
+
+// Record adds a check to a bookmark's health and works out its status
+func Record(health *models.LinkHealth, check models.LinkCheck) {
+	health.History = append(health.History, check)
+	if len(health.History) > historySize {
+		health.History = append([]models.LinkCheck(nil), health.History[len(health.History)-historySize:]...)
+	}
+	health.LastChecked = check.CheckedAt
+	health.StatusCode = check.StatusCode
+	health.FinalURL = check.FinalURL
+	health.Error = check.Error
+	health.MovedTo = ""
+
+	switch {
+	case check.Error == "" && check.StatusCode < 400:
+		health.ConsecutiveFailures = 0
+		health.LastHealthy = check.CheckedAt
+		health.Status = models.LinkHealthy
+		if movedTo := movedTo(health.URL, check.Redirects); movedTo != "" {
+			health.Status = models.LinkMoved
+			health.MovedTo = movedTo
+		}
+
+	case check.ErrorKind == ErrorRobots || check.ErrorKind == ErrorUnsupported:
+		// We may not look; that says nothing about the link
+		health.Status = models.LinkUnknown
+
+	case check.StatusCode == http.StatusUnauthorized || check.StatusCode == http.StatusForbidden ||
+		check.StatusCode == http.StatusTooManyRequests:
+		// The site is there, it just won't talk to us
+		health.Status = models.LinkUnknown
+
+	default:
+		health.ConsecutiveFailures++
+		health.Status = models.LinkUnknown
+		if gone(check) || health.ConsecutiveFailures >= deadAfter {
+			health.Status = models.LinkDead
+		}
+	}
+}
+
+// gone is true when the server, or DNS, said outright the page doesn't exist
+func gone(check models.LinkCheck) bool {
+	if check.StatusCode == http.StatusNotFound || check.StatusCode == http.StatusGone {
+		return true
+	}
+	return check.ErrorKind == ErrorDNS && strings.Contains(check.Error, "no such host")
+}
+
+// movedTo follows the permanent redirects at the start of a chain and
+// returns where they lead, unless that is the same page by another spelling
+func movedTo(original string, redirects []models.LinkRedirect) string {
+	target := ""
+	for _, hop := range redirects {
+		if hop.StatusCode != http.StatusMovedPermanently && hop.StatusCode != http.StatusPermanentRedirect {
+			break
+		}
+		target = hop.Location
+	}
+	if target == "" || samePage(original, target) {
+		return ""
+	}
+	return target
+}
+
+// samePage ignores the scheme, a leading "www." and a trailing slash, the
+// usual reasons sites redirect to themselves
+func samePage(a, b string) bool {
+	return pageKey(a) == pageKey(b)
+}
+
+func pageKey(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+	host = strings.TrimSuffix(strings.TrimSuffix(host, ":443"), ":80")
+	return host + strings.TrimSuffix(parsed.EscapedPath(), "/") + "?" + parsed.RawQuery
+}
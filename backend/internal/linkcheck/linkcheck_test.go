@@ -0,0 +1,167 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hyprlnk/internal/models"
+)
+
+// newSite stands in for the bookmarked web
+func newSite(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/login-wall", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	mux.HandleFunc("/dir", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/dir/", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/dir/", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newChecker() *Checker {
+	return New(Config{HostInterval: time.Millisecond, Timeout: 100 * time.Millisecond})
+}
+
+func checkOnce(t *testing.T, checker *Checker, rawURL string) models.LinkHealth {
+	health := models.LinkHealth{URL: rawURL}
+	Record(&health, checker.Check(context.Background(), rawURL))
+	return health
+}
+
+func TestCheck_Statuses(t *testing.T) {
+	server := newSite(t)
+	checker := newChecker()
+
+	tests := []struct {
+		path    string
+		status  string
+		code    int
+		movedTo string
+	}{
+		{"/ok", models.LinkHealthy, 200, ""},
+		{"/old", models.LinkMoved, 200, server.URL + "/new"},
+		{"/login-wall", models.LinkHealthy, 200, ""}, // temporary redirects aren't moves
+		{"/dir", models.LinkHealthy, 200, ""},        // nor is adding a trailing slash
+		{"/no-head", models.LinkHealthy, 200, ""},
+		{"/gone", models.LinkDead, 410, ""},
+		{"/missing", models.LinkDead, 404, ""},
+		{"/flaky", models.LinkUnknown, 503, ""},
+		{"/private", models.LinkUnknown, 403, ""},
+	}
+	for _, test := range tests {
+		health := checkOnce(t, checker, server.URL+test.path)
+		if health.Status != test.status || health.StatusCode != test.code || health.MovedTo != test.movedTo {
+			t.Errorf("%s: got %s %d moved to %q, want %s %d moved to %q",
+				test.path, health.Status, health.StatusCode, health.MovedTo, test.status, test.code, test.movedTo)
+		}
+	}
+}
+
+func TestCheck_RecordsRedirectsAndFallsBackToGet(t *testing.T) {
+	server := newSite(t)
+	checker := newChecker()
+
+	check := checker.Check(context.Background(), server.URL+"/old")
+	if len(check.Redirects) != 1 || check.Redirects[0].StatusCode != 301 || check.Redirects[0].Location != server.URL+"/new" {
+		t.Errorf("Unexpected redirects %+v", check.Redirects)
+	}
+	if check.FinalURL != server.URL+"/new" || check.Method != http.MethodHead {
+		t.Errorf("Expected a HEAD ending at /new, got %s ending at %s", check.Method, check.FinalURL)
+	}
+
+	check = checker.Check(context.Background(), server.URL+"/no-head")
+	if check.Method != http.MethodGet || check.StatusCode != 200 {
+		t.Errorf("Expected a GET retry to succeed, got %s %d", check.Method, check.StatusCode)
+	}
+}
+
+func TestCheck_ErrorKinds(t *testing.T) {
+	server := newSite(t)
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	checker := newChecker()
+	tests := []struct {
+		url  string
+		kind string
+	}{
+		{tlsServer.URL + "/", ErrorTLS}, // self-signed
+		{closed.URL + "/", ErrorConnection},
+		{server.URL + "/slow", ErrorTimeout},
+		{"ftp://example.com/file", ErrorUnsupported},
+	}
+	for _, test := range tests {
+		check := checker.Check(context.Background(), test.url)
+		if check.ErrorKind != test.kind {
+			t.Errorf("%s: got error kind %q (%s), want %q", test.url, check.ErrorKind, check.Error, test.kind)
+		}
+	}
+}
+
+func TestRecord_DeadAfterRepeatedFailures(t *testing.T) {
+	server := newSite(t)
+	checker := newChecker()
+	health := models.LinkHealth{URL: server.URL + "/flaky"}
+
+	for i := 1; i <= deadAfter; i++ {
+		Record(&health, checker.Check(context.Background(), health.URL))
+		want := models.LinkUnknown
+		if i == deadAfter {
+			want = models.LinkDead
+		}
+		if health.Status != want || health.ConsecutiveFailures != i {
+			t.Fatalf("After %d failures: got %s with %d failures, want %s", i, health.Status, health.ConsecutiveFailures, want)
+		}
+	}
+
+	// Recovering resets the count
+	health.URL = server.URL + "/ok"
+	Record(&health, checker.Check(context.Background(), health.URL))
+	if health.Status != models.LinkHealthy || health.ConsecutiveFailures != 0 || health.LastHealthy.IsZero() {
+		t.Fatalf("Expected a healthy link after recovering, got %+v", health)
+	}
+	if len(health.History) != deadAfter+1 {
+		t.Fatalf("Expected %d checks in the history, got %d", deadAfter+1, len(health.History))
+	}
+}
+
+func TestRecord_CapsHistory(t *testing.T) {
+	health := models.LinkHealth{URL: "https://example.com/"}
+	for i := 0; i < historySize+5; i++ {
+		Record(&health, models.LinkCheck{StatusCode: 200 + i})
+	}
+	if len(health.History) != historySize || health.History[0].StatusCode != 205 {
+		t.Fatalf("Expected the last %d checks, got %d starting at %d", historySize, len(health.History), health.History[0].StatusCode)
+	}
+}
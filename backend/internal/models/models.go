@@ -1,41 +1,421 @@
 package models
 
-import "time"
+import (
+    "encoding/json"
+    "time"
+)
 
 type Bookmark struct {
-    ID          int64     `json:"id"`
-    URL         string    `json:"url"`
-    Title       string    `json:"title"`
-    Description string    `json:"description"`
-    Tags        []string  `json:"tags"`
-    CreatedAt   time.Time `json:"created_at"`
+    ID           int64     `json:"id"`
+    URL          string    `json:"url"`
+    Title        string    `json:"title"`
+    Description  string    `json:"description"`
+    Tags         []string  `json:"tags"`
+    CollectionID int64     `json:"collection_id"`      // 0 means not filed in any collection
+    Status       string    `json:"status,omitempty"`   // reading state; empty means not on the reading list
+    Progress     int       `json:"progress,omitempty"` // percent read, 0-100
+    ReadAt       time.Time `json:"read_at"`
+    CreatedAt    time.Time `json:"created_at"`
+    UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Reading states of a bookmark on the reading list
+const (
+    ReadingUnread   = "unread"
+    ReadingReading  = "reading"  // started, see Progress
+    ReadingRead     = "read"     // finished, at ReadAt
+    ReadingArchived = "archived" // done with, read or not
+)
+
+// Annotation types
+const (
+    AnnotationNote      = "note"      // free-form Markdown about a page
+    AnnotationHighlight = "highlight" // a passage of the page, optionally with a comment
+)
+
+// Annotation is a note or highlight on a page. It belongs to a bookmark
+// when there is one and is matched to pages by canonical URL, so it also
+// covers pages only in history.
+type Annotation struct {
+    ID         int64      `json:"id"`
+    BookmarkID int64      `json:"bookmark_id,omitempty"` // 0 for a page that isn't bookmarked
+    URL        string     `json:"url"`                   // canonical URL of the page
+    Type       string     `json:"type"`
+    Body       string     `json:"body"`                  // Markdown; a highlight's comment
+    Highlight  *Highlight `json:"highlight,omitempty"`   // set for highlights
+    CreatedAt  time.Time  `json:"created_at"`
+    UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Highlight locates a passage in a page. The quoted text with its
+// surroundings finds it again after the page changes; the selector and
+// offsets are a faster path while it hasn't.
+type Highlight struct {
+    Text        string `json:"text"`               // the highlighted passage, exactly
+    Prefix      string `json:"prefix,omitempty"`   // text just before it, to tell repeats apart
+    Suffix      string `json:"suffix,omitempty"`   // text just after it
+    Selector    string `json:"selector,omitempty"` // CSS selector of the element containing it
+    StartOffset int    `json:"start_offset"`       // character offsets into that element's text
+    EndOffset   int    `json:"end_offset"`
+    Color       string `json:"color,omitempty"`
+}
+
+// Collection is a folder of bookmarks; collections nest through ParentID
+type Collection struct {
+    ID        int64     `json:"id"`
+    Name      string    `json:"name"`
+    ParentID  int64     `json:"parent_id"` // 0 for top-level collections
+    Position  int       `json:"position"`  // order among siblings
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CollectionNode is a collection with its nested children, for tree views
+type CollectionNode struct {
+    Collection
+    BookmarkCount int              `json:"bookmark_count"`
+    Children      []CollectionNode `json:"children"`
+}
+
+// TagInfo is optional metadata for a tag. Tags nest with "/", so "dev/go"
+// is a child of "dev".
+type TagInfo struct {
+    Name        string    `json:"name"`
+    Color       string    `json:"color,omitempty"`
+    Description string    `json:"description,omitempty"`
     UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// TagStats is a tag with its usage; TotalCount includes child tags
+type TagStats struct {
+    TagInfo
+    Count      int `json:"count"`
+    TotalCount int `json:"total_count"`
+}
+
+// DuplicateGroup is a set of bookmarks sharing one canonical URL
+type DuplicateGroup struct {
+    CanonicalURL string     `json:"canonical_url"`
+    Bookmarks    []Bookmark `json:"bookmarks"`
+}
+
+// Bulk operations on bookmarks
+const (
+    BulkAddTags    = "add_tags"
+    BulkRemoveTags = "remove_tags"
+    BulkMove       = "move"       // file into collection_id; 0 takes them out of their collection
+    BulkDelete     = "delete"     // move to the trash
+    BulkSetStatus  = "set_status" // set the reading state
+)
+
+// BulkRequest applies one operation to the bookmarks picked by IDs or by a
+// search query
+type BulkRequest struct {
+    IDs          []int64  `json:"ids,omitempty"`
+    Query        string   `json:"query,omitempty"`
+    Operation    string   `json:"operation"`
+    Tags         []string `json:"tags,omitempty"`
+    CollectionID int64    `json:"collection_id,omitempty"`
+    Status       string   `json:"status,omitempty"`
+    Progress     *int     `json:"progress,omitempty"`
+}
+
+// Outcomes of a bulk operation for one bookmark
+const (
+    BulkChanged   = "changed"
+    BulkUnchanged = "unchanged"
+    BulkNotFound  = "not_found"
+    BulkFailed    = "failed" // the write failed and the bookmark is as it was
+)
+
+// BulkResult is what a bulk operation did, bookmark by bookmark
+type BulkResult struct {
+    Operation string           `json:"operation"`
+    Matched   int              `json:"matched"`
+    Changed   int              `json:"changed"`
+    Items     []BulkItemResult `json:"items"`
+    Error     string           `json:"error,omitempty"` // set when a write failed partway
+}
+
+type BulkItemResult struct {
+    ID       int64     `json:"id"`
+    Result   string    `json:"result"`
+    Bookmark *Bookmark `json:"bookmark,omitempty"` // as it is now; absent once deleted
+}
+
 type Tab struct {
     URL        string `json:"url"`
     Title      string `json:"title"`
-    Active     bool   `json:"active"`
+    Active     bool   `json:"active"` // the tab shown in its window
     Index      int    `json:"index"`
     FavIconURL string `json:"favIconUrl"`
     Pinned     bool   `json:"pinned"`
+    WindowID   int    `json:"window_id,omitempty"` // the Window holding it; 0 in flat sessions
+    GroupID    int    `json:"group_id,omitempty"`  // its TabGroup; 0 when ungrouped
+}
+
+// Window states, as browsers name them
+const (
+    WindowNormal     = "normal"
+    WindowMinimized  = "minimized"
+    WindowMaximized  = "maximized"
+    WindowFullscreen = "fullscreen"
+)
+
+// Window is a browser window in a session. Window and tab group IDs only
+// need to be unique within their session.
+type Window struct {
+    ID      int    `json:"id"`
+    Focused bool   `json:"focused"`         // the window that had focus
+    State   string `json:"state,omitempty"` // normal when empty
 }
 
+// Tab group colors, as browsers name them
+const (
+    GroupGrey   = "grey"
+    GroupBlue   = "blue"
+    GroupRed    = "red"
+    GroupYellow = "yellow"
+    GroupGreen  = "green"
+    GroupPink   = "pink"
+    GroupPurple = "purple"
+    GroupCyan   = "cyan"
+    GroupOrange = "orange"
+)
+
+// TabGroup is a named group of tabs within one window
+type TabGroup struct {
+    ID        int    `json:"id"`
+    WindowID  int    `json:"window_id"`
+    Title     string `json:"title"`
+    Color     string `json:"color,omitempty"` // grey when empty
+    Collapsed bool   `json:"collapsed"`
+}
+
+// Session is a set of tabs, optionally laid out in windows and tab groups.
+// A session without windows is flat: all its tabs go in one window.
 type Session struct {
-    ID          int64     `json:"id"`
-    Name        string    `json:"name"`
-    Description string    `json:"description"`
-    Tabs        []Tab     `json:"tabs"`
-    IsActive    bool      `json:"is_active"`
-    CreatedAt   time.Time `json:"created_at"`
-    UpdatedAt   time.Time `json:"updated_at"`
+    ID          int64      `json:"id"`
+    Name        string     `json:"name"`
+    Description string     `json:"description"`
+    Tabs        []Tab      `json:"tabs"`
+    Windows     []Window   `json:"windows,omitempty"`
+    Groups      []TabGroup `json:"groups,omitempty"`
+    IsActive    bool       `json:"is_active"`
+    AutoSave    bool       `json:"auto_save,omitempty"` // kept up to date with the open tabs by the extension
+    CreatedAt   time.Time  `json:"created_at"`
+    UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Where a session version came from
+const (
+    VersionSave     = "save"     // the session was created, saved or imported
+    VersionAutoSave = "autosave" // the open tabs, posted by the extension
+    VersionRestore  = "restore"  // an earlier state was put back: a version, a revision, the trash or an undo
+)
+
+// SessionVersion is a session as it was saved at one point. Versions are
+// numbered from 1 for each session.
+type SessionVersion struct {
+    SessionID int64       `json:"session_id"`
+    Version   int         `json:"version"`
+    Source    string      `json:"source"`
+    Name      string      `json:"name"`
+    Tabs      []Tab       `json:"tabs"`
+    Windows   []Window    `json:"windows,omitempty"`
+    Groups    []TabGroup  `json:"groups,omitempty"`
+    Diff      SessionDiff `json:"diff"` // against the previous version
+    CreatedAt time.Time   `json:"created_at"`
+}
+
+// SessionDiff is how a session's tabs changed between two versions; tabs
+// are matched by URL
+type SessionDiff struct {
+    Added   []Tab `json:"added"`
+    Removed []Tab `json:"removed"`
+    Kept    int   `json:"kept"`
+}
+
+// AutoSaveRequest is the open tabs and their windows and groups, posted
+// by the extension every few minutes
+type AutoSaveRequest struct {
+    SessionID int64      `json:"session_id,omitempty"` // 0 for the default autosave session
+    Tabs      []Tab      `json:"tabs"`
+    Windows   []Window   `json:"windows,omitempty"`
+    Groups    []TabGroup `json:"groups,omitempty"`
+}
+
+// AutoSaveResult is the session's latest version after an autosave;
+// Changed is false when the tabs were the same as last time
+type AutoSaveResult struct {
+    Version *SessionVersion `json:"version"`
+    Changed bool            `json:"changed"`
+}
+
+// Kinds of items that keep revisions
+const (
+    ItemBookmark = "bookmark"
+    ItemSession  = "session"
+)
+
+// Operations recorded in revisions
+const (
+    OperationCreate  = "create"
+    OperationUpdate  = "update"
+    OperationDelete  = "delete"
+    OperationMerge   = "merge"
+    OperationImport  = "import"
+    OperationRestore = "restore" // an earlier revision was put back
+    OperationUndo    = "undo"
+)
+
+// Revision is one change to a bookmark or session, with the item as it
+// was before and after
+type Revision struct {
+    Rev            int             `json:"rev"`                       // numbered from 1 for each item
+    OperationID    int64           `json:"operation_id"`              // shared by the changes made together
+    Operation      string          `json:"operation"`
+    OperationItems int             `json:"operation_items,omitempty"` // items the operation changed; 0 in older logs
+    Before         json.RawMessage `json:"before,omitempty"`          // absent when the item was created
+    After          json.RawMessage `json:"after,omitempty"`           // absent when it was deleted
+    CreatedAt      time.Time       `json:"created_at"`
+    UndoneAt       time.Time       `json:"undone_at"`
+}
+
+// RevisionLog is the recent revisions of one item, oldest first
+type RevisionLog struct {
+    ItemType  string     `json:"item_type"`
+    ItemID    int64      `json:"item_id"`
+    Revisions []Revision `json:"revisions"`
+}
+
+// Operation is a set of changes made together, the unit of undo
+type Operation struct {
+    ID         int64     `json:"id"`
+    Operation  string    `json:"operation"`
+    Items      []ItemRef `json:"items"`
+    Incomplete bool      `json:"incomplete,omitempty"` // some items' revisions were trimmed, so it can't be undone
+    CreatedAt  time.Time `json:"created_at"`
+    UndoneAt   time.Time `json:"undone_at"`
+}
+
+// ItemRef names a bookmark or session
+type ItemRef struct {
+    Type string `json:"type"`
+    ID   int64  `json:"id"`
+}
+
+// TrashItem is a deleted bookmark or session, kept so it can be restored
+// until the trash is emptied
+type TrashItem struct {
+    Type      string          `json:"type"`
+    ID        int64           `json:"id"`
+    Title     string          `json:"title"`         // the bookmark's title or the session's name
+    URL       string          `json:"url,omitempty"` // bookmarks only
+    Item      json.RawMessage `json:"item"`
+    DeletedAt time.Time       `json:"deleted_at"`
+}
+
+// ItemState is an item to write back; an empty State deletes it
+type ItemState struct {
+    ItemType string
+    ItemID   int64
+    State    json.RawMessage
 }
 
 type ImportedBookmark struct {
-    URL       string    `json:"url"`
-    Title     string    `json:"title"`
-    Folder    string    `json:"folder"`
-    AddedDate time.Time `json:"added_date"`
+    URL         string    `json:"url"`
+    Title       string    `json:"title"`
+    Description string    `json:"description,omitempty"`
+    Tags        []string  `json:"tags,omitempty"`
+    Folder      string    `json:"folder"`                // "Bookmarks Bar/Dev/Go"; split on "/" when FolderPath is empty
+    FolderPath  []string  `json:"folder_path,omitempty"` // exact folder hierarchy, root first
+    AddedDate   time.Time `json:"added_date"`
+}
+
+// ImportResult counts what an import stored. Anything already present is
+// skipped, so re-importing the same files adds nothing.
+type ImportResult struct {
+    BookmarksImported int `json:"bookmarks_imported"`
+    HistoryImported   int `json:"history_imported"`
+    VisitsImported    int `json:"visits_imported"`
+}
+
+// Import modes decide what happens to imported bookmarks whose canonical
+// URL is already bookmarked
+const (
+    ImportSkipExisting = "skip_existing" // leave the existing bookmark alone
+    ImportOverwrite    = "overwrite"     // replace its tags, and its title, description and folder unless blank
+    ImportMergeTags    = "merge_tags"    // add the imported tags, fill in blank fields
+    ImportDryRun       = "dry_run"       // preview skip_existing without writing anything
+)
+
+// ImportBatch is everything one import brings in
+type ImportBatch struct {
+    Source    string             `json:"source"` // e.g. browser, browser-db, pocket
+    Mode      string             `json:"mode"`
+    Bookmarks []ImportedBookmark `json:"bookmarks"`
+    History   []HistoryEntry     `json:"history"`
+    Visits    []LinkClick        `json:"visits"`
+    Sessions  []Session          `json:"sessions"`
+    UseAI     bool               `json:"use_ai"`
+}
+
+// ImportDiff lists what an import did, or would do in a dry run
+type ImportDiff struct {
+    Mode    string         `json:"mode"`
+    DryRun  bool           `json:"dry_run"`
+    Creates []ImportChange `json:"creates"`
+    Updates []ImportChange `json:"updates"`
+    Skips   []ImportChange `json:"skips"`
+    Errors  []ItemError    `json:"errors,omitempty"` // entries an uploaded file's parser skipped
+}
+
+// ImportChange is the outcome for one imported bookmark. Before is the
+// existing bookmark for updates and skips; After is the stored result for
+// creates and updates.
+type ImportChange struct {
+    URL    string    `json:"url"`
+    Title  string    `json:"title"`
+    Reason string    `json:"reason,omitempty"` // why it was skipped
+    Before *Bookmark `json:"before,omitempty"`
+    After  *Bookmark `json:"after,omitempty"`
+}
+
+// ItemError explains why one item of an import or batch was skipped
+type ItemError struct {
+    Row     int    `json:"row,omitempty"`  // line or item number in an uploaded file
+    Item    string `json:"item,omitempty"` // e.g. the URL
+    Message string `json:"message"`
+}
+
+const (
+    JobQueued    = "queued"
+    JobRunning   = "running"
+    JobCompleted = "completed"
+    JobFailed    = "failed"
+    JobCancelled = "cancelled"
+)
+
+// Job is a unit of background work such as an import
+type Job struct {
+    ID         int64          `json:"id"`
+    Type       string         `json:"type"`
+    Title      string         `json:"title"` // e.g. "Import from pocket"
+    Status     string         `json:"status"`
+    Total      int            `json:"total"`     // items to process
+    Processed  int            `json:"processed"` // items done so far
+    Counts     map[string]int `json:"counts"`    // outcome tallies, e.g. bookmarks_imported
+    Errors     []ItemError    `json:"errors"`
+    Error      string         `json:"error,omitempty"` // why the job failed
+    CreatedAt  time.Time      `json:"created_at"`
+    StartedAt  time.Time      `json:"started_at"`
+    FinishedAt time.Time      `json:"finished_at"`
+}
+
+// Finished reports whether the job has stopped for good
+func (j Job) Finished() bool {
+    return j.Status == JobCompleted || j.Status == JobFailed || j.Status == JobCancelled
 }
 
 type HistoryEntry struct {
@@ -43,11 +423,143 @@ type HistoryEntry struct {
     Title         string    `json:"title"`
     VisitCount    int       `json:"visit_count"`
     LastVisitTime time.Time `json:"last_visit_time"`
+    DwellSeconds  int       `json:"dwell_seconds,omitempty"` // time spent on the page during the last visit
     SourceURL     string    `json:"source_url,omitempty"`
     SourceTitle   string    `json:"source_title,omitempty"`
     LinkText      string    `json:"link_text,omitempty"`
 }
 
+// AutoTagRule tags and files bookmarks that match all of its conditions
+type AutoTagRule struct {
+    ID           int64           `json:"id"`
+    Name         string          `json:"name"`
+    Disabled     bool            `json:"disabled,omitempty"`
+    Conditions   []RuleCondition `json:"conditions"`
+    Tags         []string        `json:"tags,omitempty"`          // added to matching bookmarks
+    CollectionID int64           `json:"collection_id,omitempty"` // files matching bookmarks not yet in a collection
+    CreatedAt    time.Time       `json:"created_at"`
+    UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// RuleCondition tests one bookmark field. Comparisons ignore case.
+type RuleCondition struct {
+    Field string `json:"field"` // domain, url, title or description
+    Op    string `json:"op"`    // contains, equals, glob ("*.atlassian.net") or regex
+    Value string `json:"value"`
+}
+
+const (
+    RuleFieldDomain      = "domain"
+    RuleFieldURL         = "url"
+    RuleFieldTitle       = "title"
+    RuleFieldDescription = "description"
+
+    RuleOpContains = "contains"
+    RuleOpEquals   = "equals"
+    RuleOpGlob     = "glob"
+    RuleOpRegex    = "regex"
+)
+
+// RuleChange is what auto-tagging rules did, or would do, to a bookmark
+type RuleChange struct {
+    BookmarkID   int64    `json:"bookmark_id"`
+    URL          string   `json:"url"`
+    Title        string   `json:"title"`
+    AddedTags    []string `json:"added_tags,omitempty"`
+    CollectionID int64    `json:"collection_id,omitempty"` // set when a rule filed the bookmark
+    RuleIDs      []int64  `json:"rule_ids"`                // the rules that changed it
+}
+
+// PageMetadata is what fetching a bookmark's page found. Stored apart from
+// the bookmark so bookmark edits can't drop it.
+type PageMetadata struct {
+    BookmarkID   int64             `json:"bookmark_id"`
+    URL          string            `json:"url"` // the bookmark URL that was fetched
+    FinalURL     string            `json:"final_url,omitempty"` // after redirects
+    StatusCode   int               `json:"status_code,omitempty"`
+    Title        string            `json:"title,omitempty"`
+    Description  string            `json:"description,omitempty"`
+    CanonicalURL string            `json:"canonical_url,omitempty"`
+    OpenGraph    map[string]string `json:"open_graph,omitempty"` // og:* properties without the prefix
+    Favicon      string            `json:"favicon,omitempty"`    // local path, e.g. /api/favicons/go.dev
+    Error        string            `json:"error,omitempty"`
+    Attempts     int               `json:"attempts"` // consecutive failed fetches
+    FetchedAt    time.Time         `json:"fetched_at"`
+}
+
+// PageArchive describes the offline copy of a bookmark's page. The snapshot
+// and text themselves are stored as files beside it.
+type PageArchive struct {
+    BookmarkID   int64     `json:"bookmark_id"`
+    URL          string    `json:"url"` // the bookmark URL that was archived
+    FinalURL     string    `json:"final_url,omitempty"`
+    Title        string    `json:"title,omitempty"`
+    TextLength   int       `json:"text_length"`   // characters of readable text
+    SnapshotSize int       `json:"snapshot_size"` // bytes of HTML snapshot
+    Inlined      int       `json:"inlined"`       // images, stylesheets and fonts embedded
+    Skipped      int       `json:"skipped"`       // resources left as links to the live site
+    ArchivedAt   time.Time `json:"archived_at"` // zero until an archive succeeds
+    Error        string    `json:"error,omitempty"`
+    Attempts     int       `json:"attempts"` // consecutive failed attempts
+    AttemptedAt  time.Time `json:"attempted_at"`
+}
+
+// Link health statuses
+const (
+    LinkHealthy = "healthy" // the URL answers
+    LinkMoved   = "moved"   // the URL permanently redirects elsewhere
+    LinkDead    = "dead"    // the page is gone, or has failed repeatedly
+    LinkUnknown = "unknown" // failing, but not yet for long enough to call it dead
+)
+
+// LinkHealth is what checking a bookmark's URL found, with a history of
+// recent checks
+type LinkHealth struct {
+    BookmarkID          int64       `json:"bookmark_id"`
+    URL                 string      `json:"url"` // the bookmark URL that was checked
+    Status              string      `json:"status"`
+    StatusCode          int         `json:"status_code,omitempty"`
+    FinalURL            string      `json:"final_url,omitempty"`
+    MovedTo             string      `json:"moved_to,omitempty"` // where a moved link now lives
+    Error               string      `json:"error,omitempty"`
+    ConsecutiveFailures int         `json:"consecutive_failures"`
+    LastChecked         time.Time   `json:"last_checked"`
+    LastHealthy         time.Time   `json:"last_healthy"`
+    History             []LinkCheck `json:"history,omitempty"` // oldest first
+}
+
+// LinkCheck is the outcome of requesting a URL once
+type LinkCheck struct {
+    CheckedAt  time.Time      `json:"checked_at"`
+    URL        string         `json:"url"` // the URL requested, after any fallback to http
+    Method     string         `json:"method"`
+    StatusCode int            `json:"status_code,omitempty"`
+    FinalURL   string         `json:"final_url,omitempty"`
+    Redirects  []LinkRedirect `json:"redirects,omitempty"`
+    Error      string         `json:"error,omitempty"`
+    ErrorKind  string         `json:"error_kind,omitempty"` // dns, tls, timeout, connection, robots or unsupported
+    DurationMs int64          `json:"duration_ms"`
+}
+
+// LinkRedirect is one hop of a redirect chain
+type LinkRedirect struct {
+    URL        string `json:"url"`
+    StatusCode int    `json:"status_code"`
+    Location   string `json:"location"`
+}
+
+// LinkHealthReport lists checked bookmarks with a count per status
+type LinkHealthReport struct {
+    Counts    map[string]int `json:"counts"`
+    Bookmarks []LinkHealth   `json:"bookmarks"`
+}
+
+// TagSuggestion is a tag the local model proposes, scored 0 to 1
+type TagSuggestion struct {
+    Tag   string  `json:"tag"`
+    Score float64 `json:"score"`
+}
+
 type AISegmentation struct {
     Category    string   `json:"category"`
     Tags        []string `json:"tags"`
@@ -65,6 +577,56 @@ type LinkClick struct {
     ClickType        string    `json:"click_type"` // external_link, internal_link, form_submit
     Domain           string    `json:"domain"`
     IsNewTab         bool      `json:"is_new_tab"`
+    Source           string    `json:"source,omitempty"` // "" for clicks the extension tracked, the browser for imported visits
     Timestamp        time.Time `json:"timestamp"`
     CreatedAt        time.Time `json:"created_at"`
-}
\ No newline at end of file
+}
+
+type Settings struct {
+    Timezone           string    `json:"timezone"` // IANA name, e.g. Europe/Berlin
+    URLRules           []URLRule `json:"url_rules"`
+    ReadDwellSeconds   int       `json:"read_dwell_seconds"`   // a visit this long marks a queued bookmark read; 0 is the default, negative turns it off
+    TrashRetentionDays int       `json:"trash_retention_days"` // deleted items are purged after this many days; 0 is the default, negative keeps them
+    AutoSaveMinutes    int       `json:"autosave_minutes"`        // how often the extension autosaves the open tabs; 0 is the default, negative turns it off
+    AutoSaveDays       int       `json:"autosave_days"`           // autosaved versions are kept this many days; 0 is the default, negative keeps them
+    UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// URLRule overrides URL canonicalization for a domain and its subdomains
+type URLRule struct {
+    Domain            string   `json:"domain"`
+    StripParams       []string `json:"strip_params,omitempty"` // extra query params to drop; "utm_*" style prefixes and "*" allowed
+    KeepParams        []string `json:"keep_params,omitempty"`  // when set, only these query params survive
+    KeepFragment      bool     `json:"keep_fragment,omitempty"`
+    KeepTrailingSlash bool     `json:"keep_trailing_slash,omitempty"`
+    KeepScheme        bool     `json:"keep_scheme,omitempty"` // don't upgrade http to https
+    KeepWWW           bool     `json:"keep_www,omitempty"`
+}
+
+// DefaultReadDwellSeconds is how long a visit to a queued bookmark's page
+// has to last before history sync marks it read
+const DefaultReadDwellSeconds = 60
+
+// DefaultTrashRetentionDays is how long deleted items stay in the trash
+const DefaultTrashRetentionDays = 30
+
+// DefaultAutoSaveMinutes is how often the extension autosaves the open tabs
+const DefaultAutoSaveMinutes = 5
+
+// DefaultAutoSaveDays is how long autosaved session versions are kept
+const DefaultAutoSaveDays = 30
+
+// DefaultSettings returns the settings used before the user saved any
+func DefaultSettings() Settings {
+    return Settings{
+        Timezone:           "UTC",
+        ReadDwellSeconds:   DefaultReadDwellSeconds,
+        TrashRetentionDays: DefaultTrashRetentionDays,
+        AutoSaveMinutes:    DefaultAutoSaveMinutes,
+        AutoSaveDays:       DefaultAutoSaveDays,
+        URLRules: []URLRule{
+            // The video ID lives in the query string
+            {Domain: "youtube.com", KeepParams: []string{"v", "list"}},
+        },
+    }
+}
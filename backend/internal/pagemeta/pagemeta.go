@@ -0,0 +1,156 @@
+// Package pagemeta extracts the title, description, Open Graph properties,
+// canonical URL and favicon from an HTML page's head.
+package pagemeta
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// Page is what a page says about itself
+type Page struct {
+	Title        string
+	Description  string
+	CanonicalURL string
+	FaviconURL   string            // the best icon link, or /favicon.ico
+	OpenGraph    map[string]string // og:* properties without the prefix
+}
+
+// Extract reads the head of an HTML document. base resolves relative
+// links; contentType, if known, helps pick the character set.
+func Extract(body []byte, base *url.URL, contentType string) Page {
+	page := Page{OpenGraph: make(map[string]string)}
+
+	var reader io.Reader = bytes.NewReader(body)
+	if decoded, err := charset.NewReader(reader, contentType); err == nil {
+		reader = decoded
+	}
+
+	var iconHref string
+	iconRank := 0
+	inTitle := false
+	var title strings.Builder
+
+	tokenizer := html.NewTokenizer(reader)
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			return finish(page, title.String(), iconHref, base)
+
+		case html.TextToken:
+			if inTitle {
+				title.Write(tokenizer.Text())
+			}
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				return finish(page, title.String(), iconHref, base)
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttrs := tokenizer.TagName()
+			attrs := map[string]string{}
+			for hasAttrs {
+				var key, value []byte
+				key, value, hasAttrs = tokenizer.TagAttr()
+				attrs[string(key)] = string(value)
+			}
+
+			switch string(name) {
+			case "title":
+				// Only the first title counts; SVG titles come later in the body
+				inTitle = title.Len() == 0 && tokenType == html.StartTagToken
+			case "body":
+				return finish(page, title.String(), iconHref, base)
+			case "meta":
+				content := strings.TrimSpace(attrs["content"])
+				property := strings.ToLower(attrs["property"])
+				if property == "" {
+					property = strings.ToLower(attrs["name"])
+				}
+				switch {
+				case property == "description" && page.Description == "":
+					page.Description = content
+				case strings.HasPrefix(property, "og:") && content != "":
+					key := strings.TrimPrefix(property, "og:")
+					if _, seen := page.OpenGraph[key]; !seen {
+						page.OpenGraph[key] = content
+					}
+				}
+			case "link":
+				href := strings.TrimSpace(attrs["href"])
+				if href == "" {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+					switch rel {
+					case "canonical":
+						if page.CanonicalURL == "" {
+							page.CanonicalURL = resolve(base, href)
+						}
+					case "icon", "apple-touch-icon":
+						// Prefer a plain icon; touch icons are large
+						rank := 1
+						if rel == "icon" {
+							rank = 2
+						}
+						if rank > iconRank {
+							iconHref, iconRank = href, rank
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func finish(page Page, title, iconHref string, base *url.URL) Page {
+	page.Title = strings.Join(strings.Fields(title), " ")
+	if iconHref != "" {
+		page.FaviconURL = resolve(base, iconHref)
+	} else if base != nil {
+		page.FaviconURL = resolve(base, "/favicon.ico")
+	}
+	if image, ok := page.OpenGraph["image"]; ok {
+		page.OpenGraph["image"] = resolve(base, image)
+	}
+	return page
+}
+
+// BestTitle prefers the Open Graph title, which sites keep free of
+// "| Site name" suffixes
+func (p Page) BestTitle() string {
+	if title := p.OpenGraph["title"]; title != "" {
+		return title
+	}
+	return p.Title
+}
+
+// BestDescription falls back to the Open Graph description
+func (p Page) BestDescription() string {
+	if p.Description != "" {
+		return p.Description
+	}
+	return p.OpenGraph["description"]
+}
+
+func resolve(base *url.URL, href string) string {
+	if base == nil {
+		return href
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(ref).String()
+}
@@ -0,0 +1,92 @@
+package pagemeta
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"hyprlnk/internal/fetch"
+)
+
+const testPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>
+    Release notes  |  Example
+  </title>
+  <meta name="description" content="What changed in 2.0">
+  <meta property="og:title" content="Release notes">
+  <meta property="og:image" content="/social.png">
+  <meta property="og:type" content="article">
+  <link rel="apple-touch-icon" href="/touch.png">
+  <link rel="shortcut icon" href="/static/favicon.png">
+  <link rel="canonical" href="https://example.com/releases/2.0">
+</head>
+<body>
+  <svg><title>Not the page title</title></svg>
+  <meta name="description" content="ignored, outside head">
+</body>
+</html>`
+
+func TestExtract_FetchedPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, testPage)
+	}))
+	defer server.Close()
+
+	resp, err := fetch.New(fetch.Config{HostInterval: time.Millisecond}).Get(context.Background(), server.URL+"/releases/latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := Extract(resp.Body, resp.URL, resp.Header.Get("Content-Type"))
+
+	if page.Title != "Release notes | Example" {
+		t.Errorf("Unexpected title %q", page.Title)
+	}
+	if page.BestTitle() != "Release notes" {
+		t.Errorf("Expected og:title to be preferred, got %q", page.BestTitle())
+	}
+	if page.Description != "What changed in 2.0" {
+		t.Errorf("Unexpected description %q", page.Description)
+	}
+	if page.CanonicalURL != "https://example.com/releases/2.0" {
+		t.Errorf("Unexpected canonical URL %q", page.CanonicalURL)
+	}
+	if page.FaviconURL != server.URL+"/static/favicon.png" {
+		t.Errorf("Expected the plain icon over the touch icon, got %q", page.FaviconURL)
+	}
+	if page.OpenGraph["image"] != server.URL+"/social.png" || page.OpenGraph["type"] != "article" {
+		t.Errorf("Unexpected Open Graph properties %v", page.OpenGraph)
+	}
+}
+
+func TestExtract_Fallbacks(t *testing.T) {
+	base, _ := url.Parse("https://example.com/a/b")
+	page := Extract([]byte(`<html><head><meta property="og:description" content="From OG"></head></html>`), base, "")
+
+	if page.FaviconURL != "https://example.com/favicon.ico" {
+		t.Errorf("Expected /favicon.ico without an icon link, got %q", page.FaviconURL)
+	}
+	if page.BestDescription() != "From OG" {
+		t.Errorf("Expected og:description as a fallback, got %q", page.BestDescription())
+	}
+	if page.BestTitle() != "" {
+		t.Errorf("Expected no title, got %q", page.BestTitle())
+	}
+}
+
+func TestExtract_DecodesCharset(t *testing.T) {
+	// "Café" in ISO-8859-1
+	body := []byte("<html><head><title>Caf\xe9</title></head></html>")
+	page := Extract(body, nil, "text/html; charset=iso-8859-1")
+
+	if page.Title != "Café" {
+		t.Fatalf("Expected the title decoded from Latin-1, got %q", page.Title)
+	}
+}
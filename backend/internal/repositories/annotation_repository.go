@@ -0,0 +1,87 @@
+package repositories
+
+import (
+    "fmt"
+    "sort"
+    "time"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/storage"
+)
+
+type annotationRepository struct {
+    storage *storage.AppendLogStorage
+}
+
+func NewAnnotationRepository(storage *storage.AppendLogStorage) AnnotationRepository {
+    return &annotationRepository{storage: storage}
+}
+
+// GetAll returns annotations oldest first
+func (r *annotationRepository) GetAll() ([]models.Annotation, error) {
+    annotations, err := r.storage.ReadAnnotations()
+    if err != nil {
+        return nil, err
+    }
+
+    sort.Slice(annotations, func(i, j int) bool {
+        return annotations[i].ID < annotations[j].ID
+    })
+
+    return annotations, nil
+}
+
+func (r *annotationRepository) GetByID(id int64) (*models.Annotation, error) {
+    annotations, err := r.storage.ReadAnnotations()
+    if err != nil {
+        return nil, err
+    }
+
+    for _, annotation := range annotations {
+        if annotation.ID == id {
+            return &annotation, nil
+        }
+    }
+
+    return nil, fmt.Errorf("annotation with ID %d not found", id)
+}
+
+func (r *annotationRepository) Create(annotation *models.Annotation) error {
+    if annotation.ID == 0 {
+        annotation.ID = time.Now().UnixNano()
+    }
+    now := time.Now()
+    annotation.CreatedAt = now
+    annotation.UpdatedAt = now
+
+    return r.storage.PutAnnotations(*annotation)
+}
+
+func (r *annotationRepository) Update(annotation *models.Annotation) error {
+    existing, err := r.GetByID(annotation.ID)
+    if err != nil {
+        return err
+    }
+
+    annotation.CreatedAt = existing.CreatedAt
+    annotation.UpdatedAt = time.Now()
+
+    return r.storage.PutAnnotations(*annotation)
+}
+
+// UpdateMany rewrites several annotations in a single storage write
+func (r *annotationRepository) UpdateMany(annotations []models.Annotation) error {
+    return r.storage.PutAnnotations(annotations...)
+}
+
+func (r *annotationRepository) Delete(id int64) error {
+    if _, err := r.GetByID(id); err != nil {
+        return err
+    }
+    return r.storage.DeleteAnnotations(id)
+}
+
+// DeleteMany removes several annotations in a single storage write
+func (r *annotationRepository) DeleteMany(ids []int64) error {
+    return r.storage.DeleteAnnotations(ids...)
+}
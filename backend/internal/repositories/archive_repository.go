@@ -0,0 +1,57 @@
+package repositories
+
+import (
+    "fmt"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/storage"
+)
+
+type archiveRepository struct {
+    storage *storage.AppendLogStorage
+}
+
+func NewArchiveRepository(storage *storage.AppendLogStorage) ArchiveRepository {
+    return &archiveRepository{storage: storage}
+}
+
+func (r *archiveRepository) GetAll() ([]models.PageArchive, error) {
+    return r.storage.ReadArchives()
+}
+
+func (r *archiveRepository) Get(bookmarkID int64) (*models.PageArchive, error) {
+    all, err := r.storage.ReadArchives()
+    if err != nil {
+        return nil, err
+    }
+
+    for _, archive := range all {
+        if archive.BookmarkID == bookmarkID {
+            return &archive, nil
+        }
+    }
+
+    return nil, fmt.Errorf("bookmark %d has not been archived", bookmarkID)
+}
+
+func (r *archiveRepository) Save(archive models.PageArchive, snapshot []byte, text string) error {
+    // Files first, so a record never points at a missing snapshot
+    if snapshot != nil {
+        if err := r.storage.WriteArchive(archive.BookmarkID, snapshot, text); err != nil {
+            return err
+        }
+    }
+    return r.storage.PutArchives(archive)
+}
+
+func (r *archiveRepository) Delete(bookmarkIDs ...int64) error {
+    return r.storage.DeleteArchives(bookmarkIDs...)
+}
+
+func (r *archiveRepository) GetSnapshot(bookmarkID int64) ([]byte, error) {
+    return r.storage.ReadArchiveSnapshot(bookmarkID)
+}
+
+func (r *archiveRepository) GetText(bookmarkID int64) (string, error) {
+    return r.storage.ReadArchiveText(bookmarkID)
+}
@@ -2,10 +2,13 @@ package repositories
 
 import (
     "fmt"
+    "sort"
     "strings"
+    "time"
 
     "hyprlnk/internal/models"
     "hyprlnk/internal/storage"
+    "hyprlnk/internal/urlnorm"
 )
 
 type bookmarkRepository struct {
@@ -21,22 +24,82 @@ func (r *bookmarkRepository) GetAll() ([]models.Bookmark, error) {
 }
 
 func (r *bookmarkRepository) GetByID(id int64) (*models.Bookmark, error) {
-    bookmarks, err := r.storage.ReadBookmarks()
+    bookmark, ok, err := r.storage.GetBookmark(id)
     if err != nil {
         return nil, err
     }
+    if !ok {
+        return nil, fmt.Errorf("bookmark with ID %d not found", id)
+    }
+    return &bookmark, nil
+}
 
-    for _, bookmark := range bookmarks {
-        if bookmark.ID == id {
-            return &bookmark, nil
-        }
+// GetByURL finds the bookmark saved under rawURL's canonical form; when
+// there are duplicates, the oldest
+func (r *bookmarkRepository) GetByURL(rawURL string) (*models.Bookmark, error) {
+    bookmarks, err := r.FindByURL(rawURL)
+    if err != nil {
+        return nil, err
+    }
+    if len(bookmarks) == 0 {
+        return nil, fmt.Errorf("no bookmark for %s", rawURL)
     }
+    return &bookmarks[0], nil
+}
 
-    return nil, fmt.Errorf("bookmark with ID %d not found", id)
+// FindByURL returns every bookmark saved under rawURL's canonical form,
+// oldest first; none is not an error
+func (r *bookmarkRepository) FindByURL(rawURL string) ([]models.Bookmark, error) {
+    return r.storage.FindBookmarksByURL(rawURL)
 }
 
-func (r *bookmarkRepository) Create(bookmark *models.Bookmark) error {
-    return r.storage.AddBookmark(*bookmark)
+// Create stores a new bookmark with its URL as given. If a bookmark with
+// the same canonical URL already exists, the new tags are merged into it
+// instead, *bookmark is replaced with the updated existing bookmark and
+// created is false.
+func (r *bookmarkRepository) Create(bookmark *models.Bookmark) (created bool, err error) {
+    bookmark.URL = strings.TrimSpace(bookmark.URL)
+    matches, err := r.storage.FindBookmarksByURL(bookmark.URL)
+    if err != nil {
+        return false, err
+    }
+
+    if len(matches) > 0 {
+        existing := matches[0]
+        original := existing
+        existing.Tags = mergeTags(existing.Tags, bookmark.Tags)
+        if existing.Description == "" {
+            existing.Description = bookmark.Description
+        }
+        // Saving a page again for later queues it
+        if existing.Status == "" {
+            existing.Status = bookmark.Status
+            existing.Progress = bookmark.Progress
+            existing.ReadAt = bookmark.ReadAt
+        }
+        existing.UpdatedAt = time.Now()
+        if err := r.storage.UpdateBookmark(existing); err != nil {
+            return false, err
+        }
+        *bookmark = existing
+        return false, recordRevisions(r.storage, models.OperationUpdate, []itemChange{
+            {itemType: models.ItemBookmark, itemID: existing.ID, before: original, after: existing},
+        }, 0)
+    }
+
+    if bookmark.ID == 0 {
+        bookmark.ID = time.Now().UnixNano()
+    }
+    now := time.Now()
+    bookmark.CreatedAt = now
+    bookmark.UpdatedAt = now
+
+    if err := r.storage.AddBookmark(*bookmark); err != nil {
+        return false, err
+    }
+    return true, recordRevisions(r.storage, models.OperationCreate, []itemChange{
+        {itemType: models.ItemBookmark, itemID: bookmark.ID, after: *bookmark},
+    }, 0)
 }
 
 func (r *bookmarkRepository) Update(bookmark *models.Bookmark) error {
@@ -48,18 +111,119 @@ func (r *bookmarkRepository) Update(bookmark *models.Bookmark) error {
     
     // Preserve creation time
     bookmark.CreatedAt = existing.CreatedAt
-    
-    return r.storage.UpdateBookmark(*bookmark)
+
+    // Clients that don't know about the reading list leave it alone
+    if bookmark.Status == "" {
+        bookmark.Status = existing.Status
+        bookmark.Progress = existing.Progress
+        bookmark.ReadAt = existing.ReadAt
+    }
+
+    if err := r.storage.UpdateBookmark(*bookmark); err != nil {
+        return err
+    }
+    return recordRevisions(r.storage, models.OperationUpdate, []itemChange{
+        {itemType: models.ItemBookmark, itemID: bookmark.ID, before: *existing, after: *bookmark},
+    }, 0)
+}
+
+// UpdateManyUntracked rewrites bookmarks for changes the app makes on its
+// own, such as titles from fetched pages or read marks from history. No
+// revisions are recorded: the changes aren't the user's to undo, and they
+// mustn't push the user's own revisions out of the bounded log.
+func (r *bookmarkRepository) UpdateManyUntracked(bookmarks []models.Bookmark) error {
+    if len(bookmarks) == 0 {
+        return nil
+    }
+    return r.storage.UpdateBookmarks(bookmarks)
+}
+
+// UpdateMany rewrites several existing bookmarks with one write to the
+// bookmarks and one to the revision log, whatever their number
+func (r *bookmarkRepository) UpdateMany(bookmarks []models.Bookmark) error {
+    if len(bookmarks) == 0 {
+        return nil
+    }
+    changes := make([]itemChange, 0, len(bookmarks))
+    for _, bookmark := range bookmarks {
+        change := itemChange{itemType: models.ItemBookmark, itemID: bookmark.ID, after: bookmark}
+        before, ok, err := r.storage.GetBookmark(bookmark.ID)
+        if err != nil {
+            return err
+        }
+        if ok {
+            change.before = before
+        }
+        changes = append(changes, change)
+    }
+    if err := r.storage.UpdateBookmarks(bookmarks); err != nil {
+        return err
+    }
+    return recordRevisions(r.storage, models.OperationUpdate, changes, 0)
 }
 
 func (r *bookmarkRepository) Delete(id int64) error {
     // Check if bookmark exists first
-    _, err := r.GetByID(id)
+    existing, err := r.GetByID(id)
     if err != nil {
         return fmt.Errorf("bookmark with ID %d not found", id)
     }
     
-    return r.storage.DeleteBookmark(id)
+    trashed, err := trashBookmark(*existing, time.Now())
+    if err != nil {
+        return err
+    }
+    if err := r.storage.PutTrash(trashed); err != nil {
+        return err
+    }
+    if err := r.storage.DeleteBookmark(id); err != nil {
+        return err
+    }
+    return recordRevisions(r.storage, models.OperationDelete, []itemChange{
+        {itemType: models.ItemBookmark, itemID: id, before: *existing},
+    }, 0)
+}
+
+// DeleteMany moves several bookmarks to the trash with one write each to
+// the trash, the bookmarks and the revision log, whatever their number.
+// The writes aren't atomic together. IDs that don't exist are skipped.
+func (r *bookmarkRepository) DeleteMany(ids []int64) error {
+    now := time.Now()
+    seen := make(map[int64]bool, len(ids))
+    var deleted []int64
+    var trash []models.TrashItem
+    var changes []itemChange
+    for _, id := range ids {
+        if seen[id] {
+            continue
+        }
+        seen[id] = true
+        existing, ok, err := r.storage.GetBookmark(id)
+        if err != nil {
+            return err
+        }
+        if !ok {
+            continue
+        }
+        trashed, err := trashBookmark(existing, now)
+        if err != nil {
+            return err
+        }
+        deleted = append(deleted, id)
+        trash = append(trash, trashed)
+        changes = append(changes, itemChange{itemType: models.ItemBookmark, itemID: id, before: existing})
+    }
+    if len(deleted) == 0 {
+        return nil
+    }
+
+    if err := r.storage.PutTrash(trash...); err != nil {
+        return err
+    }
+    if err := r.storage.DeleteBookmarks(deleted...); err != nil {
+        return err
+    }
+    return recordRevisions(r.storage, models.OperationDelete, changes, 0)
 }
 
 func (r *bookmarkRepository) Search(query string) ([]models.Bookmark, error) {
@@ -88,4 +252,163 @@ func (r *bookmarkRepository) Search(query string) ([]models.Bookmark, error) {
     }
 
     return results, nil
-}
\ No newline at end of file
+}
+
+// FindDuplicates groups bookmarks whose URLs share a canonical form
+func (r *bookmarkRepository) FindDuplicates() ([]models.DuplicateGroup, error) {
+    normalizer, err := newNormalizer(r.storage)
+    if err != nil {
+        return nil, err
+    }
+
+    bookmarks, err := r.storage.ReadBookmarks()
+    if err != nil {
+        return nil, err
+    }
+
+    groups := make(map[string][]models.Bookmark)
+    for _, bookmark := range bookmarks {
+        canonical := normalizer.Normalize(bookmark.URL)
+        groups[canonical] = append(groups[canonical], bookmark)
+    }
+
+    var duplicates []models.DuplicateGroup
+    for canonical, group := range groups {
+        if len(group) < 2 {
+            continue
+        }
+        sort.Slice(group, func(i, j int) bool {
+            return group[i].CreatedAt.Before(group[j].CreatedAt)
+        })
+        duplicates = append(duplicates, models.DuplicateGroup{
+            CanonicalURL: canonical,
+            Bookmarks:    group,
+        })
+    }
+
+    sort.Slice(duplicates, func(i, j int) bool {
+        return duplicates[i].CanonicalURL < duplicates[j].CanonicalURL
+    })
+
+    return duplicates, nil
+}
+
+// Merge folds the given bookmarks into one. The target keeps its ID and URL
+// and gains the union of all tags and the earliest creation time; the
+// others go to the trash. A zero targetID picks the oldest bookmark.
+// Repeated IDs count once.
+func (r *bookmarkRepository) Merge(ids []int64, targetID int64) (*models.Bookmark, error) {
+    var group []models.Bookmark
+    seen := make(map[int64]bool, len(ids))
+    for _, id := range ids {
+        if seen[id] {
+            continue
+        }
+        seen[id] = true
+        bookmark, err := r.GetByID(id)
+        if err != nil {
+            return nil, err
+        }
+        group = append(group, *bookmark)
+    }
+
+    sort.Slice(group, func(i, j int) bool {
+        return group[i].CreatedAt.Before(group[j].CreatedAt)
+    })
+
+    target := group[0]
+    if targetID != 0 {
+        found := false
+        for _, bookmark := range group {
+            if bookmark.ID == targetID {
+                target = bookmark
+                found = true
+                break
+            }
+        }
+        if !found {
+            return nil, fmt.Errorf("target bookmark %d is not among the merged IDs", targetID)
+        }
+    }
+
+    for _, bookmark := range group {
+        if bookmark.ID == target.ID {
+            continue
+        }
+        target.Tags = mergeTags(target.Tags, bookmark.Tags)
+        if target.Title == "" {
+            target.Title = bookmark.Title
+        }
+        if target.Description == "" {
+            target.Description = bookmark.Description
+        }
+        if target.Status == "" {
+            target.Status = bookmark.Status
+            target.Progress = bookmark.Progress
+            target.ReadAt = bookmark.ReadAt
+        }
+        if bookmark.CreatedAt.Before(target.CreatedAt) {
+            target.CreatedAt = bookmark.CreatedAt
+        }
+    }
+    now := time.Now()
+    target.UpdatedAt = now
+
+    var changes []itemChange
+    var trash []models.TrashItem
+    var merged []int64
+    for _, bookmark := range group {
+        change := itemChange{itemType: models.ItemBookmark, itemID: bookmark.ID, before: bookmark}
+        if bookmark.ID == target.ID {
+            change.after = target
+        } else {
+            trashed, err := trashBookmark(bookmark, now)
+            if err != nil {
+                return nil, err
+            }
+            trash = append(trash, trashed)
+            merged = append(merged, bookmark.ID)
+        }
+        changes = append(changes, change)
+    }
+
+    if err := r.storage.UpdateBookmark(target); err != nil {
+        return nil, err
+    }
+    if err := r.storage.PutTrash(trash...); err != nil {
+        return nil, err
+    }
+    if err := r.storage.DeleteBookmarks(merged...); err != nil {
+        return nil, err
+    }
+
+    if err := recordRevisions(r.storage, models.OperationMerge, changes, 0); err != nil {
+        return nil, err
+    }
+    return &target, nil
+}
+
+// newNormalizer builds a URL normalizer from the user's current URL rules
+func newNormalizer(storage *storage.AppendLogStorage) (*urlnorm.Normalizer, error) {
+    settings, err := storage.ReadSettings()
+    if err != nil {
+        return nil, err
+    }
+    return urlnorm.New(settings.URLRules), nil
+}
+
+// mergeTags appends the tags from extra that existing doesn't have yet,
+// comparing case-insensitively and keeping existing order
+func mergeTags(existing, extra []string) []string {
+    seen := make(map[string]bool, len(existing))
+    merged := make([]string, 0, len(existing)+len(extra))
+    for _, tag := range append(append([]string{}, existing...), extra...) {
+        key := strings.ToLower(strings.TrimSpace(tag))
+        if key == "" || seen[key] {
+            continue
+        }
+        seen[key] = true
+        merged = append(merged, tag)
+    }
+    return merged
+}
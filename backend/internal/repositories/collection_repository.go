@@ -0,0 +1,215 @@
+package repositories
+
+import (
+    "fmt"
+    "sort"
+    "time"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/storage"
+)
+
+type collectionRepository struct {
+    storage *storage.AppendLogStorage
+}
+
+func NewCollectionRepository(storage *storage.AppendLogStorage) CollectionRepository {
+    return &collectionRepository{storage: storage}
+}
+
+// GetAll returns collections ordered by parent, then position
+func (r *collectionRepository) GetAll() ([]models.Collection, error) {
+    collections, err := r.storage.ReadCollections()
+    if err != nil {
+        return nil, err
+    }
+
+    sort.SliceStable(collections, func(i, j int) bool {
+        if collections[i].ParentID != collections[j].ParentID {
+            return collections[i].ParentID < collections[j].ParentID
+        }
+        return collections[i].Position < collections[j].Position
+    })
+
+    return collections, nil
+}
+
+func (r *collectionRepository) GetByID(id int64) (*models.Collection, error) {
+    collections, err := r.storage.ReadCollections()
+    if err != nil {
+        return nil, err
+    }
+
+    for _, collection := range collections {
+        if collection.ID == id {
+            return &collection, nil
+        }
+    }
+
+    return nil, fmt.Errorf("collection with ID %d not found", id)
+}
+
+// Create adds a collection at the end of its parent's children
+func (r *collectionRepository) Create(collection *models.Collection) error {
+    collections, err := r.storage.ReadCollections()
+    if err != nil {
+        return err
+    }
+
+    if collection.ParentID != 0 && findCollection(collections, collection.ParentID) == nil {
+        return fmt.Errorf("parent collection with ID %d not found", collection.ParentID)
+    }
+
+    if collection.ID == 0 {
+        collection.ID = time.Now().UnixNano()
+    }
+    collection.Position = len(childrenOf(collections, collection.ParentID))
+    now := time.Now()
+    collection.CreatedAt = now
+    collection.UpdatedAt = now
+
+    return r.storage.PutCollections(*collection)
+}
+
+// Update changes a collection's name; use Move to change its place in the tree
+func (r *collectionRepository) Update(collection *models.Collection) error {
+    existing, err := r.GetByID(collection.ID)
+    if err != nil {
+        return err
+    }
+
+    existing.Name = collection.Name
+    existing.UpdatedAt = time.Now()
+    *collection = *existing
+
+    return r.storage.PutCollections(*existing)
+}
+
+// Delete removes a collection. Its children move up to its parent, after
+// its siblings, in the same write that closes the gap it leaves.
+func (r *collectionRepository) Delete(id int64) error {
+    collections, err := r.storage.ReadCollections()
+    if err != nil {
+        return err
+    }
+
+    existing := findCollection(collections, id)
+    if existing == nil {
+        return fmt.Errorf("collection with ID %d not found", id)
+    }
+
+    var siblings []models.Collection
+    for _, sibling := range childrenOf(collections, existing.ParentID) {
+        if sibling.ID != id {
+            siblings = append(siblings, sibling)
+        }
+    }
+    now := time.Now()
+    for _, child := range childrenOf(collections, id) {
+        child.ParentID = existing.ParentID
+        child.UpdatedAt = now
+        siblings = append(siblings, child)
+    }
+    if err := r.storage.PutCollections(renumber(siblings)...); err != nil {
+        return err
+    }
+
+    return r.storage.DeleteCollection(id)
+}
+
+// Move re-parents a collection and places it at position among its new
+// siblings; a negative or too-large position appends it at the end
+func (r *collectionRepository) Move(id, parentID int64, position int) (*models.Collection, error) {
+    collections, err := r.storage.ReadCollections()
+    if err != nil {
+        return nil, err
+    }
+
+    moving := findCollection(collections, id)
+    if moving == nil {
+        return nil, fmt.Errorf("collection with ID %d not found", id)
+    }
+    if parentID != 0 && findCollection(collections, parentID) == nil {
+        return nil, fmt.Errorf("parent collection with ID %d not found", parentID)
+    }
+
+    // Refuse to move a collection underneath itself
+    for ancestor := parentID; ancestor != 0; {
+        if ancestor == id {
+            return nil, fmt.Errorf("cannot move collection %d into its own subtree", id)
+        }
+        parent := findCollection(collections, ancestor)
+        if parent == nil {
+            break
+        }
+        ancestor = parent.ParentID
+    }
+
+    var changed []models.Collection
+
+    oldParentID := moving.ParentID
+    if oldParentID != parentID {
+        var oldSiblings []models.Collection
+        for _, sibling := range childrenOf(collections, oldParentID) {
+            if sibling.ID != id {
+                oldSiblings = append(oldSiblings, sibling)
+            }
+        }
+        changed = append(changed, renumber(oldSiblings)...)
+    }
+
+    var siblings []models.Collection
+    for _, sibling := range childrenOf(collections, parentID) {
+        if sibling.ID != id {
+            siblings = append(siblings, sibling)
+        }
+    }
+    if position < 0 || position > len(siblings) {
+        position = len(siblings)
+    }
+
+    moved := *moving
+    moved.ParentID = parentID
+    moved.UpdatedAt = time.Now()
+
+    siblings = append(siblings[:position], append([]models.Collection{moved}, siblings[position:]...)...)
+    changed = append(changed, renumber(siblings)...)
+
+    if err := r.storage.PutCollections(changed...); err != nil {
+        return nil, err
+    }
+
+    moved.Position = position
+    return &moved, nil
+}
+
+func findCollection(collections []models.Collection, id int64) *models.Collection {
+    for i := range collections {
+        if collections[i].ID == id {
+            return &collections[i]
+        }
+    }
+    return nil
+}
+
+// childrenOf returns the direct children of parentID in position order
+func childrenOf(collections []models.Collection, parentID int64) []models.Collection {
+    var children []models.Collection
+    for _, collection := range collections {
+        if collection.ParentID == parentID {
+            children = append(children, collection)
+        }
+    }
+    sort.SliceStable(children, func(i, j int) bool {
+        return children[i].Position < children[j].Position
+    })
+    return children
+}
+
+// renumber assigns consecutive positions in slice order
+func renumber(collections []models.Collection) []models.Collection {
+    for i := range collections {
+        collections[i].Position = i
+    }
+    return collections
+}
@@ -19,61 +19,26 @@ func (r *historyRepository) GetAll() ([]models.HistoryEntry, error) {
     return r.storage.ReadHistory()
 }
 
-func (r *historyRepository) GetToday() ([]models.HistoryEntry, error) {
+// GetRange returns entries last visited within [from, to). A zero bound is
+// treated as open-ended.
+func (r *historyRepository) GetRange(from, to time.Time) ([]models.HistoryEntry, error) {
     history, err := r.storage.ReadHistory()
     if err != nil {
         return nil, err
     }
 
-    today := time.Now().Truncate(24 * time.Hour)
-    tomorrow := today.Add(24 * time.Hour)
-
-    var todaysHistory []models.HistoryEntry
+    var rangeHistory []models.HistoryEntry
     for _, entry := range history {
-        if entry.LastVisitTime.After(today) && entry.LastVisitTime.Before(tomorrow) {
-            todaysHistory = append(todaysHistory, entry)
+        if !from.IsZero() && entry.LastVisitTime.Before(from) {
+            continue
         }
-    }
-
-    return todaysHistory, nil
-}
-
-func (r *historyRepository) GetWeek() ([]models.HistoryEntry, error) {
-    history, err := r.storage.ReadHistory()
-    if err != nil {
-        return nil, err
-    }
-
-    now := time.Now()
-    weekAgo := now.AddDate(0, 0, -7)
-
-    var weekHistory []models.HistoryEntry
-    for _, entry := range history {
-        if entry.LastVisitTime.After(weekAgo) {
-            weekHistory = append(weekHistory, entry)
+        if !to.IsZero() && !entry.LastVisitTime.Before(to) {
+            continue
         }
+        rangeHistory = append(rangeHistory, entry)
     }
 
-    return weekHistory, nil
-}
-
-func (r *historyRepository) GetMonth() ([]models.HistoryEntry, error) {
-    history, err := r.storage.ReadHistory()
-    if err != nil {
-        return nil, err
-    }
-
-    now := time.Now()
-    monthAgo := now.AddDate(0, 0, -30)
-
-    var monthHistory []models.HistoryEntry
-    for _, entry := range history {
-        if entry.LastVisitTime.After(monthAgo) {
-            monthHistory = append(monthHistory, entry)
-        }
-    }
-
-    return monthHistory, nil
+    return rangeHistory, nil
 }
 
 func (r *historyRepository) GetCount() (int, error) {
@@ -84,6 +49,8 @@ func (r *historyRepository) GetCount() (int, error) {
     return len(history), nil
 }
 
+// Sync stores entries for new URLs and entries newer than the stored one,
+// keeping the higher visit count. Only changed entries are written.
 func (r *historyRepository) Sync(entries []models.HistoryEntry) (int, error) {
     existingHistory, err := r.storage.ReadHistory()
     if err != nil {
@@ -95,41 +62,33 @@ func (r *historyRepository) Sync(entries []models.HistoryEntry) (int, error) {
         historyMap[entry.URL] = entry
     }
 
-    syncedCount := 0
+    var changed []models.HistoryEntry
     for _, newEntry := range entries {
-        if existing, exists := historyMap[newEntry.URL]; exists {
-            if newEntry.LastVisitTime.After(existing.LastVisitTime) {
-                historyMap[newEntry.URL] = newEntry
-                syncedCount++
-            }
-        } else {
-            historyMap[newEntry.URL] = newEntry
-            syncedCount++
+        existing, exists := historyMap[newEntry.URL]
+        if exists && !newEntry.LastVisitTime.After(existing.LastVisitTime) {
+            continue
         }
+        if exists && existing.VisitCount > newEntry.VisitCount {
+            newEntry.VisitCount = existing.VisitCount
+        }
+        historyMap[newEntry.URL] = newEntry
+        changed = append(changed, newEntry)
     }
 
-    var allHistory []models.HistoryEntry
-    for _, entry := range historyMap {
-        allHistory = append(allHistory, entry)
-    }
-
-    err = r.storage.WriteHistory(allHistory)
-    return syncedCount, err
+    err = r.storage.WriteHistory(changed)
+    return len(changed), err
 }
 
 func (r *historyRepository) EnrichWithLinkClicks(history []models.HistoryEntry) ([]models.HistoryEntry, error) {
-    linkClicks, err := r.storage.ReadLinkClicks()
+    urls := make([]string, len(history))
+    for i, entry := range history {
+        urls[i] = entry.URL
+    }
+    clickMap, err := r.storage.LatestLinkClicks(urls)
     if err != nil {
         return history, nil
     }
     
-    clickMap := make(map[string]models.LinkClick)
-    for _, click := range linkClicks {
-        if existing, exists := clickMap[click.DestinationURL]; !exists || click.Timestamp.After(existing.Timestamp) {
-            clickMap[click.DestinationURL] = click
-        }
-    }
-    
     enrichedHistory := make([]models.HistoryEntry, len(history))
     for i, entry := range history {
         enrichedHistory[i] = entry
@@ -1,7 +1,8 @@
 package repositories
 
 import (
-    "fmt"
+    "net/url"
+    "strings"
     "time"
 
     "hyprlnk/internal/models"
@@ -16,31 +17,316 @@ func NewImportRepository(storage *storage.AppendLogStorage) ImportRepository {
     return &importRepository{storage: storage}
 }
 
-func (r *importRepository) ImportBrowserData(importedBookmarks []models.ImportedBookmark, history []models.HistoryEntry, useAI bool) (int, error) {
+// Import matches imported bookmarks to existing ones by canonical URL and
+// resolves collisions according to mode. With dryRun nothing is written,
+// not even the collections for new folders, and the diff is a preview.
+// prepare, when set, can amend each new bookmark before it is stored, so
+// additions such as auto-tags go in with the same write and revision.
+func (r *importRepository) Import(importedBookmarks []models.ImportedBookmark, mode string, dryRun bool, prepare func(*models.Bookmark)) (*models.ImportDiff, error) {
     existingBookmarks, err := r.storage.ReadBookmarks()
     if err != nil {
-        return 0, err
+        return nil, err
     }
 
-    var newBookmarks []models.Bookmark
+    normalizer, err := newNormalizer(r.storage)
+    if err != nil {
+        return nil, err
+    }
+
+    folders, err := newFolderResolver(r.storage)
+    if err != nil {
+        return nil, err
+    }
+
+    existingByURL := make(map[string]*models.Bookmark, len(existingBookmarks))
+    for i := range existingBookmarks {
+        canonical := normalizer.Normalize(existingBookmarks[i].URL)
+        if _, ok := existingByURL[canonical]; !ok {
+            existingByURL[canonical] = &existingBookmarks[i]
+        }
+    }
+
+    diff := &models.ImportDiff{
+        Mode:    mode,
+        DryRun:  dryRun,
+        Creates: []models.ImportChange{},
+        Updates: []models.ImportChange{},
+        Skips:   []models.ImportChange{},
+    }
+    skip := func(imported models.ImportedBookmark, reason string, before *models.Bookmark) {
+        diff.Skips = append(diff.Skips, models.ImportChange{URL: imported.URL, Title: imported.Title, Reason: reason, Before: before})
+    }
+
+    now := time.Now()
+    lastID := int64(0)
+    seen := make(map[string]bool, len(importedBookmarks))
+    var writes []models.Bookmark
+    var changes []itemChange
+
     for _, imported := range importedBookmarks {
-        bookmark := models.Bookmark{
-            ID:          time.Now().UnixNano(),
-            URL:         imported.URL,
-            Title:       imported.Title,
-            Description: fmt.Sprintf("Imported from %s", imported.Folder),
-            Tags:        []string{imported.Folder},
-            CreatedAt:   imported.AddedDate,
-            UpdatedAt:   time.Now(),
+        if parsed, err := url.Parse(strings.TrimSpace(imported.URL)); err != nil || parsed.Scheme == "" {
+            skip(imported, "invalid URL", nil)
+            continue
+        }
+        canonical := normalizer.Normalize(imported.URL)
+        if seen[canonical] {
+            skip(imported, "repeated in this import", nil)
+            continue
+        }
+        seen[canonical] = true
+
+        existing, exists := existingByURL[canonical]
+        if !exists {
+            // IDs must stay unique even when the clock doesn't advance between items
+            id := now.UnixNano()
+            if id <= lastID {
+                id = lastID + 1
+            }
+            lastID = id
+
+            created := importedToBookmark(imported, folders.resolve(importedFolderPath(imported)), now)
+            created.ID = id
+            if prepare != nil {
+                prepare(&created)
+            }
+            writes = append(writes, created)
+            changes = append(changes, itemChange{itemType: models.ItemBookmark, itemID: id, after: created})
+            diff.Creates = append(diff.Creates, models.ImportChange{URL: imported.URL, Title: imported.Title, After: &created})
+            continue
         }
-        newBookmarks = append(newBookmarks, bookmark)
+
+        before := *existing
+        after, changed := resolveCollision(before, imported, mode, folders)
+        if !changed {
+            reason := "already bookmarked"
+            if mode != models.ImportSkipExisting {
+                reason = "already up to date"
+            }
+            skip(imported, reason, &before)
+            continue
+        }
+        after.UpdatedAt = now
+        writes = append(writes, after)
+        changes = append(changes, itemChange{itemType: models.ItemBookmark, itemID: after.ID, before: before, after: after})
+        diff.Updates = append(diff.Updates, models.ImportChange{URL: imported.URL, Title: imported.Title, Before: &before, After: &after})
     }
 
-    allBookmarks := append(existingBookmarks, newBookmarks...)
-    
-    if err := r.storage.WriteBookmarks(allBookmarks); err != nil {
-        return 0, err
+    if dryRun {
+        return diff, nil
+    }
+
+    // Collections first, so no bookmark ever points at a missing collection
+    if err := folders.save(); err != nil {
+        return nil, err
+    }
+    // Appended rather than rewriting every bookmark, so edits made while an
+    // import runs aren't lost
+    if err := r.storage.UpdateBookmarks(writes); err != nil {
+        return nil, err
+    }
+    if err := recordRevisions(r.storage, models.OperationImport, changes, 0); err != nil {
+        return nil, err
+    }
+
+    return diff, nil
+}
+
+func importedToBookmark(imported models.ImportedBookmark, collectionID int64, now time.Time) models.Bookmark {
+    addedDate := imported.AddedDate
+    if addedDate.IsZero() {
+        addedDate = now
+    }
+
+    tags := imported.Tags
+    if tags == nil {
+        tags = []string{}
+    }
+
+    return models.Bookmark{
+        URL:          strings.TrimSpace(imported.URL),
+        Title:        imported.Title,
+        Description:  imported.Description,
+        Tags:         tags,
+        CollectionID: collectionID,
+        CreatedAt:    addedDate,
+        UpdatedAt:    now,
+    }
+}
+
+// resolveCollision applies an imported bookmark to the existing bookmark
+// with the same canonical URL, reporting whether anything changed
+func resolveCollision(existing models.Bookmark, imported models.ImportedBookmark, mode string, folders *folderResolver) (models.Bookmark, bool) {
+    updated := existing
+    updated.Tags = append([]string{}, existing.Tags...)
+
+    switch mode {
+    case models.ImportOverwrite:
+        if imported.Title != "" {
+            updated.Title = imported.Title
+        }
+        // Most formats have no descriptions; a blank one isn't a request
+        // to clear the existing notes
+        if imported.Description != "" {
+            updated.Description = imported.Description
+        }
+        updated.Tags = mergeTags(nil, imported.Tags)
+        if path := importedFolderPath(imported); len(path) > 0 {
+            updated.CollectionID = folders.resolve(path)
+        }
+
+    case models.ImportMergeTags:
+        updated.Tags = mergeTags(existing.Tags, imported.Tags)
+        if updated.Title == "" {
+            updated.Title = imported.Title
+        }
+        if updated.Description == "" {
+            updated.Description = imported.Description
+        }
+        if updated.CollectionID == 0 {
+            updated.CollectionID = folders.resolve(importedFolderPath(imported))
+        }
+
+    default:
+        return existing, false
     }
 
-    return len(newBookmarks), nil
-}
\ No newline at end of file
+    changed := updated.Title != existing.Title ||
+        updated.Description != existing.Description ||
+        updated.CollectionID != existing.CollectionID ||
+        strings.Join(updated.Tags, "\x00") != strings.Join(existing.Tags, "\x00")
+    return updated, changed
+}
+
+// ImportSessions stores imported sessions, skipping any whose tabs match an
+// existing session's tabs URL for URL, and returns the ones it stored
+func (r *importRepository) ImportSessions(sessions []models.Session) ([]models.Session, error) {
+    existingSessions, err := r.storage.ReadSessions()
+    if err != nil {
+        return nil, err
+    }
+
+    normalizer, err := newNormalizer(r.storage)
+    if err != nil {
+        return nil, err
+    }
+
+    tabsKey := func(session models.Session) string {
+        urls := make([]string, len(session.Tabs))
+        for i, tab := range session.Tabs {
+            urls[i] = normalizer.Normalize(tab.URL)
+        }
+        return strings.Join(urls, "\n")
+    }
+
+    seen := make(map[string]bool, len(existingSessions))
+    for _, existing := range existingSessions {
+        seen[tabsKey(existing)] = true
+    }
+
+    var imported []models.Session
+    lastID := int64(0)
+    var changes []itemChange
+    for _, session := range sessions {
+        key := tabsKey(session)
+        if len(session.Tabs) == 0 || seen[key] {
+            continue
+        }
+        seen[key] = true
+
+        if session.ID == 0 {
+            id := time.Now().UnixNano()
+            if id <= lastID {
+                id = lastID + 1
+            }
+            lastID = id
+            session.ID = id
+        }
+        if err := r.storage.AddSession(session); err != nil {
+            return imported, err
+        }
+        changes = append(changes, itemChange{itemType: models.ItemSession, itemID: session.ID, after: session})
+        imported = append(imported, session)
+    }
+
+    return imported, recordRevisions(r.storage, models.OperationImport, changes, 0)
+}
+
+// importedFolderPath returns the browser folder hierarchy of an imported
+// bookmark, preferring the explicit path over splitting Folder on "/"
+func importedFolderPath(imported models.ImportedBookmark) []string {
+    source := imported.FolderPath
+    if len(source) == 0 {
+        source = strings.Split(imported.Folder, "/")
+    }
+
+    var path []string
+    for _, name := range source {
+        if name = strings.TrimSpace(name); name != "" {
+            path = append(path, name)
+        }
+    }
+    return path
+}
+
+// folderResolver maps browser folder paths onto collections, reusing
+// existing collections by name and creating the missing ones
+type folderResolver struct {
+    storage     *storage.AppendLogStorage
+    collections []models.Collection
+    created     []models.Collection
+    lastID      int64
+}
+
+func newFolderResolver(storage *storage.AppendLogStorage) (*folderResolver, error) {
+    collections, err := storage.ReadCollections()
+    if err != nil {
+        return nil, err
+    }
+    return &folderResolver{storage: storage, collections: collections}, nil
+}
+
+// resolve returns the collection ID for path, or 0 for an empty path
+func (f *folderResolver) resolve(path []string) int64 {
+    var parentID int64
+    for _, name := range path {
+        var match *models.Collection
+        for i := range f.collections {
+            if f.collections[i].ParentID == parentID && f.collections[i].Name == name {
+                match = &f.collections[i]
+                break
+            }
+        }
+
+        if match == nil {
+            // As for bookmarks, IDs must stay unique when the clock doesn't advance
+            now := time.Now()
+            id := now.UnixNano()
+            if id <= f.lastID {
+                id = f.lastID + 1
+            }
+            f.lastID = id
+            collection := models.Collection{
+                ID:        id,
+                Name:      name,
+                ParentID:  parentID,
+                Position:  len(childrenOf(f.collections, parentID)),
+                CreatedAt: now,
+                UpdatedAt: now,
+            }
+            f.collections = append(f.collections, collection)
+            f.created = append(f.created, collection)
+            match = &collection
+        }
+
+        parentID = match.ID
+    }
+    return parentID
+}
+
+// save persists every collection created by resolve
+func (f *folderResolver) save() error {
+    if len(f.created) == 0 {
+        return nil
+    }
+    return f.storage.PutCollections(f.created...)
+}
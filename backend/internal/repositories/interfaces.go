@@ -1,16 +1,40 @@
 package repositories
 
 import (
+    "time"
+
     "hyprlnk/internal/models"
 )
 
 type BookmarkRepository interface {
     GetAll() ([]models.Bookmark, error)
     GetByID(id int64) (*models.Bookmark, error)
-    Create(bookmark *models.Bookmark) error
+    GetByURL(rawURL string) (*models.Bookmark, error)
+    FindByURL(rawURL string) ([]models.Bookmark, error)
+    Create(bookmark *models.Bookmark) (created bool, err error)
     Update(bookmark *models.Bookmark) error
+    UpdateMany(bookmarks []models.Bookmark) error
+    UpdateManyUntracked(bookmarks []models.Bookmark) error
     Delete(id int64) error
+    DeleteMany(ids []int64) error
     Search(query string) ([]models.Bookmark, error)
+    FindDuplicates() ([]models.DuplicateGroup, error)
+    Merge(ids []int64, targetID int64) (*models.Bookmark, error)
+}
+
+type CollectionRepository interface {
+    GetAll() ([]models.Collection, error)
+    GetByID(id int64) (*models.Collection, error)
+    Create(collection *models.Collection) error
+    Update(collection *models.Collection) error
+    Delete(id int64) error
+    Move(id, parentID int64, position int) (*models.Collection, error)
+}
+
+type TagRepository interface {
+    GetAll() ([]models.TagInfo, error)
+    Save(tags ...models.TagInfo) error
+    Delete(names ...string) error
 }
 
 type SessionRepository interface {
@@ -18,14 +42,15 @@ type SessionRepository interface {
     GetByID(id int64) (*models.Session, error)
     Create(session *models.Session) error
     Update(session *models.Session) error
+    // AutoSave stores the session without a revision; autosaves are kept
+    // as session versions instead
+    AutoSave(session *models.Session) error
     Delete(id int64) error
 }
 
 type HistoryRepository interface {
     GetAll() ([]models.HistoryEntry, error)
-    GetToday() ([]models.HistoryEntry, error)
-    GetWeek() ([]models.HistoryEntry, error)
-    GetMonth() ([]models.HistoryEntry, error)
+    GetRange(from, to time.Time) ([]models.HistoryEntry, error)
     GetCount() (int, error)
     Sync(entries []models.HistoryEntry) (int, error)
     EnrichWithLinkClicks(entries []models.HistoryEntry) ([]models.HistoryEntry, error)
@@ -38,5 +63,99 @@ type LinkClickRepository interface {
 }
 
 type ImportRepository interface {
-    ImportBrowserData(bookmarks []models.ImportedBookmark, history []models.HistoryEntry, useAI bool) (int, error)
-}
\ No newline at end of file
+    Import(bookmarks []models.ImportedBookmark, mode string, dryRun bool, prepare func(*models.Bookmark)) (*models.ImportDiff, error)
+    ImportSessions(sessions []models.Session) ([]models.Session, error)
+}
+
+// JobRepository persists background jobs; it satisfies jobs.Store
+type JobRepository interface {
+    GetAll() ([]models.Job, error)
+    Save(job *models.Job) error
+    Delete(ids ...int64) error
+    SavePayload(id int64, payload []byte) error
+    LoadPayload(id int64) ([]byte, error)
+    DeletePayload(id int64) error
+}
+
+type SettingsRepository interface {
+    Get() (*models.Settings, error)
+    Update(settings *models.Settings) error
+}
+
+type RuleRepository interface {
+    GetAll() ([]models.AutoTagRule, error)
+    GetByID(id int64) (*models.AutoTagRule, error)
+    Create(rule *models.AutoTagRule) error
+    Update(rule *models.AutoTagRule) error
+    Delete(id int64) error
+}
+
+// MetadataRepository stores what fetching bookmarked pages found, and the
+// favicons of their hosts
+type MetadataRepository interface {
+    GetAll() ([]models.PageMetadata, error)
+    Get(bookmarkID int64) (*models.PageMetadata, error)
+    Save(metadata ...models.PageMetadata) error
+    Delete(bookmarkIDs ...int64) error
+    SaveFavicon(host, contentType string, data []byte) error
+    GetFavicon(host string) ([]byte, string, error)
+}
+
+// ArchiveRepository stores offline copies of bookmarked pages
+type ArchiveRepository interface {
+    GetAll() ([]models.PageArchive, error)
+    Get(bookmarkID int64) (*models.PageArchive, error)
+    // Save stores the record, and the snapshot and text when snapshot is non-nil
+    Save(archive models.PageArchive, snapshot []byte, text string) error
+    Delete(bookmarkIDs ...int64) error
+    GetSnapshot(bookmarkID int64) ([]byte, error)
+    GetText(bookmarkID int64) (string, error)
+}
+
+// LinkHealthRepository stores what checking bookmarked URLs found
+type LinkHealthRepository interface {
+    GetAll() ([]models.LinkHealth, error)
+    Get(bookmarkID int64) (*models.LinkHealth, error)
+    Save(health ...models.LinkHealth) error
+    Delete(bookmarkIDs ...int64) error
+}
+
+// AnnotationRepository stores notes and highlights on pages
+type AnnotationRepository interface {
+    GetAll() ([]models.Annotation, error)
+    GetByID(id int64) (*models.Annotation, error)
+    Create(annotation *models.Annotation) error
+    Update(annotation *models.Annotation) error
+    UpdateMany(annotations []models.Annotation) error
+    Delete(id int64) error
+    DeleteMany(ids []int64) error
+}
+
+// RevisionRepository keeps the recent revisions of bookmarks and sessions,
+// which their repositories record as they write
+type RevisionRepository interface {
+    GetAll() ([]models.RevisionLog, error)
+    Get(itemType string, itemID int64) (*models.RevisionLog, error)
+    Revert(operation string, states []models.ItemState, undoing int64) error
+}
+
+// TrashRepository holds deleted bookmarks and sessions until they are
+// restored or purged. Deleting through their repositories fills it;
+// restoring goes through RevisionRepository.Revert.
+type TrashRepository interface {
+    GetAll() ([]models.TrashItem, error)
+    Get(itemType string, id int64) (*models.TrashItem, error)
+    Purge(items ...models.ItemRef) error
+}
+
+// SessionVersionRepository keeps the saved versions of each session
+type SessionVersionRepository interface {
+    GetAll(sessionID int64) ([]models.SessionVersion, error)
+    Get(sessionID int64, version int) (*models.SessionVersion, error)
+    // Create saves session as its next version. When its name and tabs are
+    // those of the latest version, it returns that one and false instead.
+    Create(session models.Session, source string) (*models.SessionVersion, bool, error)
+    // Delete removes versions of a session; the versions after them are
+    // diffed again against the ones that now precede them
+    Delete(sessionID int64, versions ...int) error
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/storage"
+)
+
+type jobRepository struct {
+    storage *storage.AppendLogStorage
+}
+
+func NewJobRepository(storage *storage.AppendLogStorage) JobRepository {
+    return &jobRepository{storage: storage}
+}
+
+func (r *jobRepository) GetAll() ([]models.Job, error) {
+    return r.storage.ReadJobs()
+}
+
+func (r *jobRepository) Save(job *models.Job) error {
+    return r.storage.PutJobs(*job)
+}
+
+func (r *jobRepository) Delete(ids ...int64) error {
+    return r.storage.DeleteJobs(ids...)
+}
+
+func (r *jobRepository) SavePayload(id int64, payload []byte) error {
+    return r.storage.WriteJobPayload(id, payload)
+}
+
+func (r *jobRepository) LoadPayload(id int64) ([]byte, error) {
+    return r.storage.ReadJobPayload(id)
+}
+
+func (r *jobRepository) DeletePayload(id int64) error {
+    return r.storage.DeleteJobPayload(id)
+}
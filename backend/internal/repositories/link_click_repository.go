@@ -1,6 +1,7 @@
 package repositories
 
 import (
+    "fmt"
     "time"
 
     "hyprlnk/internal/models"
@@ -29,14 +30,38 @@ func (r *linkClickRepository) Create(clicks []models.LinkClick) error {
     return r.storage.WriteLinkClicks(clicks)
 }
 
+// Sync stores the clicks that aren't stored yet, so repeated syncs and
+// imports of overlapping history don't double count
 func (r *linkClickRepository) Sync(clicks []models.LinkClick) (int, error) {
-    // Use batch write for sync operation
-    for i := range clicks {
-        if clicks[i].CreatedAt.IsZero() {
-            clicks[i].CreatedAt = time.Now()
+    existingClicks, err := r.storage.ReadLinkClicks()
+    if err != nil {
+        return 0, err
+    }
+
+    seen := make(map[string]bool, len(existingClicks))
+    for _, click := range existingClicks {
+        seen[linkClickKey(click)] = true
+    }
+
+    var newClicks []models.LinkClick
+    for _, click := range clicks {
+        key := linkClickKey(click)
+        if seen[key] {
+            continue
         }
+        seen[key] = true
+
+        if click.CreatedAt.IsZero() {
+            click.CreatedAt = time.Now()
+        }
+        newClicks = append(newClicks, click)
     }
-    
-    err := r.storage.WriteLinkClicks(clicks)
-    return len(clicks), err
-}
\ No newline at end of file
+
+    err = r.storage.WriteLinkClicks(newClicks)
+    return len(newClicks), err
+}
+
+// linkClickKey identifies a click by what was clicked, from where and when
+func linkClickKey(click models.LinkClick) string {
+    return fmt.Sprintf("%s|%s|%d", click.DestinationURL, click.SourceURL, click.Timestamp.UnixMilli())
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+    "fmt"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/storage"
+)
+
+type linkHealthRepository struct {
+    storage *storage.AppendLogStorage
+}
+
+func NewLinkHealthRepository(storage *storage.AppendLogStorage) LinkHealthRepository {
+    return &linkHealthRepository{storage: storage}
+}
+
+func (r *linkHealthRepository) GetAll() ([]models.LinkHealth, error) {
+    return r.storage.ReadLinkHealth()
+}
+
+func (r *linkHealthRepository) Get(bookmarkID int64) (*models.LinkHealth, error) {
+    all, err := r.storage.ReadLinkHealth()
+    if err != nil {
+        return nil, err
+    }
+
+    for _, health := range all {
+        if health.BookmarkID == bookmarkID {
+            return &health, nil
+        }
+    }
+
+    return nil, fmt.Errorf("bookmark %d has not been checked yet", bookmarkID)
+}
+
+func (r *linkHealthRepository) Save(health ...models.LinkHealth) error {
+    return r.storage.PutLinkHealth(health...)
+}
+
+func (r *linkHealthRepository) Delete(bookmarkIDs ...int64) error {
+    return r.storage.DeleteLinkHealth(bookmarkIDs...)
+}
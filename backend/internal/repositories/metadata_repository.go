@@ -0,0 +1,51 @@
+package repositories
+
+import (
+    "fmt"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/storage"
+)
+
+type metadataRepository struct {
+    storage *storage.AppendLogStorage
+}
+
+func NewMetadataRepository(storage *storage.AppendLogStorage) MetadataRepository {
+    return &metadataRepository{storage: storage}
+}
+
+func (r *metadataRepository) GetAll() ([]models.PageMetadata, error) {
+    return r.storage.ReadMetadata()
+}
+
+func (r *metadataRepository) Get(bookmarkID int64) (*models.PageMetadata, error) {
+    all, err := r.storage.ReadMetadata()
+    if err != nil {
+        return nil, err
+    }
+
+    for _, metadata := range all {
+        if metadata.BookmarkID == bookmarkID {
+            return &metadata, nil
+        }
+    }
+
+    return nil, fmt.Errorf("no metadata fetched yet for bookmark %d", bookmarkID)
+}
+
+func (r *metadataRepository) Save(metadata ...models.PageMetadata) error {
+    return r.storage.PutMetadata(metadata...)
+}
+
+func (r *metadataRepository) Delete(bookmarkIDs ...int64) error {
+    return r.storage.DeleteMetadata(bookmarkIDs...)
+}
+
+func (r *metadataRepository) SaveFavicon(host, contentType string, data []byte) error {
+    return r.storage.WriteFavicon(host, contentType, data)
+}
+
+func (r *metadataRepository) GetFavicon(host string) ([]byte, string, error) {
+    return r.storage.ReadFavicon(host)
+}
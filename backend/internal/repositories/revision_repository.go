@@ -0,0 +1,274 @@
+package repositories
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "sync"
+    "time"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/storage"
+)
+
+// revisionsPerItem bounds each bookmark's and session's revision log
+const revisionsPerItem = 20
+
+// revisionMutex serializes updates to revision logs, which are read,
+// extended and written back
+var revisionMutex sync.Mutex
+
+// itemChange is one item's part in an operation; before or after is nil
+// when the item didn't exist
+type itemChange struct {
+    itemType string
+    itemID   int64
+    before   any
+    after    any
+}
+
+// recordRevisions adds the changes to their items' revision logs as one
+// operation, reading only those items' logs. undoing, when not 0, is an
+// operation the changes revert; its revisions are marked undone, so the
+// changes must cover all its items, unchanged ones included.
+func recordRevisions(storage *storage.AppendLogStorage, operation string, changes []itemChange, undoing int64) error {
+    if len(changes) == 0 {
+        return nil
+    }
+
+    revisionMutex.Lock()
+    defer revisionMutex.Unlock()
+
+    refs := make([]models.ItemRef, len(changes))
+    for i, change := range changes {
+        refs[i] = models.ItemRef{Type: change.itemType, ID: change.itemID}
+    }
+    stored, err := storage.GetRevisions(refs...)
+    if err != nil {
+        return err
+    }
+    logs := make(map[models.ItemRef]*models.RevisionLog, len(stored))
+    for i := range stored {
+        logs[models.ItemRef{Type: stored[i].ItemType, ID: stored[i].ItemID}] = &stored[i]
+    }
+
+    now := time.Now()
+    operationID := now.UnixNano()
+    touched := make(map[models.ItemRef]bool)
+    var order []models.ItemRef
+    touch := func(ref models.ItemRef) {
+        if !touched[ref] {
+            touched[ref] = true
+            order = append(order, ref)
+        }
+    }
+
+    // Marked even when reverting it changed nothing, so it isn't undone again
+    if undoing != 0 {
+        for ref, log := range logs {
+            for i := range log.Revisions {
+                if log.Revisions[i].OperationID == undoing {
+                    log.Revisions[i].UndoneAt = now
+                    touch(ref)
+                }
+            }
+        }
+    }
+
+    type revised struct {
+        ref           models.ItemRef
+        before, after json.RawMessage
+    }
+    var revisions []revised
+    for _, change := range changes {
+        before, err := snapshot(change.before)
+        if err != nil {
+            return err
+        }
+        after, err := snapshot(change.after)
+        if err != nil {
+            return err
+        }
+        if !bytes.Equal(before, after) {
+            revisions = append(revisions, revised{models.ItemRef{Type: change.itemType, ID: change.itemID}, before, after})
+        }
+    }
+
+    for _, revision := range revisions {
+        ref := revision.ref
+        log := logs[ref]
+        if log == nil {
+            log = &models.RevisionLog{ItemType: ref.Type, ItemID: ref.ID}
+            logs[ref] = log
+        }
+        rev := 1
+        if n := len(log.Revisions); n > 0 {
+            rev = log.Revisions[n-1].Rev + 1
+        }
+        // Every revision carries the operation's size, so undo can tell
+        // when trimming has dropped some of them
+        log.Revisions = append(log.Revisions, models.Revision{
+            Rev:            rev,
+            OperationID:    operationID,
+            Operation:      operation,
+            OperationItems: len(revisions),
+            Before:         revision.before,
+            After:          revision.after,
+            CreatedAt:      now,
+        })
+        if len(log.Revisions) > revisionsPerItem {
+            log.Revisions = append([]models.Revision(nil), log.Revisions[len(log.Revisions)-revisionsPerItem:]...)
+        }
+        touch(ref)
+    }
+
+    updated := make([]models.RevisionLog, 0, len(order))
+    for _, ref := range order {
+        updated = append(updated, *logs[ref])
+    }
+    if len(updated) == 0 {
+        return nil
+    }
+    return storage.PutRevisions(updated...)
+}
+
+// snapshot encodes an item for a revision; nil stays nil
+func snapshot(item any) (json.RawMessage, error) {
+    if item == nil {
+        return nil, nil
+    }
+    return json.Marshal(item)
+}
+
+type revisionRepository struct {
+    storage *storage.AppendLogStorage
+}
+
+func NewRevisionRepository(storage *storage.AppendLogStorage) RevisionRepository {
+    return &revisionRepository{storage: storage}
+}
+
+func (r *revisionRepository) GetAll() ([]models.RevisionLog, error) {
+    return r.storage.ReadRevisions()
+}
+
+func (r *revisionRepository) Get(itemType string, itemID int64) (*models.RevisionLog, error) {
+    logs, err := r.storage.GetRevisions(models.ItemRef{Type: itemType, ID: itemID})
+    if err != nil {
+        return nil, err
+    }
+    if len(logs) == 1 {
+        return &logs[0], nil
+    }
+
+    return nil, fmt.Errorf("no revisions of %s %d", itemType, itemID)
+}
+
+// Revert writes the items back as given, timestamps included, taking them
+// out of the trash, and moves those with an empty state to the trash. It
+// records that as one operation; undoing, when not 0, is the operation
+// being undone, and states must cover all its items.
+func (r *revisionRepository) Revert(operation string, states []models.ItemState, undoing int64) error {
+    now := time.Now()
+    var changes []itemChange
+    var writeBookmarks []models.Bookmark
+    var deleteBookmarks []int64
+    var writeSessions []models.Session
+    var deleteSessions []int64
+    var untrash []models.ItemRef
+    var trash []models.TrashItem
+
+    for _, state := range states {
+        change := itemChange{itemType: state.ItemType, itemID: state.ItemID}
+
+        switch state.ItemType {
+        case models.ItemBookmark:
+            current, ok, err := r.storage.GetBookmark(state.ItemID)
+            if err != nil {
+                return err
+            }
+            if ok {
+                change.before = current
+            }
+            if len(state.State) == 0 {
+                // Already gone, but its revisions may still need marking undone
+                if change.before == nil {
+                    break
+                }
+                trashed, err := trashBookmark(change.before.(models.Bookmark), now)
+                if err != nil {
+                    return err
+                }
+                trash = append(trash, trashed)
+                deleteBookmarks = append(deleteBookmarks, state.ItemID)
+                break
+            }
+            var bookmark models.Bookmark
+            if err := json.Unmarshal(state.State, &bookmark); err != nil {
+                return err
+            }
+            untrash = append(untrash, models.ItemRef{Type: state.ItemType, ID: state.ItemID})
+            change.after = bookmark
+            writeBookmarks = append(writeBookmarks, bookmark)
+
+        case models.ItemSession:
+            current, ok, err := r.storage.GetSession(state.ItemID)
+            if err != nil {
+                return err
+            }
+            if ok {
+                change.before = current
+            }
+            if len(state.State) == 0 {
+                if change.before == nil {
+                    break
+                }
+                trashed, err := trashSession(change.before.(models.Session), now)
+                if err != nil {
+                    return err
+                }
+                trash = append(trash, trashed)
+                deleteSessions = append(deleteSessions, state.ItemID)
+                break
+            }
+            var session models.Session
+            if err := json.Unmarshal(state.State, &session); err != nil {
+                return err
+            }
+            untrash = append(untrash, models.ItemRef{Type: state.ItemType, ID: state.ItemID})
+            change.after = session
+            writeSessions = append(writeSessions, session)
+
+        default:
+            return fmt.Errorf("unknown item type %q", state.ItemType)
+        }
+        changes = append(changes, change)
+    }
+
+    if err := r.storage.PutTrash(trash...); err != nil {
+        return err
+    }
+    if err := r.storage.RestoreBookmarks(writeBookmarks); err != nil {
+        return err
+    }
+    for _, id := range deleteBookmarks {
+        if err := r.storage.DeleteBookmark(id); err != nil {
+            return err
+        }
+    }
+    for _, session := range writeSessions {
+        if err := r.storage.RestoreSession(session); err != nil {
+            return err
+        }
+    }
+    for _, id := range deleteSessions {
+        if err := r.storage.DeleteSession(id); err != nil {
+            return err
+        }
+    }
+    if err := r.storage.DeleteTrash(untrash...); err != nil {
+        return err
+    }
+
+    return recordRevisions(r.storage, operation, changes, undoing)
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+    "fmt"
+    "sort"
+    "time"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/storage"
+)
+
+type ruleRepository struct {
+    storage *storage.AppendLogStorage
+}
+
+func NewRuleRepository(storage *storage.AppendLogStorage) RuleRepository {
+    return &ruleRepository{storage: storage}
+}
+
+// GetAll returns rules in the order they were created, which is the order
+// they are applied in
+func (r *ruleRepository) GetAll() ([]models.AutoTagRule, error) {
+    rules, err := r.storage.ReadRules()
+    if err != nil {
+        return nil, err
+    }
+
+    sort.Slice(rules, func(i, j int) bool {
+        return rules[i].ID < rules[j].ID
+    })
+
+    return rules, nil
+}
+
+func (r *ruleRepository) GetByID(id int64) (*models.AutoTagRule, error) {
+    rules, err := r.storage.ReadRules()
+    if err != nil {
+        return nil, err
+    }
+
+    for _, rule := range rules {
+        if rule.ID == id {
+            return &rule, nil
+        }
+    }
+
+    return nil, fmt.Errorf("rule with ID %d not found", id)
+}
+
+func (r *ruleRepository) Create(rule *models.AutoTagRule) error {
+    if rule.ID == 0 {
+        rule.ID = time.Now().UnixNano()
+    }
+    now := time.Now()
+    rule.CreatedAt = now
+    rule.UpdatedAt = now
+
+    return r.storage.PutRules(*rule)
+}
+
+func (r *ruleRepository) Update(rule *models.AutoTagRule) error {
+    existing, err := r.GetByID(rule.ID)
+    if err != nil {
+        return err
+    }
+
+    rule.CreatedAt = existing.CreatedAt
+    rule.UpdatedAt = time.Now()
+
+    return r.storage.PutRules(*rule)
+}
+
+func (r *ruleRepository) Delete(id int64) error {
+    if _, err := r.GetByID(id); err != nil {
+        return err
+    }
+    return r.storage.DeleteRule(id)
+}
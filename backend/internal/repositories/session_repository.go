@@ -2,6 +2,7 @@ package repositories
 
 import (
     "fmt"
+    "time"
 
     "hyprlnk/internal/models"
     "hyprlnk/internal/storage"
@@ -20,23 +21,31 @@ func (r *sessionRepository) GetAll() ([]models.Session, error) {
 }
 
 func (r *sessionRepository) GetByID(id int64) (*models.Session, error) {
-    sessions, err := r.storage.ReadSessions()
+    session, ok, err := r.storage.GetSession(id)
     if err != nil {
         return nil, err
     }
-
-    for _, session := range sessions {
-        if session.ID == id {
-            return &session, nil
-        }
+    if !ok {
+        return nil, fmt.Errorf("session with ID %d not found", id)
     }
-
-    return nil, fmt.Errorf("session with ID %d not found", id)
+    return &session, nil
 }
 
 func (r *sessionRepository) Create(session *models.Session) error {
     session.IsActive = true
-    return r.storage.AddSession(*session)
+    if session.ID == 0 {
+        session.ID = time.Now().UnixNano()
+    }
+    now := time.Now()
+    session.CreatedAt = now
+    session.UpdatedAt = now
+
+    if err := r.storage.AddSession(*session); err != nil {
+        return err
+    }
+    return recordRevisions(r.storage, models.OperationCreate, []itemChange{
+        {itemType: models.ItemSession, itemID: session.ID, after: *session},
+    }, 0)
 }
 
 func (r *sessionRepository) Update(session *models.Session) error {
@@ -49,15 +58,43 @@ func (r *sessionRepository) Update(session *models.Session) error {
     // Preserve creation time
     session.CreatedAt = existing.CreatedAt
     
+    if err := r.storage.UpdateSession(*session); err != nil {
+        return err
+    }
+    return recordRevisions(r.storage, models.OperationUpdate, []itemChange{
+        {itemType: models.ItemSession, itemID: session.ID, before: *existing, after: *session},
+    }, 0)
+}
+
+func (r *sessionRepository) AutoSave(session *models.Session) error {
+    existing, err := r.GetByID(session.ID)
+    if err != nil {
+        return err
+    }
+
+    session.CreatedAt = existing.CreatedAt
+    session.UpdatedAt = time.Now()
     return r.storage.UpdateSession(*session)
 }
 
 func (r *sessionRepository) Delete(id int64) error {
     // Check if session exists first
-    _, err := r.GetByID(id)
+    existing, err := r.GetByID(id)
     if err != nil {
         return fmt.Errorf("session with ID %d not found", id)
     }
     
-    return r.storage.DeleteSession(id)
+    trashed, err := trashSession(*existing, time.Now())
+    if err != nil {
+        return err
+    }
+    if err := r.storage.PutTrash(trashed); err != nil {
+        return err
+    }
+    if err := r.storage.DeleteSession(id); err != nil {
+        return err
+    }
+    return recordRevisions(r.storage, models.OperationDelete, []itemChange{
+        {itemType: models.ItemSession, itemID: id, before: *existing},
+    }, 0)
 }
\ No newline at end of file
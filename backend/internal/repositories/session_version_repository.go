@@ -0,0 +1,207 @@
+package repositories
+
+import (
+    "encoding/json"
+    "fmt"
+    "sort"
+    "sync"
+    "time"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/storage"
+)
+
+// sessionVersionMutex serializes numbering and rewriting session versions
+var sessionVersionMutex sync.Mutex
+
+type sessionVersionRepository struct {
+    storage *storage.AppendLogStorage
+}
+
+func NewSessionVersionRepository(storage *storage.AppendLogStorage) SessionVersionRepository {
+    return &sessionVersionRepository{storage: storage}
+}
+
+// GetAll returns a session's versions, oldest first
+func (r *sessionVersionRepository) GetAll(sessionID int64) ([]models.SessionVersion, error) {
+    stored, err := r.storage.ReadSessionVersions()
+    if err != nil {
+        return nil, err
+    }
+
+    versions := []models.SessionVersion{}
+    for _, version := range stored {
+        if version.SessionID == sessionID {
+            versions = append(versions, version)
+        }
+    }
+    sort.Slice(versions, func(i, j int) bool {
+        return versions[i].Version < versions[j].Version
+    })
+    return versions, nil
+}
+
+func (r *sessionVersionRepository) Get(sessionID int64, number int) (*models.SessionVersion, error) {
+    versions, err := r.GetAll(sessionID)
+    if err != nil {
+        return nil, err
+    }
+
+    for _, version := range versions {
+        if version.Version == number {
+            return &version, nil
+        }
+    }
+
+    return nil, fmt.Errorf("session %d has no version %d", sessionID, number)
+}
+
+func (r *sessionVersionRepository) Create(session models.Session, source string) (*models.SessionVersion, bool, error) {
+    sessionVersionMutex.Lock()
+    defer sessionVersionMutex.Unlock()
+
+    versions, err := r.GetAll(session.ID)
+    if err != nil {
+        return nil, false, err
+    }
+
+    version := models.SessionVersion{
+        SessionID: session.ID,
+        Version:   1,
+        Source:    source,
+        Name:      session.Name,
+        Tabs:      session.Tabs,
+        Windows:   session.Windows,
+        Groups:    session.Groups,
+        Diff:      diffTabs(nil, session.Tabs),
+        CreatedAt: time.Now(),
+    }
+    if n := len(versions); n > 0 {
+        latest := versions[n-1]
+        if latest.Name == session.Name && sameLayout(latest, version) {
+            return &latest, false, nil
+        }
+        version.Version = latest.Version + 1
+        version.Diff = diffTabs(latest.Tabs, session.Tabs)
+    }
+
+    if err := r.storage.PutSessionVersions(version); err != nil {
+        return nil, false, err
+    }
+    return &version, true, nil
+}
+
+func (r *sessionVersionRepository) Delete(sessionID int64, numbers ...int) error {
+    if len(numbers) == 0 {
+        return nil
+    }
+
+    sessionVersionMutex.Lock()
+    defer sessionVersionMutex.Unlock()
+
+    versions, err := r.GetAll(sessionID)
+    if err != nil {
+        return err
+    }
+    drop := make(map[int]bool, len(numbers))
+    for _, number := range numbers {
+        drop[number] = true
+    }
+
+    var deleted, rediffed []models.SessionVersion
+    var previous *models.SessionVersion
+    previousDropped := false
+    for i := range versions {
+        version := versions[i]
+        if drop[version.Version] {
+            deleted = append(deleted, version)
+            previousDropped = true
+            continue
+        }
+        if previousDropped {
+            var before []models.Tab
+            if previous != nil {
+                before = previous.Tabs
+            }
+            version.Diff = diffTabs(before, version.Tabs)
+            rediffed = append(rediffed, version)
+        }
+        previous = &versions[i]
+        previousDropped = false
+    }
+
+    if err := r.storage.DeleteSessionVersions(deleted...); err != nil {
+        return err
+    }
+    return r.storage.PutSessionVersions(rediffed...)
+}
+
+// deleteSessionVersions removes every version of the given sessions
+func deleteSessionVersions(storage *storage.AppendLogStorage, sessionIDs ...int64) error {
+    if len(sessionIDs) == 0 {
+        return nil
+    }
+
+    sessionVersionMutex.Lock()
+    defer sessionVersionMutex.Unlock()
+
+    stored, err := storage.ReadSessionVersions()
+    if err != nil {
+        return err
+    }
+    purged := make(map[int64]bool, len(sessionIDs))
+    for _, id := range sessionIDs {
+        purged[id] = true
+    }
+
+    var deleted []models.SessionVersion
+    for _, version := range stored {
+        if purged[version.SessionID] {
+            deleted = append(deleted, version)
+        }
+    }
+    return storage.DeleteSessionVersions(deleted...)
+}
+
+// diffTabs works out which tabs were opened and closed between two
+// versions. Tabs are matched by URL, so a tab that only moved or changed
+// its title counts as kept.
+func diffTabs(before, after []models.Tab) models.SessionDiff {
+    open := make(map[string]int, len(before))
+    for _, tab := range before {
+        open[tab.URL]++
+    }
+
+    diff := models.SessionDiff{Added: []models.Tab{}, Removed: []models.Tab{}}
+    for _, tab := range after {
+        if open[tab.URL] > 0 {
+            open[tab.URL]--
+            diff.Kept++
+            continue
+        }
+        diff.Added = append(diff.Added, tab)
+    }
+    for _, tab := range before {
+        if open[tab.URL] > 0 {
+            open[tab.URL]--
+            diff.Removed = append(diff.Removed, tab)
+        }
+    }
+    return diff
+}
+
+// sessionLayout is what sameLayout compares; empty and missing lists
+// encode the same
+type sessionLayout struct {
+    Tabs    []models.Tab      `json:"tabs,omitempty"`
+    Windows []models.Window   `json:"windows,omitempty"`
+    Groups  []models.TabGroup `json:"groups,omitempty"`
+}
+
+// sameLayout reports whether two versions hold the same tabs, in the same
+// order, state, windows and groups
+func sameLayout(a, b models.SessionVersion) bool {
+    encodedA, errA := json.Marshal(sessionLayout{Tabs: a.Tabs, Windows: a.Windows, Groups: a.Groups})
+    encodedB, errB := json.Marshal(sessionLayout{Tabs: b.Tabs, Windows: b.Windows, Groups: b.Groups})
+    return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
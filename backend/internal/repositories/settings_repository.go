@@ -0,0 +1,29 @@
+package repositories
+
+import (
+    "time"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/storage"
+)
+
+type settingsRepository struct {
+    storage *storage.AppendLogStorage
+}
+
+func NewSettingsRepository(storage *storage.AppendLogStorage) SettingsRepository {
+    return &settingsRepository{storage: storage}
+}
+
+func (r *settingsRepository) Get() (*models.Settings, error) {
+    settings, err := r.storage.ReadSettings()
+    if err != nil {
+        return nil, err
+    }
+    return &settings, nil
+}
+
+func (r *settingsRepository) Update(settings *models.Settings) error {
+    settings.UpdatedAt = time.Now()
+    return r.storage.WriteSettings(*settings)
+}
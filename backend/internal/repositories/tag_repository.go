@@ -0,0 +1,35 @@
+package repositories
+
+import (
+    "time"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/storage"
+)
+
+type tagRepository struct {
+    storage *storage.AppendLogStorage
+}
+
+func NewTagRepository(storage *storage.AppendLogStorage) TagRepository {
+    return &tagRepository{storage: storage}
+}
+
+func (r *tagRepository) GetAll() ([]models.TagInfo, error) {
+    return r.storage.ReadTagInfo()
+}
+
+func (r *tagRepository) Save(tags ...models.TagInfo) error {
+    now := time.Now()
+    for i := range tags {
+        tags[i].UpdatedAt = now
+    }
+    return r.storage.PutTagInfo(tags...)
+}
+
+func (r *tagRepository) Delete(names ...string) error {
+    if len(names) == 0 {
+        return nil
+    }
+    return r.storage.DeleteTagInfo(names...)
+}
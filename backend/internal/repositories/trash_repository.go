@@ -0,0 +1,122 @@
+package repositories
+
+import (
+    "encoding/json"
+    "fmt"
+    "sort"
+    "time"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/storage"
+)
+
+// trashBookmark builds the trash entry for a bookmark being deleted
+func trashBookmark(bookmark models.Bookmark, deletedAt time.Time) (models.TrashItem, error) {
+    item, err := json.Marshal(bookmark)
+    if err != nil {
+        return models.TrashItem{}, err
+    }
+    return models.TrashItem{
+        Type:      models.ItemBookmark,
+        ID:        bookmark.ID,
+        Title:     bookmark.Title,
+        URL:       bookmark.URL,
+        Item:      item,
+        DeletedAt: deletedAt,
+    }, nil
+}
+
+// trashSession builds the trash entry for a session being deleted
+func trashSession(session models.Session, deletedAt time.Time) (models.TrashItem, error) {
+    item, err := json.Marshal(session)
+    if err != nil {
+        return models.TrashItem{}, err
+    }
+    return models.TrashItem{
+        Type:      models.ItemSession,
+        ID:        session.ID,
+        Title:     session.Name,
+        Item:      item,
+        DeletedAt: deletedAt,
+    }, nil
+}
+
+type trashRepository struct {
+    storage *storage.AppendLogStorage
+}
+
+func NewTrashRepository(storage *storage.AppendLogStorage) TrashRepository {
+    return &trashRepository{storage: storage}
+}
+
+// GetAll lists the trash, most recently deleted first
+func (r *trashRepository) GetAll() ([]models.TrashItem, error) {
+    items, err := r.storage.ReadTrash()
+    if err != nil {
+        return nil, err
+    }
+    sort.SliceStable(items, func(i, j int) bool {
+        return items[i].DeletedAt.After(items[j].DeletedAt)
+    })
+    return items, nil
+}
+
+func (r *trashRepository) Get(itemType string, id int64) (*models.TrashItem, error) {
+    items, err := r.storage.ReadTrash()
+    if err != nil {
+        return nil, err
+    }
+
+    for _, item := range items {
+        if item.Type == itemType && item.ID == id {
+            return &item, nil
+        }
+    }
+
+    return nil, fmt.Errorf("%s with ID %d is not in the trash", itemType, id)
+}
+
+// Purge removes items for good, whether still live or in the trash: the
+// items themselves, their trash entries, their revisions and a session's
+// versions. The logs are compacted afterwards so no copy is left on disk.
+func (r *trashRepository) Purge(items ...models.ItemRef) error {
+    if len(items) == 0 {
+        return nil
+    }
+
+    var sessionIDs []int64
+    for _, item := range items {
+        var live bool
+        var err error
+        switch item.Type {
+        case models.ItemBookmark:
+            if _, live, err = r.storage.GetBookmark(item.ID); err == nil && live {
+                err = r.storage.DeleteBookmark(item.ID)
+            }
+        case models.ItemSession:
+            if _, live, err = r.storage.GetSession(item.ID); err == nil && live {
+                err = r.storage.DeleteSession(item.ID)
+            }
+            sessionIDs = append(sessionIDs, item.ID)
+        default:
+            err = fmt.Errorf("unknown item type %q", item.Type)
+        }
+        if err != nil {
+            return err
+        }
+    }
+
+    if err := r.storage.DeleteTrash(items...); err != nil {
+        return err
+    }
+    revisionMutex.Lock()
+    err := r.storage.DeleteRevisions(items...)
+    revisionMutex.Unlock()
+    if err != nil {
+        return err
+    }
+    if err := deleteSessionVersions(r.storage, sessionIDs...); err != nil {
+        return err
+    }
+    return r.storage.Compact()
+}
@@ -0,0 +1,150 @@
+// Package search is an in-memory full-text index, used for bookmarks'
+// archived page text and for annotations.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Hit is a matching document and how well it matched
+type Hit struct {
+	ID    int64
+	Score float64
+}
+
+// Index is an inverted index from terms to the documents containing them.
+// It is safe for concurrent use.
+type Index struct {
+	mutex    sync.RWMutex
+	postings map[string]map[int64]int // term -> document -> occurrences
+	terms    map[int64][]string       // document -> its distinct terms, for removal
+}
+
+func New() *Index {
+	return &Index{
+		postings: make(map[string]map[int64]int),
+		terms:    make(map[int64][]string),
+	}
+}
+
+// Set indexes text as document id, replacing what it held before
+func (i *Index) Set(id int64, text string) {
+	counts := make(map[string]int)
+	for _, term := range tokenize(text) {
+		counts[term]++
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.remove(id)
+	if len(counts) == 0 {
+		return
+	}
+	terms := make([]string, 0, len(counts))
+	for term, count := range counts {
+		postings := i.postings[term]
+		if postings == nil {
+			postings = make(map[int64]int)
+			i.postings[term] = postings
+		}
+		postings[id] = count
+		terms = append(terms, term)
+	}
+	i.terms[id] = terms
+}
+
+// Delete drops documents from the index
+func (i *Index) Delete(ids ...int64) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	for _, id := range ids {
+		i.remove(id)
+	}
+}
+
+func (i *Index) remove(id int64) {
+	for _, term := range i.terms[id] {
+		delete(i.postings[term], id)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	delete(i.terms, id)
+}
+
+// Len is the number of indexed documents
+func (i *Index) Len() int {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	return len(i.terms)
+}
+
+// Search returns the documents containing every term of query, best
+// first. The last term also matches as a prefix, so results keep up with
+// a query as it is typed.
+func (i *Index) Search(query string) []Hit {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	total := float64(len(i.terms))
+	var scores map[int64]float64
+	for n, term := range terms {
+		matches := map[string]map[int64]int{term: i.postings[term]}
+		if n == len(terms)-1 {
+			for indexed, postings := range i.postings {
+				if strings.HasPrefix(indexed, term) {
+					matches[indexed] = postings
+				}
+			}
+		}
+
+		// Score with tf-idf, so rare terms count for more than common ones
+		termScores := make(map[int64]float64)
+		for _, postings := range matches {
+			idf := math.Log(1 + total/float64(len(postings)))
+			for id, count := range postings {
+				termScores[id] = math.Max(termScores[id], (1+math.Log(float64(count)))*idf)
+			}
+		}
+
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id := range scores {
+			if score, ok := termScores[id]; ok {
+				scores[id] += score
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].ID < hits[b].ID
+	})
+	return hits
+}
+
+// tokenize splits text into lowercase words of letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
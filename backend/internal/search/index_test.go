@@ -0,0 +1,64 @@
+package search
+
+import (
+	"testing"
+)
+
+func ids(hits []Hit) []int64 {
+	result := make([]int64, len(hits))
+	for i, hit := range hits {
+		result[i] = hit.ID
+	}
+	return result
+}
+
+func TestIndex_Search(t *testing.T) {
+	index := New()
+	index.Set(1, "Go concurrency patterns: goroutines and channels")
+	index.Set(2, "Channels in Go, channels everywhere. Channels!")
+	index.Set(3, "Rust ownership and borrowing")
+
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{"channels", []int64{2, 1}}, // more occurrences rank higher
+		{"GO Channels", []int64{2, 1}},
+		{"go rust", []int64{}},
+		{"gorout", []int64{1}}, // the last term matches as a prefix
+		{"gorout channels", []int64{}},
+		{"borrowing", []int64{3}},
+		{"  ", []int64{}},
+	}
+	for _, test := range tests {
+		got := ids(index.Search(test.query))
+		if len(got) != len(test.want) {
+			t.Errorf("Search(%q) = %v, want %v", test.query, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("Search(%q) = %v, want %v", test.query, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestIndex_SetReplacesAndDeleteRemoves(t *testing.T) {
+	index := New()
+	index.Set(1, "original words")
+	index.Set(1, "replacement text")
+
+	if hits := index.Search("original"); len(hits) != 0 {
+		t.Errorf("Expected replaced text to be gone, got %v", ids(hits))
+	}
+	if hits := index.Search("replacement"); len(hits) != 1 {
+		t.Errorf("Expected the new text to match, got %v", ids(hits))
+	}
+
+	index.Delete(1)
+	if index.Len() != 0 || len(index.Search("replacement")) != 0 {
+		t.Error("Expected the document to be gone after Delete")
+	}
+}
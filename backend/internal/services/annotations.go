@@ -0,0 +1,269 @@
+package services
+
+import (
+    "fmt"
+    "log"
+    "strings"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/search"
+    "hyprlnk/internal/urlnorm"
+)
+
+// GetAnnotations lists notes and highlights, oldest first. bookmarkID
+// narrows them to a bookmark's page and pageURL to any page, bookmarked or
+// not; annotationType picks notes or highlights. A query keeps only
+// annotations whose text matches, best first.
+func (s *hyprLinkService) GetAnnotations(bookmarkID int64, pageURL, annotationType, query string) ([]models.Annotation, error) {
+    if annotationType != "" && annotationType != models.AnnotationNote && annotationType != models.AnnotationHighlight {
+        return nil, fmt.Errorf("%w: unknown annotation type %q", ErrInvalidInput, annotationType)
+    }
+    normalizer, err := s.urlNormalizer()
+    if err != nil {
+        return nil, err
+    }
+
+    all, err := s.annotationRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+    if query != "" {
+        byID := make(map[int64]models.Annotation, len(all))
+        for _, annotation := range all {
+            byID[annotation.ID] = annotation
+        }
+        all = all[:0]
+        for _, hit := range s.annotationIndex().Search(query) {
+            if annotation, ok := byID[hit.ID]; ok {
+                all = append(all, annotation)
+            }
+        }
+    }
+
+    // A bookmark's annotations include those made on its page before it was saved
+    var bookmarkURL string
+    if bookmarkID != 0 {
+        bookmark, err := s.bookmarkRepo.GetByID(bookmarkID)
+        if err != nil {
+            return nil, kindError{kind: ErrNotFound, err: err}
+        }
+        bookmarkURL = normalizer.Normalize(bookmark.URL)
+    }
+    if pageURL != "" {
+        pageURL = normalizer.Normalize(pageURL)
+    }
+
+    annotations := []models.Annotation{}
+    for _, annotation := range all {
+        canonical := normalizer.Normalize(annotation.URL)
+        if bookmarkID != 0 && annotation.BookmarkID != bookmarkID && canonical != bookmarkURL {
+            continue
+        }
+        if pageURL != "" && canonical != pageURL {
+            continue
+        }
+        if annotationType != "" && annotation.Type != annotationType {
+            continue
+        }
+        annotations = append(annotations, annotation)
+    }
+    return annotations, nil
+}
+
+func (s *hyprLinkService) GetAnnotation(id int64) (*models.Annotation, error) {
+    annotation, err := s.annotationRepo.GetByID(id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+    return annotation, nil
+}
+
+// CreateAnnotation adds a note or highlight to a bookmark, given its
+// bookmark_id, or to any page, given its url. An annotation on a
+// bookmarked page is attached to the bookmark.
+func (s *hyprLinkService) CreateAnnotation(annotation *models.Annotation) error {
+    if err := validateAnnotation(annotation); err != nil {
+        return err
+    }
+    normalizer, err := s.urlNormalizer()
+    if err != nil {
+        return err
+    }
+
+    if annotation.BookmarkID != 0 {
+        bookmark, err := s.bookmarkRepo.GetByID(annotation.BookmarkID)
+        if err != nil {
+            return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+        }
+        annotation.URL = normalizer.Normalize(bookmark.URL)
+    } else {
+        if strings.TrimSpace(annotation.URL) == "" {
+            return fmt.Errorf("%w: a bookmark_id or url is required", ErrInvalidInput)
+        }
+        annotation.URL = normalizer.Normalize(annotation.URL)
+
+        if bookmark, err := s.bookmarkRepo.GetByURL(annotation.URL); err == nil {
+            annotation.BookmarkID = bookmark.ID
+        }
+    }
+
+    if err := s.annotationRepo.Create(annotation); err != nil {
+        return err
+    }
+    s.annotationIndex().Set(annotation.ID, annotationText(*annotation))
+    return nil
+}
+
+// UpdateAnnotation changes an annotation's text; it stays on its page
+func (s *hyprLinkService) UpdateAnnotation(annotation *models.Annotation) error {
+    existing, err := s.annotationRepo.GetByID(annotation.ID)
+    if err != nil {
+        return kindError{kind: ErrNotFound, err: err}
+    }
+    if annotation.Type == "" {
+        annotation.Type = existing.Type
+    }
+    if err := validateAnnotation(annotation); err != nil {
+        return err
+    }
+
+    annotation.BookmarkID = existing.BookmarkID
+    annotation.URL = existing.URL
+    if err := s.annotationRepo.Update(annotation); err != nil {
+        return err
+    }
+    s.annotationIndex().Set(annotation.ID, annotationText(*annotation))
+    return nil
+}
+
+func (s *hyprLinkService) DeleteAnnotation(id int64) error {
+    if err := s.annotationRepo.Delete(id); err != nil {
+        return kindError{kind: ErrNotFound, err: err}
+    }
+    s.annotationIndex().Delete(id)
+    return nil
+}
+
+// validateAnnotation checks an annotation's content, working out its type
+// when it isn't given
+func validateAnnotation(annotation *models.Annotation) error {
+    if annotation.Type == "" {
+        annotation.Type = models.AnnotationNote
+        if annotation.Highlight != nil {
+            annotation.Type = models.AnnotationHighlight
+        }
+    }
+
+    switch annotation.Type {
+    case models.AnnotationNote:
+        if strings.TrimSpace(annotation.Body) == "" {
+            return fmt.Errorf("%w: a note needs a body", ErrInvalidInput)
+        }
+        annotation.Highlight = nil
+    case models.AnnotationHighlight:
+        highlight := annotation.Highlight
+        if highlight == nil || strings.TrimSpace(highlight.Text) == "" {
+            return fmt.Errorf("%w: a highlight needs the highlighted text", ErrInvalidInput)
+        }
+        if highlight.StartOffset < 0 || highlight.EndOffset < highlight.StartOffset {
+            return fmt.Errorf("%w: invalid highlight offsets %d-%d", ErrInvalidInput, highlight.StartOffset, highlight.EndOffset)
+        }
+    default:
+        return fmt.Errorf("%w: unknown annotation type %q", ErrInvalidInput, annotation.Type)
+    }
+    return nil
+}
+
+// linkAnnotations attaches the annotations already made on a newly saved
+// bookmark's page to it
+func (s *hyprLinkService) linkAnnotations(bookmark models.Bookmark) error {
+    normalizer, err := s.urlNormalizer()
+    if err != nil {
+        return err
+    }
+    all, err := s.annotationRepo.GetAll()
+    if err != nil {
+        return err
+    }
+
+    canonical := normalizer.Normalize(bookmark.URL)
+    var linked []models.Annotation
+    for _, annotation := range all {
+        if annotation.BookmarkID == 0 && normalizer.Normalize(annotation.URL) == canonical {
+            annotation.BookmarkID = bookmark.ID
+            linked = append(linked, annotation)
+        }
+    }
+    if len(linked) == 0 {
+        return nil
+    }
+    return s.annotationRepo.UpdateMany(linked)
+}
+
+// moveAnnotations moves the annotations attached to the bookmarks in ids
+// onto bookmark and its URL, so they follow merges and URL changes. A nil
+// bookmark detaches them, leaving them on their page.
+func (s *hyprLinkService) moveAnnotations(ids []int64, bookmark *models.Bookmark) error {
+    normalizer, err := s.urlNormalizer()
+    if err != nil {
+        return err
+    }
+    all, err := s.annotationRepo.GetAll()
+    if err != nil {
+        return err
+    }
+
+    from := make(map[int64]bool, len(ids))
+    for _, id := range ids {
+        from[id] = true
+    }
+    var moved []models.Annotation
+    for _, annotation := range all {
+        if annotation.BookmarkID == 0 || !from[annotation.BookmarkID] {
+            continue
+        }
+        annotation.BookmarkID = 0
+        if bookmark != nil {
+            annotation.BookmarkID = bookmark.ID
+            annotation.URL = normalizer.Normalize(bookmark.URL)
+        }
+        moved = append(moved, annotation)
+    }
+    if len(moved) == 0 {
+        return nil
+    }
+    return s.annotationRepo.UpdateMany(moved)
+}
+
+// annotationText is what search matches an annotation by
+func annotationText(annotation models.Annotation) string {
+    if annotation.Highlight == nil {
+        return annotation.Body
+    }
+    return annotation.Highlight.Text + "\n" + annotation.Body
+}
+
+// annotationIndex returns the full-text index of annotations, filling it
+// on first use
+func (s *hyprLinkService) annotationIndex() *search.Index {
+    s.annotationIndexOnce.Do(func() {
+        annotations, err := s.annotationRepo.GetAll()
+        if err != nil {
+            log.Printf("search: reading annotations: %v", err)
+            return
+        }
+        for _, annotation := range annotations {
+            s.annotationTextIndex.Set(annotation.ID, annotationText(annotation))
+        }
+    })
+    return s.annotationTextIndex
+}
+
+// urlNormalizer canonicalizes URLs with the user's current URL rules
+func (s *hyprLinkService) urlNormalizer() (*urlnorm.Normalizer, error) {
+    settings, err := s.settingsRepo.Get()
+    if err != nil {
+        return nil, err
+    }
+    return urlnorm.New(settings.URLRules), nil
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+    "errors"
+    "testing"
+
+    "hyprlnk/internal/models"
+)
+
+// mustCreateAnnotation saves an annotation, failing the test on error
+func mustCreateAnnotation(t *testing.T, service *hyprLinkService, annotation models.Annotation) models.Annotation {
+    t.Helper()
+    if err := service.CreateAnnotation(&annotation); err != nil {
+        t.Fatalf("Failed to create annotation: %v", err)
+    }
+    return annotation
+}
+
+func newNote(url, body string) models.Annotation {
+    return models.Annotation{URL: url, Body: body}
+}
+
+func TestCreateAnnotation_Validation(t *testing.T) {
+    service, _ := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://example.com/a"))
+
+    tests := []struct {
+        name       string
+        annotation models.Annotation
+    }{
+        {"empty note", newNote("https://example.com/a", "  ")},
+        {"no page", models.Annotation{Body: "Orphan"}},
+        {"unknown bookmark", models.Annotation{BookmarkID: 42, Body: "Lost"}},
+        {"unknown type", models.Annotation{URL: "https://example.com/a", Type: "doodle", Body: "x"}},
+        {"highlight without text", models.Annotation{URL: "https://example.com/a", Type: models.AnnotationHighlight}},
+        {"reversed offsets", models.Annotation{BookmarkID: bookmark.ID,
+            Highlight: &models.Highlight{Text: "passage", StartOffset: 10, EndOffset: 5}}},
+        {"negative offset", models.Annotation{BookmarkID: bookmark.ID,
+            Highlight: &models.Highlight{Text: "passage", StartOffset: -1, EndOffset: 5}}},
+    }
+    for _, tt := range tests {
+        annotation := tt.annotation
+        if err := service.CreateAnnotation(&annotation); !errors.Is(err, ErrInvalidInput) {
+            t.Errorf("%s: expected invalid input, got %v", tt.name, err)
+        }
+    }
+
+    // The type follows from what is given, and notes carry no highlight
+    highlight := mustCreateAnnotation(t, service, models.Annotation{BookmarkID: bookmark.ID,
+        Highlight: &models.Highlight{Text: "passage", StartOffset: 2, EndOffset: 9}})
+    if highlight.Type != models.AnnotationHighlight {
+        t.Errorf("Expected a highlight, got %q", highlight.Type)
+    }
+    plain := mustCreateAnnotation(t, service, models.Annotation{BookmarkID: bookmark.ID, Type: models.AnnotationNote,
+        Body: "Note", Highlight: &models.Highlight{Text: "stray"}})
+    if plain.Highlight != nil {
+        t.Errorf("Expected a note's highlight dropped, got %+v", plain.Highlight)
+    }
+
+    if _, err := service.GetAnnotations(0, "", "doodle", ""); !errors.Is(err, ErrInvalidInput) {
+        t.Errorf("Expected an unknown type filter rejected, got %v", err)
+    }
+    if err := service.DeleteAnnotation(42); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected deleting an unknown annotation not found, got %v", err)
+    }
+}
+
+func TestAnnotations_LinkByCanonicalURL(t *testing.T) {
+    service, _ := newTestService(t)
+    saved := mustCreateBookmark(t, service, newBookmark("https://example.com/saved"))
+
+    // A note on a bookmarked page, by another spelling of its URL
+    onSaved := mustCreateAnnotation(t, service, newNote("http://www.example.com/saved/?utm_source=feed", "On the saved page"))
+    if onSaved.BookmarkID != saved.ID || onSaved.URL != "https://example.com/saved" {
+        t.Errorf("Expected the note attached to bookmark %d at its canonical URL, got %d at %s", saved.ID, onSaved.BookmarkID, onSaved.URL)
+    }
+
+    // Notes made before a page is saved are picked up when it is
+    early := mustCreateAnnotation(t, service, newNote("https://example.com/later", "Before saving"))
+    if early.BookmarkID != 0 {
+        t.Fatalf("Expected a note on an unsaved page unattached, got bookmark %d", early.BookmarkID)
+    }
+    later := mustCreateBookmark(t, service, newBookmark("https://www.example.com/later?utm_medium=email"))
+    if got, _ := service.GetAnnotation(early.ID); got.BookmarkID != later.ID {
+        t.Errorf("Expected the note linked to the new bookmark %d, got %d", later.ID, got.BookmarkID)
+    }
+
+    annotations, err := service.GetAnnotations(later.ID, "", "", "")
+    if err != nil || len(annotations) != 1 || annotations[0].ID != early.ID {
+        t.Errorf("Expected the bookmark's one note, got %+v (%v)", annotations, err)
+    }
+    annotations, _ = service.GetAnnotations(0, "http://example.com/saved", "", "")
+    if len(annotations) != 1 || annotations[0].ID != onSaved.ID {
+        t.Errorf("Expected the page's one note, got %+v", annotations)
+    }
+}
+
+func TestAnnotations_FollowBookmark(t *testing.T) {
+    service, _ := newTestService(t)
+    first := mustCreateBookmark(t, service, newBookmark("https://example.com/a"))
+    second := mustCreateBookmark(t, service, newBookmark("https://example.com/b"))
+    onFirst := mustCreateAnnotation(t, service, models.Annotation{BookmarkID: first.ID, Body: "First"})
+    onSecond := mustCreateAnnotation(t, service, models.Annotation{BookmarkID: second.ID, Body: "Second"})
+
+    // A URL change takes the annotations along
+    first.URL = "https://example.com/moved"
+    if err := service.UpdateBookmark(&first); err != nil {
+        t.Fatal(err)
+    }
+    if got, _ := service.GetAnnotation(onFirst.ID); got.BookmarkID != first.ID || got.URL != "https://example.com/moved" {
+        t.Errorf("Expected the note moved to the new URL, got %d at %s", got.BookmarkID, got.URL)
+    }
+
+    // A merge gathers them on the kept bookmark
+    merged, err := service.MergeBookmarks([]int64{first.ID, second.ID}, first.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got, _ := service.GetAnnotation(onSecond.ID); got.BookmarkID != merged.ID || got.URL != "https://example.com/moved" {
+        t.Errorf("Expected the merged-away note on bookmark %d, got %d at %s", merged.ID, got.BookmarkID, got.URL)
+    }
+    annotations, _ := service.GetAnnotations(merged.ID, "", "", "")
+    if len(annotations) != 2 {
+        t.Errorf("Expected both notes on the kept bookmark, got %+v", annotations)
+    }
+}
+
+func TestAnnotations_Search(t *testing.T) {
+    service, _ := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://example.com/a"))
+    quoted := mustCreateAnnotation(t, service, models.Annotation{BookmarkID: bookmark.ID,
+        Highlight: &models.Highlight{Text: "goroutines are cheap"}})
+    loose := mustCreateAnnotation(t, service, newNote("https://example.com/unsaved", "channels everywhere"))
+
+    ids := func(query string) []int64 {
+        t.Helper()
+        annotations, err := service.GetAnnotations(0, "", "", query)
+        if err != nil {
+            t.Fatal(err)
+        }
+        found := []int64{}
+        for _, annotation := range annotations {
+            found = append(found, annotation.ID)
+        }
+        return found
+    }
+
+    if got := ids("goroutines"); len(got) != 1 || got[0] != quoted.ID {
+        t.Errorf("Expected the highlight found by its text, got %v", got)
+    }
+    if got := ids("channels"); len(got) != 1 || got[0] != loose.ID {
+        t.Errorf("Expected the note found by its body, got %v", got)
+    }
+
+    // Bookmark search includes bookmarks whose annotations match
+    results, err := service.SearchBookmarks("goroutines")
+    if err != nil || len(results) != 1 || results[0].ID != bookmark.ID {
+        t.Errorf("Expected the annotated bookmark found, got %+v (%v)", results, err)
+    }
+
+    // The index follows edits and deletes
+    loose.Body = "select statements"
+    if err := service.UpdateAnnotation(&loose); err != nil {
+        t.Fatal(err)
+    }
+    if got := ids("channels"); len(got) != 0 {
+        t.Errorf("Expected the old text gone from the index, got %v", got)
+    }
+    if got := ids("select"); len(got) != 1 || got[0] != loose.ID {
+        t.Errorf("Expected the new text indexed, got %v", got)
+    }
+    if err := service.DeleteAnnotation(quoted.ID); err != nil {
+        t.Fatal(err)
+    }
+    if got := ids("goroutines"); len(got) != 0 {
+        t.Errorf("Expected a deleted annotation gone from the index, got %v", got)
+    }
+}
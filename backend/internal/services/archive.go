@@ -0,0 +1,176 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+
+    "hyprlnk/internal/archive"
+    "hyprlnk/internal/fetch"
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/pagemeta"
+    "hyprlnk/internal/search"
+)
+
+// errNotHTML is recorded for pages, such as PDFs, that can't be archived
+var errNotHTML = errors.New("not an HTML page")
+
+// GetBookmarkArchive returns the record of a bookmark's offline copy
+func (s *hyprLinkService) GetBookmarkArchive(id int64) (*models.PageArchive, error) {
+    record, err := s.archiveRepo.Get(id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+    return record, nil
+}
+
+// GetArchiveSnapshot returns the self-contained HTML copy of a bookmark's page
+func (s *hyprLinkService) GetArchiveSnapshot(id int64) ([]byte, error) {
+    if _, err := s.archivedRecord(id); err != nil {
+        return nil, err
+    }
+    snapshot, err := s.archiveRepo.GetSnapshot(id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: fmt.Errorf("snapshot of bookmark %d is missing", id)}
+    }
+    return snapshot, nil
+}
+
+// GetArchiveText returns the readable text of a bookmark's archived page
+func (s *hyprLinkService) GetArchiveText(id int64) (string, error) {
+    if _, err := s.archivedRecord(id); err != nil {
+        return "", err
+    }
+    text, err := s.archiveRepo.GetText(id)
+    if err != nil {
+        return "", kindError{kind: ErrNotFound, err: fmt.Errorf("text of bookmark %d is missing", id)}
+    }
+    return text, nil
+}
+
+// archivedRecord returns the bookmark's archive record if an archive of
+// its page succeeded at some point
+func (s *hyprLinkService) archivedRecord(id int64) (*models.PageArchive, error) {
+    record, err := s.GetBookmarkArchive(id)
+    if err != nil {
+        return nil, err
+    }
+    if record.ArchivedAt.IsZero() {
+        return nil, kindError{kind: ErrNotFound, err: fmt.Errorf("bookmark %d could not be archived: %s", id, record.Error)}
+    }
+    return record, nil
+}
+
+// ArchiveBookmark archives a bookmark's page now. It works whether or not
+// background archiving is enabled, as long as page fetching is.
+func (s *hyprLinkService) ArchiveBookmark(id int64) (*models.PageArchive, error) {
+    if s.fetcher == nil {
+        return nil, fmt.Errorf("%w: page fetching is disabled", ErrConflict)
+    }
+    bookmark, err := s.bookmarkRepo.GetByID(id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+
+    ctx := context.Background()
+    previous, _ := s.archiveRepo.Get(id)
+    resp, err := s.fetcher.Get(ctx, bookmark.URL)
+    if err != nil {
+        return s.archiveFailed(*bookmark, previous, err)
+    }
+    return s.archivePage(ctx, *bookmark, resp, previous)
+}
+
+// needsArchive is true for bookmarks never archived, whose URL changed
+// since, or whose last attempt failed and is due a retry
+func needsArchive(bookmark models.Bookmark, record *models.PageArchive) bool {
+    if record == nil || record.URL != bookmark.URL {
+        return true
+    }
+    if record.Error == "" || record.Attempts >= enrichMaxAttempts {
+        return false
+    }
+    retryAfter := enrichRetryDelay << (record.Attempts - 1)
+    return time.Since(record.AttemptedAt) >= retryAfter
+}
+
+// archivePage stores a snapshot and the readable text of a fetched page,
+// and indexes the text for search
+func (s *hyprLinkService) archivePage(ctx context.Context, bookmark models.Bookmark, resp *fetch.Response, previous *models.PageArchive) (*models.PageArchive, error) {
+    if resp.StatusCode >= 400 {
+        return s.archiveFailed(bookmark, previous, fmt.Errorf("HTTP %d", resp.StatusCode))
+    }
+    contentType := resp.Header.Get("Content-Type")
+    if !isHTML(contentType) {
+        return s.archiveFailed(bookmark, previous, errNotHTML)
+    }
+
+    snapshot, err := archive.Take(ctx, s.fetcher, resp.Body, resp.URL, contentType, archive.Limits{})
+    if err != nil {
+        return s.archiveFailed(bookmark, previous, err)
+    }
+    text := archive.Readable(resp.Body, contentType)
+    now := time.Now()
+
+    record := models.PageArchive{
+        BookmarkID:   bookmark.ID,
+        URL:          bookmark.URL,
+        FinalURL:     resp.URL.String(),
+        Title:        pagemeta.Extract(resp.Body, resp.URL, contentType).BestTitle(),
+        TextLength:   len([]rune(text)),
+        SnapshotSize: len(snapshot.HTML),
+        Inlined:      snapshot.Inlined,
+        Skipped:      snapshot.Skipped,
+        ArchivedAt:   now,
+        AttemptedAt:  now,
+    }
+    if err := s.archiveRepo.Save(record, snapshot.HTML, text); err != nil {
+        return nil, err
+    }
+    s.searchIndex().Set(bookmark.ID, record.Title+"\n"+text)
+    return &record, nil
+}
+
+// archiveFailed records a failed attempt. A previous archive of the same
+// URL is kept: a page that has since gone is exactly what it is for.
+func (s *hyprLinkService) archiveFailed(bookmark models.Bookmark, previous *models.PageArchive, err error) (*models.PageArchive, error) {
+    record := models.PageArchive{BookmarkID: bookmark.ID, URL: bookmark.URL}
+    if previous != nil && previous.URL == bookmark.URL {
+        record = *previous
+    } else {
+        s.searchIndex().Delete(bookmark.ID)
+    }
+
+    record.Error = err.Error()
+    record.Attempts++
+    record.AttemptedAt = time.Now()
+    if errors.Is(err, fetch.ErrDisallowed) || errors.Is(err, errNotHTML) {
+        record.Attempts = enrichMaxAttempts
+    }
+    return &record, s.archiveRepo.Save(record, nil, "")
+}
+
+// searchIndex returns the full-text index of archived pages, reading the
+// archived text into it on first use
+func (s *hyprLinkService) searchIndex() *search.Index {
+    s.indexOnce.Do(func() {
+        records, err := s.archiveRepo.GetAll()
+        if err != nil {
+            log.Printf("search: reading archives: %v", err)
+            return
+        }
+        for _, record := range records {
+            if record.ArchivedAt.IsZero() {
+                continue
+            }
+            text, err := s.archiveRepo.GetText(record.BookmarkID)
+            if err != nil {
+                continue
+            }
+            s.textIndex.Set(record.BookmarkID, record.Title+"\n"+text)
+        }
+    })
+    return s.textIndex
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "hyprlnk/internal/models"
+)
+
+// BulkBookmarks applies one operation to many bookmarks, picked by ID or
+// by a search query as /api/bookmarks/search would find them. The changed
+// bookmarks are written in one storage write, so they all change or none
+// do, and recorded as one operation that undo reverts as a whole. What goes
+// with them, the trash, revisions and annotations, is written separately:
+// when any write fails, the result comes back along with the error and
+// says which bookmarks did change.
+func (s *hyprLinkService) BulkBookmarks(request models.BulkRequest) (*models.BulkResult, error) {
+    apply, err := s.bulkOperation(request)
+    if err != nil {
+        return nil, err
+    }
+
+    result := &models.BulkResult{Operation: request.Operation, Items: []models.BulkItemResult{}}
+    var selected []models.Bookmark
+    switch {
+    case len(request.IDs) > 0 && request.Query != "":
+        return nil, fmt.Errorf("%w: give either ids or query, not both", ErrInvalidInput)
+    case len(request.IDs) > 0:
+        bookmarks, err := s.bookmarkRepo.GetAll()
+        if err != nil {
+            return nil, err
+        }
+        byID := make(map[int64]models.Bookmark, len(bookmarks))
+        for _, bookmark := range bookmarks {
+            byID[bookmark.ID] = bookmark
+        }
+        seen := make(map[int64]bool, len(request.IDs))
+        for _, id := range request.IDs {
+            if seen[id] {
+                continue
+            }
+            seen[id] = true
+            bookmark, ok := byID[id]
+            if !ok {
+                result.Items = append(result.Items, models.BulkItemResult{ID: id, Result: models.BulkNotFound})
+                continue
+            }
+            selected = append(selected, bookmark)
+        }
+    case strings.TrimSpace(request.Query) != "":
+        if selected, err = s.SearchBookmarks(request.Query); err != nil {
+            return nil, err
+        }
+    default:
+        return nil, fmt.Errorf("%w: ids or query is required", ErrInvalidInput)
+    }
+    result.Matched = len(selected)
+
+    now := time.Now()
+    var changed []models.Bookmark
+    var deleted []int64
+    for _, bookmark := range selected {
+        if request.Operation == models.BulkDelete {
+            deleted = append(deleted, bookmark.ID)
+            result.Items = append(result.Items, models.BulkItemResult{ID: bookmark.ID, Result: models.BulkChanged})
+            continue
+        }
+
+        updated := bookmark
+        updated.Tags = append([]string(nil), bookmark.Tags...)
+        apply(&updated, now)
+        item := models.BulkItemResult{ID: bookmark.ID, Result: models.BulkUnchanged, Bookmark: &updated}
+        if !bulkEqual(bookmark, updated) {
+            updated.UpdatedAt = now
+            changed = append(changed, updated)
+            item.Result = models.BulkChanged
+        }
+        result.Items = append(result.Items, item)
+    }
+
+    result.Changed = len(deleted) + len(changed)
+    if request.Operation == models.BulkDelete {
+        if err = s.bookmarkRepo.DeleteMany(deleted); err == nil {
+            err = s.moveAnnotations(deleted, nil)
+        }
+    } else {
+        err = s.bookmarkRepo.UpdateMany(changed)
+    }
+    if err != nil {
+        return s.bulkFailed(result, err)
+    }
+    return result, nil
+}
+
+// bulkFailed checks which bookmarks a failed bulk write changed after all,
+// marking the others failed, and returns the result with err
+func (s *hyprLinkService) bulkFailed(result *models.BulkResult, err error) (*models.BulkResult, error) {
+    result.Error = err.Error()
+    bookmarks, readErr := s.bookmarkRepo.GetAll()
+    if readErr != nil {
+        return nil, err
+    }
+    byID := make(map[int64]models.Bookmark, len(bookmarks))
+    for _, bookmark := range bookmarks {
+        byID[bookmark.ID] = bookmark
+    }
+
+    for i := range result.Items {
+        item := &result.Items[i]
+        if item.Result != models.BulkChanged {
+            continue
+        }
+        current, live := byID[item.ID]
+        written := !live
+        if result.Operation != models.BulkDelete {
+            written = live && bulkEqual(current, *item.Bookmark)
+        }
+        if !written {
+            item.Result = models.BulkFailed
+            if live {
+                item.Bookmark = &current
+            }
+            result.Changed--
+        }
+    }
+    return result, err
+}
+
+// bulkOperation validates a bulk request's operation and returns the
+// change it makes to each bookmark; delete is handled by the caller
+func (s *hyprLinkService) bulkOperation(request models.BulkRequest) (func(*models.Bookmark, time.Time), error) {
+    var tags []string
+    for _, tag := range request.Tags {
+        if tag = cleanTagName(tag); tag != "" {
+            tags = append(tags, tag)
+        }
+    }
+
+    switch request.Operation {
+    case models.BulkAddTags:
+        if len(tags) == 0 {
+            return nil, fmt.Errorf("%w: tags are required", ErrInvalidInput)
+        }
+        return func(bookmark *models.Bookmark, _ time.Time) {
+            for _, tag := range tags {
+                if !hasTag(bookmark.Tags, tag) {
+                    bookmark.Tags = append(bookmark.Tags, tag)
+                }
+            }
+        }, nil
+
+    case models.BulkRemoveTags:
+        if len(tags) == 0 {
+            return nil, fmt.Errorf("%w: tags are required", ErrInvalidInput)
+        }
+        return func(bookmark *models.Bookmark, _ time.Time) {
+            kept := bookmark.Tags[:0]
+            for _, tag := range bookmark.Tags {
+                if !hasTag(tags, tag) {
+                    kept = append(kept, tag)
+                }
+            }
+            bookmark.Tags = kept
+        }, nil
+
+    case models.BulkMove:
+        if request.CollectionID != 0 {
+            if _, err := s.collectionRepo.GetByID(request.CollectionID); err != nil {
+                return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+            }
+        }
+        return func(bookmark *models.Bookmark, _ time.Time) {
+            bookmark.CollectionID = request.CollectionID
+        }, nil
+
+    case models.BulkSetStatus:
+        if !readingStatuses[request.Status] {
+            return nil, fmt.Errorf("%w: unknown reading status %q", ErrInvalidInput, request.Status)
+        }
+        if request.Progress != nil && (*request.Progress < 0 || *request.Progress > 100) {
+            return nil, fmt.Errorf("%w: progress must be between 0 and 100", ErrInvalidInput)
+        }
+        return func(bookmark *models.Bookmark, now time.Time) {
+            updatedAt := bookmark.UpdatedAt
+            applyReadingState(bookmark, request.Status, request.Progress, now)
+            bookmark.UpdatedAt = updatedAt
+        }, nil
+
+    case models.BulkDelete:
+        return nil, nil
+    }
+    return nil, fmt.Errorf("%w: unknown bulk operation %q (use %s, %s, %s, %s or %s)", ErrInvalidInput, request.Operation,
+        models.BulkAddTags, models.BulkRemoveTags, models.BulkMove, models.BulkDelete, models.BulkSetStatus)
+}
+
+// bulkEqual reports whether a bulk operation left a bookmark as it was
+func bulkEqual(a, b models.Bookmark) bool {
+    if len(a.Tags) != len(b.Tags) {
+        return false
+    }
+    for i := range a.Tags {
+        if a.Tags[i] != b.Tags[i] {
+            return false
+        }
+    }
+    return a.CollectionID == b.CollectionID && a.Status == b.Status &&
+        a.Progress == b.Progress && a.ReadAt.Equal(b.ReadAt)
+}
+
+// hasTag reports whether tags holds tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+    for _, existing := range tags {
+        if strings.EqualFold(existing, tag) {
+            return true
+        }
+    }
+    return false
+}
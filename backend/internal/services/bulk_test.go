@@ -0,0 +1,255 @@
+package services
+
+import (
+    "errors"
+    "reflect"
+    "testing"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/repositories"
+)
+
+// missingID is a bookmark ID nothing is saved under
+const missingID = 42
+
+func TestBulkBookmarks_Operations(t *testing.T) {
+    tests := []struct {
+        name    string
+        request models.BulkRequest
+        first   string // result for the untagged, unfiled bookmark
+        second  string // result for the tagged, filed one
+        check   func(t *testing.T, service *hyprLinkService, first, second models.Bookmark)
+    }{
+        {
+            name:    "add tags",
+            request: models.BulkRequest{Operation: models.BulkAddTags, Tags: []string{"rust"}},
+            first:   models.BulkChanged,
+            second:  models.BulkUnchanged, // already tagged, in another case
+            check: func(t *testing.T, service *hyprLinkService, first, second models.Bookmark) {
+                if got := tagsOf(t, service, first.ID); !reflect.DeepEqual(got, []string{"go", "rust"}) {
+                    t.Errorf("Expected the tag added, got %v", got)
+                }
+                if got := tagsOf(t, service, second.ID); !reflect.DeepEqual(got, []string{"go", "Rust"}) {
+                    t.Errorf("Expected the tags left alone, got %v", got)
+                }
+            },
+        },
+        {
+            name:    "remove tags",
+            request: models.BulkRequest{Operation: models.BulkRemoveTags, Tags: []string{"RUST"}},
+            first:   models.BulkUnchanged,
+            second:  models.BulkChanged,
+            check: func(t *testing.T, service *hyprLinkService, first, second models.Bookmark) {
+                if got := tagsOf(t, service, second.ID); !reflect.DeepEqual(got, []string{"go"}) {
+                    t.Errorf("Expected the tag removed regardless of case, got %v", got)
+                }
+            },
+        },
+        {
+            name:    "move",
+            request: models.BulkRequest{Operation: models.BulkMove}, // collection set below
+            first:   models.BulkChanged,
+            second:  models.BulkUnchanged,
+            check: func(t *testing.T, service *hyprLinkService, first, second models.Bookmark) {
+                got, _ := service.GetBookmark(first.ID)
+                if got.CollectionID != second.CollectionID {
+                    t.Errorf("Expected the bookmark filed into %d, got %d", second.CollectionID, got.CollectionID)
+                }
+            },
+        },
+        {
+            name:    "set status",
+            request: models.BulkRequest{Operation: models.BulkSetStatus, Status: models.ReadingRead},
+            first:   models.BulkChanged,
+            second:  models.BulkChanged,
+            check: func(t *testing.T, service *hyprLinkService, first, second models.Bookmark) {
+                for _, id := range []int64{first.ID, second.ID} {
+                    got, _ := service.GetBookmark(id)
+                    if got.Status != models.ReadingRead || got.Progress != 100 || got.ReadAt.IsZero() {
+                        t.Errorf("Expected bookmark %d read, got %+v", id, got)
+                    }
+                }
+            },
+        },
+        {
+            name:    "delete",
+            request: models.BulkRequest{Operation: models.BulkDelete},
+            first:   models.BulkChanged,
+            second:  models.BulkChanged,
+            check: func(t *testing.T, service *hyprLinkService, first, second models.Bookmark) {
+                if bookmarks, _ := service.GetAllBookmarks(); len(bookmarks) != 0 {
+                    t.Errorf("Expected both bookmarks deleted, got %+v", bookmarks)
+                }
+                if trash, _ := service.GetTrash(); len(trash) != 2 {
+                    t.Errorf("Expected both bookmarks in the trash, got %+v", trash)
+                }
+            },
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            service, _ := newTestService(t)
+            collection := models.Collection{Name: "Work"}
+            if err := service.CreateCollection(&collection); err != nil {
+                t.Fatal(err)
+            }
+            first := mustCreateBookmark(t, service, newBookmark("https://one.example", "go"))
+            second := newBookmark("https://two.example", "go", "Rust")
+            second.CollectionID = collection.ID
+            second = mustCreateBookmark(t, service, second)
+            operationsBefore, _ := service.GetOperations(0)
+
+            request := tt.request
+            request.IDs = []int64{first.ID, second.ID, missingID, first.ID}
+            if request.Operation == models.BulkMove {
+                request.CollectionID = collection.ID
+            }
+            result, err := service.BulkBookmarks(request)
+            if err != nil {
+                t.Fatalf("BulkBookmarks failed: %v", err)
+            }
+
+            got := make(map[int64]string, len(result.Items))
+            for _, item := range result.Items {
+                got[item.ID] = item.Result
+            }
+            want := map[int64]string{first.ID: tt.first, second.ID: tt.second, missingID: models.BulkNotFound}
+            if !reflect.DeepEqual(got, want) || len(result.Items) != 3 {
+                t.Errorf("Expected results %v, got %+v", want, result.Items)
+            }
+            changed := 0
+            for _, outcome := range []string{tt.first, tt.second} {
+                if outcome == models.BulkChanged {
+                    changed++
+                }
+            }
+            if result.Matched != 2 || result.Changed != changed {
+                t.Errorf("Expected 2 matched and %d changed, got %d and %d", changed, result.Matched, result.Changed)
+            }
+            tt.check(t, service, first, second)
+
+            // The whole batch is one operation
+            if operations, _ := service.GetOperations(0); len(operations) != len(operationsBefore)+1 {
+                t.Errorf("Expected one operation recorded, had %d now %d", len(operationsBefore), len(operations))
+            }
+        })
+    }
+}
+
+func TestBulkBookmarks_UnchangedWritesNothing(t *testing.T) {
+    service, _ := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://one.example", "go"))
+    operationsBefore, _ := service.GetOperations(0)
+
+    result, err := service.BulkBookmarks(models.BulkRequest{IDs: []int64{bookmark.ID}, Operation: models.BulkAddTags, Tags: []string{"Go"}})
+    if err != nil {
+        t.Fatalf("BulkBookmarks failed: %v", err)
+    }
+    if result.Changed != 0 || len(result.Items) != 1 || result.Items[0].Result != models.BulkUnchanged || result.Items[0].Bookmark == nil {
+        t.Errorf("Expected the bookmark reported unchanged, got %+v", result)
+    }
+    if operations, _ := service.GetOperations(0); len(operations) != len(operationsBefore) {
+        t.Errorf("Expected no operation recorded, had %d now %d", len(operationsBefore), len(operations))
+    }
+}
+
+func TestBulkBookmarks_QueryAndUndo(t *testing.T) {
+    service, _ := newTestService(t)
+    kubernetes := mustCreateBookmark(t, service, newBookmark("https://kubernetes.io/docs"))
+    other := mustCreateBookmark(t, service, newBookmark("https://go.dev"))
+
+    result, err := service.BulkBookmarks(models.BulkRequest{Query: "kubernetes", Operation: models.BulkAddTags, Tags: []string{"k8s"}})
+    if err != nil {
+        t.Fatalf("BulkBookmarks failed: %v", err)
+    }
+    if result.Matched != 1 || result.Items[0].ID != kubernetes.ID {
+        t.Errorf("Expected only the matching bookmark picked, got %+v", result)
+    }
+    if got := tagsOf(t, service, other.ID); len(got) != 0 {
+        t.Errorf("Expected the other bookmark untouched, got %v", got)
+    }
+
+    deleted, err := service.BulkBookmarks(models.BulkRequest{IDs: []int64{kubernetes.ID, other.ID}, Operation: models.BulkDelete})
+    if err != nil || deleted.Changed != 2 {
+        t.Fatalf("Expected both deleted, got %+v (%v)", deleted, err)
+    }
+    undoOne(t, service, models.OperationDelete)
+    if bookmarks, _ := service.GetAllBookmarks(); len(bookmarks) != 2 {
+        t.Errorf("Expected one undo to bring both back, got %d", len(bookmarks))
+    }
+    if got := tagsOf(t, service, kubernetes.ID); !reflect.DeepEqual(got, []string{"k8s"}) {
+        t.Errorf("Expected the bookmark back as it was deleted, got %v", got)
+    }
+}
+
+func TestBulkBookmarks_InvalidRequests(t *testing.T) {
+    service, _ := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://one.example"))
+    ids := []int64{bookmark.ID}
+
+    requests := map[string]models.BulkRequest{
+        "ids and query":      {IDs: ids, Query: "one", Operation: models.BulkDelete},
+        "nothing picked":     {Operation: models.BulkDelete},
+        "unknown operation":  {IDs: ids, Operation: "archive"},
+        "no tags":            {IDs: ids, Operation: models.BulkAddTags, Tags: []string{" "}},
+        "unknown collection": {IDs: ids, Operation: models.BulkMove, CollectionID: missingID},
+        "unknown status":     {IDs: ids, Operation: models.BulkSetStatus, Status: "skimmed"},
+    }
+    for name, request := range requests {
+        if _, err := service.BulkBookmarks(request); !errors.Is(err, ErrInvalidInput) {
+            t.Errorf("%s: expected invalid input, got %v", name, err)
+        }
+    }
+}
+
+// failingWrites writes only the first bookmark of a batch and then fails,
+// as a write interrupted partway would
+type failingWrites struct {
+    repositories.BookmarkRepository
+}
+
+var errWriteFailed = errors.New("disk full")
+
+func (r failingWrites) UpdateMany(bookmarks []models.Bookmark) error {
+    if err := r.BookmarkRepository.UpdateMany(bookmarks[:1]); err != nil {
+        return err
+    }
+    return errWriteFailed
+}
+
+func (r failingWrites) DeleteMany(ids []int64) error {
+    if err := r.BookmarkRepository.DeleteMany(ids[:1]); err != nil {
+        return err
+    }
+    return errWriteFailed
+}
+
+func TestBulkBookmarks_PartialFailure(t *testing.T) {
+    for _, operation := range []string{models.BulkAddTags, models.BulkDelete} {
+        t.Run(operation, func(t *testing.T) {
+            service, _ := newTestService(t)
+            first := mustCreateBookmark(t, service, newBookmark("https://example.com/a"))
+            second := mustCreateBookmark(t, service, newBookmark("https://example.com/b"))
+            service.bookmarkRepo = failingWrites{service.bookmarkRepo}
+
+            request := models.BulkRequest{IDs: []int64{first.ID, second.ID}, Operation: operation, Tags: []string{"new"}}
+            result, err := service.BulkBookmarks(request)
+            if !errors.Is(err, errWriteFailed) || result == nil {
+                t.Fatalf("Expected the failure returned with a result, got %+v (%v)", result, err)
+            }
+            if result.Changed != 1 || result.Error == "" {
+                t.Errorf("Expected one bookmark changed and the error reported, got %+v", result)
+            }
+            if got := result.Items[0]; got.ID != first.ID || got.Result != models.BulkChanged {
+                t.Errorf("Expected the first bookmark changed, got %+v", got)
+            }
+            got := result.Items[1]
+            if got.ID != second.ID || got.Result != models.BulkFailed {
+                t.Errorf("Expected the second bookmark failed, got %+v", got)
+            }
+            if got.Bookmark == nil || len(got.Bookmark.Tags) != 0 {
+                t.Errorf("Expected the second bookmark reported as it is, got %+v", got.Bookmark)
+            }
+        })
+    }
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "hyprlnk/internal/models"
+)
+
+// segmentBatchSize is how many bookmarks BulkSegmentBookmarks classifies
+// and stores at a time
+const segmentBatchSize = 50
+
+func (s *hyprLinkService) BulkSegmentBookmarks() (int, error) {
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return 0, err
+    }
+
+    var untagged []models.Bookmark
+    for _, bookmark := range bookmarks {
+        if len(bookmark.Tags) == 0 {
+            untagged = append(untagged, bookmark)
+        }
+    }
+
+    processedCount := 0
+    for start := 0; start < len(untagged); start += segmentBatchSize {
+        batch := untagged[start:min(start+segmentBatchSize, len(untagged))]
+        segmentations, err := s.classify(context.Background(), batch)
+        if err != nil {
+            return processedCount, fmt.Errorf("failed to classify bookmarks: %w", err)
+        }
+
+        var updated []models.Bookmark
+        now := time.Now()
+        for i, bookmark := range batch {
+            if !applySegmentation(&bookmark.Tags, &bookmark.Description, segmentations[i]) {
+                continue
+            }
+            bookmark.UpdatedAt = now
+            updated = append(updated, bookmark)
+        }
+        if err := s.bookmarkRepo.UpdateManyUntracked(updated); err != nil {
+            return processedCount, err
+        }
+        processedCount += len(updated)
+    }
+
+    return processedCount, nil
+}
+
+// SuggestTags ranks tags for a bookmark that may not be saved yet, using
+// the model trained on existing bookmarks
+func (s *hyprLinkService) SuggestTags(bookmark models.Bookmark, limit int) ([]models.TagSuggestion, error) {
+    return s.suggester.Suggest(bookmark, limit)
+}
+
+// SuggestBookmarkTags ranks tags a saved bookmark doesn't have yet
+func (s *hyprLinkService) SuggestBookmarkTags(id int64, limit int) ([]models.TagSuggestion, error) {
+    bookmark, err := s.bookmarkRepo.GetByID(id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+    return s.suggester.Suggest(*bookmark, limit)
+}
+
+// classifyImports fills in tags, and blank descriptions, for untagged
+// bookmarks that importing would create. Bookmarks the import skips or
+// merges into existing ones are left alone, so re-imports cost nothing.
+func (s *hyprLinkService) classifyImports(ctx context.Context, items []models.ImportedBookmark, mode string) ([]models.ImportedBookmark, error) {
+    preview, err := s.importRepo.Import(items, mode, true, nil)
+    if err != nil {
+        return items, err
+    }
+    creates := make(map[string]bool, len(preview.Creates))
+    for _, change := range preview.Creates {
+        creates[change.URL] = true
+    }
+
+    var indexes []int
+    var targets []models.Bookmark
+    for i, item := range items {
+        if len(item.Tags) > 0 || !creates[item.URL] {
+            continue
+        }
+        indexes = append(indexes, i)
+        targets = append(targets, models.Bookmark{URL: item.URL, Title: item.Title, Description: item.Description})
+    }
+    if len(targets) == 0 {
+        return items, nil
+    }
+
+    segmentations, err := s.classify(ctx, targets)
+    if err != nil {
+        return items, fmt.Errorf("failed to classify imported bookmarks: %w", err)
+    }
+
+    classified := append([]models.ImportedBookmark{}, items...)
+    for j, i := range indexes {
+        applySegmentation(&classified[i].Tags, &classified[i].Description, segmentations[j])
+    }
+    return classified, nil
+}
+
+// classify runs the classifier, making sure it returned a result for every
+// bookmark before callers index into the results
+func (s *hyprLinkService) classify(ctx context.Context, bookmarks []models.Bookmark) ([]models.AISegmentation, error) {
+    segmentations, err := s.classifier.Classify(ctx, bookmarks)
+    if err != nil {
+        return nil, err
+    }
+    if len(segmentations) != len(bookmarks) {
+        return nil, fmt.Errorf("classifier returned %d results for %d bookmarks", len(segmentations), len(bookmarks))
+    }
+    return segmentations, nil
+}
+
+// applySegmentation sets tags from a classifier result, falling back to its
+// category, and fills a blank description. It reports whether tags were set.
+func applySegmentation(tags *[]string, description *string, segmentation models.AISegmentation) bool {
+    suggested := segmentation.Tags
+    if len(suggested) == 0 && segmentation.Category != "" {
+        suggested = []string{segmentation.Category}
+    }
+    if len(suggested) == 0 {
+        return false
+    }
+
+    *tags = suggested
+    if *description == "" {
+        *description = segmentation.Description
+    }
+    return true
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+    "context"
+    "testing"
+
+    "hyprlnk/internal/models"
+)
+
+// shortClassifier breaks the Classifier contract by dropping the last result
+type shortClassifier struct{}
+
+func (shortClassifier) Classify(ctx context.Context, bookmarks []models.Bookmark) ([]models.AISegmentation, error) {
+    results := make([]models.AISegmentation, max(len(bookmarks)-1, 0))
+    for i := range results {
+        results[i] = models.AISegmentation{Tags: []string{"short"}}
+    }
+    return results, nil
+}
+
+func TestBulkSegmentBookmarks(t *testing.T) {
+    service, _ := newTestService(t)
+    untagged := mustCreateBookmark(t, service, newBookmark("https://github.com/golang/go"))
+    tagged := mustCreateBookmark(t, service, newBookmark("https://youtube.com/watch", "mine"))
+
+    count, err := service.BulkSegmentBookmarks()
+    if err != nil {
+        t.Fatalf("BulkSegmentBookmarks failed: %v", err)
+    }
+    if count != 1 {
+        t.Errorf("Expected 1 bookmark tagged, got %d", count)
+    }
+    if tags := tagsOf(t, service, untagged.ID); len(tags) == 0 || tags[0] != "development" {
+        t.Errorf("Expected the untagged bookmark to be classified, got %v", tags)
+    }
+    if tags := tagsOf(t, service, tagged.ID); len(tags) != 1 || tags[0] != "mine" {
+        t.Errorf("Expected the tagged bookmark to be left alone, got %v", tags)
+    }
+}
+
+func TestBulkSegmentBookmarks_ShortResults(t *testing.T) {
+    service, _ := newTestService(t)
+    service.classifier = shortClassifier{}
+    first := mustCreateBookmark(t, service, newBookmark("https://one.example"))
+    mustCreateBookmark(t, service, newBookmark("https://two.example"))
+
+    if _, err := service.BulkSegmentBookmarks(); err == nil {
+        t.Fatal("Expected an error when the classifier returns too few results")
+    }
+    if tags := tagsOf(t, service, first.ID); len(tags) != 0 {
+        t.Errorf("Expected nothing tagged from a short result, got %v", tags)
+    }
+}
+
+func TestClassifyImports_ShortResults(t *testing.T) {
+    service, _ := newTestService(t)
+    service.classifier = shortClassifier{}
+
+    items := []models.ImportedBookmark{{URL: "https://one.example"}, {URL: "https://two.example"}}
+    classified, err := service.classifyImports(context.Background(), items, models.ImportSkipExisting)
+    if err == nil {
+        t.Fatal("Expected an error when the classifier returns too few results")
+    }
+    if len(classified) != 2 || len(classified[0].Tags) != 0 {
+        t.Errorf("Expected the items back unclassified, got %+v", classified)
+    }
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "slices"
+    "testing"
+
+    "hyprlnk/internal/models"
+)
+
+// mustCreateCollection saves a collection under parentID, failing the test
+// on error
+func mustCreateCollection(t *testing.T, service *hyprLinkService, name string, parentID int64) models.Collection {
+    t.Helper()
+    collection := models.Collection{Name: name, ParentID: parentID}
+    if err := service.CreateCollection(&collection); err != nil {
+        t.Fatalf("Failed to create collection %s: %v", name, err)
+    }
+    return collection
+}
+
+// childNames returns the names of a collection's children, in order
+func childNames(t *testing.T, service *hyprLinkService, parentID int64) []string {
+    t.Helper()
+    collections, err := service.GetAllCollections()
+    if err != nil {
+        t.Fatal(err)
+    }
+    names := []string{}
+    for _, collection := range collections {
+        if collection.ParentID == parentID {
+            names = append(names, collection.Name)
+        }
+    }
+    return names
+}
+
+func TestCollections_CRUD(t *testing.T) {
+    service, _ := newTestService(t)
+
+    if err := service.CreateCollection(&models.Collection{Name: "  "}); !errors.Is(err, ErrInvalidInput) {
+        t.Errorf("Expected a blank name rejected, got %v", err)
+    }
+
+    work := mustCreateCollection(t, service, "Work", 0)
+    mustCreateCollection(t, service, "Home", 0)
+    if got := childNames(t, service, 0); !slices.Equal(got, []string{"Work", "Home"}) {
+        t.Errorf("Expected Work, Home in creation order, got %v", got)
+    }
+
+    work.Name = "Job"
+    if err := service.UpdateCollection(&work); err != nil {
+        t.Fatalf("Update failed: %v", err)
+    }
+    if got, err := service.collectionRepo.GetByID(work.ID); err != nil || got.Name != "Job" {
+        t.Errorf("Expected the collection renamed, got %+v (%v)", got, err)
+    }
+
+    if _, err := service.GetCollectionBookmarks(42, false); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected an unknown collection not found, got %v", err)
+    }
+    if err := service.DeleteCollection(42); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected deleting an unknown collection not found, got %v", err)
+    }
+}
+
+func TestMoveCollection(t *testing.T) {
+    service, _ := newTestService(t)
+    a := mustCreateCollection(t, service, "A", 0)
+    b := mustCreateCollection(t, service, "B", 0)
+    c := mustCreateCollection(t, service, "C", 0)
+    child := mustCreateCollection(t, service, "A1", a.ID)
+    grandchild := mustCreateCollection(t, service, "A1a", child.ID)
+
+    if _, err := service.MoveCollection(c.ID, 0, 0); err != nil {
+        t.Fatalf("Move failed: %v", err)
+    }
+    if got := childNames(t, service, 0); !slices.Equal(got, []string{"C", "A", "B"}) {
+        t.Errorf("Expected C moved to the front, got %v", got)
+    }
+
+    if _, err := service.MoveCollection(b.ID, a.ID, -1); err != nil {
+        t.Fatalf("Move failed: %v", err)
+    }
+    if got := childNames(t, service, a.ID); !slices.Equal(got, []string{"A1", "B"}) {
+        t.Errorf("Expected B appended under A, got %v", got)
+    }
+    if got := childNames(t, service, 0); !slices.Equal(got, []string{"C", "A"}) {
+        t.Errorf("Expected the gap B left closed, got %v", got)
+    }
+
+    // A collection can't go into itself or anything below it
+    for _, parentID := range []int64{a.ID, child.ID, grandchild.ID} {
+        if _, err := service.MoveCollection(a.ID, parentID, -1); !errors.Is(err, ErrInvalidInput) {
+            t.Errorf("Expected moving A under %d rejected, got %v", parentID, err)
+        }
+    }
+    if _, err := service.MoveCollection(a.ID, 42, -1); !errors.Is(err, ErrInvalidInput) {
+        t.Errorf("Expected an unknown parent rejected, got %v", err)
+    }
+    if _, err := service.MoveCollection(42, 0, -1); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected an unknown collection not found, got %v", err)
+    }
+}
+
+func TestDeleteCollection_MovesContentsUp(t *testing.T) {
+    service, _ := newTestService(t)
+    parent := mustCreateCollection(t, service, "Parent", 0)
+    mustCreateCollection(t, service, "Sibling", parent.ID)
+    doomed := mustCreateCollection(t, service, "Doomed", parent.ID)
+    mustCreateCollection(t, service, "Child 1", doomed.ID)
+    mustCreateCollection(t, service, "Child 2", doomed.ID)
+
+    var ids []int64
+    for _, url := range []string{"https://example.com/a", "https://example.com/b"} {
+        bookmark := newBookmark(url)
+        bookmark.CollectionID = doomed.ID
+        ids = append(ids, mustCreateBookmark(t, service, bookmark).ID)
+    }
+    operationsBefore, _ := service.GetOperations(0)
+
+    if err := service.DeleteCollection(doomed.ID); err != nil {
+        t.Fatalf("Delete failed: %v", err)
+    }
+
+    if got := childNames(t, service, parent.ID); !slices.Equal(got, []string{"Sibling", "Child 1", "Child 2"}) {
+        t.Errorf("Expected the children moved up after the siblings, got %v", got)
+    }
+    collections, _ := service.GetAllCollections()
+    for _, collection := range collections {
+        if collection.ParentID == parent.ID && collection.Position >= 3 {
+            t.Errorf("Expected positions renumbered, %s is at %d", collection.Name, collection.Position)
+        }
+    }
+    for _, id := range ids {
+        bookmark, err := service.GetBookmark(id)
+        if err != nil || bookmark.CollectionID != parent.ID {
+            t.Errorf("Expected bookmark %d moved to the parent, got %+v (%v)", id, bookmark, err)
+        }
+    }
+
+    // Both bookmarks move in a single operation
+    operations, _ := service.GetOperations(0)
+    if len(operations) != len(operationsBefore)+1 || len(operations[0].Items) != 2 {
+        t.Errorf("Expected one operation moving both bookmarks, got %+v", operations[0])
+    }
+}
+
+func TestImport_FolderHierarchy(t *testing.T) {
+    service, _ := newTestService(t)
+    items := []models.ImportedBookmark{
+        {URL: "https://example.com/go", Title: "Go", FolderPath: []string{"Dev", "Go"}},
+        {URL: "https://example.com/rust", Title: "Rust", FolderPath: []string{"Dev", "Rust"}},
+        {URL: "https://example.com/tools", Title: "Tools", FolderPath: []string{"Dev"}},
+    }
+    diff, _, err := service.importBookmarks(context.Background(), items, models.ImportSkipExisting, false)
+    if err != nil {
+        t.Fatalf("Import failed: %v", err)
+    }
+
+    collections, _ := service.GetAllCollections()
+    byName := make(map[string]models.Collection)
+    ids := make(map[int64]bool)
+    for _, collection := range collections {
+        byName[collection.Name] = collection
+        ids[collection.ID] = true
+    }
+    if len(collections) != 3 || len(ids) != 3 {
+        t.Fatalf("Expected Dev, Go and Rust created once each, got %+v", collections)
+    }
+    dev := byName["Dev"]
+    if dev.ParentID != 0 || byName["Go"].ParentID != dev.ID || byName["Rust"].ParentID != dev.ID {
+        t.Errorf("Expected Go and Rust nested under Dev, got %+v", collections)
+    }
+
+    want := map[string]int64{"Go": byName["Go"].ID, "Rust": byName["Rust"].ID, "Tools": dev.ID}
+    for _, create := range diff.Creates {
+        if got := create.After.CollectionID; got != want[create.After.Title] {
+            t.Errorf("Expected %s in collection %d, got %d", create.After.Title, want[create.After.Title], got)
+        }
+    }
+}
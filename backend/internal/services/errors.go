@@ -0,0 +1,27 @@
+package services
+
+import "errors"
+
+// ErrInvalidInput marks errors caused by bad client input rather than
+// storage failures, so handlers can answer 400 instead of 500.
+var ErrInvalidInput = errors.New("invalid input")
+
+// ErrNotFound marks lookups of things that don't exist (404)
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict marks requests the current state doesn't allow (409)
+var ErrConflict = errors.New("conflict")
+
+// kindError tags err with one of the errors above without changing its message
+type kindError struct {
+    kind error
+    err  error
+}
+
+func (e kindError) Error() string {
+    return e.err.Error()
+}
+
+func (e kindError) Unwrap() []error {
+    return []error{e.kind, e.err}
+}
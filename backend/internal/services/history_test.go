@@ -0,0 +1,115 @@
+package services
+
+import (
+    "errors"
+    "slices"
+    "sort"
+    "testing"
+    "time"
+
+    "hyprlnk/internal/models"
+)
+
+func TestSameDayLastMonth(t *testing.T) {
+    tests := []struct {
+        day  string
+        want string
+    }{
+        {"2026-03-15", "2026-02-15"},
+        {"2026-03-31", "2026-02-28"},
+        {"2024-03-31", "2024-02-29"},
+        {"2026-05-31", "2026-04-30"},
+        {"2026-01-31", "2025-12-31"},
+        {"2026-02-28", "2026-01-28"},
+    }
+    for _, tt := range tests {
+        day, _ := time.Parse("2006-01-02", tt.day)
+        if got := sameDayLastMonth(day).Format("2006-01-02"); got != tt.want {
+            t.Errorf("sameDayLastMonth(%s) = %s, want %s", tt.day, got, tt.want)
+        }
+    }
+}
+
+func TestHistoryLocation(t *testing.T) {
+    service, _ := newTestService(t)
+    settings, _ := service.settingsRepo.Get()
+    settings.Timezone = "Europe/Berlin"
+    if err := service.settingsRepo.Update(settings); err != nil {
+        t.Fatal(err)
+    }
+
+    if loc, err := service.HistoryLocation(""); err != nil || loc.String() != "Europe/Berlin" {
+        t.Errorf("Expected the timezone setting, got %v (%v)", loc, err)
+    }
+    if loc, err := service.HistoryLocation("Asia/Tokyo"); err != nil || loc.String() != "Asia/Tokyo" {
+        t.Errorf("Expected the given timezone, got %v (%v)", loc, err)
+    }
+    if _, err := service.HistoryLocation("Mars/Olympus_Mons"); !errors.Is(err, ErrInvalidInput) {
+        t.Errorf("Expected an unknown timezone rejected, got %v", err)
+    }
+}
+
+// visitedURLs returns the URLs of entries, sorted
+func visitedURLs(entries []models.HistoryEntry) []string {
+    urls := []string{}
+    for _, entry := range entries {
+        urls = append(urls, entry.URL)
+    }
+    sort.Strings(urls)
+    return urls
+}
+
+func TestCalendarHistory(t *testing.T) {
+    service, _ := newTestService(t)
+    settings, _ := service.settingsRepo.Get()
+    settings.Timezone = "America/New_York"
+    if err := service.settingsRepo.Update(settings); err != nil {
+        t.Fatal(err)
+    }
+    // 23:30 on Mar 31 in New York, already Apr 1 in UTC. Clocks went
+    // forward on Mar 8, so the month window spans the change.
+    service.now = func() time.Time { return time.Date(2026, 4, 1, 3, 30, 0, 0, time.UTC) }
+
+    visits := map[string]time.Time{
+        "https://late-yesterday": time.Date(2026, 3, 31, 3, 59, 0, 0, time.UTC), // Mar 30, 23:59 EDT
+        "https://midnight":       time.Date(2026, 3, 31, 4, 0, 0, 0, time.UTC),  // Mar 31, 00:00 EDT
+        "https://now":            time.Date(2026, 4, 1, 3, 29, 0, 0, time.UTC),  // Mar 31, 23:29 EDT
+        "https://tomorrow":       time.Date(2026, 4, 1, 4, 0, 0, 0, time.UTC),   // Apr 1, 00:00 EDT
+        "https://week-start":     time.Date(2026, 3, 25, 4, 0, 0, 0, time.UTC),  // Mar 25, 00:00 EDT
+        "https://before-week":    time.Date(2026, 3, 25, 3, 59, 0, 0, time.UTC), // Mar 24, 23:59 EDT
+        "https://month-start":    time.Date(2026, 3, 1, 5, 0, 0, 0, time.UTC),   // Mar 1, 00:00 EST
+        "https://before-month":   time.Date(2026, 3, 1, 4, 59, 0, 0, time.UTC),  // Feb 28, 23:59 EST
+    }
+    var entries []models.HistoryEntry
+    for url, at := range visits {
+        entries = append(entries, models.HistoryEntry{URL: url, Title: url, VisitCount: 1, LastVisitTime: at})
+    }
+    if _, err := service.historyRepo.Sync(entries); err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        name  string
+        query func() ([]models.HistoryEntry, error)
+        want  []string
+    }{
+        {"today", service.GetTodaysHistory, []string{"https://midnight", "https://now"}},
+        {"week", service.GetWeekHistory, []string{
+            "https://late-yesterday", "https://midnight", "https://now", "https://week-start",
+        }},
+        // Mar 31 reaches back to the whole of March, not Mar 4
+        {"month", service.GetMonthHistory, []string{
+            "https://before-week", "https://late-yesterday", "https://midnight",
+            "https://month-start", "https://now", "https://week-start",
+        }},
+    }
+    for _, tt := range tests {
+        history, err := tt.query()
+        if err != nil {
+            t.Fatalf("%s: %v", tt.name, err)
+        }
+        if got := visitedURLs(history); !slices.Equal(got, tt.want) {
+            t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+        }
+    }
+}
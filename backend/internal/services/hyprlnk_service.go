@@ -1,67 +1,499 @@
 package services
 
 import (
+    "context"
+    "fmt"
+    "slices"
     "strings"
+    "sync"
     "time"
 
+    "hyprlnk/internal/classify"
+    "hyprlnk/internal/fetch"
+    "hyprlnk/internal/jobs"
+    "hyprlnk/internal/linkcheck"
     "hyprlnk/internal/models"
     "hyprlnk/internal/repositories"
+    "hyprlnk/internal/search"
 )
 
 type hyprLinkService struct {
     bookmarkRepo   repositories.BookmarkRepository
+    collectionRepo repositories.CollectionRepository
+    tagRepo        repositories.TagRepository
     sessionRepo    repositories.SessionRepository
     historyRepo    repositories.HistoryRepository
     linkClickRepo  repositories.LinkClickRepository
     importRepo     repositories.ImportRepository
+    settingsRepo   repositories.SettingsRepository
+    classifier     classify.Classifier
+    suggester      *classify.Bayes
+    ruleRepo       repositories.RuleRepository
+    metadataRepo   repositories.MetadataRepository
+    archiveRepo    repositories.ArchiveRepository
+    linkHealthRepo repositories.LinkHealthRepository
+    annotationRepo repositories.AnnotationRepository
+    revisionRepo   repositories.RevisionRepository
+    trashRepo      repositories.TrashRepository
+    versionRepo    repositories.SessionVersionRepository
+    fetcher        *fetch.Fetcher     // nil when page fetching is disabled
+    archivePages   bool               // archive pages in the background as they are fetched
+    linkChecker    *linkcheck.Checker // nil when link checking is disabled
+    enrichWake     chan struct{}
+    linkWake       chan struct{}
+    textIndex      *search.Index
+    indexOnce      sync.Once
+    jobs           *jobs.Runner
+    now            func() time.Time // the clock calendar-day queries go by
+
+    annotationTextIndex *search.Index
+    annotationIndexOnce sync.Once
+}
+
+// Config is what the service is built from. The repositories and the
+// classifier are required; a nil Fetcher or LinkChecker turns page
+// fetching or link checking off.
+type Config struct {
+    BookmarkRepo   repositories.BookmarkRepository
+    CollectionRepo repositories.CollectionRepository
+    TagRepo        repositories.TagRepository
+    SessionRepo    repositories.SessionRepository
+    HistoryRepo    repositories.HistoryRepository
+    LinkClickRepo  repositories.LinkClickRepository
+    ImportRepo     repositories.ImportRepository
+    SettingsRepo   repositories.SettingsRepository
+    JobRepo        repositories.JobRepository
+    RuleRepo       repositories.RuleRepository
+    MetadataRepo   repositories.MetadataRepository
+    ArchiveRepo    repositories.ArchiveRepository
+    LinkHealthRepo repositories.LinkHealthRepository
+    AnnotationRepo repositories.AnnotationRepository
+    RevisionRepo   repositories.RevisionRepository
+    TrashRepo      repositories.TrashRepository
+    VersionRepo    repositories.SessionVersionRepository
+
+    Classifier   classify.Classifier
+    Suggester    *classify.Bayes    // backs tag suggestions
+    Fetcher      *fetch.Fetcher     // nil disables page fetching
+    ArchivePages bool               // archive pages as they are fetched; needs Fetcher
+    LinkChecker  *linkcheck.Checker // nil disables link checking
 }
 
-func NewHyprLinkService(
-    bookmarkRepo repositories.BookmarkRepository,
-    sessionRepo repositories.SessionRepository,
-    historyRepo repositories.HistoryRepository,
-    linkClickRepo repositories.LinkClickRepository,
-    importRepo repositories.ImportRepository,
-) HyprLinkService {
-    return &hyprLinkService{
-        bookmarkRepo:  bookmarkRepo,
-        sessionRepo:   sessionRepo,
-        historyRepo:   historyRepo,
-        linkClickRepo: linkClickRepo,
-        importRepo:    importRepo,
+func NewHyprLinkService(config Config) HyprLinkService {
+    service := &hyprLinkService{
+        bookmarkRepo:   config.BookmarkRepo,
+        collectionRepo: config.CollectionRepo,
+        tagRepo:        config.TagRepo,
+        sessionRepo:    config.SessionRepo,
+        historyRepo:    config.HistoryRepo,
+        linkClickRepo:  config.LinkClickRepo,
+        importRepo:     config.ImportRepo,
+        settingsRepo:   config.SettingsRepo,
+        classifier:     config.Classifier,
+        suggester:      config.Suggester,
+        ruleRepo:       config.RuleRepo,
+        metadataRepo:   config.MetadataRepo,
+        archiveRepo:    config.ArchiveRepo,
+        linkHealthRepo: config.LinkHealthRepo,
+        annotationRepo: config.AnnotationRepo,
+        revisionRepo:   config.RevisionRepo,
+        trashRepo:      config.TrashRepo,
+        versionRepo:    config.VersionRepo,
+        fetcher:        config.Fetcher,
+        archivePages:   config.ArchivePages && config.Fetcher != nil,
+        linkChecker:    config.LinkChecker,
+        enrichWake:     make(chan struct{}, 1),
+        linkWake:       make(chan struct{}, 1),
+        textIndex:      search.New(),
+        jobs:           jobs.NewRunner(config.JobRepo),
+        now:            time.Now,
+
+        annotationTextIndex: search.New(),
     }
+    service.jobs.Register(jobTypeImport, service.runImportJob)
+    return service
 }
 
 func (s *hyprLinkService) GetAllBookmarks() ([]models.Bookmark, error) {
     return s.bookmarkRepo.GetAll()
 }
 
-func (s *hyprLinkService) CreateBookmark(bookmark *models.Bookmark) error {
-    return s.bookmarkRepo.Create(bookmark)
+func (s *hyprLinkService) GetBookmark(id int64) (*models.Bookmark, error) {
+    bookmark, err := s.bookmarkRepo.GetByID(id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+    return bookmark, nil
+}
+
+// LookupBookmark finds the bookmark for a page by its canonical URL, so
+// tracking parameters and the like don't matter
+func (s *hyprLinkService) LookupBookmark(rawURL string) (*models.Bookmark, error) {
+    if strings.TrimSpace(rawURL) == "" {
+        return nil, fmt.Errorf("%w: url is required", ErrInvalidInput)
+    }
+    bookmark, err := s.bookmarkRepo.GetByURL(rawURL)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+    return bookmark, nil
+}
+
+func (s *hyprLinkService) CreateBookmark(bookmark *models.Bookmark) (bool, error) {
+    if err := checkReadingState(bookmark); err != nil {
+        return false, err
+    }
+    engine, err := s.ruleEngine()
+    if err != nil {
+        return false, err
+    }
+    engine.Apply(bookmark)
+    created, err := s.bookmarkRepo.Create(bookmark)
+    if err != nil {
+        return false, err
+    }
+    s.wakeWorkers()
+    return created, s.linkAnnotations(*bookmark)
 }
 
 func (s *hyprLinkService) UpdateBookmark(bookmark *models.Bookmark) error {
-    return s.bookmarkRepo.Update(bookmark)
+    if err := checkReadingState(bookmark); err != nil {
+        return err
+    }
+    previous, err := s.bookmarkRepo.GetByID(bookmark.ID)
+    if err != nil {
+        return kindError{kind: ErrNotFound, err: err}
+    }
+    if err := s.bookmarkRepo.Update(bookmark); err != nil {
+        return err
+    }
+    s.wakeWorkers()
+    if bookmark.URL != previous.URL {
+        return s.moveAnnotations([]int64{bookmark.ID}, bookmark)
+    }
+    return nil
 }
 
+// DeleteBookmark deletes a bookmark; its annotations stay on the page
 func (s *hyprLinkService) DeleteBookmark(id int64) error {
-    return s.bookmarkRepo.Delete(id)
+    if err := s.bookmarkRepo.Delete(id); err != nil {
+        return err
+    }
+    return s.moveAnnotations([]int64{id}, nil)
 }
 
+// SearchBookmarks matches bookmarks' titles, URLs, descriptions and tags,
+// followed by bookmarks whose archived page text matches, best first, and
+// then bookmarks with matching notes or highlights
 func (s *hyprLinkService) SearchBookmarks(query string) ([]models.Bookmark, error) {
-    return s.bookmarkRepo.Search(query)
+    results, err := s.bookmarkRepo.Search(query)
+    if err != nil {
+        return nil, err
+    }
+    hits := s.searchIndex().Search(query)
+    annotations, err := s.GetAnnotations(0, "", "", query)
+    if err != nil {
+        return nil, err
+    }
+    for _, annotation := range annotations {
+        if annotation.BookmarkID != 0 {
+            hits = append(hits, search.Hit{ID: annotation.BookmarkID})
+        }
+    }
+    if len(hits) == 0 {
+        return results, nil
+    }
+
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+    byID := make(map[int64]models.Bookmark, len(bookmarks))
+    for _, bookmark := range bookmarks {
+        byID[bookmark.ID] = bookmark
+    }
+    found := make(map[int64]bool, len(results))
+    for _, bookmark := range results {
+        found[bookmark.ID] = true
+    }
+    for _, hit := range hits {
+        if bookmark, ok := byID[hit.ID]; ok && !found[hit.ID] {
+            results = append(results, bookmark)
+            found[hit.ID] = true
+        }
+    }
+    return results, nil
+}
+
+func (s *hyprLinkService) FindDuplicateBookmarks() ([]models.DuplicateGroup, error) {
+    return s.bookmarkRepo.FindDuplicates()
+}
+
+// MergeBookmarks folds bookmarks into targetID, or the oldest of them when
+// it is 0; the others go to the trash
+func (s *hyprLinkService) MergeBookmarks(ids []int64, targetID int64) (*models.Bookmark, error) {
+    ids = uniqueIDs(ids)
+    if len(ids) < 2 {
+        return nil, fmt.Errorf("%w: at least two different bookmark IDs are required to merge", ErrInvalidInput)
+    }
+    merged, err := s.bookmarkRepo.Merge(ids, targetID)
+    if err != nil {
+        return nil, err
+    }
+    return merged, s.moveAnnotations(ids, merged)
+}
+
+// uniqueIDs returns ids without repeats, in their first order
+func uniqueIDs(ids []int64) []int64 {
+    seen := make(map[int64]bool, len(ids))
+    unique := make([]int64, 0, len(ids))
+    for _, id := range ids {
+        if !seen[id] {
+            seen[id] = true
+            unique = append(unique, id)
+        }
+    }
+    return unique
+}
+
+// MergeAllDuplicates merges every duplicate group into its oldest bookmark
+func (s *hyprLinkService) MergeAllDuplicates() ([]models.Bookmark, error) {
+    groups, err := s.bookmarkRepo.FindDuplicates()
+    if err != nil {
+        return nil, err
+    }
+
+    merged := make([]models.Bookmark, 0, len(groups))
+    for _, group := range groups {
+        ids := make([]int64, 0, len(group.Bookmarks))
+        for _, bookmark := range group.Bookmarks {
+            ids = append(ids, bookmark.ID)
+        }
+
+        bookmark, err := s.bookmarkRepo.Merge(ids, 0)
+        if err != nil {
+            return merged, err
+        }
+        if err := s.moveAnnotations(ids, bookmark); err != nil {
+            return merged, err
+        }
+        merged = append(merged, *bookmark)
+    }
+
+    return merged, nil
+}
+
+// MoveBookmark files a bookmark into a collection; 0 removes it from any collection
+func (s *hyprLinkService) MoveBookmark(id, collectionID int64) (*models.Bookmark, error) {
+    bookmark, err := s.bookmarkRepo.GetByID(id)
+    if err != nil {
+        return nil, err
+    }
+
+    if collectionID != 0 {
+        if _, err := s.collectionRepo.GetByID(collectionID); err != nil {
+            return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+        }
+    }
+
+    bookmark.CollectionID = collectionID
+    if err := s.bookmarkRepo.Update(bookmark); err != nil {
+        return nil, err
+    }
+    return bookmark, nil
+}
+
+func (s *hyprLinkService) GetAllCollections() ([]models.Collection, error) {
+    return s.collectionRepo.GetAll()
+}
+
+// GetCollectionTree returns top-level collections with their children nested
+func (s *hyprLinkService) GetCollectionTree() ([]models.CollectionNode, error) {
+    collections, err := s.collectionRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+
+    counts := make(map[int64]int)
+    for _, bookmark := range bookmarks {
+        counts[bookmark.CollectionID]++
+    }
+
+    // collections is ordered by parent then position, so children stay ordered
+    children := make(map[int64][]models.Collection)
+    for _, collection := range collections {
+        children[collection.ParentID] = append(children[collection.ParentID], collection)
+    }
+
+    var build func(parentID int64) []models.CollectionNode
+    build = func(parentID int64) []models.CollectionNode {
+        nodes := make([]models.CollectionNode, 0, len(children[parentID]))
+        for _, collection := range children[parentID] {
+            nodes = append(nodes, models.CollectionNode{
+                Collection:    collection,
+                BookmarkCount: counts[collection.ID],
+                Children:      build(collection.ID),
+            })
+        }
+        return nodes
+    }
+
+    return build(0), nil
+}
+
+// GetCollectionBookmarks returns the bookmarks filed directly in a collection,
+// or in it and all of its descendants when recursive is set
+func (s *hyprLinkService) GetCollectionBookmarks(id int64, recursive bool) ([]models.Bookmark, error) {
+    if _, err := s.collectionRepo.GetByID(id); err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+
+    included := map[int64]bool{id: true}
+    if recursive {
+        collections, err := s.collectionRepo.GetAll()
+        if err != nil {
+            return nil, err
+        }
+        for _, descendant := range descendantIDs(collections, id) {
+            included[descendant] = true
+        }
+    }
+
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+
+    result := []models.Bookmark{}
+    for _, bookmark := range bookmarks {
+        if included[bookmark.CollectionID] {
+            result = append(result, bookmark)
+        }
+    }
+    return result, nil
+}
+
+func (s *hyprLinkService) CreateCollection(collection *models.Collection) error {
+    if strings.TrimSpace(collection.Name) == "" {
+        return fmt.Errorf("%w: collection name is required", ErrInvalidInput)
+    }
+    return s.collectionRepo.Create(collection)
+}
+
+func (s *hyprLinkService) UpdateCollection(collection *models.Collection) error {
+    if strings.TrimSpace(collection.Name) == "" {
+        return fmt.Errorf("%w: collection name is required", ErrInvalidInput)
+    }
+    return s.collectionRepo.Update(collection)
+}
+
+// DeleteCollection removes a collection without losing its contents: child
+// collections and bookmarks move up to the deleted collection's parent. The
+// bookmarks move in one write, recorded as one operation.
+func (s *hyprLinkService) DeleteCollection(id int64) error {
+    collection, err := s.collectionRepo.GetByID(id)
+    if err != nil {
+        return kindError{kind: ErrNotFound, err: err}
+    }
+
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return err
+    }
+    now := time.Now()
+    var moved []models.Bookmark
+    for _, bookmark := range bookmarks {
+        if bookmark.CollectionID != id {
+            continue
+        }
+        bookmark.CollectionID = collection.ParentID
+        bookmark.UpdatedAt = now
+        moved = append(moved, bookmark)
+    }
+    if err := s.bookmarkRepo.UpdateMany(moved); err != nil {
+        return err
+    }
+
+    return s.collectionRepo.Delete(id)
+}
+
+// MoveCollection re-parents a collection. A missing collection is not
+// found; a missing parent or a move into its own subtree is invalid input.
+func (s *hyprLinkService) MoveCollection(id, parentID int64, position int) (*models.Collection, error) {
+    collections, err := s.collectionRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+    if !hasCollection(collections, id) {
+        return nil, kindError{kind: ErrNotFound, err: fmt.Errorf("collection with ID %d not found", id)}
+    }
+    if parentID != 0 && !hasCollection(collections, parentID) {
+        return nil, fmt.Errorf("%w: parent collection with ID %d not found", ErrInvalidInput, parentID)
+    }
+    if parentID == id || slices.Contains(descendantIDs(collections, id), parentID) {
+        return nil, fmt.Errorf("%w: cannot move collection %d into its own subtree", ErrInvalidInput, id)
+    }
+
+    return s.collectionRepo.Move(id, parentID, position)
+}
+
+func hasCollection(collections []models.Collection, id int64) bool {
+    for _, collection := range collections {
+        if collection.ID == id {
+            return true
+        }
+    }
+    return false
+}
+
+// descendantIDs returns the IDs of every collection nested below id
+func descendantIDs(collections []models.Collection, id int64) []int64 {
+    var result []int64
+    queue := []int64{id}
+    for len(queue) > 0 {
+        parentID := queue[0]
+        queue = queue[1:]
+        for _, collection := range collections {
+            if collection.ParentID == parentID {
+                result = append(result, collection.ID)
+                queue = append(queue, collection.ID)
+            }
+        }
+    }
+    return result
 }
 
 func (s *hyprLinkService) GetAllSessions() ([]models.Session, error) {
     return s.sessionRepo.GetAll()
 }
 
+// CreateSession stores a new session as its first version
 func (s *hyprLinkService) CreateSession(session *models.Session) error {
-    return s.sessionRepo.Create(session)
+    if err := checkSessionLayout(session); err != nil {
+        return err
+    }
+    if err := s.sessionRepo.Create(session); err != nil {
+        return err
+    }
+    _, _, err := s.versionRepo.Create(*session, models.VersionSave)
+    return err
 }
 
+// UpdateSession saves a session, adding a version when it changed
 func (s *hyprLinkService) UpdateSession(session *models.Session) error {
-    return s.sessionRepo.Update(session)
+    if err := checkSessionLayout(session); err != nil {
+        return err
+    }
+    if err := s.sessionRepo.Update(session); err != nil {
+        return err
+    }
+    _, _, err := s.versionRepo.Create(*session, models.VersionSave)
+    return err
 }
 
 func (s *hyprLinkService) DeleteSession(id int64) error {
@@ -76,103 +508,227 @@ func (s *hyprLinkService) GetAllHistory() ([]models.HistoryEntry, error) {
     return s.historyRepo.EnrichWithLinkClicks(history)
 }
 
-func (s *hyprLinkService) GetTodaysHistory() ([]models.HistoryEntry, error) {
-    history, err := s.historyRepo.GetToday()
+// GetHistoryRange returns enriched history last visited within [from, to)
+func (s *hyprLinkService) GetHistoryRange(from, to time.Time) ([]models.HistoryEntry, error) {
+    history, err := s.historyRepo.GetRange(from, to)
     if err != nil {
         return nil, err
     }
     return s.historyRepo.EnrichWithLinkClicks(history)
 }
 
+// HistoryLocation resolves tz to a location, falling back to the user's
+// configured timezone when tz is empty
+func (s *hyprLinkService) HistoryLocation(tz string) (*time.Location, error) {
+    if tz == "" {
+        settings, err := s.settingsRepo.Get()
+        if err != nil {
+            return nil, err
+        }
+        tz = settings.Timezone
+    }
+
+    loc, err := time.LoadLocation(tz)
+    if err != nil {
+        return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, tz)
+    }
+    return loc, nil
+}
+
+// GetTodaysHistory returns history for the current calendar day in the user's timezone
+func (s *hyprLinkService) GetTodaysHistory() ([]models.HistoryEntry, error) {
+    today, err := s.startOfToday()
+    if err != nil {
+        return nil, err
+    }
+    return s.GetHistoryRange(today, today.AddDate(0, 0, 1))
+}
+
+// GetWeekHistory returns history for the last 7 calendar days, today included
 func (s *hyprLinkService) GetWeekHistory() ([]models.HistoryEntry, error) {
-    history, err := s.historyRepo.GetWeek()
+    today, err := s.startOfToday()
     if err != nil {
         return nil, err
     }
-    return s.historyRepo.EnrichWithLinkClicks(history)
+    return s.GetHistoryRange(today.AddDate(0, 0, -6), today.AddDate(0, 0, 1))
 }
 
+// GetMonthHistory returns history for the last month of calendar days,
+// from the day after the same calendar day last month through today
 func (s *hyprLinkService) GetMonthHistory() ([]models.HistoryEntry, error) {
-    history, err := s.historyRepo.GetMonth()
+    today, err := s.startOfToday()
     if err != nil {
         return nil, err
     }
-    return s.historyRepo.EnrichWithLinkClicks(history)
+    return s.GetHistoryRange(sameDayLastMonth(today).AddDate(0, 0, 1), today.AddDate(0, 0, 1))
+}
+
+// sameDayLastMonth returns midnight of day's calendar day a month earlier,
+// or of the last day of that month when it is shorter (Mar 31 gives Feb 28)
+func sameDayLastMonth(day time.Time) time.Time {
+    year, month, date := day.Date()
+    if last := time.Date(year, month, 0, 0, 0, 0, 0, day.Location()).Day(); date > last {
+        date = last
+    }
+    return time.Date(year, month-1, date, 0, 0, 0, 0, day.Location())
+}
+
+// startOfToday returns local midnight in the user's configured timezone
+func (s *hyprLinkService) startOfToday() (time.Time, error) {
+    loc, err := s.HistoryLocation("")
+    if err != nil {
+        return time.Time{}, err
+    }
+    return startOfDay(s.now().In(loc)), nil
+}
+
+// startOfDay returns midnight of t's calendar day in t's location
+func startOfDay(t time.Time) time.Time {
+    year, month, day := t.Date()
+    return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
 }
 
 func (s *hyprLinkService) GetHistoryCount() (int, error) {
     return s.historyRepo.GetCount()
 }
 
+// SyncHistory stores the browser's latest visits and marks queued
+// bookmarks read when one of them lasted long enough
 func (s *hyprLinkService) SyncHistory(entries []models.HistoryEntry) (int, error) {
-    return s.historyRepo.Sync(entries)
+    synced, err := s.historyRepo.Sync(entries)
+    if err != nil {
+        return synced, err
+    }
+    return synced, s.markVisitedRead(entries)
 }
 
-func (s *hyprLinkService) GetAllLinkClicks() ([]models.LinkClick, error) {
-    return s.linkClickRepo.GetAll()
+// GetAllLinkClicks returns the clicks the extension tracked. Visits
+// imported from a browser profile are left out unless source names their
+// browser, or is "all".
+func (s *hyprLinkService) GetAllLinkClicks(source string) ([]models.LinkClick, error) {
+    clicks, err := s.linkClickRepo.GetAll()
+    if err != nil || source == "all" {
+        return clicks, err
+    }
+    if source == "extension" {
+        source = ""
+    }
+
+    filtered := []models.LinkClick{}
+    for _, click := range clicks {
+        if click.Source == source {
+            filtered = append(filtered, click)
+        }
+    }
+    return filtered, nil
 }
 
 func (s *hyprLinkService) SyncLinkClicks(clicks []models.LinkClick) (int, error) {
     return s.linkClickRepo.Sync(clicks)
 }
 
-func (s *hyprLinkService) ImportBrowserData(bookmarks []models.ImportedBookmark, history []models.HistoryEntry, useAI bool) (int, error) {
-    return s.importRepo.ImportBrowserData(bookmarks, history, useAI)
+// PreviewImport reports what importing batch's bookmarks would create,
+// update and skip, without writing anything
+func (s *hyprLinkService) PreviewImport(batch *models.ImportBatch) (*models.ImportDiff, error) {
+    mode, _, err := importMode(batch.Mode)
+    if err != nil {
+        return nil, err
+    }
+    // The preview shows the tags and collections rules would add
+    autoTag, err := s.autoTagger()
+    if err != nil {
+        return nil, err
+    }
+    return s.importRepo.Import(batch.Bookmarks, mode, true, autoTag)
 }
 
-func (s *hyprLinkService) BulkSegmentBookmarks() (int, error) {
-    bookmarks, err := s.bookmarkRepo.GetAll()
+// importBookmarks imports one batch of bookmarks: useAI has the classifier
+// tag untagged new ones first, and auto-tagging rules run on every
+// bookmark created. A classifier failure doesn't stop the import; it is
+// returned among the item errors and the bookmarks go in untagged.
+func (s *hyprLinkService) importBookmarks(ctx context.Context, items []models.ImportedBookmark, mode string, useAI bool) (*models.ImportDiff, []models.ItemError, error) {
+    var warnings []models.ItemError
+    if useAI {
+        classified, err := s.classifyImports(ctx, items, mode)
+        if err != nil {
+            warnings = append(warnings, models.ItemError{Message: err.Error()})
+        }
+        items = classified
+    }
+
+    autoTag, err := s.autoTagger()
+    if err != nil {
+        return nil, warnings, err
+    }
+    diff, err := s.importRepo.Import(items, mode, false, autoTag)
     if err != nil {
-        return 0, err
+        return nil, warnings, err
     }
+    s.wakeWorkers()
+    return diff, warnings, nil
+}
 
-    processedCount := 0
-    for _, bookmark := range bookmarks {
-        if len(bookmark.Tags) == 0 {
-            bookmark.Tags = s.generateTagsFromContent(bookmark)
-            bookmark.UpdatedAt = time.Now()
-            if err := s.bookmarkRepo.Update(&bookmark); err != nil {
-                return processedCount, err
-            }
-            processedCount++
-        }
-    }
-
-    return processedCount, nil
-}
-
-func (s *hyprLinkService) generateTagsFromContent(bookmark models.Bookmark) []string {
-    tags := []string{}
-    
-    content := strings.ToLower(bookmark.Title + " " + bookmark.Description + " " + bookmark.URL)
-    
-    keywords := map[string]string{
-        "github.com":     "development",
-        "stackoverflow":  "development",
-        "youtube.com":    "video",
-        "medium.com":     "article",
-        "news":           "news",
-        "blog":           "blog",
-        "tutorial":       "learning",
-        "documentation":  "docs",
-        "api":            "development",
-        "react":          "frontend",
-        "javascript":     "development",
-        "python":         "development",
-        "golang":         "development",
-        "design":         "design",
-        "tool":           "tools",
-    }
-    
-    for keyword, tag := range keywords {
-        if strings.Contains(content, keyword) {
-            tags = append(tags, tag)
-        }
-    }
-    
-    if len(tags) == 0 {
-        tags = append(tags, "uncategorized")
-    }
-    
-    return tags
-}
\ No newline at end of file
+// autoTagger returns a hook that applies the current auto-tagging rules to
+// a bookmark an import is about to create
+func (s *hyprLinkService) autoTagger() (func(*models.Bookmark), error) {
+    engine, err := s.ruleEngine()
+    if err != nil {
+        return nil, err
+    }
+    return func(bookmark *models.Bookmark) {
+        // The tags may share an array with the imported item
+        bookmark.Tags = append([]string{}, bookmark.Tags...)
+        engine.Apply(bookmark)
+    }, nil
+}
+
+// importMode validates an import mode, returning the collision handling to
+// apply and whether the import is only a preview
+func importMode(mode string) (string, bool, error) {
+    switch mode {
+    case "":
+        return models.ImportSkipExisting, false, nil
+    case models.ImportDryRun:
+        return models.ImportSkipExisting, true, nil
+    case models.ImportSkipExisting, models.ImportOverwrite, models.ImportMergeTags:
+        return mode, false, nil
+    default:
+        return "", false, fmt.Errorf("%w: unknown import mode %q (use %s, %s, %s or %s)", ErrInvalidInput, mode,
+            models.ImportSkipExisting, models.ImportOverwrite, models.ImportMergeTags, models.ImportDryRun)
+    }
+}
+
+// ImportBrowserHistory stores bookmarks, per-URL history and individual
+// visits read from a browser profile, skipping what is already stored
+func (s *hyprLinkService) ImportBrowserHistory(bookmarks []models.ImportedBookmark, history []models.HistoryEntry, visits []models.LinkClick) (*models.ImportResult, error) {
+    var result models.ImportResult
+
+    diff, _, err := s.importBookmarks(context.Background(), bookmarks, models.ImportSkipExisting, false)
+    if err != nil {
+        return &result, err
+    }
+    result.BookmarksImported = len(diff.Creates)
+
+    if result.HistoryImported, err = s.historyRepo.Sync(history); err != nil {
+        return &result, err
+    }
+    if result.VisitsImported, err = s.linkClickRepo.Sync(visits); err != nil {
+        return &result, err
+    }
+
+    return &result, nil
+}
+
+func (s *hyprLinkService) GetSettings() (*models.Settings, error) {
+    return s.settingsRepo.Get()
+}
+
+func (s *hyprLinkService) UpdateSettings(settings *models.Settings) error {
+    if settings.Timezone == "" {
+        settings.Timezone = models.DefaultSettings().Timezone
+    }
+    if _, err := time.LoadLocation(settings.Timezone); err != nil {
+        return fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, settings.Timezone)
+    }
+    return s.settingsRepo.Update(settings)
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+    "context"
+    "reflect"
+    "testing"
+
+    "hyprlnk/internal/models"
+)
+
+// existingForImport saves the bookmark the imports below collide with
+func existingForImport(t *testing.T, service *hyprLinkService) models.Bookmark {
+    t.Helper()
+    collection := models.Collection{Name: "Reading"}
+    if err := service.CreateCollection(&collection); err != nil {
+        t.Fatal(err)
+    }
+    existing := newBookmark("https://example.com/article", "old")
+    existing.Title = "Old title"
+    existing.Description = "My notes"
+    existing.CollectionID = collection.ID
+    return mustCreateBookmark(t, service, existing)
+}
+
+func TestImport_Modes(t *testing.T) {
+    imported := models.ImportedBookmark{
+        URL:    "http://www.example.com/article/", // same canonical URL
+        Title:  "New title",
+        Tags:   []string{"new"},
+        Folder: "Imported",
+    }
+
+    tests := []struct {
+        mode        string
+        title       string
+        description string
+        tags        []string
+        newFolder   bool
+        updated     bool
+    }{
+        {models.ImportSkipExisting, "Old title", "My notes", []string{"old"}, false, false},
+        {models.ImportMergeTags, "Old title", "My notes", []string{"old", "new"}, false, true},
+        // The import has no description, so overwriting keeps the notes
+        {models.ImportOverwrite, "New title", "My notes", []string{"new"}, true, true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.mode, func(t *testing.T) {
+            service, _ := newTestService(t)
+            existing := existingForImport(t, service)
+
+            diff, _, err := service.importBookmarks(context.Background(), []models.ImportedBookmark{imported}, tt.mode, false)
+            if err != nil {
+                t.Fatalf("Import failed: %v", err)
+            }
+            if len(diff.Creates) != 0 || (len(diff.Updates) == 1) != tt.updated || (len(diff.Skips) == 1) == tt.updated {
+                t.Errorf("Unexpected diff: %d creates, %d updates, %d skips", len(diff.Creates), len(diff.Updates), len(diff.Skips))
+            }
+
+            got, err := service.GetBookmark(existing.ID)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if got.URL != existing.URL {
+                t.Errorf("Expected the URL to stay %s, got %s", existing.URL, got.URL)
+            }
+            if got.Title != tt.title || got.Description != tt.description || !reflect.DeepEqual(got.Tags, tt.tags) {
+                t.Errorf("Expected %q / %q / %v, got %q / %q / %v", tt.title, tt.description, tt.tags, got.Title, got.Description, got.Tags)
+            }
+            if movedToNew := got.CollectionID != existing.CollectionID; movedToNew != tt.newFolder {
+                t.Errorf("Expected folder change %v, collection went from %d to %d", tt.newFolder, existing.CollectionID, got.CollectionID)
+            }
+        })
+    }
+}
+
+func TestImport_OverwriteDescription(t *testing.T) {
+    service, _ := newTestService(t)
+    existing := existingForImport(t, service)
+
+    imported := models.ImportedBookmark{URL: existing.URL, Description: "Imported notes"}
+    if _, _, err := service.importBookmarks(context.Background(), []models.ImportedBookmark{imported}, models.ImportOverwrite, false); err != nil {
+        t.Fatalf("Import failed: %v", err)
+    }
+    got, _ := service.GetBookmark(existing.ID)
+    if got.Description != "Imported notes" || got.Title != "Old title" || got.CollectionID != existing.CollectionID {
+        t.Errorf("Expected only the non-blank imported fields to overwrite, got %+v", got)
+    }
+}
+
+func TestImport_DryRunWritesNothing(t *testing.T) {
+    service, _ := newTestService(t)
+    existing := existingForImport(t, service)
+
+    collectionsBefore, _ := service.GetAllCollections()
+    operationsBefore, _ := service.GetOperations(0)
+
+    batch := &models.ImportBatch{
+        Mode: models.ImportOverwrite,
+        Bookmarks: []models.ImportedBookmark{
+            {URL: existing.URL, Title: "New title", Tags: []string{"new"}},
+            {URL: "https://new.example", Title: "New", FolderPath: []string{"Brand", "New"}},
+        },
+    }
+    diff, err := service.PreviewImport(batch)
+    if err != nil {
+        t.Fatalf("Preview failed: %v", err)
+    }
+    if !diff.DryRun || len(diff.Creates) != 1 || len(diff.Updates) != 1 {
+        t.Errorf("Expected a dry run previewing 1 create and 1 update, got %+v", diff)
+    }
+
+    bookmarks, _ := service.GetAllBookmarks()
+    if len(bookmarks) != 1 || bookmarks[0].Title != "Old title" {
+        t.Errorf("Expected the bookmarks untouched, got %+v", bookmarks)
+    }
+    if collections, _ := service.GetAllCollections(); len(collections) != len(collectionsBefore) {
+        t.Errorf("Expected no collections created, had %d now %d", len(collectionsBefore), len(collections))
+    }
+    if operations, _ := service.GetOperations(0); len(operations) != len(operationsBefore) {
+        t.Errorf("Expected no operations recorded, had %d now %d", len(operationsBefore), len(operations))
+    }
+}
+
+func TestImport_AppliesRulesBeforeWriting(t *testing.T) {
+    service, _ := newTestService(t)
+    rule := models.AutoTagRule{
+        Name:       "Go",
+        Conditions: []models.RuleCondition{{Field: models.RuleFieldDomain, Op: models.RuleOpEquals, Value: "go.dev"}},
+        Tags:       []string{"golang"},
+    }
+    if err := service.CreateRule(&rule); err != nil {
+        t.Fatal(err)
+    }
+
+    items := []models.ImportedBookmark{{URL: "https://go.dev", Title: "Go", Tags: []string{"lang"}}}
+    preview, err := service.PreviewImport(&models.ImportBatch{Bookmarks: items})
+    if err != nil {
+        t.Fatalf("Preview failed: %v", err)
+    }
+    if got := preview.Creates[0].After.Tags; !reflect.DeepEqual(got, []string{"lang", "golang"}) {
+        t.Errorf("Expected the preview to show the rule's tag, got %v", got)
+    }
+
+    diff, _, err := service.importBookmarks(context.Background(), items, models.ImportSkipExisting, false)
+    if err != nil {
+        t.Fatalf("Import failed: %v", err)
+    }
+    if got := tagsOf(t, service, diff.Creates[0].After.ID); !reflect.DeepEqual(got, []string{"lang", "golang"}) {
+        t.Errorf("Expected the rule's tag stored, got %v", got)
+    }
+    if !reflect.DeepEqual(items[0].Tags, []string{"lang"}) {
+        t.Errorf("Expected the imported item left as it was, got %v", items[0].Tags)
+    }
+
+    // One write: the import's own operation, with the tags in its revision
+    operations, _ := service.GetOperations(0)
+    if len(operations) != 1 || operations[0].Operation != models.OperationImport {
+        t.Errorf("Expected only the import operation, got %+v", operations)
+    }
+    log, err := service.GetRevisions(models.ItemBookmark, diff.Creates[0].After.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(log.Revisions) != 1 {
+        t.Errorf("Expected one revision for the imported bookmark, got %d", len(log.Revisions))
+    }
+}
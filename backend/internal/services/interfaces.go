@@ -1,29 +1,111 @@
 package services
 
-import "hyprlnk/internal/models"
+import (
+    "encoding/json"
+    "time"
+
+    "hyprlnk/internal/models"
+)
 
 type HyprLinkService interface {
     GetAllBookmarks() ([]models.Bookmark, error)
-    CreateBookmark(bookmark *models.Bookmark) error
+    GetBookmark(id int64) (*models.Bookmark, error)
+    LookupBookmark(rawURL string) (*models.Bookmark, error)
+    CreateBookmark(bookmark *models.Bookmark) (created bool, err error)
     UpdateBookmark(bookmark *models.Bookmark) error
     DeleteBookmark(id int64) error
     SearchBookmarks(query string) ([]models.Bookmark, error)
+    FindDuplicateBookmarks() ([]models.DuplicateGroup, error)
+    MergeBookmarks(ids []int64, targetID int64) (*models.Bookmark, error)
+    MergeAllDuplicates() ([]models.Bookmark, error)
+    BulkBookmarks(request models.BulkRequest) (*models.BulkResult, error)
+    MoveBookmark(id, collectionID int64) (*models.Bookmark, error)
+    GetBookmarksByTag(tag string) ([]models.Bookmark, error)
+    SuggestTags(bookmark models.Bookmark, limit int) ([]models.TagSuggestion, error)
+    GetBookmarkMetadata(id int64) (*models.PageMetadata, error)
+    RefreshBookmarkMetadata(id int64) (*models.PageMetadata, error)
+    GetFavicon(host string) ([]byte, string, error)
+    GetBookmarkArchive(id int64) (*models.PageArchive, error)
+    GetArchiveSnapshot(id int64) ([]byte, error)
+    GetArchiveText(id int64) (string, error)
+    ArchiveBookmark(id int64) (*models.PageArchive, error)
+    GetLinkHealth(status string) (*models.LinkHealthReport, error)
+    GetBookmarkHealth(id int64) (*models.LinkHealth, error)
+    CheckBookmarkLink(id int64) (*models.LinkHealth, error)
+    ApplyRedirects(ids []int64) ([]models.Bookmark, error)
+    SuggestBookmarkTags(id int64, limit int) ([]models.TagSuggestion, error)
+    GetReadingList(status, sortBy string) ([]models.Bookmark, error)
+    SetReadingState(id int64, status string, progress *int) (*models.Bookmark, error)
+    RemoveFromReadingList(id int64) (*models.Bookmark, error)
+
+    GetAnnotations(bookmarkID int64, pageURL, annotationType, query string) ([]models.Annotation, error)
+    GetAnnotation(id int64) (*models.Annotation, error)
+    CreateAnnotation(annotation *models.Annotation) error
+    UpdateAnnotation(annotation *models.Annotation) error
+    DeleteAnnotation(id int64) error
+
+    GetRevisions(itemType string, id int64) (*models.RevisionLog, error)
+    RestoreRevision(itemType string, id int64, rev int) (json.RawMessage, error)
+    GetOperations(limit int) ([]models.Operation, error)
+    Undo(count int) ([]models.Operation, error)
+
+    GetTrash() ([]models.TrashItem, error)
+    RestoreFromTrash(itemType string, id int64) (json.RawMessage, error)
+    PurgeItem(itemType string, id int64) error
+    EmptyTrash() (int, error)
+    
+    GetRules() ([]models.AutoTagRule, error)
+    CreateRule(rule *models.AutoTagRule) error
+    UpdateRule(rule *models.AutoTagRule) error
+    DeleteRule(id int64) error
+    ApplyRules(dryRun bool) ([]models.RuleChange, error)
+
+    GetTags() ([]models.TagStats, error)
+    RenameTag(from, to string) (int, error)
+    MergeTags(sources []string, target string) (int, error)
+    DeleteTag(name string, recursive bool) (int, error)
+    UpdateTagInfo(info *models.TagInfo) error
+    
+    GetAllCollections() ([]models.Collection, error)
+    GetCollectionTree() ([]models.CollectionNode, error)
+    GetCollectionBookmarks(id int64, recursive bool) ([]models.Bookmark, error)
+    CreateCollection(collection *models.Collection) error
+    UpdateCollection(collection *models.Collection) error
+    DeleteCollection(id int64) error
+    MoveCollection(id, parentID int64, position int) (*models.Collection, error)
     
     GetAllSessions() ([]models.Session, error)
     CreateSession(session *models.Session) error
     UpdateSession(session *models.Session) error
     DeleteSession(id int64) error
+    GetSessionVersions(id int64) ([]models.SessionVersion, error)
+    GetSessionVersion(id int64, version int) (*models.SessionVersion, error)
+    GetSessionVersionAt(id int64, at time.Time) (*models.SessionVersion, error)
+    RestoreSessionVersion(id int64, version int) (*models.Session, error)
+    AutoSaveSession(request models.AutoSaveRequest) (*models.AutoSaveResult, error)
     
     GetAllHistory() ([]models.HistoryEntry, error)
+    GetHistoryRange(from, to time.Time) ([]models.HistoryEntry, error)
+    HistoryLocation(tz string) (*time.Location, error)
     GetTodaysHistory() ([]models.HistoryEntry, error)
     GetWeekHistory() ([]models.HistoryEntry, error)
     GetMonthHistory() ([]models.HistoryEntry, error)
     GetHistoryCount() (int, error)
     SyncHistory(entries []models.HistoryEntry) (int, error)
     
-    GetAllLinkClicks() ([]models.LinkClick, error)
+    GetAllLinkClicks(source string) ([]models.LinkClick, error)
     SyncLinkClicks(clicks []models.LinkClick) (int, error)
     
-    ImportBrowserData(bookmarks []models.ImportedBookmark, history []models.HistoryEntry, useAI bool) (int, error)
+    ImportBrowserHistory(bookmarks []models.ImportedBookmark, history []models.HistoryEntry, visits []models.LinkClick) (*models.ImportResult, error)
     BulkSegmentBookmarks() (int, error)
+    
+    StartJobs() error
+    PreviewImport(batch *models.ImportBatch) (*models.ImportDiff, error)
+    SubmitImport(batch *models.ImportBatch, parseErrors []models.ItemError) (*models.Job, error)
+    GetJobs() []models.Job
+    GetJob(id int64) (*models.Job, error)
+    CancelJob(id int64) (*models.Job, error)
+    
+    GetSettings() (*models.Settings, error)
+    UpdateSettings(settings *models.Settings) error
 }
\ No newline at end of file
@@ -0,0 +1,177 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+
+    "hyprlnk/internal/jobs"
+    "hyprlnk/internal/models"
+)
+
+const jobTypeImport = "import"
+
+// importChunkSize is how many items an import job stores between progress
+// checkpoints and cancellation checks
+const importChunkSize = 500
+
+// StartJobs resumes unfinished jobs and starts the background workers.
+// Only the server calls it; one-off commands import synchronously.
+func (s *hyprLinkService) StartJobs() error {
+    if err := s.jobs.Start(); err != nil {
+        return err
+    }
+    if s.fetcher != nil {
+        go s.runEnrichment()
+    }
+    if s.linkChecker != nil {
+        go s.runLinkChecks()
+    }
+    go s.runTrashCleanup()
+    return nil
+}
+
+// wakeWorkers has the page fetcher and link checker look for new and
+// edited bookmarks now, rather than at their next scheduled run
+func (s *hyprLinkService) wakeWorkers() {
+    for _, wake := range []chan struct{}{s.enrichWake, s.linkWake} {
+        select {
+        case wake <- struct{}{}:
+        default:
+        }
+    }
+}
+
+// SubmitImport queues batch as a background import job. parseErrors are
+// entries of the uploaded file that were already skipped while parsing.
+func (s *hyprLinkService) SubmitImport(batch *models.ImportBatch, parseErrors []models.ItemError) (*models.Job, error) {
+    mode, dryRun, err := importMode(batch.Mode)
+    if err != nil {
+        return nil, err
+    }
+    if dryRun {
+        return nil, fmt.Errorf("%w: dry runs are previewed, not queued", ErrInvalidInput)
+    }
+    batch.Mode = mode
+
+    payload, err := json.Marshal(batch)
+    if err != nil {
+        return nil, err
+    }
+
+    counts := map[string]int{
+        "bookmarks_imported": 0,
+        "bookmarks_updated":  0,
+        "bookmarks_skipped":  0,
+        "history_imported":   0,
+        "visits_imported":    0,
+        "sessions_imported":  0,
+    }
+    return s.jobs.Submit(jobTypeImport, "Import from "+batch.Source, payload, counts, parseErrors)
+}
+
+func (s *hyprLinkService) GetJobs() []models.Job {
+    return s.jobs.List()
+}
+
+func (s *hyprLinkService) GetJob(id int64) (*models.Job, error) {
+    job, err := s.jobs.Get(id)
+    return job, jobError(err)
+}
+
+func (s *hyprLinkService) CancelJob(id int64) (*models.Job, error) {
+    job, err := s.jobs.Cancel(id)
+    return job, jobError(err)
+}
+
+// jobError tags runner errors so handlers pick the right status
+func jobError(err error) error {
+    switch {
+    case errors.Is(err, jobs.ErrNotFound):
+        return kindError{kind: ErrNotFound, err: err}
+    case errors.Is(err, jobs.ErrFinished):
+        return kindError{kind: ErrConflict, err: err}
+    }
+    return err
+}
+
+// runImportJob stores an ImportBatch chunk by chunk. Every import step
+// skips what is already stored, so redoing part of a chunk after a crash
+// is harmless.
+func (s *hyprLinkService) runImportJob(ctx context.Context, payload []byte, p *jobs.Progress) error {
+    var batch models.ImportBatch
+    if err := json.Unmarshal(payload, &batch); err != nil {
+        return fmt.Errorf("invalid import job input: %w", err)
+    }
+
+    total := len(batch.Bookmarks) + len(batch.History) + len(batch.Visits) + len(batch.Sessions)
+    if err := p.SetTotal(total); err != nil {
+        return err
+    }
+
+    chunks := &importChunker{ctx: ctx, progress: p, done: p.Job().Processed}
+    if err := importInChunks(chunks, batch.Bookmarks, func(items []models.ImportedBookmark) (map[string]int, error) {
+        diff, warnings, err := s.importBookmarks(ctx, items, batch.Mode, batch.UseAI)
+        if len(warnings) > 0 {
+            if err := p.AddErrors(warnings...); err != nil {
+                return nil, err
+            }
+        }
+        if err != nil {
+            return nil, err
+        }
+        return map[string]int{
+            "bookmarks_imported": len(diff.Creates),
+            "bookmarks_updated":  len(diff.Updates),
+            "bookmarks_skipped":  len(diff.Skips),
+        }, nil
+    }); err != nil {
+        return err
+    }
+    if err := importInChunks(chunks, batch.History, countAs("history_imported", s.historyRepo.Sync)); err != nil {
+        return err
+    }
+    if err := importInChunks(chunks, batch.Visits, countAs("visits_imported", s.linkClickRepo.Sync)); err != nil {
+        return err
+    }
+    return importInChunks(chunks, batch.Sessions, countAs("sessions_imported", s.importSessions))
+}
+
+// importChunker tracks a job's position across the item lists it imports
+type importChunker struct {
+    ctx      context.Context
+    progress *jobs.Progress
+    done     int // items checkpointed before this run, when resuming
+    offset   int // items in the lists already walked
+}
+
+func importInChunks[T any](c *importChunker, items []T, store func([]T) (map[string]int, error)) error {
+    for start := 0; start < len(items); start += importChunkSize {
+        end := min(start+importChunkSize, len(items))
+        if c.offset+end <= c.done {
+            continue // finished before a restart
+        }
+        if err := c.ctx.Err(); err != nil {
+            return err
+        }
+
+        counts, err := store(items[start:end])
+        if err != nil {
+            return err
+        }
+        if err := c.progress.Advance(end-start, counts); err != nil {
+            return err
+        }
+    }
+    c.offset += len(items)
+    return nil
+}
+
+// countAs adapts a store step that returns a single count
+func countAs[T any](key string, store func([]T) (int, error)) func([]T) (map[string]int, error) {
+    return func(items []T) (map[string]int, error) {
+        count, err := store(items)
+        return map[string]int{key: count}, err
+    }
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+    "encoding/json"
+    "errors"
+    "testing"
+    "time"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/repositories"
+)
+
+// waitForJob polls until the job has finished
+func waitForJob(t *testing.T, service *hyprLinkService, id int64) *models.Job {
+    t.Helper()
+    deadline := time.Now().Add(5 * time.Second)
+    for time.Now().Before(deadline) {
+        job, err := service.GetJob(id)
+        if err != nil {
+            t.Fatalf("Failed to read job %d: %v", id, err)
+        }
+        if job.Finished() {
+            return job
+        }
+        time.Sleep(5 * time.Millisecond)
+    }
+    t.Fatalf("Job %d didn't finish", id)
+    return nil
+}
+
+func TestImportJob_ResumesFromCheckpoint(t *testing.T) {
+    service, store := newTestService(t)
+
+    batch := models.ImportBatch{
+        Source: "test",
+        Mode:   models.ImportSkipExisting,
+        Bookmarks: []models.ImportedBookmark{
+            {URL: "https://one.example", Title: "One"},
+            {URL: "https://two.example", Title: "Two"},
+        },
+        History: []models.HistoryEntry{
+            {URL: "https://three.example", Title: "Three", VisitCount: 1, LastVisitTime: time.Now()},
+        },
+    }
+    payload, err := json.Marshal(batch)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // A job that checkpointed the bookmarks, then the server stopped
+    interrupted := &models.Job{
+        ID:        1,
+        Type:      jobTypeImport,
+        Status:    models.JobRunning,
+        Total:     3,
+        Processed: 2,
+        Counts:    map[string]int{"bookmarks_imported": 2},
+        CreatedAt: time.Now(),
+    }
+    jobRepo := repositories.NewJobRepository(store)
+    if err := jobRepo.SavePayload(interrupted.ID, payload); err != nil {
+        t.Fatal(err)
+    }
+    if err := jobRepo.Save(interrupted); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := service.jobs.Start(); err != nil {
+        t.Fatalf("Failed to start jobs: %v", err)
+    }
+    job := waitForJob(t, service, interrupted.ID)
+    if job.Status != models.JobCompleted || job.Processed != 3 {
+        t.Fatalf("Expected the job to complete all 3 items, got %+v", job)
+    }
+    if job.Counts["bookmarks_imported"] != 2 || job.Counts["history_imported"] != 1 {
+        t.Errorf("Expected the checkpointed counts to be kept, got %v", job.Counts)
+    }
+
+    // The bookmark chunk was done before the restart, so only history ran
+    bookmarks, err := service.GetAllBookmarks()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(bookmarks) != 0 {
+        t.Errorf("Expected the checkpointed bookmarks to be skipped, got %d", len(bookmarks))
+    }
+    history, err := service.GetAllHistory()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(history) != 1 || history[0].URL != "https://three.example" {
+        t.Errorf("Expected the history entry to be imported, got %+v", history)
+    }
+}
+
+func TestImportJob_Cancel(t *testing.T) {
+    service, _ := newTestService(t)
+
+    job, err := service.SubmitImport(&models.ImportBatch{Source: "test"}, nil)
+    if err != nil {
+        t.Fatalf("Failed to submit: %v", err)
+    }
+    if _, err := service.CancelJob(job.ID); err != nil {
+        t.Fatalf("Failed to cancel a queued job: %v", err)
+    }
+    if _, err := service.CancelJob(job.ID); !errors.Is(err, ErrConflict) {
+        t.Errorf("Expected a conflict cancelling a cancelled job, got %v", err)
+    }
+    if _, err := service.CancelJob(42); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected not found cancelling an unknown job, got %v", err)
+    }
+    if _, err := service.SubmitImport(&models.ImportBatch{Source: "test", Mode: models.ImportDryRun}, nil); !errors.Is(err, ErrInvalidInput) {
+        t.Errorf("Expected dry runs to be refused, got %v", err)
+    }
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "sort"
+    "sync"
+    "time"
+
+    "hyprlnk/internal/linkcheck"
+    "hyprlnk/internal/models"
+)
+
+// linkCheckTick is how often the link checker looks for URLs that are due
+const linkCheckTick = time.Hour
+
+// linkStatusOrder lists problems first
+var linkStatusOrder = map[string]int{
+    models.LinkDead:    0,
+    models.LinkMoved:   1,
+    models.LinkUnknown: 2,
+    models.LinkHealthy: 3,
+}
+
+// GetLinkHealth lists the checked bookmarks, optionally only those with
+// the given status, problems first. Check histories are left out.
+func (s *hyprLinkService) GetLinkHealth(status string) (*models.LinkHealthReport, error) {
+    if _, ok := linkStatusOrder[status]; status != "" && !ok {
+        return nil, fmt.Errorf("%w: unknown link status %q", ErrInvalidInput, status)
+    }
+
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+    all, err := s.linkHealthRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+
+    current := make(map[int64]string, len(bookmarks))
+    for _, bookmark := range bookmarks {
+        current[bookmark.ID] = bookmark.URL
+    }
+
+    report := &models.LinkHealthReport{
+        Counts:    map[string]int{models.LinkHealthy: 0, models.LinkMoved: 0, models.LinkDead: 0, models.LinkUnknown: 0},
+        Bookmarks: []models.LinkHealth{},
+    }
+    for _, health := range all {
+        // Skip deleted bookmarks and results for a URL since edited
+        if url, ok := current[health.BookmarkID]; !ok || url != health.URL {
+            continue
+        }
+        report.Counts[health.Status]++
+        if status == "" || health.Status == status {
+            health.History = nil
+            report.Bookmarks = append(report.Bookmarks, health)
+        }
+    }
+
+    sort.Slice(report.Bookmarks, func(i, j int) bool {
+        a, b := report.Bookmarks[i], report.Bookmarks[j]
+        if linkStatusOrder[a.Status] != linkStatusOrder[b.Status] {
+            return linkStatusOrder[a.Status] < linkStatusOrder[b.Status]
+        }
+        return a.BookmarkID < b.BookmarkID
+    })
+    return report, nil
+}
+
+// GetBookmarkHealth returns a bookmark's link health with its check history
+func (s *hyprLinkService) GetBookmarkHealth(id int64) (*models.LinkHealth, error) {
+    health, err := s.linkHealthRepo.Get(id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+    return health, nil
+}
+
+// CheckBookmarkLink checks a bookmark's URL now
+func (s *hyprLinkService) CheckBookmarkLink(id int64) (*models.LinkHealth, error) {
+    if s.linkChecker == nil {
+        return nil, fmt.Errorf("%w: link checking is disabled", ErrConflict)
+    }
+    bookmark, err := s.bookmarkRepo.GetByID(id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+
+    previous, _ := s.linkHealthRepo.Get(id)
+    return s.checkLink(context.Background(), *bookmark, previous)
+}
+
+// ApplyRedirects points moved bookmarks at where their links now lead.
+// With no IDs, every moved bookmark is updated; IDs of bookmarks that
+// haven't moved are ignored. A bookmark whose new URL is already
+// bookmarked, or is where another moved bookmark now goes, is merged into
+// that bookmark instead. It returns the updated and merged-into bookmarks.
+func (s *hyprLinkService) ApplyRedirects(ids []int64) ([]models.Bookmark, error) {
+    report, err := s.GetLinkHealth(models.LinkMoved)
+    if err != nil {
+        return nil, err
+    }
+    wanted := make(map[int64]bool, len(ids))
+    for _, id := range ids {
+        wanted[id] = true
+    }
+
+    moved := make(map[int64]models.LinkHealth)
+    for _, health := range report.Bookmarks {
+        if len(ids) == 0 || wanted[health.BookmarkID] {
+            moved[health.BookmarkID] = health
+        }
+    }
+    if len(moved) == 0 {
+        return []models.Bookmark{}, nil
+    }
+
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+    // Oldest first, so of two bookmarks moving to one URL the older is kept
+    sort.Slice(bookmarks, func(i, j int) bool {
+        if !bookmarks[i].CreatedAt.Equal(bookmarks[j].CreatedAt) {
+            return bookmarks[i].CreatedAt.Before(bookmarks[j].CreatedAt)
+        }
+        return bookmarks[i].ID < bookmarks[j].ID
+    })
+
+    normalizer, err := s.urlNormalizer()
+    if err != nil {
+        return nil, err
+    }
+
+    now := time.Now()
+    var updated []models.Bookmark
+    var records []models.LinkHealth
+    var merges [][2]int64              // {bookmark kept, moved bookmark folded into it}
+    movingTo := make(map[string]int64) // canonical new URL -> bookmark updated to it
+    for _, bookmark := range bookmarks {
+        health, ok := moved[bookmark.ID]
+        if !ok {
+            continue
+        }
+
+        existing, err := s.bookmarkRepo.FindByURL(health.MovedTo)
+        if err != nil {
+            return nil, err
+        }
+        if keep := collidingBookmark(existing, moved); keep != 0 {
+            merges = append(merges, [2]int64{keep, bookmark.ID})
+            continue
+        }
+        canonical := normalizer.Normalize(health.MovedTo)
+        if keep, ok := movingTo[canonical]; ok {
+            merges = append(merges, [2]int64{keep, bookmark.ID})
+            continue
+        }
+        movingTo[canonical] = bookmark.ID
+
+        bookmark.URL = health.MovedTo
+        bookmark.UpdatedAt = now
+        updated = append(updated, bookmark)
+
+        // The new URL answered when the redirect was followed
+        full, err := s.linkHealthRepo.Get(bookmark.ID)
+        if err != nil {
+            continue
+        }
+        full.URL = health.MovedTo
+        full.Status = models.LinkHealthy
+        full.MovedTo = ""
+        records = append(records, *full)
+    }
+
+    if err := s.bookmarkRepo.UpdateMany(updated); err != nil {
+        return nil, err
+    }
+    if err := s.linkHealthRepo.Save(records...); err != nil {
+        return nil, err
+    }
+    for i := range updated {
+        if err := s.moveAnnotations([]int64{updated[i].ID}, &updated[i]); err != nil {
+            return nil, err
+        }
+    }
+
+    // After the updates, so bookmarks moved in this call are merged into
+    // with their new URLs
+    for _, merge := range merges {
+        merged, err := s.MergeBookmarks(merge[:], merge[0])
+        if err != nil {
+            return updated, err
+        }
+        replaced := false
+        for i := range updated {
+            if updated[i].ID == merged.ID {
+                updated[i], replaced = *merged, true
+            }
+        }
+        if !replaced {
+            updated = append(updated, *merged)
+        }
+    }
+    s.wakeWorkers()
+    return updated, nil
+}
+
+// collidingBookmark returns the oldest of matches that is staying where it
+// is, or 0 when there is none. Moving bookmarks, the one being moved among
+// them, are leaving that URL; two landing on the same new URL are caught
+// as they are updated.
+func collidingBookmark(matches []models.Bookmark, moving map[int64]models.LinkHealth) int64 {
+    for _, match := range matches {
+        if _, ok := moving[match.ID]; !ok {
+            return match.ID
+        }
+    }
+    return 0
+}
+
+// checkLink checks a bookmark's URL and records the result
+func (s *hyprLinkService) checkLink(ctx context.Context, bookmark models.Bookmark, previous *models.LinkHealth) (*models.LinkHealth, error) {
+    health := models.LinkHealth{BookmarkID: bookmark.ID, URL: bookmark.URL}
+    if previous != nil && previous.URL == bookmark.URL {
+        health = *previous
+    }
+    linkcheck.Record(&health, s.linkChecker.Check(ctx, bookmark.URL))
+    return &health, s.linkHealthRepo.Save(health)
+}
+
+// runLinkChecks checks each bookmarked URL once per interval, and new
+// bookmarks as they arrive, until the process exits
+func (s *hyprLinkService) runLinkChecks() {
+    ticker := time.NewTicker(min(linkCheckTick, s.linkChecker.Interval()))
+    defer ticker.Stop()
+
+    for {
+        if err := s.checkDueLinks(context.Background()); err != nil {
+            log.Printf("link check: %v", err)
+        }
+        select {
+        case <-s.linkWake:
+        case <-ticker.C:
+        }
+    }
+}
+
+// checkDueLinks checks the URLs never checked, edited since, or last
+// checked over an interval ago, and forgets the results of deleted bookmarks
+func (s *hyprLinkService) checkDueLinks(ctx context.Context) error {
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return err
+    }
+    stored, err := s.linkHealthRepo.GetAll()
+    if err != nil {
+        return err
+    }
+
+    byBookmark := make(map[int64]*models.LinkHealth, len(stored))
+    for i := range stored {
+        byBookmark[stored[i].BookmarkID] = &stored[i]
+    }
+
+    pending := make(chan models.Bookmark)
+    var workers sync.WaitGroup
+    for i := 0; i < s.linkChecker.Workers(); i++ {
+        workers.Add(1)
+        go func() {
+            defer workers.Done()
+            for bookmark := range pending {
+                if _, err := s.checkLink(ctx, bookmark, byBookmark[bookmark.ID]); err != nil {
+                    log.Printf("link check: bookmark %d: %v", bookmark.ID, err)
+                }
+            }
+        }()
+    }
+
+    current := make(map[int64]bool, len(bookmarks))
+    for _, bookmark := range bookmarks {
+        current[bookmark.ID] = true
+        health := byBookmark[bookmark.ID]
+        if health == nil || health.URL != bookmark.URL || time.Since(health.LastChecked) >= s.linkChecker.Interval() {
+            pending <- bookmark
+        }
+    }
+    close(pending)
+    workers.Wait()
+
+    var orphans []int64
+    for id := range byBookmark {
+        if !current[id] {
+            orphans = append(orphans, id)
+        }
+    }
+    if len(orphans) == 0 {
+        return nil
+    }
+    return s.linkHealthRepo.Delete(orphans...)
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+    "reflect"
+    "sort"
+    "testing"
+
+    "hyprlnk/internal/models"
+)
+
+// markMoved records a link check that found bookmark redirecting to movedTo
+func markMoved(t *testing.T, service *hyprLinkService, bookmark models.Bookmark, movedTo string) {
+    t.Helper()
+    health := models.LinkHealth{BookmarkID: bookmark.ID, URL: bookmark.URL, Status: models.LinkMoved, MovedTo: movedTo}
+    if err := service.linkHealthRepo.Save(health); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestApplyRedirects(t *testing.T) {
+    service, _ := newTestService(t)
+    moving := mustCreateBookmark(t, service, newBookmark("https://old.example/a", "a"))
+    taken := mustCreateBookmark(t, service, newBookmark("https://new.example/b", "b"))
+    first := mustCreateBookmark(t, service, newBookmark("https://old.example/c", "c"))
+    second := mustCreateBookmark(t, service, newBookmark("https://old.example/d", "d"))
+    untouched := mustCreateBookmark(t, service, newBookmark("https://old.example/e", "e"))
+
+    markMoved(t, service, moving, "https://new.example/b")   // already bookmarked
+    markMoved(t, service, first, "https://fresh.example/")   // free
+    markMoved(t, service, second, "https://fresh.example")   // same place as first
+    markMoved(t, service, untouched, "https://elsewhere.example/")
+
+    result, err := service.ApplyRedirects([]int64{moving.ID, first.ID, second.ID})
+    if err != nil {
+        t.Fatalf("ApplyRedirects failed: %v", err)
+    }
+    var returned []int64
+    for _, bookmark := range result {
+        returned = append(returned, bookmark.ID)
+    }
+    sort.Slice(returned, func(i, j int) bool { return returned[i] < returned[j] })
+    if want := []int64{taken.ID, first.ID}; !reflect.DeepEqual(returned, want) {
+        t.Errorf("Expected the bookmarks kept, %v, got %v", want, returned)
+    }
+
+    // Moving onto an existing bookmark merges into it
+    if _, err := service.GetBookmark(moving.ID); err == nil {
+        t.Error("Expected the moved bookmark to be merged away")
+    }
+    if got := tagsOf(t, service, taken.ID); !reflect.DeepEqual(got, []string{"b", "a"}) {
+        t.Errorf("Expected the existing bookmark to gain the moved one's tags, got %v", got)
+    }
+
+    // Two bookmarks moving to one URL end up as one bookmark there
+    kept, err := service.GetBookmark(first.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if kept.URL != "https://fresh.example/" || !reflect.DeepEqual(kept.Tags, []string{"c", "d"}) {
+        t.Errorf("Expected one bookmark at the new URL with both tags, got %+v", kept)
+    }
+    if _, err := service.GetBookmark(second.ID); err == nil {
+        t.Error("Expected the second bookmark moving to the same URL to be merged away")
+    }
+
+    if bookmark, _ := service.GetBookmark(untouched.ID); bookmark.URL != untouched.URL {
+        t.Errorf("Expected a bookmark not asked for to stay, got %s", bookmark.URL)
+    }
+    bookmarks, _ := service.GetAllBookmarks()
+    if len(bookmarks) != 3 {
+        t.Errorf("Expected 3 bookmarks left, got %d", len(bookmarks))
+    }
+}
+
+func TestApplyRedirects_Swap(t *testing.T) {
+    service, _ := newTestService(t)
+    a := mustCreateBookmark(t, service, newBookmark("https://example.com/a", "a"))
+    b := mustCreateBookmark(t, service, newBookmark("https://example.com/b", "b"))
+
+    // b's URL is being vacated by b, so a can move there
+    markMoved(t, service, a, "https://example.com/b")
+    markMoved(t, service, b, "https://example.com/c")
+
+    result, err := service.ApplyRedirects(nil)
+    if err != nil {
+        t.Fatalf("ApplyRedirects failed: %v", err)
+    }
+    if len(result) != 2 {
+        t.Fatalf("Expected both bookmarks updated, got %+v", result)
+    }
+    if got, _ := service.GetBookmark(a.ID); got.URL != "https://example.com/b" {
+        t.Errorf("Expected a to move to /b, got %s", got.URL)
+    }
+    if got, _ := service.GetBookmark(b.ID); got.URL != "https://example.com/c" {
+        t.Errorf("Expected b to move to /c, got %s", got.URL)
+    }
+}
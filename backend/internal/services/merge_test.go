@@ -0,0 +1,81 @@
+package services
+
+import (
+    "errors"
+    "reflect"
+    "testing"
+
+    "hyprlnk/internal/models"
+)
+
+func TestMergeBookmarks(t *testing.T) {
+    service, _ := newTestService(t)
+    kept := mustCreateBookmark(t, service, newBookmark("https://example.com/a", "a"))
+    merged := mustCreateBookmark(t, service, newBookmark("https://example.com/b", "b"))
+
+    result, err := service.MergeBookmarks([]int64{kept.ID, merged.ID, merged.ID}, 0)
+    if err != nil {
+        t.Fatalf("Merge failed: %v", err)
+    }
+    if result.ID != kept.ID || !reflect.DeepEqual(result.Tags, []string{"a", "b"}) {
+        t.Errorf("Expected the oldest bookmark kept with both tags, got %+v", result)
+    }
+    if _, err := service.GetBookmark(merged.ID); err == nil {
+        t.Error("Expected the other bookmark merged away")
+    }
+
+    // The merged-away bookmark can be got back from the trash
+    trash, _ := service.GetTrash()
+    if len(trash) != 1 || trash[0].ID != merged.ID {
+        t.Fatalf("Expected the merged-away bookmark in the trash, got %+v", trash)
+    }
+    if _, err := service.RestoreFromTrash(models.ItemBookmark, merged.ID); err != nil {
+        t.Fatalf("Restore failed: %v", err)
+    }
+    if got := tagsOf(t, service, merged.ID); !reflect.DeepEqual(got, []string{"b"}) {
+        t.Errorf("Expected the bookmark back as it was, got %v", got)
+    }
+
+    log, _ := service.GetRevisions(models.ItemBookmark, kept.ID)
+    if n := len(log.Revisions); n != 2 {
+        t.Errorf("Expected a create and one merge revision, got %d", n)
+    }
+}
+
+func TestMergeBookmarks_Undo(t *testing.T) {
+    service, _ := newTestService(t)
+    kept := mustCreateBookmark(t, service, newBookmark("https://example.com/a", "a"))
+    merged := mustCreateBookmark(t, service, newBookmark("https://example.com/b", "b"))
+    if _, err := service.MergeBookmarks([]int64{kept.ID, merged.ID}, kept.ID); err != nil {
+        t.Fatal(err)
+    }
+
+    undoOne(t, service, models.OperationMerge)
+    if got := tagsOf(t, service, kept.ID); !reflect.DeepEqual(got, []string{"a"}) {
+        t.Errorf("Expected the kept bookmark's tags back, got %v", got)
+    }
+    if got := tagsOf(t, service, merged.ID); !reflect.DeepEqual(got, []string{"b"}) {
+        t.Errorf("Expected the merged-away bookmark back, got %v", got)
+    }
+    if trash, _ := service.GetTrash(); len(trash) != 0 {
+        t.Errorf("Expected the trash emptied by the undo, got %+v", trash)
+    }
+}
+
+func TestMergeBookmarks_Invalid(t *testing.T) {
+    service, _ := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://example.com/a"))
+    other := mustCreateBookmark(t, service, newBookmark("https://example.com/b"))
+
+    for _, ids := range [][]int64{nil, {bookmark.ID}, {bookmark.ID, bookmark.ID}} {
+        if _, err := service.MergeBookmarks(ids, 0); !errors.Is(err, ErrInvalidInput) {
+            t.Errorf("%v: expected invalid input, got %v", ids, err)
+        }
+    }
+    if _, err := service.MergeBookmarks([]int64{bookmark.ID, other.ID}, 42); err == nil {
+        t.Error("Expected a target outside the merged IDs rejected")
+    }
+    if log, _ := service.GetRevisions(models.ItemBookmark, bookmark.ID); len(log.Revisions) != 1 {
+        t.Errorf("Expected no revisions from rejected merges, got %d", len(log.Revisions))
+    }
+}
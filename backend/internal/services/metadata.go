@@ -0,0 +1,312 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "mime"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+
+    "hyprlnk/internal/fetch"
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/pagemeta"
+)
+
+const (
+    // enrichWorkers fetch pages in parallel; the fetcher still spaces out
+    // requests to any one host
+    enrichWorkers = 4
+    // enrichInterval is how often the worker looks for bookmarks to fetch
+    // when nothing wakes it sooner
+    enrichInterval = 10 * time.Minute
+    // enrichMaxAttempts is how often a failing page is tried before giving up
+    enrichMaxAttempts = 3
+    // enrichRetryDelay is the wait before the first retry, doubling after each
+    enrichRetryDelay = time.Hour
+    // maxFaviconSize caps downloaded icons
+    maxFaviconSize = 256 << 10
+)
+
+// GetBookmarkMetadata returns what fetching a bookmark's page found
+func (s *hyprLinkService) GetBookmarkMetadata(id int64) (*models.PageMetadata, error) {
+    metadata, err := s.metadataRepo.Get(id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+    return metadata, nil
+}
+
+// RefreshBookmarkMetadata fetches a bookmark's page now, rather than
+// waiting for the background worker
+func (s *hyprLinkService) RefreshBookmarkMetadata(id int64) (*models.PageMetadata, error) {
+    if s.fetcher == nil {
+        return nil, fmt.Errorf("%w: page fetching is disabled", ErrConflict)
+    }
+    bookmark, err := s.bookmarkRepo.GetByID(id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+
+    previous, _ := s.metadataRepo.Get(id)
+    previousArchive, _ := s.archiveRepo.Get(id)
+    return s.enrichBookmark(context.Background(), *bookmark, previous, previousArchive)
+}
+
+// GetFavicon returns a host's stored icon and its content type
+func (s *hyprLinkService) GetFavicon(host string) ([]byte, string, error) {
+    data, contentType, err := s.metadataRepo.GetFavicon(strings.ToLower(host))
+    if err != nil {
+        return nil, "", kindError{kind: ErrNotFound, err: fmt.Errorf("no favicon stored for %s", host)}
+    }
+    return data, contentType, nil
+}
+
+// runEnrichment fetches metadata for new bookmarks, and for bookmarks whose
+// URL changed, until the process exits
+func (s *hyprLinkService) runEnrichment() {
+    ticker := time.NewTicker(enrichInterval)
+    defer ticker.Stop()
+
+    for {
+        if err := s.enrichPending(context.Background()); err != nil {
+            log.Printf("metadata: %v", err)
+        }
+        select {
+        case <-s.enrichWake:
+        case <-ticker.C:
+        }
+    }
+}
+
+// enrichPending fetches every bookmark that needs it and forgets the
+// metadata of deleted bookmarks
+func (s *hyprLinkService) enrichPending(ctx context.Context) error {
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return err
+    }
+    stored, err := s.metadataRepo.GetAll()
+    if err != nil {
+        return err
+    }
+    archived, err := s.archiveRepo.GetAll()
+    if err != nil {
+        return err
+    }
+
+    byBookmark := make(map[int64]*models.PageMetadata, len(stored))
+    for i := range stored {
+        byBookmark[stored[i].BookmarkID] = &stored[i]
+    }
+    archives := make(map[int64]*models.PageArchive, len(archived))
+    for i := range archived {
+        archives[archived[i].BookmarkID] = &archived[i]
+    }
+
+    pending := make(chan models.Bookmark)
+    var workers sync.WaitGroup
+    for i := 0; i < enrichWorkers; i++ {
+        workers.Add(1)
+        go func() {
+            defer workers.Done()
+            for bookmark := range pending {
+                if _, err := s.enrichBookmark(ctx, bookmark, byBookmark[bookmark.ID], archives[bookmark.ID]); err != nil {
+                    log.Printf("metadata: bookmark %d: %v", bookmark.ID, err)
+                }
+            }
+        }()
+    }
+
+    current := make(map[int64]bool, len(bookmarks))
+    for _, bookmark := range bookmarks {
+        current[bookmark.ID] = true
+        metadata := byBookmark[bookmark.ID]
+        // Archiving reuses the metadata fetch, and waits out its retries
+        archiveDue := s.archivePages && metadata != nil && metadata.Error == "" && needsArchive(bookmark, archives[bookmark.ID])
+        if needsMetadata(bookmark, metadata) || archiveDue {
+            pending <- bookmark
+        }
+    }
+    close(pending)
+    workers.Wait()
+
+    var orphans, orphanArchives []int64
+    for id := range byBookmark {
+        if !current[id] {
+            orphans = append(orphans, id)
+        }
+    }
+    for id := range archives {
+        if !current[id] {
+            orphanArchives = append(orphanArchives, id)
+        }
+    }
+    if len(orphanArchives) > 0 {
+        if err := s.archiveRepo.Delete(orphanArchives...); err != nil {
+            return err
+        }
+        s.searchIndex().Delete(orphanArchives...)
+    }
+    if len(orphans) == 0 {
+        return nil
+    }
+    return s.metadataRepo.Delete(orphans...)
+}
+
+// needsMetadata is true for bookmarks never fetched, whose URL changed
+// since, or whose last fetch failed and is due a retry
+func needsMetadata(bookmark models.Bookmark, metadata *models.PageMetadata) bool {
+    if metadata == nil || metadata.URL != bookmark.URL {
+        return true
+    }
+    if metadata.Error == "" || metadata.Attempts >= enrichMaxAttempts {
+        return false
+    }
+    retryAfter := enrichRetryDelay << (metadata.Attempts - 1)
+    return time.Since(metadata.FetchedAt) >= retryAfter
+}
+
+// enrichBookmark fetches a bookmark's page, stores its metadata and
+// favicon, and fills in a blank or junk title and an empty description.
+// With background archiving on, the same response is archived.
+func (s *hyprLinkService) enrichBookmark(ctx context.Context, bookmark models.Bookmark, previous *models.PageMetadata, previousArchive *models.PageArchive) (*models.PageMetadata, error) {
+    metadata := models.PageMetadata{BookmarkID: bookmark.ID, URL: bookmark.URL, FetchedAt: time.Now()}
+    failed := func(err error) (*models.PageMetadata, error) {
+        metadata.Error = err.Error()
+        metadata.Attempts = 1
+        if previous != nil && previous.URL == bookmark.URL {
+            metadata.Attempts = previous.Attempts + 1
+        }
+        if errors.Is(err, fetch.ErrDisallowed) {
+            metadata.Attempts = enrichMaxAttempts
+        }
+        return &metadata, s.metadataRepo.Save(metadata)
+    }
+
+    resp, err := s.fetcher.Get(ctx, bookmark.URL)
+    if err != nil {
+        return failed(err)
+    }
+    metadata.FinalURL = resp.URL.String()
+    metadata.StatusCode = resp.StatusCode
+    if resp.StatusCode >= 400 {
+        return failed(fmt.Errorf("HTTP %d", resp.StatusCode))
+    }
+
+    contentType := resp.Header.Get("Content-Type")
+    if isHTML(contentType) {
+        page := pagemeta.Extract(resp.Body, resp.URL, contentType)
+        metadata.Title = page.BestTitle()
+        metadata.Description = page.BestDescription()
+        metadata.CanonicalURL = page.CanonicalURL
+        if len(page.OpenGraph) > 0 {
+            metadata.OpenGraph = page.OpenGraph
+        }
+        if page.FaviconURL != "" {
+            // Stored under the bookmarked host, which is what clients know
+            metadata.Favicon = s.storeFavicon(ctx, hostOf(bookmark.URL), page.FaviconURL)
+        }
+    }
+
+    if err := s.metadataRepo.Save(metadata); err != nil {
+        return nil, err
+    }
+    if s.archivePages && needsArchive(bookmark, previousArchive) {
+        if _, err := s.archivePage(ctx, bookmark, resp, previousArchive); err != nil {
+            log.Printf("archive: bookmark %d: %v", bookmark.ID, err)
+        }
+    }
+    return &metadata, s.applyMetadata(bookmark.ID, metadata)
+}
+
+// isHTML is true for HTML pages, and for pages that don't say what they are
+func isHTML(contentType string) bool {
+    mediaType, _, _ := mime.ParseMediaType(contentType)
+    return mediaType == "text/html" || mediaType == "application/xhtml+xml" || contentType == ""
+}
+
+// storeFavicon downloads a host's icon unless it is already stored, and
+// returns the local path it is served from, or "" if there is none
+func (s *hyprLinkService) storeFavicon(ctx context.Context, host, iconURL string) string {
+    if host == "" {
+        return ""
+    }
+    path := "/api/favicons/" + url.PathEscape(host)
+    if _, _, err := s.metadataRepo.GetFavicon(host); err == nil {
+        return path
+    }
+
+    resp, err := s.fetcher.GetLimit(ctx, iconURL, maxFaviconSize)
+    if err != nil || resp.StatusCode != http.StatusOK || resp.Truncated || len(resp.Body) == 0 {
+        return ""
+    }
+
+    contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+    if !strings.HasPrefix(contentType, "image/") {
+        // Servers often send icons as application/octet-stream
+        contentType, _, _ = mime.ParseMediaType(http.DetectContentType(resp.Body))
+    }
+    if err := s.metadataRepo.SaveFavicon(host, contentType, resp.Body); err != nil {
+        return ""
+    }
+    return path
+}
+
+// applyMetadata fills in the bookmark's title if it is blank or junk, and
+// its description if empty. It re-reads the bookmark so edits made while
+// the page was fetched aren't lost.
+func (s *hyprLinkService) applyMetadata(id int64, metadata models.PageMetadata) error {
+    bookmark, err := s.bookmarkRepo.GetByID(id)
+    if err != nil || bookmark.URL != metadata.URL {
+        return nil // deleted or changed meanwhile
+    }
+
+    changed := false
+    if metadata.Title != "" && isJunkTitle(bookmark.Title, bookmark.URL) {
+        bookmark.Title = metadata.Title
+        changed = true
+    }
+    if metadata.Description != "" && strings.TrimSpace(bookmark.Description) == "" {
+        bookmark.Description = metadata.Description
+        changed = true
+    }
+    if !changed {
+        return nil
+    }
+
+    bookmark.UpdatedAt = time.Now()
+    return s.bookmarkRepo.UpdateManyUntracked([]models.Bookmark{*bookmark})
+}
+
+func hostOf(rawURL string) string {
+    parsed, err := url.Parse(rawURL)
+    if err != nil {
+        return ""
+    }
+    return strings.ToLower(parsed.Hostname())
+}
+
+// isJunkTitle spots titles that say nothing: blank, the URL itself, the
+// bare host name or browser placeholders
+func isJunkTitle(title, rawURL string) bool {
+    title = strings.ToLower(strings.TrimSpace(title))
+    if title == "" || title == strings.ToLower(rawURL) {
+        return true
+    }
+    if strings.HasPrefix(title, "http://") || strings.HasPrefix(title, "https://") {
+        return true
+    }
+    if host := hostOf(rawURL); host != "" && (title == host || title == strings.TrimPrefix(host, "www.")) {
+        return true
+    }
+    switch title {
+    case "untitled", "new tab", "loading...", "loading…", "home", "index", "about:blank":
+        return true
+    }
+    return false
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/urlnorm"
+)
+
+// Reading list orders
+const (
+    readingSortNewest   = "newest"   // most recently saved first
+    readingSortOldest   = "oldest"   // first saved first
+    readingSortTitle    = "title"    // alphabetical
+    readingSortProgress = "progress" // furthest along first
+    readingSortRead     = "read"     // most recently read first
+)
+
+var readingStatuses = map[string]bool{
+    models.ReadingUnread:   true,
+    models.ReadingReading:  true,
+    models.ReadingRead:     true,
+    models.ReadingArchived: true,
+}
+
+// GetReadingList returns the bookmarks on the reading list. With no status
+// it lists what is still to read, unread and reading; "all" lists every
+// state. sortBy is one of newest (the default), oldest, title, progress
+// and read.
+func (s *hyprLinkService) GetReadingList(status, sortBy string) ([]models.Bookmark, error) {
+    if status != "" && status != "all" && !readingStatuses[status] {
+        return nil, fmt.Errorf("%w: unknown reading status %q", ErrInvalidInput, status)
+    }
+    less, err := readingOrder(sortBy)
+    if err != nil {
+        return nil, err
+    }
+
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+
+    queue := []models.Bookmark{}
+    for _, bookmark := range bookmarks {
+        switch {
+        case bookmark.Status == "":
+            continue
+        case status == "":
+            if bookmark.Status != models.ReadingUnread && bookmark.Status != models.ReadingReading {
+                continue
+            }
+        case status != "all" && bookmark.Status != status:
+            continue
+        }
+        queue = append(queue, bookmark)
+    }
+
+    sort.Slice(queue, func(i, j int) bool {
+        return less(queue[i], queue[j])
+    })
+    return queue, nil
+}
+
+// readingOrder returns the comparison for a reading list order; ties go
+// to the most recently saved bookmark
+func readingOrder(sortBy string) (func(a, b models.Bookmark) bool, error) {
+    newest := func(a, b models.Bookmark) bool {
+        if !a.CreatedAt.Equal(b.CreatedAt) {
+            return a.CreatedAt.After(b.CreatedAt)
+        }
+        return a.ID > b.ID
+    }
+
+    switch sortBy {
+    case "", readingSortNewest:
+        return newest, nil
+    case readingSortOldest:
+        return func(a, b models.Bookmark) bool { return newest(b, a) }, nil
+    case readingSortTitle:
+        return func(a, b models.Bookmark) bool {
+            if ta, tb := strings.ToLower(a.Title), strings.ToLower(b.Title); ta != tb {
+                return ta < tb
+            }
+            return newest(a, b)
+        }, nil
+    case readingSortProgress:
+        return func(a, b models.Bookmark) bool {
+            if a.Progress != b.Progress {
+                return a.Progress > b.Progress
+            }
+            return newest(a, b)
+        }, nil
+    case readingSortRead:
+        return func(a, b models.Bookmark) bool {
+            if !a.ReadAt.Equal(b.ReadAt) {
+                return a.ReadAt.After(b.ReadAt)
+            }
+            return newest(a, b)
+        }, nil
+    }
+    return nil, fmt.Errorf("%w: unknown reading list order %q (use %s, %s, %s, %s or %s)", ErrInvalidInput, sortBy,
+        readingSortNewest, readingSortOldest, readingSortTitle, readingSortProgress, readingSortRead)
+}
+
+// SetReadingState puts a bookmark on the reading list or moves it along.
+// An empty status keeps the current one, or queues the bookmark as unread,
+// and progress alone advances unread to reading and reading to read at 100%.
+// A nil progress leaves it alone, except that read means 100% and unread 0%.
+func (s *hyprLinkService) SetReadingState(id int64, status string, progress *int) (*models.Bookmark, error) {
+    if status != "" && !readingStatuses[status] {
+        return nil, fmt.Errorf("%w: unknown reading status %q", ErrInvalidInput, status)
+    }
+    if progress != nil && (*progress < 0 || *progress > 100) {
+        return nil, fmt.Errorf("%w: progress must be between 0 and 100", ErrInvalidInput)
+    }
+
+    bookmark, err := s.bookmarkRepo.GetByID(id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+
+    applyReadingState(bookmark, status, progress, time.Now())
+
+    if err := s.bookmarkRepo.UpdateMany([]models.Bookmark{*bookmark}); err != nil {
+        return nil, err
+    }
+    return bookmark, nil
+}
+
+// applyReadingState moves a bookmark to status and progress, both already
+// validated, as SetReadingState describes
+func applyReadingState(bookmark *models.Bookmark, status string, progress *int, now time.Time) {
+    explicit := status != ""
+    if !explicit {
+        status = bookmark.Status
+        if status == "" {
+            status = models.ReadingUnread
+        }
+    }
+    if progress != nil {
+        bookmark.Progress = *progress
+        if !explicit && status == models.ReadingUnread && bookmark.Progress > 0 {
+            status = models.ReadingReading
+        }
+        if !explicit && status == models.ReadingReading && bookmark.Progress == 100 {
+            status = models.ReadingRead
+        }
+    }
+
+    switch status {
+    case models.ReadingUnread:
+        if progress == nil {
+            bookmark.Progress = 0
+        }
+        bookmark.ReadAt = time.Time{}
+    case models.ReadingReading:
+        bookmark.ReadAt = time.Time{}
+    case models.ReadingRead:
+        if progress == nil {
+            bookmark.Progress = 100
+        }
+        if bookmark.Status != models.ReadingRead || bookmark.ReadAt.IsZero() {
+            bookmark.ReadAt = now
+        }
+    }
+    bookmark.Status = status
+    bookmark.UpdatedAt = now
+}
+
+// RemoveFromReadingList takes a bookmark off the reading list, forgetting
+// its progress; the bookmark itself stays
+func (s *hyprLinkService) RemoveFromReadingList(id int64) (*models.Bookmark, error) {
+    bookmark, err := s.bookmarkRepo.GetByID(id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+    if bookmark.Status == "" {
+        return bookmark, nil
+    }
+
+    bookmark.Status = ""
+    bookmark.Progress = 0
+    bookmark.ReadAt = time.Time{}
+    bookmark.UpdatedAt = time.Now()
+    // Update would keep the old state for a bookmark without one
+    if err := s.bookmarkRepo.UpdateMany([]models.Bookmark{*bookmark}); err != nil {
+        return nil, err
+    }
+    return bookmark, nil
+}
+
+// checkReadingState validates the reading state of a bookmark being saved,
+// stamping ReadAt on bookmarks saved as read
+func checkReadingState(bookmark *models.Bookmark) error {
+    if bookmark.Status != "" && !readingStatuses[bookmark.Status] {
+        return fmt.Errorf("%w: unknown reading status %q", ErrInvalidInput, bookmark.Status)
+    }
+    if bookmark.Progress < 0 || bookmark.Progress > 100 {
+        return fmt.Errorf("%w: progress must be between 0 and 100", ErrInvalidInput)
+    }
+    if bookmark.Status == models.ReadingRead && bookmark.ReadAt.IsZero() {
+        bookmark.ReadAt = time.Now()
+    }
+    return nil
+}
+
+// markVisitedRead marks queued bookmarks read when synced history shows a
+// visit to their page that lasted the configured dwell time
+func (s *hyprLinkService) markVisitedRead(entries []models.HistoryEntry) error {
+    settings, err := s.settingsRepo.Get()
+    if err != nil {
+        return err
+    }
+    dwell := readDwellSeconds(settings)
+    if dwell <= 0 {
+        return nil
+    }
+
+    now := time.Now()
+    normalizer := urlnorm.New(settings.URLRules)
+    visited := make(map[string]time.Time)
+    for _, entry := range entries {
+        if entry.DwellSeconds < dwell {
+            continue
+        }
+        at := entry.LastVisitTime
+        if at.IsZero() {
+            at = now
+        }
+        key := normalizer.Normalize(entry.URL)
+        if at.After(visited[key]) {
+            visited[key] = at
+        }
+    }
+    if len(visited) == 0 {
+        return nil
+    }
+
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return err
+    }
+    var updated []models.Bookmark
+    for _, bookmark := range bookmarks {
+        if bookmark.Status != models.ReadingUnread && bookmark.Status != models.ReadingReading {
+            continue
+        }
+        // The visit during which the page was saved doesn't count
+        at, ok := visited[normalizer.Normalize(bookmark.URL)]
+        if !ok || !at.After(bookmark.CreatedAt) {
+            continue
+        }
+        bookmark.Status = models.ReadingRead
+        bookmark.Progress = 100
+        bookmark.ReadAt = at
+        bookmark.UpdatedAt = now
+        updated = append(updated, bookmark)
+    }
+    if len(updated) == 0 {
+        return nil
+    }
+    return s.bookmarkRepo.UpdateManyUntracked(updated)
+}
+
+// readDwellSeconds is the visit length that marks a bookmark read; 0
+// means the default and a negative value that visits never do
+func readDwellSeconds(settings *models.Settings) int {
+    if settings.ReadDwellSeconds == 0 {
+        return models.DefaultReadDwellSeconds
+    }
+    return max(settings.ReadDwellSeconds, 0)
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+    "errors"
+    "slices"
+    "testing"
+    "time"
+
+    "hyprlnk/internal/models"
+)
+
+func TestApplyReadingState(t *testing.T) {
+    earlier := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+    now := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+    percent := func(p int) *int { return &p }
+
+    tests := []struct {
+        name     string
+        before   models.Bookmark
+        status   string
+        progress *int
+        want     models.Bookmark
+    }{
+        {"queue", models.Bookmark{}, "", nil,
+            models.Bookmark{Status: models.ReadingUnread}},
+        {"progress starts reading", models.Bookmark{Status: models.ReadingUnread}, "", percent(30),
+            models.Bookmark{Status: models.ReadingReading, Progress: 30}},
+        {"progress on a new bookmark", models.Bookmark{}, "", percent(10),
+            models.Bookmark{Status: models.ReadingReading, Progress: 10}},
+        {"finishing reads", models.Bookmark{Status: models.ReadingReading, Progress: 80}, "", percent(100),
+            models.Bookmark{Status: models.ReadingRead, Progress: 100, ReadAt: now}},
+        {"full progress on unread", models.Bookmark{Status: models.ReadingUnread}, "", percent(100),
+            models.Bookmark{Status: models.ReadingRead, Progress: 100, ReadAt: now}},
+        {"mark read", models.Bookmark{Status: models.ReadingReading, Progress: 40}, models.ReadingRead, nil,
+            models.Bookmark{Status: models.ReadingRead, Progress: 100, ReadAt: now}},
+        {"read keeps its first ReadAt", models.Bookmark{Status: models.ReadingRead, Progress: 100, ReadAt: earlier}, models.ReadingRead, nil,
+            models.Bookmark{Status: models.ReadingRead, Progress: 100, ReadAt: earlier}},
+        {"explicit status with progress", models.Bookmark{Status: models.ReadingUnread}, models.ReadingUnread, percent(50),
+            models.Bookmark{Status: models.ReadingUnread, Progress: 50}},
+        {"back to unread", models.Bookmark{Status: models.ReadingRead, Progress: 100, ReadAt: earlier}, models.ReadingUnread, nil,
+            models.Bookmark{Status: models.ReadingUnread}},
+        {"reread", models.Bookmark{Status: models.ReadingRead, Progress: 100, ReadAt: earlier}, models.ReadingReading, nil,
+            models.Bookmark{Status: models.ReadingReading, Progress: 100}},
+        {"archive", models.Bookmark{Status: models.ReadingRead, Progress: 100, ReadAt: earlier}, models.ReadingArchived, nil,
+            models.Bookmark{Status: models.ReadingArchived, Progress: 100, ReadAt: earlier}},
+    }
+    for _, tt := range tests {
+        bookmark := tt.before
+        applyReadingState(&bookmark, tt.status, tt.progress, now)
+        if bookmark.Status != tt.want.Status || bookmark.Progress != tt.want.Progress || !bookmark.ReadAt.Equal(tt.want.ReadAt) {
+            t.Errorf("%s: expected %s at %d%% read %s, got %s at %d%% read %s", tt.name,
+                tt.want.Status, tt.want.Progress, tt.want.ReadAt, bookmark.Status, bookmark.Progress, bookmark.ReadAt)
+        }
+        if !bookmark.UpdatedAt.Equal(now) {
+            t.Errorf("%s: expected UpdatedAt stamped, got %s", tt.name, bookmark.UpdatedAt)
+        }
+    }
+}
+
+func TestReadDwellSeconds(t *testing.T) {
+    tests := []struct{ setting, want int }{
+        {0, models.DefaultReadDwellSeconds},
+        {-1, 0},
+        {-30, 0},
+        {120, 120},
+    }
+    for _, tt := range tests {
+        if got := readDwellSeconds(&models.Settings{ReadDwellSeconds: tt.setting}); got != tt.want {
+            t.Errorf("readDwellSeconds(%d) = %d, want %d", tt.setting, got, tt.want)
+        }
+    }
+}
+
+func TestMarkVisitedRead(t *testing.T) {
+    later := time.Now().Add(time.Hour)
+
+    tests := []struct {
+        name    string
+        setting int
+        entry   models.HistoryEntry
+        read    bool
+    }{
+        {"long visit", 0, models.HistoryEntry{URL: "https://example.com/article", DwellSeconds: 60, LastVisitTime: later}, true},
+        {"short visit", 0, models.HistoryEntry{URL: "https://example.com/article", DwellSeconds: 59, LastVisitTime: later}, false},
+        {"same page by canonical URL", 0, models.HistoryEntry{URL: "http://www.example.com/article/?utm_source=feed", DwellSeconds: 90, LastVisitTime: later}, true},
+        {"the visit that saved it", 0, models.HistoryEntry{URL: "https://example.com/article", DwellSeconds: 300, LastVisitTime: time.Now().Add(-time.Hour)}, false},
+        {"custom threshold", 10, models.HistoryEntry{URL: "https://example.com/article", DwellSeconds: 10, LastVisitTime: later}, true},
+        {"turned off", -1, models.HistoryEntry{URL: "https://example.com/article", DwellSeconds: 3600, LastVisitTime: later}, false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            service, _ := newTestService(t)
+            settings, _ := service.settingsRepo.Get()
+            settings.ReadDwellSeconds = tt.setting
+            if err := service.settingsRepo.Update(settings); err != nil {
+                t.Fatal(err)
+            }
+            queued := newBookmark("https://example.com/article")
+            queued.Status = models.ReadingUnread
+            queued = mustCreateBookmark(t, service, queued)
+            unqueued := mustCreateBookmark(t, service, newBookmark("https://example.com/other"))
+
+            visit := tt.entry
+            visit.Title, visit.VisitCount = "Visit", 1
+            other := models.HistoryEntry{URL: unqueued.URL, Title: "Other", VisitCount: 1, DwellSeconds: 3600, LastVisitTime: later}
+            if _, err := service.SyncHistory([]models.HistoryEntry{visit, other}); err != nil {
+                t.Fatalf("Sync failed: %v", err)
+            }
+
+            got, _ := service.GetBookmark(queued.ID)
+            if read := got.Status == models.ReadingRead; read != tt.read {
+                t.Fatalf("Expected read %v, got status %q", tt.read, got.Status)
+            }
+            if tt.read && (got.Progress != 100 || !got.ReadAt.Equal(tt.entry.LastVisitTime)) {
+                t.Errorf("Expected 100%% read at the visit, got %d%% at %s", got.Progress, got.ReadAt)
+            }
+            if got, _ := service.GetBookmark(unqueued.ID); got.Status != "" {
+                t.Errorf("Expected a bookmark off the reading list left alone, got %q", got.Status)
+            }
+        })
+    }
+}
+
+func TestGetReadingList(t *testing.T) {
+    service, _ := newTestService(t)
+    base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+    states := []struct {
+        title    string
+        status   string
+        progress int
+        readAt   time.Time
+    }{
+        {"Bravo", models.ReadingUnread, 0, time.Time{}},
+        {"alpha", models.ReadingReading, 40, time.Time{}},
+        {"Charlie", models.ReadingRead, 100, base.Add(2 * time.Hour)},
+        {"Delta", models.ReadingArchived, 100, base.Add(time.Hour)},
+        {"Echo", models.ReadingReading, 70, time.Time{}},
+        {"Plain", "", 0, time.Time{}},
+    }
+    var bookmarks []models.Bookmark
+    for i, state := range states {
+        bookmark := mustCreateBookmark(t, service, newBookmark("https://example.com/"+state.title))
+        bookmark.Title = state.title
+        bookmark.Status = state.status
+        bookmark.Progress = state.progress
+        bookmark.ReadAt = state.readAt
+        bookmark.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+        bookmarks = append(bookmarks, bookmark)
+    }
+    // Stored directly, to control the save times
+    if err := service.bookmarkRepo.UpdateManyUntracked(bookmarks); err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        status, sortBy string
+        want           []string
+    }{
+        {"", "", []string{"Echo", "alpha", "Bravo"}},
+        {"", "oldest", []string{"Bravo", "alpha", "Echo"}},
+        {"", "title", []string{"alpha", "Bravo", "Echo"}},
+        {"", "progress", []string{"Echo", "alpha", "Bravo"}},
+        {models.ReadingRead, "", []string{"Charlie"}},
+        {"all", "read", []string{"Charlie", "Delta", "Echo", "alpha", "Bravo"}},
+    }
+    for _, tt := range tests {
+        list, err := service.GetReadingList(tt.status, tt.sortBy)
+        if err != nil {
+            t.Fatalf("%q/%q: %v", tt.status, tt.sortBy, err)
+        }
+        got := []string{}
+        for _, bookmark := range list {
+            got = append(got, bookmark.Title)
+        }
+        if !slices.Equal(got, tt.want) {
+            t.Errorf("%q/%q: expected %v, got %v", tt.status, tt.sortBy, tt.want, got)
+        }
+    }
+
+    if _, err := service.GetReadingList("someday", ""); !errors.Is(err, ErrInvalidInput) {
+        t.Errorf("Expected an unknown status rejected, got %v", err)
+    }
+    if _, err := service.GetReadingList("", "random"); !errors.Is(err, ErrInvalidInput) {
+        t.Errorf("Expected an unknown order rejected, got %v", err)
+    }
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+    "encoding/json"
+    "fmt"
+    "sort"
+
+    "hyprlnk/internal/models"
+)
+
+// GetRevisions returns a bookmark's or session's recent revisions, oldest
+// first
+func (s *hyprLinkService) GetRevisions(itemType string, id int64) (*models.RevisionLog, error) {
+    log, err := s.revisionRepo.Get(itemType, id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+    return log, nil
+}
+
+// RestoreRevision puts an item back as it was after revision rev, or as it
+// was before it when rev deleted the item, and returns it. Restoring is
+// itself recorded, so it can be undone.
+func (s *hyprLinkService) RestoreRevision(itemType string, id int64, rev int) (json.RawMessage, error) {
+    log, err := s.GetRevisions(itemType, id)
+    if err != nil {
+        return nil, err
+    }
+
+    var revision *models.Revision
+    for i := range log.Revisions {
+        if log.Revisions[i].Rev == rev {
+            revision = &log.Revisions[i]
+            break
+        }
+    }
+    if revision == nil {
+        return nil, kindError{kind: ErrNotFound, err: fmt.Errorf("%s %d has no revision %d", itemType, id, rev)}
+    }
+
+    state := revision.After
+    if len(state) == 0 {
+        state = revision.Before
+    }
+    states := []models.ItemState{{ItemType: itemType, ItemID: id, State: state}}
+    if err := s.revert(models.OperationRestore, states, 0); err != nil {
+        return nil, err
+    }
+    return state, nil
+}
+
+// GetOperations lists the most recent operations, newest first, as far
+// back as the items' revision logs reach
+func (s *hyprLinkService) GetOperations(limit int) ([]models.Operation, error) {
+    operations, _, err := s.operations()
+    if err != nil {
+        return nil, err
+    }
+    if limit > 0 && len(operations) > limit {
+        operations = operations[:limit]
+    }
+    return operations, nil
+}
+
+// Undo reverts the last count operations that haven't been undone yet,
+// newest first, putting every item they touched back as it was before.
+// Deleted items come back and created ones are deleted. Undos themselves
+// are skipped, so undoing repeatedly keeps going further back. It stops
+// with a conflict at an operation whose revisions were partly trimmed,
+// rather than undoing only some of its items.
+func (s *hyprLinkService) Undo(count int) ([]models.Operation, error) {
+    if count < 0 {
+        return nil, fmt.Errorf("%w: count must not be negative", ErrInvalidInput)
+    }
+    if count == 0 {
+        count = 1
+    }
+
+    operations, befores, err := s.operations()
+    if err != nil {
+        return nil, err
+    }
+
+    undone := []models.Operation{}
+    for _, operation := range operations {
+        if len(undone) == count {
+            break
+        }
+        if operation.Operation == models.OperationUndo || !operation.UndoneAt.IsZero() {
+            continue
+        }
+        if operation.Incomplete {
+            return undone, kindError{kind: ErrConflict, err: fmt.Errorf(
+                "operation %d (%s) can't be undone: the revisions of some of its items are no longer kept", operation.ID, operation.Operation)}
+        }
+        if err := s.revert(models.OperationUndo, befores[operation.ID], operation.ID); err != nil {
+            return undone, err
+        }
+        undone = append(undone, operation)
+    }
+    return undone, nil
+}
+
+// operations groups the revisions of all items into the operations that
+// made them, newest first, along with each operation's item states from
+// before it ran
+func (s *hyprLinkService) operations() ([]models.Operation, map[int64][]models.ItemState, error) {
+    logs, err := s.revisionRepo.GetAll()
+    if err != nil {
+        return nil, nil, err
+    }
+
+    byID := make(map[int64]*models.Operation)
+    sizes := make(map[int64]int)
+    befores := make(map[int64][]models.ItemState)
+    for _, log := range logs {
+        for _, revision := range log.Revisions {
+            operation := byID[revision.OperationID]
+            if operation == nil {
+                operation = &models.Operation{
+                    ID:        revision.OperationID,
+                    Operation: revision.Operation,
+                    CreatedAt: revision.CreatedAt,
+                    UndoneAt:  revision.UndoneAt,
+                }
+                byID[revision.OperationID] = operation
+                sizes[revision.OperationID] = revision.OperationItems
+            }
+            operation.Items = append(operation.Items, models.ItemRef{Type: log.ItemType, ID: log.ItemID})
+            befores[revision.OperationID] = append(befores[revision.OperationID], models.ItemState{
+                ItemType: log.ItemType,
+                ItemID:   log.ItemID,
+                State:    revision.Before,
+            })
+        }
+    }
+
+    operations := make([]models.Operation, 0, len(byID))
+    for _, operation := range byID {
+        // Logs are trimmed per item, so an old operation can lose some
+        // items' revisions while others keep theirs
+        operation.Incomplete = len(operation.Items) < sizes[operation.ID]
+        sort.Slice(operation.Items, func(i, j int) bool {
+            if operation.Items[i].Type != operation.Items[j].Type {
+                return operation.Items[i].Type < operation.Items[j].Type
+            }
+            return operation.Items[i].ID < operation.Items[j].ID
+        })
+        operations = append(operations, *operation)
+    }
+    sort.Slice(operations, func(i, j int) bool {
+        return operations[i].ID > operations[j].ID
+    })
+    return operations, befores, nil
+}
+
+// revert writes items back through the revision log and lets the rest of
+// the app catch up: restored sessions get a version, restored bookmarks
+// pick their annotations back up and the background workers look at URLs
+// that may have changed
+func (s *hyprLinkService) revert(operation string, states []models.ItemState, undoing int64) error {
+    if err := s.revisionRepo.Revert(operation, states, undoing); err != nil {
+        return err
+    }
+
+    for _, state := range states {
+        if len(state.State) == 0 {
+            continue
+        }
+        if state.ItemType == models.ItemSession {
+            var session models.Session
+            if err := json.Unmarshal(state.State, &session); err != nil {
+                return err
+            }
+            if _, _, err := s.versionRepo.Create(session, models.VersionRestore); err != nil {
+                return err
+            }
+            continue
+        }
+        var bookmark models.Bookmark
+        if err := json.Unmarshal(state.State, &bookmark); err != nil {
+            return err
+        }
+        if err := s.linkAnnotations(bookmark); err != nil {
+            return err
+        }
+    }
+    s.wakeWorkers()
+    return nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "testing"
+
+    "hyprlnk/internal/models"
+)
+
+// undoOne undoes the latest operation, which must be of the given kind
+func undoOne(t *testing.T, service *hyprLinkService, operation string) {
+    t.Helper()
+    undone, err := service.Undo(1)
+    if err != nil {
+        t.Fatalf("Undo failed: %v", err)
+    }
+    if len(undone) != 1 || undone[0].Operation != operation {
+        t.Fatalf("Expected to undo a %s, got %+v", operation, undone)
+    }
+}
+
+func TestUndo_Update(t *testing.T) {
+    service, _ := newTestService(t)
+    created := mustCreateBookmark(t, service, newBookmark("https://example.com", "old"))
+    bookmark, _ := service.GetBookmark(created.ID)
+
+    edited := *bookmark
+    edited.Title = "Edited"
+    edited.Tags = []string{"new"}
+    if err := service.UpdateBookmark(&edited); err != nil {
+        t.Fatal(err)
+    }
+
+    undoOne(t, service, models.OperationUpdate)
+    got, err := service.GetBookmark(bookmark.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got.Title != bookmark.Title || len(got.Tags) != 1 || got.Tags[0] != "old" {
+        t.Errorf("Expected the bookmark as it was before the edit, got %+v", got)
+    }
+    if !got.UpdatedAt.Equal(bookmark.UpdatedAt) {
+        t.Errorf("Expected UpdatedAt restored to %s, got %s", bookmark.UpdatedAt, got.UpdatedAt)
+    }
+
+    // The next undo skips the undo and goes back to the create
+    undoOne(t, service, models.OperationCreate)
+}
+
+func TestRestoreRevision(t *testing.T) {
+    service, _ := newTestService(t)
+    created := mustCreateBookmark(t, service, newBookmark("https://example.com"))
+    original, _ := service.GetBookmark(created.ID)
+
+    edited := *original
+    edited.Title = "Edited"
+    if err := service.UpdateBookmark(&edited); err != nil {
+        t.Fatal(err)
+    }
+
+    // Restoring the revision that created the bookmark brings back that state
+    restored, err := service.RestoreRevision(models.ItemBookmark, original.ID, 1)
+    if err != nil {
+        t.Fatalf("Restore failed: %v", err)
+    }
+    var item models.Bookmark
+    if err := json.Unmarshal(restored, &item); err != nil || item.Title != original.Title {
+        t.Errorf("Expected the created bookmark returned, got %s (%v)", restored, err)
+    }
+    got, _ := service.GetBookmark(original.ID)
+    if got.Title != original.Title || !got.UpdatedAt.Equal(original.UpdatedAt) {
+        t.Errorf("Expected the bookmark as created, got %+v", got)
+    }
+
+    // Restoring a delete puts back the state before it
+    if err := service.DeleteBookmark(original.ID); err != nil {
+        t.Fatal(err)
+    }
+    log, _ := service.GetRevisions(models.ItemBookmark, original.ID)
+    last := log.Revisions[len(log.Revisions)-1]
+    if _, err := service.RestoreRevision(models.ItemBookmark, original.ID, last.Rev); err != nil {
+        t.Fatalf("Restore failed: %v", err)
+    }
+    if got, err := service.GetBookmark(original.ID); err != nil || got.Title != original.Title {
+        t.Errorf("Expected the deleted bookmark back, got %+v (%v)", got, err)
+    }
+
+    if _, err := service.RestoreRevision(models.ItemBookmark, original.ID, 99); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected an unknown revision not found, got %v", err)
+    }
+}
+
+func TestUndo_Delete(t *testing.T) {
+    service, _ := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://example.com", "keep"))
+    if err := service.DeleteBookmark(bookmark.ID); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := service.GetBookmark(bookmark.ID); err == nil {
+        t.Fatal("Expected the bookmark to be gone after deleting it")
+    }
+
+    undoOne(t, service, models.OperationDelete)
+    got, err := service.GetBookmark(bookmark.ID)
+    if err != nil {
+        t.Fatalf("Expected the deleted bookmark back: %v", err)
+    }
+    if got.URL != bookmark.URL || len(got.Tags) != 1 || got.Tags[0] != "keep" {
+        t.Errorf("Expected the bookmark as it was, got %+v", got)
+    }
+}
+
+func TestUndo_Create(t *testing.T) {
+    service, _ := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://example.com"))
+
+    undoOne(t, service, models.OperationCreate)
+    if _, err := service.GetBookmark(bookmark.ID); err == nil {
+        t.Error("Expected undoing the create to delete the bookmark")
+    }
+    if _, err := service.Undo(1); err != nil {
+        t.Errorf("Expected nothing left to undo, got %v", err)
+    }
+}
+
+func TestSystemWritesAreNotRecorded(t *testing.T) {
+    service, _ := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://example.com"))
+
+    // The title is the URL, so fetched metadata fills it in
+    if err := service.applyMetadata(bookmark.ID, models.PageMetadata{URL: bookmark.URL, Title: "Example", Description: "An example"}); err != nil {
+        t.Fatal(err)
+    }
+    got, _ := service.GetBookmark(bookmark.ID)
+    if got.Title != "Example" || got.Description != "An example" {
+        t.Fatalf("Expected the metadata applied, got %+v", got)
+    }
+
+    log, err := service.GetRevisions(models.ItemBookmark, bookmark.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(log.Revisions) != 1 {
+        t.Errorf("Expected only the create's revision, got %d", len(log.Revisions))
+    }
+    operations, _ := service.GetOperations(0)
+    if len(operations) != 1 || operations[0].Operation != models.OperationCreate {
+        t.Errorf("Expected only the create operation, got %+v", operations)
+    }
+}
+
+func TestUndo_RefusesTrimmedOperation(t *testing.T) {
+    service, _ := newTestService(t)
+    items := []models.ImportedBookmark{{URL: "https://one.example"}, {URL: "https://two.example"}}
+    diff, _, err := service.importBookmarks(context.Background(), items, models.ImportSkipExisting, false)
+    if err != nil {
+        t.Fatalf("Import failed: %v", err)
+    }
+    first, second := diff.Creates[0].After, diff.Creates[1].After
+
+    // Enough edits to push the import's revision out of the first item's log
+    for i := 0; i < 20; i++ {
+        edited, _ := service.GetBookmark(first.ID)
+        edited.Title = fmt.Sprintf("Edit %d", i)
+        if err := service.UpdateBookmark(edited); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    operations, _ := service.GetOperations(0)
+    last := operations[len(operations)-1]
+    if last.Operation != models.OperationImport || !last.Incomplete || len(last.Items) != 1 {
+        t.Fatalf("Expected the import listed as incomplete, got %+v", last)
+    }
+
+    undone, err := service.Undo(len(operations))
+    if !errors.Is(err, ErrConflict) {
+        t.Fatalf("Expected a conflict at the trimmed import, got %v", err)
+    }
+    if len(undone) != 20 {
+        t.Errorf("Expected the 20 edits undone before stopping, got %d", len(undone))
+    }
+    if _, err := service.GetBookmark(second.ID); err != nil {
+        t.Errorf("Expected the import's other bookmark left in place: %v", err)
+    }
+}
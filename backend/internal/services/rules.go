@@ -0,0 +1,101 @@
+package services
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "hyprlnk/internal/autotag"
+    "hyprlnk/internal/models"
+)
+
+func (s *hyprLinkService) GetRules() ([]models.AutoTagRule, error) {
+    return s.ruleRepo.GetAll()
+}
+
+func (s *hyprLinkService) CreateRule(rule *models.AutoTagRule) error {
+    if err := s.validateRule(rule); err != nil {
+        return err
+    }
+    return s.ruleRepo.Create(rule)
+}
+
+func (s *hyprLinkService) UpdateRule(rule *models.AutoTagRule) error {
+    if err := s.validateRule(rule); err != nil {
+        return err
+    }
+    if err := s.ruleRepo.Update(rule); err != nil {
+        return kindError{kind: ErrNotFound, err: err}
+    }
+    return nil
+}
+
+func (s *hyprLinkService) DeleteRule(id int64) error {
+    if err := s.ruleRepo.Delete(id); err != nil {
+        return kindError{kind: ErrNotFound, err: err}
+    }
+    return nil
+}
+
+func (s *hyprLinkService) validateRule(rule *models.AutoTagRule) error {
+    if strings.TrimSpace(rule.Name) == "" {
+        return fmt.Errorf("%w: rule name is required", ErrInvalidInput)
+    }
+    if err := autotag.Validate(*rule); err != nil {
+        return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+    }
+    if rule.CollectionID != 0 {
+        if _, err := s.collectionRepo.GetByID(rule.CollectionID); err != nil {
+            return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+        }
+    }
+    return nil
+}
+
+// ApplyRules runs the rules over every stored bookmark and reports what
+// changed. Rules only ever add, so applying them twice changes nothing
+// more. With dryRun the changes are reported but not saved.
+func (s *hyprLinkService) ApplyRules(dryRun bool) ([]models.RuleChange, error) {
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+
+    changes, updated, err := s.applyRules(bookmarks)
+    if err != nil || dryRun {
+        return changes, err
+    }
+    return changes, s.bookmarkRepo.UpdateMany(updated)
+}
+
+// applyRules applies the current rules to bookmarks, returning the changes
+// and the changed bookmarks
+func (s *hyprLinkService) applyRules(bookmarks []models.Bookmark) ([]models.RuleChange, []models.Bookmark, error) {
+    engine, err := s.ruleEngine()
+    if err != nil {
+        return nil, nil, err
+    }
+
+    changes := []models.RuleChange{}
+    var updated []models.Bookmark
+    now := time.Now()
+    for _, bookmark := range bookmarks {
+        bookmark.Tags = append([]string{}, bookmark.Tags...)
+        change := engine.Apply(&bookmark)
+        if change == nil {
+            continue
+        }
+        bookmark.UpdatedAt = now
+        changes = append(changes, *change)
+        updated = append(updated, bookmark)
+    }
+    return changes, updated, nil
+}
+
+func (s *hyprLinkService) ruleEngine() (*autotag.Engine, error) {
+    rules, err := s.ruleRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+    return autotag.New(rules)
+}
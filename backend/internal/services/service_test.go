@@ -0,0 +1,71 @@
+package services
+
+import (
+    "testing"
+
+    "hyprlnk/internal/classify"
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/repositories"
+    "hyprlnk/internal/storage"
+)
+
+// newTestService wires a service to real repositories over a temporary
+// data directory, without page fetching or link checks
+func newTestService(t *testing.T) (*hyprLinkService, *storage.AppendLogStorage) {
+    t.Helper()
+    store := storage.NewAppendLogStorage(t.TempDir())
+    t.Cleanup(func() { store.Close() })
+
+    bookmarkRepo := repositories.NewBookmarkRepository(store)
+    service := NewHyprLinkService(Config{
+        BookmarkRepo:   bookmarkRepo,
+        CollectionRepo: repositories.NewCollectionRepository(store),
+        TagRepo:        repositories.NewTagRepository(store),
+        SessionRepo:    repositories.NewSessionRepository(store),
+        HistoryRepo:    repositories.NewHistoryRepository(store),
+        LinkClickRepo:  repositories.NewLinkClickRepository(store),
+        ImportRepo:     repositories.NewImportRepository(store),
+        SettingsRepo:   repositories.NewSettingsRepository(store),
+        JobRepo:        repositories.NewJobRepository(store),
+        RuleRepo:       repositories.NewRuleRepository(store),
+        MetadataRepo:   repositories.NewMetadataRepository(store),
+        ArchiveRepo:    repositories.NewArchiveRepository(store),
+        LinkHealthRepo: repositories.NewLinkHealthRepository(store),
+        AnnotationRepo: repositories.NewAnnotationRepository(store),
+        RevisionRepo:   repositories.NewRevisionRepository(store),
+        TrashRepo:      repositories.NewTrashRepository(store),
+        VersionRepo:    repositories.NewSessionVersionRepository(store),
+        Classifier:     classify.NewRules(classify.DefaultRules()),
+        Suggester:      classify.NewBayes(bookmarkRepo.GetAll),
+    })
+    return service.(*hyprLinkService), store
+}
+
+// mustCreateBookmark saves a bookmark, failing the test on error
+func mustCreateBookmark(t *testing.T, service *hyprLinkService, bookmark models.Bookmark) models.Bookmark {
+    t.Helper()
+    if bookmark.Tags == nil {
+        bookmark.Tags = []string{}
+    }
+    if _, err := service.CreateBookmark(&bookmark); err != nil {
+        t.Fatalf("Failed to create bookmark %s: %v", bookmark.URL, err)
+    }
+    return bookmark
+}
+
+// tagsOf returns the bookmark's current tags
+func tagsOf(t *testing.T, service *hyprLinkService, id int64) []string {
+    t.Helper()
+    bookmark, err := service.GetBookmark(id)
+    if err != nil {
+        t.Fatalf("Failed to read bookmark %d: %v", id, err)
+    }
+    return bookmark.Tags
+}
+
+func newBookmark(url string, tags ...string) models.Bookmark {
+    if tags == nil {
+        tags = []string{}
+    }
+    return models.Bookmark{URL: url, Title: url, Tags: tags}
+}
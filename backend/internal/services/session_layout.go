@@ -0,0 +1,98 @@
+package services
+
+import (
+    "fmt"
+
+    "hyprlnk/internal/models"
+)
+
+var windowStates = map[string]bool{
+    "":                      true,
+    models.WindowNormal:     true,
+    models.WindowMinimized:  true,
+    models.WindowMaximized:  true,
+    models.WindowFullscreen: true,
+}
+
+var groupColors = map[string]bool{
+    "":                 true,
+    models.GroupGrey:   true,
+    models.GroupBlue:   true,
+    models.GroupRed:    true,
+    models.GroupYellow: true,
+    models.GroupGreen:  true,
+    models.GroupPink:   true,
+    models.GroupPurple: true,
+    models.GroupCyan:   true,
+    models.GroupOrange: true,
+}
+
+// checkSessionLayout validates a session's windows and tab groups and
+// files tabs that name no window in the first one. A session without
+// windows is flat, so its tabs can't name windows or groups either.
+func checkSessionLayout(session *models.Session) error {
+    if len(session.Windows) == 0 {
+        if len(session.Groups) > 0 {
+            return fmt.Errorf("%w: tab groups need windows", ErrInvalidInput)
+        }
+        for _, tab := range session.Tabs {
+            if tab.WindowID != 0 || tab.GroupID != 0 {
+                return fmt.Errorf("%w: tab %q names a window or group but the session has no windows", ErrInvalidInput, tab.URL)
+            }
+        }
+        return nil
+    }
+
+    windows := make(map[int]bool, len(session.Windows))
+    focused := 0
+    for _, window := range session.Windows {
+        if window.ID <= 0 || windows[window.ID] {
+            return fmt.Errorf("%w: window IDs must be positive and unique, got %d", ErrInvalidInput, window.ID)
+        }
+        if !windowStates[window.State] {
+            return fmt.Errorf("%w: unknown window state %q", ErrInvalidInput, window.State)
+        }
+        if window.Focused {
+            focused++
+        }
+        windows[window.ID] = true
+    }
+    if focused > 1 {
+        return fmt.Errorf("%w: only one window can have focus", ErrInvalidInput)
+    }
+
+    groupWindows := make(map[int]int, len(session.Groups))
+    for _, group := range session.Groups {
+        if _, ok := groupWindows[group.ID]; group.ID <= 0 || ok {
+            return fmt.Errorf("%w: tab group IDs must be positive and unique, got %d", ErrInvalidInput, group.ID)
+        }
+        if !windows[group.WindowID] {
+            return fmt.Errorf("%w: tab group %d is in unknown window %d", ErrInvalidInput, group.ID, group.WindowID)
+        }
+        if !groupColors[group.Color] {
+            return fmt.Errorf("%w: unknown tab group color %q", ErrInvalidInput, group.Color)
+        }
+        groupWindows[group.ID] = group.WindowID
+    }
+
+    for i := range session.Tabs {
+        tab := &session.Tabs[i]
+        if tab.WindowID == 0 {
+            tab.WindowID = session.Windows[0].ID
+        }
+        if !windows[tab.WindowID] {
+            return fmt.Errorf("%w: tab %q is in unknown window %d", ErrInvalidInput, tab.URL, tab.WindowID)
+        }
+        if tab.GroupID == 0 {
+            continue
+        }
+        window, ok := groupWindows[tab.GroupID]
+        if !ok {
+            return fmt.Errorf("%w: tab %q is in unknown tab group %d", ErrInvalidInput, tab.URL, tab.GroupID)
+        }
+        if window != tab.WindowID {
+            return fmt.Errorf("%w: tab %q is in window %d but its tab group %d is in window %d", ErrInvalidInput, tab.URL, tab.WindowID, tab.GroupID, window)
+        }
+    }
+    return nil
+}
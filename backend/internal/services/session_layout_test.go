@@ -0,0 +1,178 @@
+package services
+
+import (
+    "errors"
+    "reflect"
+    "testing"
+
+    "hyprlnk/internal/models"
+)
+
+// laidOutSession has two windows, the second focused and holding a tab
+// group
+func laidOutSession(name string) models.Session {
+    return models.Session{
+        Name: name,
+        Tabs: []models.Tab{
+            {URL: "https://a.example", Title: "A", WindowID: 1},
+            {URL: "https://b.example", Title: "B", WindowID: 2, GroupID: 1},
+            {URL: "https://c.example", Title: "C", WindowID: 2, GroupID: 1},
+        },
+        Windows: []models.Window{{ID: 1, State: models.WindowMaximized}, {ID: 2, Focused: true}},
+        Groups:  []models.TabGroup{{ID: 1, WindowID: 2, Title: "Reading", Color: models.GroupBlue, Collapsed: true}},
+    }
+}
+
+func TestCheckSessionLayout(t *testing.T) {
+    tests := []struct {
+        name   string
+        change func(session *models.Session)
+        valid  bool
+    }{
+        {"laid out", func(session *models.Session) {}, true},
+        {"flat", func(session *models.Session) {
+            session.Windows, session.Groups = nil, nil
+            session.Tabs = tabs("https://a.example")
+        }, true},
+        {"flat tab naming a window", func(session *models.Session) {
+            session.Windows, session.Groups = nil, nil
+            session.Tabs = []models.Tab{{URL: "https://a.example", WindowID: 1}}
+        }, false},
+        {"groups without windows", func(session *models.Session) {
+            session.Windows = nil
+            session.Tabs = tabs("https://a.example")
+        }, false},
+        {"tab in unknown window", func(session *models.Session) { session.Tabs[0].WindowID = 3 }, false},
+        {"tab in unknown group", func(session *models.Session) { session.Tabs[1].GroupID = 2 }, false},
+        {"group spanning windows", func(session *models.Session) { session.Tabs[0].GroupID = 1 }, false},
+        {"group in unknown window", func(session *models.Session) { session.Groups[0].WindowID = 3 }, false},
+        {"repeated window ID", func(session *models.Session) { session.Windows[1].ID = 1 }, false},
+        {"repeated group ID", func(session *models.Session) {
+            session.Groups = append(session.Groups, models.TabGroup{ID: 1, WindowID: 1})
+        }, false},
+        {"two focused windows", func(session *models.Session) { session.Windows[0].Focused = true }, false},
+        {"unknown window state", func(session *models.Session) { session.Windows[0].State = "docked" }, false},
+        {"unknown group color", func(session *models.Session) { session.Groups[0].Color = "magenta" }, false},
+    }
+    for _, tt := range tests {
+        session := laidOutSession("Work")
+        tt.change(&session)
+        err := checkSessionLayout(&session)
+        if tt.valid && err != nil {
+            t.Errorf("%s: expected valid, got %v", tt.name, err)
+        }
+        if !tt.valid && !errors.Is(err, ErrInvalidInput) {
+            t.Errorf("%s: expected invalid input, got %v", tt.name, err)
+        }
+    }
+
+    // A tab naming no window goes in the first one
+    session := laidOutSession("Work")
+    session.Tabs = append(session.Tabs, models.Tab{URL: "https://d.example"})
+    if err := checkSessionLayout(&session); err != nil {
+        t.Fatal(err)
+    }
+    if got := session.Tabs[3].WindowID; got != 1 {
+        t.Errorf("Expected the tab filed in window 1, got %d", got)
+    }
+}
+
+func TestSessionLayout_RejectedOnEveryWritePath(t *testing.T) {
+    service, _ := newTestService(t)
+    broken := laidOutSession("Broken")
+    broken.Tabs[0].GroupID = 1 // the group is in the other window
+
+    if err := service.CreateSession(&broken); !errors.Is(err, ErrInvalidInput) {
+        t.Errorf("Create: expected invalid input, got %v", err)
+    }
+
+    session := mustCreateSession(t, service, "Work", "https://a.example")
+    broken.ID = session.ID
+    if err := service.UpdateSession(&broken); !errors.Is(err, ErrInvalidInput) {
+        t.Errorf("Update: expected invalid input, got %v", err)
+    }
+
+    request := models.AutoSaveRequest{Tabs: broken.Tabs, Windows: broken.Windows, Groups: broken.Groups}
+    if _, err := service.AutoSaveSession(request); !errors.Is(err, ErrInvalidInput) {
+        t.Errorf("Autosave: expected invalid input, got %v", err)
+    }
+    if sessions, _ := service.GetAllSessions(); len(sessions) != 1 {
+        t.Errorf("Expected nothing saved, got %d sessions", len(sessions))
+    }
+}
+
+func TestSessionLayout_OldFlatSession(t *testing.T) {
+    service, store := newTestService(t)
+    // Stored before sessions had windows
+    if err := store.AddSession(models.Session{ID: 7, Name: "Old", Tabs: tabs("https://a.example", "https://b.example")}); err != nil {
+        t.Fatal(err)
+    }
+
+    sessions, err := service.GetAllSessions()
+    if err != nil || len(sessions) != 1 {
+        t.Fatalf("Expected the old session to load, got %+v (%v)", sessions, err)
+    }
+    old := sessions[0]
+    if len(old.Windows) != 0 || len(old.Groups) != 0 || !reflect.DeepEqual(urlsOf(old.Tabs), []string{"https://a.example", "https://b.example"}) {
+        t.Errorf("Expected a flat session with its tabs, got %+v", old)
+    }
+
+    // It can still be edited as it is, and given a layout later
+    old.Name = "Renamed"
+    if err := service.UpdateSession(&old); err != nil {
+        t.Fatalf("Expected a flat session to save, got %v", err)
+    }
+    laidOut := laidOutSession("Renamed")
+    laidOut.ID = old.ID
+    if err := service.UpdateSession(&laidOut); err != nil {
+        t.Fatalf("Expected the session to take a layout, got %v", err)
+    }
+}
+
+func TestSessionLayout_Restore(t *testing.T) {
+    service, _ := newTestService(t)
+    session := laidOutSession("Work")
+    if err := service.CreateSession(&session); err != nil {
+        t.Fatal(err)
+    }
+    want := session
+
+    assertLayout := func(how string) {
+        t.Helper()
+        sessions, err := service.GetAllSessions()
+        if err != nil || len(sessions) != 1 {
+            t.Fatalf("%s: expected the session, got %+v (%v)", how, sessions, err)
+        }
+        got := sessions[0]
+        if !reflect.DeepEqual(got.Windows, want.Windows) || !reflect.DeepEqual(got.Groups, want.Groups) || !reflect.DeepEqual(got.Tabs, want.Tabs) {
+            t.Errorf("%s: expected the layout back, got windows %+v, groups %+v, tabs %+v", how, got.Windows, got.Groups, got.Tabs)
+        }
+    }
+
+    // Flattened, then restored from its first version
+    flat := session
+    flat.Tabs, flat.Windows, flat.Groups = tabs("https://a.example"), nil, nil
+    if err := service.UpdateSession(&flat); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := service.RestoreSessionVersion(session.ID, 1); err != nil {
+        t.Fatalf("Restoring the version failed: %v", err)
+    }
+    assertLayout("version restore")
+
+    // Deleted, then restored from the trash
+    if err := service.DeleteSession(session.ID); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := service.RestoreFromTrash(models.ItemSession, session.ID); err != nil {
+        t.Fatalf("Restoring from the trash failed: %v", err)
+    }
+    assertLayout("trash restore")
+
+    // Flattened again, then undone
+    if err := service.UpdateSession(&flat); err != nil {
+        t.Fatal(err)
+    }
+    undoOne(t, service, models.OperationUpdate)
+    assertLayout("undo")
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+    "fmt"
+    "sort"
+    "time"
+
+    "hyprlnk/internal/models"
+)
+
+// autoSaveThinAfter is how long every autosaved version is kept; older
+// ones are thinned to the last of each hour
+const autoSaveThinAfter = 24 * time.Hour
+
+// autoSaveSessionName names the session autosaves go to unless the
+// extension picks one
+const autoSaveSessionName = "Autosave"
+
+// GetSessionVersions lists a session's versions, newest first
+func (s *hyprLinkService) GetSessionVersions(id int64) ([]models.SessionVersion, error) {
+    if _, err := s.sessionRepo.GetByID(id); err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+
+    versions, err := s.versionRepo.GetAll(id)
+    if err != nil {
+        return nil, err
+    }
+    sort.Slice(versions, func(i, j int) bool {
+        return versions[i].Version > versions[j].Version
+    })
+    return versions, nil
+}
+
+func (s *hyprLinkService) GetSessionVersion(id int64, version int) (*models.SessionVersion, error) {
+    found, err := s.versionRepo.Get(id, version)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+    return found, nil
+}
+
+// GetSessionVersionAt returns the version of a session that was current
+// at the given time: the last one saved at or before it
+func (s *hyprLinkService) GetSessionVersionAt(id int64, at time.Time) (*models.SessionVersion, error) {
+    versions, err := s.GetSessionVersions(id)
+    if err != nil {
+        return nil, err
+    }
+
+    for _, version := range versions {
+        if !version.CreatedAt.After(at) {
+            return &version, nil
+        }
+    }
+    return nil, kindError{kind: ErrNotFound, err: fmt.Errorf("session %d has no version from before %s", id, at.Format(time.RFC3339))}
+}
+
+// RestoreSessionVersion makes an earlier version the session's current
+// state. It is saved as a new version, and can be undone like any edit.
+func (s *hyprLinkService) RestoreSessionVersion(id int64, version int) (*models.Session, error) {
+    session, err := s.sessionRepo.GetByID(id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+    restored, err := s.GetSessionVersion(id, version)
+    if err != nil {
+        return nil, err
+    }
+
+    session.Name = restored.Name
+    session.Tabs = restored.Tabs
+    session.Windows = restored.Windows
+    session.Groups = restored.Groups
+    if err := s.sessionRepo.Update(session); err != nil {
+        return nil, err
+    }
+    if _, _, err := s.versionRepo.Create(*session, models.VersionRestore); err != nil {
+        return nil, err
+    }
+    return session, nil
+}
+
+// AutoSaveSession records the open tabs posted by the extension. Each
+// change becomes a version of the autosave session, creating that session
+// on first use, so its versions form a timeline of what was open when.
+func (s *hyprLinkService) AutoSaveSession(request models.AutoSaveRequest) (*models.AutoSaveResult, error) {
+    // Checked first, so a rejected autosave doesn't create the session
+    layout := models.Session{Tabs: request.Tabs, Windows: request.Windows, Groups: request.Groups}
+    if err := checkSessionLayout(&layout); err != nil {
+        return nil, err
+    }
+    session, err := s.autoSaveSession(request.SessionID)
+    if err != nil {
+        return nil, err
+    }
+
+    session.Tabs = layout.Tabs
+    session.Windows = layout.Windows
+    session.Groups = layout.Groups
+    version, changed, err := s.versionRepo.Create(*session, models.VersionAutoSave)
+    if err != nil {
+        return nil, err
+    }
+    if changed {
+        if err := s.sessionRepo.AutoSave(session); err != nil {
+            return nil, err
+        }
+        if err := s.thinAutoSaves(session.ID, time.Now()); err != nil {
+            return nil, err
+        }
+    }
+    return &models.AutoSaveResult{Version: version, Changed: changed}, nil
+}
+
+// importSessions stores imported sessions, each with its first version,
+// and returns how many were new
+func (s *hyprLinkService) importSessions(sessions []models.Session) (int, error) {
+    imported, err := s.importRepo.ImportSessions(sessions)
+    for _, session := range imported {
+        if _, _, err := s.versionRepo.Create(session, models.VersionSave); err != nil {
+            return len(imported), err
+        }
+    }
+    return len(imported), err
+}
+
+// autoSaveSession returns the session with the given ID, or for 0 the
+// oldest autosave session, created if there is none yet
+func (s *hyprLinkService) autoSaveSession(id int64) (*models.Session, error) {
+    if id != 0 {
+        session, err := s.sessionRepo.GetByID(id)
+        if err != nil {
+            return nil, kindError{kind: ErrNotFound, err: err}
+        }
+        return session, nil
+    }
+
+    sessions, err := s.sessionRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+    var oldest *models.Session
+    for i := range sessions {
+        if sessions[i].AutoSave && (oldest == nil || sessions[i].CreatedAt.Before(oldest.CreatedAt)) {
+            oldest = &sessions[i]
+        }
+    }
+    if oldest != nil {
+        return oldest, nil
+    }
+
+    session := &models.Session{Name: autoSaveSessionName, AutoSave: true}
+    if err := s.sessionRepo.Create(session); err != nil {
+        return nil, err
+    }
+    return session, nil
+}
+
+// thinAutoSaves keeps a session's autosave timeline bounded: every
+// autosave from the last day, the last of each hour before that, and
+// nothing older than the configured retention. Saved and restored versions
+// and the latest version are always kept.
+func (s *hyprLinkService) thinAutoSaves(id int64, now time.Time) error {
+    settings, err := s.settingsRepo.Get()
+    if err != nil {
+        return err
+    }
+    versions, err := s.versionRepo.GetAll(id)
+    if err != nil || len(versions) == 0 {
+        return err
+    }
+
+    days := autoSaveDays(settings)
+    var drop []int
+    lastInHour := make(map[time.Time]int)
+    for _, version := range versions[:len(versions)-1] {
+        if version.Source != models.VersionAutoSave {
+            continue
+        }
+        if days >= 0 && version.CreatedAt.Before(now.AddDate(0, 0, -days)) {
+            drop = append(drop, version.Version)
+            continue
+        }
+        if version.CreatedAt.Before(now.Add(-autoSaveThinAfter)) {
+            hour := version.CreatedAt.Truncate(time.Hour)
+            if earlier, ok := lastInHour[hour]; ok {
+                drop = append(drop, earlier)
+            }
+            lastInHour[hour] = version.Version
+        }
+    }
+    return s.versionRepo.Delete(id, drop...)
+}
+
+// autoSaveDays is how long autosaved versions are kept; 0 means the
+// default and a negative value keeps them
+func autoSaveDays(settings *models.Settings) int {
+    if settings.AutoSaveDays == 0 {
+        return models.DefaultAutoSaveDays
+    }
+    if settings.AutoSaveDays < 0 {
+        return -1
+    }
+    return settings.AutoSaveDays
+}
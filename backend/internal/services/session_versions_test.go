@@ -0,0 +1,273 @@
+package services
+
+import (
+    "errors"
+    "reflect"
+    "testing"
+    "time"
+
+    "hyprlnk/internal/models"
+    "hyprlnk/internal/storage"
+)
+
+func tabs(urls ...string) []models.Tab {
+    result := make([]models.Tab, len(urls))
+    for i, url := range urls {
+        result[i] = models.Tab{URL: url, Title: url}
+    }
+    return result
+}
+
+func urlsOf(tabs []models.Tab) []string {
+    urls := []string{}
+    for _, tab := range tabs {
+        urls = append(urls, tab.URL)
+    }
+    return urls
+}
+
+// mustCreateSession saves a session, failing the test on error
+func mustCreateSession(t *testing.T, service *hyprLinkService, name string, urls ...string) models.Session {
+    t.Helper()
+    session := models.Session{Name: name, Tabs: tabs(urls...)}
+    if err := service.CreateSession(&session); err != nil {
+        t.Fatalf("Failed to create session %s: %v", name, err)
+    }
+    return session
+}
+
+// versionSources lists a session's versions' sources, oldest first
+func versionSources(t *testing.T, service *hyprLinkService, id int64) []string {
+    t.Helper()
+    versions, err := service.versionRepo.GetAll(id)
+    if err != nil {
+        t.Fatal(err)
+    }
+    sources := []string{}
+    for _, version := range versions {
+        sources = append(sources, version.Source)
+    }
+    return sources
+}
+
+func TestSessionVersions_Diff(t *testing.T) {
+    service, _ := newTestService(t)
+    session := mustCreateSession(t, service, "Research", "https://a.example", "https://b.example", "https://b.example")
+
+    session.Tabs = tabs("https://b.example", "https://c.example")
+    if err := service.UpdateSession(&session); err != nil {
+        t.Fatal(err)
+    }
+    // Saving the same tabs again adds no version
+    if err := service.UpdateSession(&session); err != nil {
+        t.Fatal(err)
+    }
+
+    versions, err := service.GetSessionVersions(session.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(versions) != 2 {
+        t.Fatalf("Expected 2 versions, got %d", len(versions))
+    }
+    latest, first := versions[0].Diff, versions[1].Diff
+    if got := urlsOf(first.Added); len(got) != 3 || len(first.Removed) != 0 || first.Kept != 0 {
+        t.Errorf("Expected the first version to add every tab, got %+v", first)
+    }
+    // One of the two b tabs stays open; a and the other b close
+    if !reflect.DeepEqual(urlsOf(latest.Added), []string{"https://c.example"}) ||
+        !reflect.DeepEqual(urlsOf(latest.Removed), []string{"https://a.example", "https://b.example"}) || latest.Kept != 1 {
+        t.Errorf("Unexpected diff %+v", latest)
+    }
+}
+
+func TestSessionVersions_EveryWritePath(t *testing.T) {
+    service, _ := newTestService(t)
+    session := mustCreateSession(t, service, "Research", "https://a.example")
+
+    session.Tabs = tabs("https://a.example", "https://b.example")
+    if err := service.UpdateSession(&session); err != nil {
+        t.Fatal(err)
+    }
+    undoOne(t, service, models.OperationUpdate)
+    want := []string{models.VersionSave, models.VersionSave, models.VersionRestore}
+    if got := versionSources(t, service, session.ID); !reflect.DeepEqual(got, want) {
+        t.Errorf("Expected undo to add a version, got %v", got)
+    }
+
+    log, err := service.GetRevisions(models.ItemSession, session.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := service.RestoreRevision(models.ItemSession, session.ID, log.Revisions[1].Rev); err != nil {
+        t.Fatal(err)
+    }
+    versions, _ := service.GetSessionVersions(session.ID)
+    if versions[0].Source != models.VersionRestore || len(versions[0].Tabs) != 2 {
+        t.Errorf("Expected restoring a revision to add a version with its tabs, got %+v", versions[0])
+    }
+
+    // Restoring from the trash brings back the tabs of the latest version
+    if err := service.DeleteSession(session.ID); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := service.RestoreFromTrash(models.ItemSession, session.ID); err != nil {
+        t.Fatal(err)
+    }
+    restored, _ := service.GetSessionVersions(session.ID)
+    current, _ := service.sessionRepo.GetByID(session.ID)
+    if !reflect.DeepEqual(urlsOf(restored[0].Tabs), urlsOf(current.Tabs)) {
+        t.Errorf("Expected the latest version to match the restored session, got %v and %v", urlsOf(restored[0].Tabs), urlsOf(current.Tabs))
+    }
+}
+
+func TestImportSessions_Versions(t *testing.T) {
+    service, _ := newTestService(t)
+    sessions := []models.Session{{Name: "Imported", Tabs: tabs("https://a.example")}}
+
+    count, err := service.importSessions(sessions)
+    if err != nil || count != 1 {
+        t.Fatalf("Expected 1 session imported, got %d (%v)", count, err)
+    }
+    all, _ := service.GetAllSessions()
+    if len(all) != 1 {
+        t.Fatalf("Expected the session stored, got %+v", all)
+    }
+    if got := versionSources(t, service, all[0].ID); !reflect.DeepEqual(got, []string{models.VersionSave}) {
+        t.Errorf("Expected the imported session to get its first version, got %v", got)
+    }
+
+    if count, err := service.importSessions(sessions); err != nil || count != 0 {
+        t.Errorf("Expected the same tabs skipped, got %d (%v)", count, err)
+    }
+}
+
+func TestAutoSaveSession_UpdatedAt(t *testing.T) {
+    service, _ := newTestService(t)
+    first, err := service.AutoSaveSession(models.AutoSaveRequest{Tabs: tabs("https://a.example")})
+    if err != nil {
+        t.Fatal(err)
+    }
+    session, _ := service.sessionRepo.GetByID(first.Version.SessionID)
+    savedAt := session.UpdatedAt
+
+    time.Sleep(time.Millisecond)
+    if _, err := service.AutoSaveSession(models.AutoSaveRequest{SessionID: session.ID, Tabs: tabs("https://b.example")}); err != nil {
+        t.Fatal(err)
+    }
+    session, _ = service.sessionRepo.GetByID(session.ID)
+    if !session.UpdatedAt.After(savedAt) {
+        t.Errorf("Expected the autosave to move UpdatedAt past %s, got %s", savedAt, session.UpdatedAt)
+    }
+}
+
+// putVersions stores versions of a session as they were at the given
+// times, bypassing the repository's clock
+func putVersions(t *testing.T, store *storage.AppendLogStorage, sessionID int64, versions ...models.SessionVersion) {
+    t.Helper()
+    for i := range versions {
+        versions[i].SessionID = sessionID
+        versions[i].Tabs = tabs("https://example.com")
+    }
+    if err := store.PutSessionVersions(versions...); err != nil {
+        t.Fatal(err)
+    }
+}
+
+// remainingVersions lists the numbers of a session's versions
+func remainingVersions(t *testing.T, service *hyprLinkService, id int64) []int {
+    t.Helper()
+    versions, err := service.versionRepo.GetAll(id)
+    if err != nil {
+        t.Fatal(err)
+    }
+    numbers := []int{}
+    for _, version := range versions {
+        numbers = append(numbers, version.Version)
+    }
+    return numbers
+}
+
+func TestThinAutoSaves(t *testing.T) {
+    now := time.Now()
+    hour := now.Add(-48 * time.Hour).Truncate(time.Hour)
+    versions := []models.SessionVersion{
+        {Version: 1, Source: models.VersionAutoSave, CreatedAt: hour.Add(10 * time.Minute)}, // superseded within its hour
+        {Version: 2, Source: models.VersionAutoSave, CreatedAt: hour.Add(20 * time.Minute)}, // last of its hour
+        {Version: 3, Source: models.VersionSave, CreatedAt: hour.Add(30 * time.Minute)},     // saved, always kept
+        {Version: 4, Source: models.VersionAutoSave, CreatedAt: hour.Add(2 * time.Hour)},    // alone in its hour
+        {Version: 5, Source: models.VersionAutoSave, CreatedAt: now.AddDate(0, 0, -40)},     // past retention
+        {Version: 6, Source: models.VersionAutoSave, CreatedAt: now.Add(-time.Hour)},        // from the last day
+        {Version: 7, Source: models.VersionAutoSave, CreatedAt: now.Add(-time.Hour + time.Minute)},
+        {Version: 8, Source: models.VersionAutoSave, CreatedAt: now.AddDate(0, 0, -40)}, // the latest, always kept
+    }
+
+    tests := []struct {
+        days int
+        want []int
+    }{
+        {0, []int{2, 3, 4, 6, 7, 8}},
+        {-1, []int{2, 3, 4, 5, 6, 7, 8}},
+    }
+    for _, tt := range tests {
+        service, store := newTestService(t)
+        settings, _ := service.settingsRepo.Get()
+        settings.AutoSaveDays = tt.days
+        if err := service.settingsRepo.Update(settings); err != nil {
+            t.Fatal(err)
+        }
+        session := models.Session{Name: "Autosave", AutoSave: true}
+        if err := service.sessionRepo.Create(&session); err != nil {
+            t.Fatal(err)
+        }
+        putVersions(t, store, session.ID, append([]models.SessionVersion{}, versions...)...)
+
+        if err := service.thinAutoSaves(session.ID, now); err != nil {
+            t.Fatalf("thinAutoSaves failed: %v", err)
+        }
+        if got := remainingVersions(t, service, session.ID); !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("autosave_days %d: expected versions %v kept, got %v", tt.days, tt.want, got)
+        }
+    }
+}
+
+func TestGetSessionVersionAt(t *testing.T) {
+    service, store := newTestService(t)
+    session := models.Session{Name: "Research"}
+    if err := service.sessionRepo.Create(&session); err != nil {
+        t.Fatal(err)
+    }
+    monday := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+    putVersions(t, store, session.ID,
+        models.SessionVersion{Version: 1, Source: models.VersionSave, CreatedAt: monday},
+        models.SessionVersion{Version: 2, Source: models.VersionAutoSave, CreatedAt: monday.Add(6 * time.Hour)},
+        models.SessionVersion{Version: 3, Source: models.VersionAutoSave, CreatedAt: monday.AddDate(0, 0, 1)},
+    )
+
+    tests := []struct {
+        at   time.Time
+        want int
+    }{
+        {monday, 1},
+        {monday.Add(6*time.Hour - time.Second), 1},
+        {monday.Add(15 * time.Hour), 2},
+        {monday.AddDate(0, 1, 0), 3},
+    }
+    for _, tt := range tests {
+        version, err := service.GetSessionVersionAt(session.ID, tt.at)
+        if err != nil {
+            t.Errorf("At %s: %v", tt.at, err)
+            continue
+        }
+        if version.Version != tt.want {
+            t.Errorf("At %s: expected version %d, got %d", tt.at, tt.want, version.Version)
+        }
+    }
+
+    if _, err := service.GetSessionVersionAt(session.ID, monday.Add(-time.Minute)); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected not found before the first version, got %v", err)
+    }
+    if _, err := service.GetSessionVersionAt(42, monday); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected not found for an unknown session, got %v", err)
+    }
+}
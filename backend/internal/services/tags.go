@@ -0,0 +1,301 @@
+package services
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+
+    "hyprlnk/internal/models"
+)
+
+// tagSeparator splits nested tags: "dev/go" is a child of "dev"
+const tagSeparator = "/"
+
+// GetTags returns every tag in use or with stored metadata, including
+// implicit parents of nested tags, sorted by name
+func (s *hyprLinkService) GetTags() ([]models.TagStats, error) {
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+
+    infos, err := s.tagRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+
+    stats := make(map[string]*models.TagStats)
+    node := func(name string) *models.TagStats {
+        key := strings.ToLower(name)
+        if stat, exists := stats[key]; exists {
+            return stat
+        }
+        stat := &models.TagStats{TagInfo: models.TagInfo{Name: name}}
+        stats[key] = stat
+        return stat
+    }
+
+    for _, info := range infos {
+        node(info.Name).TagInfo = info
+    }
+
+    for _, bookmark := range bookmarks {
+        counted := make(map[string]bool)
+        for _, tag := range bookmark.Tags {
+            tag = cleanTagName(tag)
+            if tag == "" {
+                continue
+            }
+            node(tag).Count++
+
+            // Credit the tag and each of its ancestors once per bookmark
+            for _, name := range tagLineage(tag) {
+                key := strings.ToLower(name)
+                if !counted[key] {
+                    counted[key] = true
+                    node(name).TotalCount++
+                }
+            }
+        }
+    }
+
+    result := make([]models.TagStats, 0, len(stats))
+    for _, stat := range stats {
+        result = append(result, *stat)
+    }
+    sort.Slice(result, func(i, j int) bool {
+        return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name)
+    })
+
+    return result, nil
+}
+
+// GetBookmarksByTag returns bookmarks tagged with tag or any of its children
+func (s *hyprLinkService) GetBookmarksByTag(tag string) ([]models.Bookmark, error) {
+    tag = cleanTagName(tag)
+
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return nil, err
+    }
+
+    result := []models.Bookmark{}
+    for _, bookmark := range bookmarks {
+        for _, candidate := range bookmark.Tags {
+            if tagIsWithin(cleanTagName(candidate), tag) {
+                result = append(result, bookmark)
+                break
+            }
+        }
+    }
+    return result, nil
+}
+
+// RenameTag renames a tag and everything nested under it on every bookmark,
+// returning how many bookmarks changed
+func (s *hyprLinkService) RenameTag(from, to string) (int, error) {
+    return s.MergeTags([]string{from}, to)
+}
+
+// MergeTags folds each source tag, and its children, into target. All
+// affected bookmarks are rewritten in one storage write.
+func (s *hyprLinkService) MergeTags(sources []string, target string) (int, error) {
+    target = cleanTagName(target)
+    if target == "" {
+        return 0, fmt.Errorf("%w: target tag is required", ErrInvalidInput)
+    }
+
+    var cleaned []string
+    for _, source := range sources {
+        source = cleanTagName(source)
+        if source == "" {
+            continue
+        }
+        if tagIsWithin(target, source) && !strings.EqualFold(target, source) {
+            return 0, fmt.Errorf("%w: cannot move tag %q into its own child %q", ErrInvalidInput, source, target)
+        }
+        cleaned = append(cleaned, source)
+    }
+    if len(cleaned) == 0 {
+        return 0, fmt.Errorf("%w: at least one source tag is required", ErrInvalidInput)
+    }
+
+    rename := func(tag string) (string, bool) {
+        for _, source := range cleaned {
+            if tagIsWithin(tag, source) {
+                return target + tag[len(source):], true
+            }
+        }
+        return tag, true
+    }
+
+    changed, err := s.rewriteBookmarkTags(rename)
+    if err != nil {
+        return 0, err
+    }
+
+    // Carry metadata along, without clobbering metadata the target already has
+    infos, err := s.tagRepo.GetAll()
+    if err != nil {
+        return changed, err
+    }
+
+    existing := make(map[string]bool, len(infos))
+    for _, info := range infos {
+        existing[strings.ToLower(info.Name)] = true
+    }
+
+    var moved []models.TagInfo
+    var removed []string
+    for _, info := range infos {
+        newName, _ := rename(cleanTagName(info.Name))
+        if strings.EqualFold(newName, info.Name) {
+            continue
+        }
+        removed = append(removed, info.Name)
+        if !existing[strings.ToLower(newName)] {
+            info.Name = newName
+            moved = append(moved, info)
+        }
+    }
+    if err := s.tagRepo.Delete(removed...); err != nil {
+        return changed, err
+    }
+    if len(moved) > 0 {
+        if err := s.tagRepo.Save(moved...); err != nil {
+            return changed, err
+        }
+    }
+
+    return changed, nil
+}
+
+// DeleteTag removes a tag from every bookmark; with recursive set its
+// children go too. Returns how many bookmarks changed.
+func (s *hyprLinkService) DeleteTag(name string, recursive bool) (int, error) {
+    name = cleanTagName(name)
+    if name == "" {
+        return 0, fmt.Errorf("%w: tag name is required", ErrInvalidInput)
+    }
+
+    matches := func(tag string) bool {
+        if recursive {
+            return tagIsWithin(tag, name)
+        }
+        return strings.EqualFold(tag, name)
+    }
+
+    changed, err := s.rewriteBookmarkTags(func(tag string) (string, bool) {
+        return tag, !matches(tag)
+    })
+    if err != nil {
+        return 0, err
+    }
+
+    infos, err := s.tagRepo.GetAll()
+    if err != nil {
+        return changed, err
+    }
+    var removed []string
+    for _, info := range infos {
+        if matches(cleanTagName(info.Name)) {
+            removed = append(removed, info.Name)
+        }
+    }
+
+    return changed, s.tagRepo.Delete(removed...)
+}
+
+// UpdateTagInfo stores a tag's color and description
+func (s *hyprLinkService) UpdateTagInfo(info *models.TagInfo) error {
+    info.Name = cleanTagName(info.Name)
+    if info.Name == "" {
+        return fmt.Errorf("%w: tag name is required", ErrInvalidInput)
+    }
+    saved := []models.TagInfo{*info}
+    if err := s.tagRepo.Save(saved...); err != nil {
+        return err
+    }
+    *info = saved[0]
+    return nil
+}
+
+// rewriteBookmarkTags applies rewrite to every tag of every bookmark and
+// saves the bookmarks that changed in a single write. rewrite returns the
+// new tag name and whether to keep the tag at all.
+func (s *hyprLinkService) rewriteBookmarkTags(rewrite func(tag string) (string, bool)) (int, error) {
+    bookmarks, err := s.bookmarkRepo.GetAll()
+    if err != nil {
+        return 0, err
+    }
+
+    var changed []models.Bookmark
+    for _, bookmark := range bookmarks {
+        seen := make(map[string]bool)
+        tags := make([]string, 0, len(bookmark.Tags))
+        modified := false
+
+        for _, tag := range bookmark.Tags {
+            // Compared cleaned, so stray whitespace alone doesn't count as a change
+            cleaned := cleanTagName(tag)
+            if cleaned == "" {
+                continue
+            }
+            newTag, keep := rewrite(cleaned)
+            if !keep || newTag == "" {
+                modified = true
+                continue
+            }
+            if newTag != cleaned {
+                modified = true
+            }
+            key := strings.ToLower(newTag)
+            if seen[key] {
+                modified = true
+                continue
+            }
+            seen[key] = true
+            tags = append(tags, newTag)
+        }
+
+        if modified {
+            bookmark.Tags = tags
+            changed = append(changed, bookmark)
+        }
+    }
+
+    if err := s.bookmarkRepo.UpdateMany(changed); err != nil {
+        return 0, err
+    }
+    return len(changed), nil
+}
+
+// cleanTagName trims whitespace and stray separators: " dev//go/ " -> "dev/go"
+func cleanTagName(tag string) string {
+    var parts []string
+    for _, part := range strings.Split(tag, tagSeparator) {
+        if part = strings.TrimSpace(part); part != "" {
+            parts = append(parts, part)
+        }
+    }
+    return strings.Join(parts, tagSeparator)
+}
+
+// tagIsWithin reports whether tag is parent or nested below it
+func tagIsWithin(tag, parent string) bool {
+    if strings.EqualFold(tag, parent) {
+        return true
+    }
+    prefix := parent + tagSeparator
+    return len(tag) > len(prefix) && strings.EqualFold(tag[:len(prefix)], prefix)
+}
+
+// tagLineage returns tag and its ancestors: "a/b/c" -> ["a", "a/b", "a/b/c"]
+func tagLineage(tag string) []string {
+    parts := strings.Split(tag, tagSeparator)
+    lineage := make([]string, len(parts))
+    for i := range parts {
+        lineage[i] = strings.Join(parts[:i+1], tagSeparator)
+    }
+    return lineage
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestRenameTag_Nested(t *testing.T) {
+    service, _ := newTestService(t)
+    nested := mustCreateBookmark(t, service, newBookmark("https://example.com/go", "dev/go", "news"))
+    parent := mustCreateBookmark(t, service, newBookmark("https://example.com/dev", "dev"))
+    other := mustCreateBookmark(t, service, newBookmark("https://example.com/devops", "devops"))
+
+    changed, err := service.RenameTag("dev", "code")
+    if err != nil {
+        t.Fatalf("Failed to rename tag: %v", err)
+    }
+    if changed != 2 {
+        t.Errorf("Expected 2 bookmarks changed, got %d", changed)
+    }
+    if tags := tagsOf(t, service, nested.ID); !reflect.DeepEqual(tags, []string{"code/go", "news"}) {
+        t.Errorf("Expected the child tag to move, got %v", tags)
+    }
+    if tags := tagsOf(t, service, parent.ID); !reflect.DeepEqual(tags, []string{"code"}) {
+        t.Errorf("Expected the tag to be renamed, got %v", tags)
+    }
+    if tags := tagsOf(t, service, other.ID); !reflect.DeepEqual(tags, []string{"devops"}) {
+        t.Errorf("Expected a tag that only shares a prefix to stay, got %v", tags)
+    }
+
+    if _, err := service.RenameTag("code", "code/go"); err == nil {
+        t.Error("Expected renaming a tag into its own child to fail")
+    }
+}
+
+func TestMergeTags_Nested(t *testing.T) {
+    service, _ := newTestService(t)
+    both := mustCreateBookmark(t, service, newBookmark("https://example.com/a", "golang", "go/tools"))
+    one := mustCreateBookmark(t, service, newBookmark("https://example.com/b", "golang/testing"))
+
+    changed, err := service.MergeTags([]string{"golang", "go"}, "lang/go")
+    if err != nil {
+        t.Fatalf("Failed to merge tags: %v", err)
+    }
+    if changed != 2 {
+        t.Errorf("Expected 2 bookmarks changed, got %d", changed)
+    }
+    if tags := tagsOf(t, service, both.ID); !reflect.DeepEqual(tags, []string{"lang/go", "lang/go/tools"}) {
+        t.Errorf("Unexpected tags after merge: %v", tags)
+    }
+    if tags := tagsOf(t, service, one.ID); !reflect.DeepEqual(tags, []string{"lang/go/testing"}) {
+        t.Errorf("Unexpected tags after merge: %v", tags)
+    }
+}
+
+func TestDeleteTag_Nested(t *testing.T) {
+    service, _ := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://example.com/a", "dev", "dev/go", "news"))
+
+    if _, err := service.DeleteTag("dev", false); err != nil {
+        t.Fatalf("Failed to delete tag: %v", err)
+    }
+    if tags := tagsOf(t, service, bookmark.ID); !reflect.DeepEqual(tags, []string{"dev/go", "news"}) {
+        t.Errorf("Expected only the tag itself to go, got %v", tags)
+    }
+
+    if _, err := service.DeleteTag("dev", true); err != nil {
+        t.Fatalf("Failed to delete tag recursively: %v", err)
+    }
+    if tags := tagsOf(t, service, bookmark.ID); !reflect.DeepEqual(tags, []string{"news"}) {
+        t.Errorf("Expected children to go too, got %v", tags)
+    }
+}
+
+func TestRewriteBookmarkTags_IgnoresWhitespace(t *testing.T) {
+    service, store := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://example.com/a", " news ", "dev/ go"))
+    // Stored as the user typed them, which the rename below doesn't touch
+    bookmark.Tags = []string{" news ", "dev/ go"}
+    if err := store.UpdateBookmark(bookmark); err != nil {
+        t.Fatalf("Failed to store bookmark: %v", err)
+    }
+
+    changed, err := service.RenameTag("unused", "other")
+    if err != nil {
+        t.Fatalf("Failed to rename tag: %v", err)
+    }
+    if changed != 0 {
+        t.Errorf("Expected tags differing only by whitespace to be left alone, got %d changed", changed)
+    }
+}
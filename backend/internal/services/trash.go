@@ -0,0 +1,199 @@
+package services
+
+import (
+    "encoding/json"
+    "fmt"
+    "log"
+    "time"
+
+    "hyprlnk/internal/models"
+)
+
+// trashCleanupInterval is how often expired items are purged from the trash
+const trashCleanupInterval = time.Hour
+
+// GetTrash lists the deleted bookmarks and sessions that can still be
+// restored, most recently deleted first
+func (s *hyprLinkService) GetTrash() ([]models.TrashItem, error) {
+    return s.trashRepo.GetAll()
+}
+
+// RestoreFromTrash puts a deleted bookmark or session back as it was when
+// it was deleted, and returns it. Restoring is recorded like any other
+// change, so it can be undone.
+func (s *hyprLinkService) RestoreFromTrash(itemType string, id int64) (json.RawMessage, error) {
+    if err := checkItemType(itemType); err != nil {
+        return nil, err
+    }
+    item, err := s.trashRepo.Get(itemType, id)
+    if err != nil {
+        return nil, kindError{kind: ErrNotFound, err: err}
+    }
+
+    states := []models.ItemState{{ItemType: itemType, ItemID: id, State: item.Item}}
+    if err := s.revert(models.OperationRestore, states, 0); err != nil {
+        return nil, err
+    }
+    return item.Item, nil
+}
+
+// PurgeItem deletes a bookmark or session for good, whether it is still
+// live or already in the trash, along with its revisions. A bookmark's
+// archived page, fetched metadata, link checks and the notes and
+// highlights on its page go with it.
+func (s *hyprLinkService) PurgeItem(itemType string, id int64) error {
+    if err := checkItemType(itemType); err != nil {
+        return err
+    }
+
+    var url string
+    if trashed, err := s.trashRepo.Get(itemType, id); err == nil {
+        url = trashed.URL
+    } else if itemType == models.ItemBookmark {
+        bookmark, err := s.bookmarkRepo.GetByID(id)
+        if err != nil {
+            return kindError{kind: ErrNotFound, err: err}
+        }
+        url = bookmark.URL
+    } else if _, err := s.sessionRepo.GetByID(id); err != nil {
+        return kindError{kind: ErrNotFound, err: err}
+    }
+
+    if itemType == models.ItemBookmark {
+        if err := s.deletePageAnnotations(id, url); err != nil {
+            return err
+        }
+    }
+    return s.purge([]models.ItemRef{{Type: itemType, ID: id}})
+}
+
+// deletePageAnnotations deletes the annotations attached to a bookmark
+// and, as deleting it left them there, those on its page that no other
+// bookmark holds
+func (s *hyprLinkService) deletePageAnnotations(bookmarkID int64, url string) error {
+    normalizer, err := s.urlNormalizer()
+    if err != nil {
+        return err
+    }
+    all, err := s.annotationRepo.GetAll()
+    if err != nil {
+        return err
+    }
+
+    canonical := normalizer.Normalize(url)
+    var ids []int64
+    for _, annotation := range all {
+        onPage := annotation.BookmarkID == 0 && normalizer.Normalize(annotation.URL) == canonical
+        if annotation.BookmarkID == bookmarkID || onPage {
+            ids = append(ids, annotation.ID)
+        }
+    }
+    if len(ids) == 0 {
+        return nil
+    }
+    if err := s.annotationRepo.DeleteMany(ids); err != nil {
+        return err
+    }
+    for _, id := range ids {
+        s.annotationIndex().Delete(id)
+    }
+    return nil
+}
+
+// EmptyTrash purges everything in the trash and returns how many items
+// went
+func (s *hyprLinkService) EmptyTrash() (int, error) {
+    return s.emptyTrashBefore(time.Now())
+}
+
+// emptyTrashBefore purges the items deleted before cutoff
+func (s *hyprLinkService) emptyTrashBefore(cutoff time.Time) (int, error) {
+    items, err := s.trashRepo.GetAll()
+    if err != nil {
+        return 0, err
+    }
+
+    var expired []models.ItemRef
+    for _, item := range items {
+        if item.DeletedAt.Before(cutoff) {
+            expired = append(expired, models.ItemRef{Type: item.Type, ID: item.ID})
+        }
+    }
+    if len(expired) == 0 {
+        return 0, nil
+    }
+    return len(expired), s.purge(expired)
+}
+
+// purge removes items for good, with everything stored about them. The
+// notes and highlights on a bookmark's page are the page's, so only
+// PurgeItem, a privacy purge, deletes them.
+func (s *hyprLinkService) purge(items []models.ItemRef) error {
+    var bookmarkIDs []int64
+    for _, item := range items {
+        if item.Type == models.ItemBookmark {
+            bookmarkIDs = append(bookmarkIDs, item.ID)
+        }
+    }
+
+    if len(bookmarkIDs) > 0 {
+        if err := s.archiveRepo.Delete(bookmarkIDs...); err != nil {
+            return err
+        }
+        if err := s.metadataRepo.Delete(bookmarkIDs...); err != nil {
+            return err
+        }
+        if err := s.linkHealthRepo.Delete(bookmarkIDs...); err != nil {
+            return err
+        }
+        // Undoing a save trashes a bookmark still holding its annotations
+        if err := s.moveAnnotations(bookmarkIDs, nil); err != nil {
+            return err
+        }
+        for _, id := range bookmarkIDs {
+            s.searchIndex().Delete(id)
+        }
+    }
+    return s.trashRepo.Purge(items...)
+}
+
+// runTrashCleanup purges items that have been in the trash longer than
+// the configured retention
+func (s *hyprLinkService) runTrashCleanup() {
+    ticker := time.NewTicker(trashCleanupInterval)
+    defer ticker.Stop()
+
+    for {
+        settings, err := s.settingsRepo.Get()
+        if err != nil {
+            log.Printf("trash: reading settings: %v", err)
+        } else if days := trashRetentionDays(settings); days >= 0 {
+            cutoff := time.Now().AddDate(0, 0, -days)
+            if purged, err := s.emptyTrashBefore(cutoff); err != nil {
+                log.Printf("trash: %v", err)
+            } else if purged > 0 {
+                log.Printf("trash: purged %d items deleted over %d days ago", purged, days)
+            }
+        }
+        <-ticker.C
+    }
+}
+
+// trashRetentionDays is how long deleted items are kept; 0 means the
+// default and a negative value keeps them until the trash is emptied
+func trashRetentionDays(settings *models.Settings) int {
+    if settings.TrashRetentionDays == 0 {
+        return models.DefaultTrashRetentionDays
+    }
+    if settings.TrashRetentionDays < 0 {
+        return -1
+    }
+    return settings.TrashRetentionDays
+}
+
+func checkItemType(itemType string) error {
+    if itemType != models.ItemBookmark && itemType != models.ItemSession {
+        return fmt.Errorf("%w: unknown item type %q", ErrInvalidInput, itemType)
+    }
+    return nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+    "encoding/json"
+    "errors"
+    "testing"
+    "time"
+
+    "hyprlnk/internal/models"
+)
+
+func TestRestoreFromTrash(t *testing.T) {
+    service, _ := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://example.com", "keep"))
+    if err := service.DeleteBookmark(bookmark.ID); err != nil {
+        t.Fatal(err)
+    }
+
+    trash, _ := service.GetTrash()
+    if len(trash) != 1 || trash[0].Type != models.ItemBookmark || trash[0].ID != bookmark.ID {
+        t.Fatalf("Expected the bookmark in the trash, got %+v", trash)
+    }
+
+    state, err := service.RestoreFromTrash(models.ItemBookmark, bookmark.ID)
+    if err != nil {
+        t.Fatalf("Restore failed: %v", err)
+    }
+    var restored models.Bookmark
+    if err := json.Unmarshal(state, &restored); err != nil || restored.ID != bookmark.ID {
+        t.Errorf("Expected the restored bookmark back, got %s", state)
+    }
+    if got := tagsOf(t, service, bookmark.ID); len(got) != 1 || got[0] != "keep" {
+        t.Errorf("Expected the bookmark as it was, got tags %v", got)
+    }
+    if trash, _ := service.GetTrash(); len(trash) != 0 {
+        t.Errorf("Expected the trash empty after restoring, got %+v", trash)
+    }
+
+    if _, err := service.RestoreFromTrash(models.ItemBookmark, bookmark.ID); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected not found restoring twice, got %v", err)
+    }
+    if _, err := service.RestoreFromTrash("folder", bookmark.ID); !errors.Is(err, ErrInvalidInput) {
+        t.Errorf("Expected an unknown item type rejected, got %v", err)
+    }
+}
+
+func TestEmptyTrashBefore(t *testing.T) {
+    service, _ := newTestService(t)
+    for _, url := range []string{"https://one.example", "https://two.example"} {
+        bookmark := mustCreateBookmark(t, service, newBookmark(url))
+        if err := service.DeleteBookmark(bookmark.ID); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    if purged, err := service.emptyTrashBefore(time.Now().Add(-time.Hour)); err != nil || purged != 0 {
+        t.Errorf("Expected nothing deleted an hour ago to purge, got %d (%v)", purged, err)
+    }
+    if purged, err := service.emptyTrashBefore(time.Now().Add(time.Second)); err != nil || purged != 2 {
+        t.Errorf("Expected both expired items purged, got %d (%v)", purged, err)
+    }
+    if trash, _ := service.GetTrash(); len(trash) != 0 {
+        t.Errorf("Expected the trash empty, got %+v", trash)
+    }
+}
+
+func TestTrashRetentionDays(t *testing.T) {
+    tests := []struct {
+        setting int
+        want    int
+    }{
+        {0, models.DefaultTrashRetentionDays},
+        {7, 7},
+        {-5, -1},
+    }
+    for _, tt := range tests {
+        if got := trashRetentionDays(&models.Settings{TrashRetentionDays: tt.setting}); got != tt.want {
+            t.Errorf("trashRetentionDays(%d) = %d, want %d", tt.setting, got, tt.want)
+        }
+    }
+}
+
+func TestPurgeItem_Live(t *testing.T) {
+    service, _ := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://example.com/post"))
+    note := models.Annotation{BookmarkID: bookmark.ID, Body: "Worth rereading"}
+    if err := service.CreateAnnotation(&note); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := service.PurgeItem(models.ItemBookmark, bookmark.ID); err != nil {
+        t.Fatalf("Purge failed: %v", err)
+    }
+    if _, err := service.GetBookmark(bookmark.ID); err == nil {
+        t.Error("Expected the bookmark gone")
+    }
+    if trash, _ := service.GetTrash(); len(trash) != 0 {
+        t.Errorf("Expected a purged bookmark to skip the trash, got %+v", trash)
+    }
+    if _, err := service.GetRevisions(models.ItemBookmark, bookmark.ID); err == nil {
+        t.Error("Expected the revisions purged")
+    }
+
+    // A privacy purge takes the notes on the page too
+    if _, err := service.GetAnnotation(note.ID); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected the note purged, got %v", err)
+    }
+    if found, _ := service.GetAnnotations(0, "", "", "rereading"); len(found) != 0 {
+        t.Errorf("Expected the note gone from search, got %+v", found)
+    }
+
+    if err := service.PurgeItem(models.ItemBookmark, bookmark.ID); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected not found purging twice, got %v", err)
+    }
+}
+
+func TestPurgeItem_TrashedAnnotations(t *testing.T) {
+    service, _ := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://example.com/post"))
+    other := mustCreateBookmark(t, service, newBookmark("https://example.com/other"))
+    onPage := models.Annotation{BookmarkID: bookmark.ID, Body: "On the page"}
+    elsewhere := models.Annotation{BookmarkID: other.ID, Body: "Elsewhere"}
+    for _, annotation := range []*models.Annotation{&onPage, &elsewhere} {
+        if err := service.CreateAnnotation(annotation); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    // Deleting leaves the note on its page; purging from the trash removes it
+    if err := service.DeleteBookmark(bookmark.ID); err != nil {
+        t.Fatal(err)
+    }
+    if got, err := service.GetAnnotation(onPage.ID); err != nil || got.BookmarkID != 0 {
+        t.Fatalf("Expected the note detached onto its page, got %+v (%v)", got, err)
+    }
+    if err := service.PurgeItem(models.ItemBookmark, bookmark.ID); err != nil {
+        t.Fatalf("Purge failed: %v", err)
+    }
+    if _, err := service.GetAnnotation(onPage.ID); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected the page's note purged, got %v", err)
+    }
+    if got, err := service.GetAnnotation(elsewhere.ID); err != nil || got.BookmarkID != other.ID {
+        t.Errorf("Expected another bookmark's note kept, got %+v (%v)", got, err)
+    }
+}
+
+func TestPurgeItem_Trashed(t *testing.T) {
+    service, _ := newTestService(t)
+    bookmark := mustCreateBookmark(t, service, newBookmark("https://example.com"))
+    if err := service.DeleteBookmark(bookmark.ID); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := service.PurgeItem(models.ItemBookmark, bookmark.ID); err != nil {
+        t.Fatalf("Purge failed: %v", err)
+    }
+    if trash, _ := service.GetTrash(); len(trash) != 0 {
+        t.Errorf("Expected the trash empty, got %+v", trash)
+    }
+    if _, err := service.RestoreFromTrash(models.ItemBookmark, bookmark.ID); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected nothing left to restore, got %v", err)
+    }
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -40,6 +43,42 @@ type AppendLogStorage struct {
 	linkClickDeltaBuffer []models.LinkClick
 	linkClickDeltaCount  int
 	
+	// Materialized views of the data sets above (see view.go); nil until
+	// first read, or while a view doesn't fit the budget
+	bookmarkIndex  *bookmarkIndex
+	sessionIndex   *sessionIndex
+	historyIndex   *historyIndex
+	linkClickIndex *linkClickIndex
+	viewBudget     int64           // bytes all views may hold; 0 turns them off
+	viewSkipped    map[string]bool // views over budget until their next compaction
+	
+	// Settings storage (single small JSON document, rewritten in place)
+	settingsFile string
+	
+	// Small keyed collections stored as JSON documents
+	collections *documentLog
+	tags        *documentLog
+	jobs        *documentLog
+	rules       *documentLog
+	metadata    *documentLog
+	archives    *documentLog
+	linkHealth  *documentLog
+	annotations *documentLog
+	revisions   *documentLog
+	trash       *documentLog
+	
+	// Session versions, keyed by session ID and version number
+	sessionVersions *documentLog
+	
+	// Job payloads are opaque blobs kept alongside, one file per job
+	jobPayloadDir string
+	
+	// Favicons are stored as fetched, one file per host
+	faviconDir string
+	
+	// Archived pages: an HTML snapshot and the readable text per bookmark
+	archiveDir string
+	
 	compactThreshold int
 	mutex           sync.RWMutex
 	flushTicker     *time.Ticker
@@ -74,6 +113,29 @@ func NewAppendLogStorage(dataDir string) *AppendLogStorage {
 		linkClickDeltaFile: filepath.Join(dataDir, "link_clicks.delta.json"),
 		linkClickDeltaBuffer: make([]models.LinkClick, 0),
 		
+		// Settings file
+		settingsFile: filepath.Join(dataDir, "settings.json"),
+		
+		collections: newDocumentLog(dataDir, "collections"),
+		tags:        newDocumentLog(dataDir, "tags"),
+		jobs:        newDocumentLog(dataDir, "jobs"),
+		rules:       newDocumentLog(dataDir, "rules"),
+		metadata:    newDocumentLog(dataDir, "metadata"),
+		archives:    newDocumentLog(dataDir, "archives"),
+		linkHealth:  newDocumentLog(dataDir, "link_health"),
+		annotations: newDocumentLog(dataDir, "annotations"),
+		revisions:   newDocumentLog(dataDir, "revisions"),
+		trash:       newDocumentLog(dataDir, "trash"),
+		
+		sessionVersions: newDocumentLog(dataDir, "session_versions"),
+		
+		jobPayloadDir: filepath.Join(dataDir, "jobs"),
+		faviconDir:    filepath.Join(dataDir, "favicons"),
+		archiveDir:    filepath.Join(dataDir, "archive"),
+		
+		viewBudget:  DefaultViewBudget,
+		viewSkipped: make(map[string]bool),
+		
 		compactThreshold: 100, // Compact after 100 delta entries per type
 		stopChan:        make(chan bool),
 		parquetStorage:  &ParquetStorage{dataDir: dataDir},
@@ -127,15 +189,34 @@ func (als *AppendLogStorage) WriteBookmarks(bookmarks []models.Bookmark) error {
 	os.Remove(als.bookmarkDeltaFile)
 	als.bookmarkDeltaBuffer = make([]models.Bookmark, 0)
 	als.bookmarkDeltaCount = 0
+	delete(als.viewSkipped, viewBookmarks)
+	if _, err := als.materializeBookmarksLocked(bookmarks); err != nil {
+		return err
+	}
 	
 	return nil
 }
 
 // ReadBookmarks reads all bookmarks with updates/deletes applied
-func (als *AppendLogStorage) ReadBookmarks() ([]models.Bookmark, error) {
-	als.mutex.RLock()
-	defer als.mutex.RUnlock()
-	
+func (als *AppendLogStorage) ReadBookmarks() (bookmarks []models.Bookmark, err error) {
+	err = als.readView(viewBookmarks, als.bookmarkViewBuilt, func() error {
+		if !als.bookmarkViewBuilt() && !als.viewEnabledLocked(viewBookmarks) {
+			bookmarks, err = als.readBookmarksLocked()
+			return err
+		}
+		index, err := als.bookmarkIndexLocked()
+		if err != nil {
+			return err
+		}
+		bookmarks = index.all()
+		return nil
+	})
+	return bookmarks, err
+}
+
+// readBookmarksLocked merges main file and delta from disk; caller must
+// hold the mutex
+func (als *AppendLogStorage) readBookmarksLocked() ([]models.Bookmark, error) {
 	// Read main Parquet file
 	mainBookmarks, err := als.parquetStorage.ReadBookmarks()
 	if err != nil && !os.IsNotExist(err) {
@@ -178,13 +259,18 @@ func (als *AppendLogStorage) AddBookmark(bookmark models.Bookmark) error {
 		bookmark.ID = time.Now().UnixNano()
 	}
 	
-	// Set timestamps
+	// Set timestamps unless the caller already did
 	now := time.Now()
-	bookmark.CreatedAt = now
-	bookmark.UpdatedAt = now
+	if bookmark.CreatedAt.IsZero() {
+		bookmark.CreatedAt = now
+	}
+	if bookmark.UpdatedAt.IsZero() {
+		bookmark.UpdatedAt = now
+	}
 	
 	// Add to in-memory buffer
 	als.bookmarkDeltaBuffer = append(als.bookmarkDeltaBuffer, bookmark)
+	als.indexBookmarksLocked(bookmark)
 	
 	// Persist to delta log immediately (append-only, fast)
 	if err := als.appendBookmarkToDelta(bookmark); err != nil {
@@ -211,6 +297,7 @@ func (als *AppendLogStorage) UpdateBookmark(bookmark models.Bookmark) error {
 	
 	// Add updated bookmark to delta (latest version wins during read)
 	als.bookmarkDeltaBuffer = append(als.bookmarkDeltaBuffer, bookmark)
+	als.indexBookmarksLocked(bookmark)
 	
 	if err := als.appendBookmarkToDelta(bookmark); err != nil {
 		return fmt.Errorf("failed to append bookmark update: %w", err)
@@ -225,6 +312,48 @@ func (als *AppendLogStorage) UpdateBookmark(bookmark models.Bookmark) error {
 	return nil
 }
 
+// UpdateBookmarks writes several new or changed bookmarks as one delta
+// append, so either all of them are persisted or none are
+func (als *AppendLogStorage) UpdateBookmarks(bookmarks []models.Bookmark) error {
+	if len(bookmarks) == 0 {
+		return nil
+	}
+	
+	now := time.Now()
+	updated := make([]models.Bookmark, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		bookmark.UpdatedAt = now
+		updated[i] = bookmark
+	}
+	
+	return als.RestoreBookmarks(updated)
+}
+
+// RestoreBookmarks writes bookmarks exactly as given, timestamps included,
+// as one delta append; for putting back an earlier state
+func (als *AppendLogStorage) RestoreBookmarks(bookmarks []models.Bookmark) error {
+	if len(bookmarks) == 0 {
+		return nil
+	}
+	
+	als.mutex.Lock()
+	defer als.mutex.Unlock()
+	
+	if err := appendManyToDeltaFile(als.bookmarkDeltaFile, bookmarks); err != nil {
+		return fmt.Errorf("failed to append bookmark updates: %w", err)
+	}
+	
+	als.bookmarkDeltaBuffer = append(als.bookmarkDeltaBuffer, bookmarks...)
+	als.indexBookmarksLocked(bookmarks...)
+	als.bookmarkDeltaCount += len(bookmarks)
+	
+	if als.bookmarkDeltaCount >= als.compactThreshold {
+		go als.compactBookmarks()
+	}
+	
+	return nil
+}
+
 // DeleteBookmark marks a bookmark as deleted
 func (als *AppendLogStorage) DeleteBookmark(id int64) error {
 	als.mutex.Lock()
@@ -238,6 +367,7 @@ func (als *AppendLogStorage) DeleteBookmark(id int64) error {
 	}
 	
 	als.bookmarkDeltaBuffer = append(als.bookmarkDeltaBuffer, tombstone)
+	als.indexBookmarksLocked(tombstone)
 	
 	if err := als.appendBookmarkToDelta(tombstone); err != nil {
 		return fmt.Errorf("failed to append bookmark deletion: %w", err)
@@ -252,6 +382,40 @@ func (als *AppendLogStorage) DeleteBookmark(id int64) error {
 	return nil
 }
 
+// DeleteBookmarks marks several bookmarks as deleted in one delta append
+func (als *AppendLogStorage) DeleteBookmarks(ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	
+	als.mutex.Lock()
+	defer als.mutex.Unlock()
+	
+	now := time.Now()
+	tombstones := make([]models.Bookmark, len(ids))
+	for i, id := range ids {
+		tombstones[i] = models.Bookmark{
+			ID:        id,
+			Title:     "__DELETED__",
+			UpdatedAt: now,
+		}
+	}
+	
+	if err := appendManyToDeltaFile(als.bookmarkDeltaFile, tombstones); err != nil {
+		return fmt.Errorf("failed to append bookmark deletions: %w", err)
+	}
+	
+	als.bookmarkDeltaBuffer = append(als.bookmarkDeltaBuffer, tombstones...)
+	als.indexBookmarksLocked(tombstones...)
+	als.bookmarkDeltaCount += len(tombstones)
+	
+	if als.bookmarkDeltaCount >= als.compactThreshold {
+		go als.compactBookmarks()
+	}
+	
+	return nil
+}
+
 // ============== SESSION METHODS ==============
 
 // WriteSessions replaces all sessions (used for imports)
@@ -268,15 +432,32 @@ func (als *AppendLogStorage) WriteSessions(sessions []models.Session) error {
 	os.Remove(als.sessionDeltaFile)
 	als.sessionDeltaBuffer = make([]models.Session, 0)
 	als.sessionDeltaCount = 0
+	delete(als.viewSkipped, viewSessions)
+	als.materializeSessionsLocked(sessions)
 	
 	return nil
 }
 
 // ReadSessions reads all sessions with updates/deletes applied
-func (als *AppendLogStorage) ReadSessions() ([]models.Session, error) {
-	als.mutex.RLock()
-	defer als.mutex.RUnlock()
-	
+func (als *AppendLogStorage) ReadSessions() (sessions []models.Session, err error) {
+	err = als.readView(viewSessions, als.sessionViewBuilt, func() error {
+		if !als.sessionViewBuilt() && !als.viewEnabledLocked(viewSessions) {
+			sessions, err = als.readSessionsLocked()
+			return err
+		}
+		index, err := als.sessionIndexLocked()
+		if err != nil {
+			return err
+		}
+		sessions = index.all()
+		return nil
+	})
+	return sessions, err
+}
+
+// readSessionsLocked merges main file and delta from disk; caller must
+// hold the mutex
+func (als *AppendLogStorage) readSessionsLocked() ([]models.Session, error) {
 	// Read main Parquet file
 	mainSessions, err := als.parquetStorage.ReadSessions()
 	if err != nil && !os.IsNotExist(err) {
@@ -326,6 +507,7 @@ func (als *AppendLogStorage) AddSession(session models.Session) error {
 	
 	// Add to buffer
 	als.sessionDeltaBuffer = append(als.sessionDeltaBuffer, session)
+	als.indexSessionsLocked(session)
 	
 	// Persist to delta log
 	if err := als.appendSessionToDelta(session); err != nil {
@@ -343,12 +525,18 @@ func (als *AppendLogStorage) AddSession(session models.Session) error {
 
 // UpdateSession updates an existing session
 func (als *AppendLogStorage) UpdateSession(session models.Session) error {
+	session.UpdatedAt = time.Now()
+	return als.RestoreSession(session)
+}
+
+// RestoreSession writes a session exactly as given, timestamps included;
+// for putting back an earlier state
+func (als *AppendLogStorage) RestoreSession(session models.Session) error {
 	als.mutex.Lock()
 	defer als.mutex.Unlock()
 	
-	session.UpdatedAt = time.Now()
-	
 	als.sessionDeltaBuffer = append(als.sessionDeltaBuffer, session)
+	als.indexSessionsLocked(session)
 	
 	if err := als.appendSessionToDelta(session); err != nil {
 		return fmt.Errorf("failed to append session update: %w", err)
@@ -375,6 +563,7 @@ func (als *AppendLogStorage) DeleteSession(id int64) error {
 	}
 	
 	als.sessionDeltaBuffer = append(als.sessionDeltaBuffer, tombstone)
+	als.indexSessionsLocked(tombstone)
 	
 	if err := als.appendSessionToDelta(tombstone); err != nil {
 		return fmt.Errorf("failed to append session deletion: %w", err)
@@ -392,21 +581,23 @@ func (als *AppendLogStorage) DeleteSession(id int64) error {
 // ============== HISTORY METHODS ==============
 // History is append-only by nature (no updates/deletes)
 
-// WriteHistory writes history entries (batch operation)
+// WriteHistory writes history entries (batch operation). An entry for a
+// URL that is already stored replaces the older one.
 func (als *AppendLogStorage) WriteHistory(history []models.HistoryEntry) error {
 	als.mutex.Lock()
 	defer als.mutex.Unlock()
 	
-	// For batch operations, append to delta buffer
-	for _, entry := range history {
-		als.historyDeltaBuffer = append(als.historyDeltaBuffer, entry)
-		
-		if err := als.appendHistoryToDelta(entry); err != nil {
-			return fmt.Errorf("failed to append history: %w", err)
-		}
-		
-		als.historyDeltaCount++
+	if len(history) == 0 {
+		return nil
+	}
+	
+	// One write and fsync for the whole batch; imports can be large
+	if err := appendManyToDeltaFile(als.historyDeltaFile, history); err != nil {
+		return fmt.Errorf("failed to append history: %w", err)
 	}
+	als.historyDeltaBuffer = append(als.historyDeltaBuffer, history...)
+	als.indexHistoryLocked(history...)
+	als.historyDeltaCount += len(history)
 	
 	// Compact if needed
 	if als.historyDeltaCount >= als.compactThreshold {
@@ -416,19 +607,45 @@ func (als *AppendLogStorage) WriteHistory(history []models.HistoryEntry) error {
 	return nil
 }
 
-// ReadHistory reads all history entries
-func (als *AppendLogStorage) ReadHistory() ([]models.HistoryEntry, error) {
-	als.mutex.RLock()
-	defer als.mutex.RUnlock()
-	
+// ReadHistory reads all history entries, one per URL
+func (als *AppendLogStorage) ReadHistory() (history []models.HistoryEntry, err error) {
+	err = als.readView(viewHistory, als.historyViewBuilt, func() error {
+		if !als.historyViewBuilt() && !als.viewEnabledLocked(viewHistory) {
+			history, err = als.readHistoryLocked()
+			return err
+		}
+		index, err := als.historyIndexLocked()
+		if err != nil {
+			return err
+		}
+		history = index.all()
+		return nil
+	})
+	return history, err
+}
+
+// readHistoryLocked merges main file and delta from disk; caller must hold
+// the mutex
+func (als *AppendLogStorage) readHistoryLocked() ([]models.HistoryEntry, error) {
 	// Read main file
 	mainHistory, err := als.parquetStorage.ReadHistory()
 	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to read main history: %w", err)
 	}
 	
-	// Append delta buffer (no deduplication needed for history)
-	result := append(mainHistory, als.historyDeltaBuffer...)
+	// Later entries for a URL supersede earlier ones
+	all := append(mainHistory, als.historyDeltaBuffer...)
+	latest := make(map[string]int, len(all))
+	for i, entry := range all {
+		latest[entry.URL] = i
+	}
+	
+	result := make([]models.HistoryEntry, 0, len(latest))
+	for i, entry := range all {
+		if latest[entry.URL] == i {
+			result = append(result, entry)
+		}
+	}
 	
 	return result, nil
 }
@@ -441,22 +658,27 @@ func (als *AppendLogStorage) WriteLinkClicks(clicks []models.LinkClick) error {
 	als.mutex.Lock()
 	defer als.mutex.Unlock()
 	
-	// For batch operations, append to delta buffer
-	for _, click := range clicks {
-		// Generate ID if not set
+	if len(clicks) == 0 {
+		return nil
+	}
+	
+	batch := make([]models.LinkClick, len(clicks))
+	for i, click := range clicks {
+		// Generate ID if not set; offset so a fast loop can't repeat one
 		if click.ID == 0 {
-			click.ID = time.Now().UnixNano()
-		}
-		
-		als.linkClickDeltaBuffer = append(als.linkClickDeltaBuffer, click)
-		
-		if err := als.appendLinkClickToDelta(click); err != nil {
-			return fmt.Errorf("failed to append link click: %w", err)
+			click.ID = time.Now().UnixNano() + int64(i)
 		}
-		
-		als.linkClickDeltaCount++
+		batch[i] = click
 	}
 	
+	// One write and fsync for the whole batch; imports can be large
+	if err := appendManyToDeltaFile(als.linkClickDeltaFile, batch); err != nil {
+		return fmt.Errorf("failed to append link clicks: %w", err)
+	}
+	als.linkClickDeltaBuffer = append(als.linkClickDeltaBuffer, batch...)
+	als.indexLinkClicksLocked(batch...)
+	als.linkClickDeltaCount += len(batch)
+	
 	// Compact if needed
 	if als.linkClickDeltaCount >= als.compactThreshold {
 		go als.compactLinkClicks()
@@ -466,10 +688,25 @@ func (als *AppendLogStorage) WriteLinkClicks(clicks []models.LinkClick) error {
 }
 
 // ReadLinkClicks reads all link click entries
-func (als *AppendLogStorage) ReadLinkClicks() ([]models.LinkClick, error) {
-	als.mutex.RLock()
-	defer als.mutex.RUnlock()
-	
+func (als *AppendLogStorage) ReadLinkClicks() (clicks []models.LinkClick, err error) {
+	err = als.readView(viewLinkClicks, als.linkClickViewBuilt, func() error {
+		if !als.linkClickViewBuilt() && !als.viewEnabledLocked(viewLinkClicks) {
+			clicks, err = als.readLinkClicksLocked()
+			return err
+		}
+		index, err := als.linkClickIndexLocked()
+		if err != nil {
+			return err
+		}
+		clicks = append([]models.LinkClick(nil), index.clicks...)
+		return nil
+	})
+	return clicks, err
+}
+
+// readLinkClicksLocked reads main file and delta from disk; caller must
+// hold the mutex
+func (als *AppendLogStorage) readLinkClicksLocked() ([]models.LinkClick, error) {
 	// Read main file
 	mainClicks, err := als.parquetStorage.ReadLinkClicks()
 	if err != nil && !os.IsNotExist(err) {
@@ -482,6 +719,476 @@ func (als *AppendLogStorage) ReadLinkClicks() ([]models.LinkClick, error) {
 	return result, nil
 }
 
+// ============== COLLECTION METHODS ==============
+
+// ReadCollections reads all bookmark collections
+func (als *AppendLogStorage) ReadCollections() ([]models.Collection, error) {
+	return readDocuments[models.Collection](als, als.collections)
+}
+
+// PutCollections adds or replaces collections in a single write
+func (als *AppendLogStorage) PutCollections(collections ...models.Collection) error {
+	return putDocuments(als, als.collections, collectionKey, collections...)
+}
+
+// DeleteCollection removes a collection
+func (als *AppendLogStorage) DeleteCollection(id int64) error {
+	return deleteDocuments(als, als.collections, strconv.FormatInt(id, 10))
+}
+
+func collectionKey(collection models.Collection) string {
+	return strconv.FormatInt(collection.ID, 10)
+}
+
+// ============== TAG METHODS ==============
+// Only tag metadata lives here; which bookmarks carry a tag is stored on the bookmark
+
+// ReadTagInfo reads all stored tag metadata
+func (als *AppendLogStorage) ReadTagInfo() ([]models.TagInfo, error) {
+	return readDocuments[models.TagInfo](als, als.tags)
+}
+
+// PutTagInfo adds or replaces tag metadata in a single write
+func (als *AppendLogStorage) PutTagInfo(tags ...models.TagInfo) error {
+	return putDocuments(als, als.tags, tagKey, tags...)
+}
+
+// DeleteTagInfo removes the metadata of the named tags
+func (als *AppendLogStorage) DeleteTagInfo(names ...string) error {
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = tagKey(models.TagInfo{Name: name})
+	}
+	return deleteDocuments(als, als.tags, keys...)
+}
+
+// tagKey keys metadata case-insensitively, matching how tags are compared
+func tagKey(tag models.TagInfo) string {
+	return strings.ToLower(tag.Name)
+}
+
+// ============== AUTO-TAG RULE METHODS ==============
+
+// ReadRules reads all auto-tagging rules
+func (als *AppendLogStorage) ReadRules() ([]models.AutoTagRule, error) {
+	return readDocuments[models.AutoTagRule](als, als.rules)
+}
+
+// PutRules adds or replaces rules in a single write
+func (als *AppendLogStorage) PutRules(rules ...models.AutoTagRule) error {
+	return putDocuments(als, als.rules, ruleKey, rules...)
+}
+
+// DeleteRule removes a rule
+func (als *AppendLogStorage) DeleteRule(id int64) error {
+	return deleteDocuments(als, als.rules, strconv.FormatInt(id, 10))
+}
+
+func ruleKey(rule models.AutoTagRule) string {
+	return strconv.FormatInt(rule.ID, 10)
+}
+
+// ============== JOB METHODS ==============
+
+// ReadJobs reads all background jobs
+func (als *AppendLogStorage) ReadJobs() ([]models.Job, error) {
+	return readDocuments[models.Job](als, als.jobs)
+}
+
+// PutJobs adds or replaces jobs in a single write
+func (als *AppendLogStorage) PutJobs(jobs ...models.Job) error {
+	return putDocuments(als, als.jobs, jobKey, jobs...)
+}
+
+// DeleteJobs removes jobs and their payloads
+func (als *AppendLogStorage) DeleteJobs(ids ...int64) error {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = jobKey(models.Job{ID: id})
+		os.Remove(als.jobPayloadFile(id))
+	}
+	return deleteDocuments(als, als.jobs, keys...)
+}
+
+func jobKey(job models.Job) string {
+	return strconv.FormatInt(job.ID, 10)
+}
+
+// WriteJobPayload stores the input a job works from
+func (als *AppendLogStorage) WriteJobPayload(id int64, payload []byte) error {
+	if err := os.MkdirAll(als.jobPayloadDir, 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(als.jobPayloadFile(id), payload)
+}
+
+// ReadJobPayload reads the input stored for a job
+func (als *AppendLogStorage) ReadJobPayload(id int64) ([]byte, error) {
+	return os.ReadFile(als.jobPayloadFile(id))
+}
+
+// DeleteJobPayload removes a job's input once it is no longer needed
+func (als *AppendLogStorage) DeleteJobPayload(id int64) error {
+	err := os.Remove(als.jobPayloadFile(id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (als *AppendLogStorage) jobPayloadFile(id int64) string {
+	return filepath.Join(als.jobPayloadDir, strconv.FormatInt(id, 10)+".json")
+}
+
+// ============== PAGE METADATA METHODS ==============
+
+// ReadMetadata reads the fetched metadata of all bookmarks
+func (als *AppendLogStorage) ReadMetadata() ([]models.PageMetadata, error) {
+	return readDocuments[models.PageMetadata](als, als.metadata)
+}
+
+// PutMetadata adds or replaces bookmark metadata in a single write
+func (als *AppendLogStorage) PutMetadata(metadata ...models.PageMetadata) error {
+	return putDocuments(als, als.metadata, metadataKey, metadata...)
+}
+
+// DeleteMetadata removes the metadata of the given bookmarks
+func (als *AppendLogStorage) DeleteMetadata(bookmarkIDs ...int64) error {
+	keys := make([]string, len(bookmarkIDs))
+	for i, id := range bookmarkIDs {
+		keys[i] = metadataKey(models.PageMetadata{BookmarkID: id})
+	}
+	return deleteDocuments(als, als.metadata, keys...)
+}
+
+func metadataKey(metadata models.PageMetadata) string {
+	return strconv.FormatInt(metadata.BookmarkID, 10)
+}
+
+// faviconHost keeps host names from escaping the favicon directory
+var faviconHost = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?(:[0-9]+)?$`)
+
+// faviconExtensions maps the icon types kept to file extensions
+var faviconExtensions = map[string]string{
+	"image/x-icon":             ".ico",
+	"image/vnd.microsoft.icon": ".ico",
+	"image/png":                ".png",
+	"image/svg+xml":            ".svg",
+	"image/gif":                ".gif",
+	"image/jpeg":               ".jpg",
+	"image/webp":               ".webp",
+}
+
+// WriteFavicon stores a host's icon, replacing any earlier one
+func (als *AppendLogStorage) WriteFavicon(host, contentType string, data []byte) error {
+	if !faviconHost.MatchString(host) {
+		return fmt.Errorf("invalid favicon host %q", host)
+	}
+	ext, ok := faviconExtensions[contentType]
+	if !ok {
+		return fmt.Errorf("unsupported favicon type %q", contentType)
+	}
+	if err := os.MkdirAll(als.faviconDir, 0755); err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(filepath.Join(als.faviconDir, host+ext), data); err != nil {
+		return err
+	}
+	// Only this host's own names: a glob on host+".*" would also match
+	// other hosts, such as example.com.au.png for example.com
+	for _, other := range faviconExtensions {
+		if other == ext {
+			continue
+		}
+		if err := os.Remove(filepath.Join(als.faviconDir, host+other)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadFavicon returns a host's stored icon and its content type
+func (als *AppendLogStorage) ReadFavicon(host string) ([]byte, string, error) {
+	if !faviconHost.MatchString(host) {
+		return nil, "", os.ErrNotExist
+	}
+	for contentType, ext := range faviconExtensions {
+		data, err := os.ReadFile(filepath.Join(als.faviconDir, host+ext))
+		if err == nil {
+			if ext == ".ico" {
+				contentType = "image/x-icon"
+			}
+			return data, contentType, nil
+		}
+	}
+	return nil, "", os.ErrNotExist
+}
+
+// ============== PAGE ARCHIVE METHODS ==============
+
+// ReadArchives reads the archive records of all bookmarks
+func (als *AppendLogStorage) ReadArchives() ([]models.PageArchive, error) {
+	return readDocuments[models.PageArchive](als, als.archives)
+}
+
+// PutArchives adds or replaces archive records in a single write
+func (als *AppendLogStorage) PutArchives(archives ...models.PageArchive) error {
+	return putDocuments(als, als.archives, archiveKey, archives...)
+}
+
+// DeleteArchives removes the archive records and files of the given bookmarks
+func (als *AppendLogStorage) DeleteArchives(bookmarkIDs ...int64) error {
+	keys := make([]string, len(bookmarkIDs))
+	for i, id := range bookmarkIDs {
+		keys[i] = archiveKey(models.PageArchive{BookmarkID: id})
+		for _, file := range []string{als.archiveFile(id, ".html"), als.archiveFile(id, ".txt")} {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return deleteDocuments(als, als.archives, keys...)
+}
+
+func archiveKey(archive models.PageArchive) string {
+	return strconv.FormatInt(archive.BookmarkID, 10)
+}
+
+// WriteArchive stores a bookmark's page snapshot and readable text,
+// replacing any earlier archive
+func (als *AppendLogStorage) WriteArchive(bookmarkID int64, snapshot []byte, text string) error {
+	if err := os.MkdirAll(als.archiveDir, 0755); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(als.archiveFile(bookmarkID, ".html"), snapshot); err != nil {
+		return err
+	}
+	return writeFileAtomic(als.archiveFile(bookmarkID, ".txt"), []byte(text))
+}
+
+// ReadArchiveSnapshot returns a bookmark's archived HTML snapshot
+func (als *AppendLogStorage) ReadArchiveSnapshot(bookmarkID int64) ([]byte, error) {
+	return os.ReadFile(als.archiveFile(bookmarkID, ".html"))
+}
+
+// ReadArchiveText returns a bookmark's archived readable text
+func (als *AppendLogStorage) ReadArchiveText(bookmarkID int64) (string, error) {
+	data, err := os.ReadFile(als.archiveFile(bookmarkID, ".txt"))
+	return string(data), err
+}
+
+func (als *AppendLogStorage) archiveFile(bookmarkID int64, ext string) string {
+	return filepath.Join(als.archiveDir, strconv.FormatInt(bookmarkID, 10)+ext)
+}
+
+// ============== LINK HEALTH METHODS ==============
+
+// ReadLinkHealth reads the link check results of all bookmarks
+func (als *AppendLogStorage) ReadLinkHealth() ([]models.LinkHealth, error) {
+	return readDocuments[models.LinkHealth](als, als.linkHealth)
+}
+
+// PutLinkHealth adds or replaces link check results in a single write
+func (als *AppendLogStorage) PutLinkHealth(health ...models.LinkHealth) error {
+	return putDocuments(als, als.linkHealth, linkHealthKey, health...)
+}
+
+// DeleteLinkHealth removes the link check results of the given bookmarks
+func (als *AppendLogStorage) DeleteLinkHealth(bookmarkIDs ...int64) error {
+	keys := make([]string, len(bookmarkIDs))
+	for i, id := range bookmarkIDs {
+		keys[i] = linkHealthKey(models.LinkHealth{BookmarkID: id})
+	}
+	return deleteDocuments(als, als.linkHealth, keys...)
+}
+
+func linkHealthKey(health models.LinkHealth) string {
+	return strconv.FormatInt(health.BookmarkID, 10)
+}
+
+// ============== ANNOTATION METHODS ==============
+
+// ReadAnnotations reads all notes and highlights
+func (als *AppendLogStorage) ReadAnnotations() ([]models.Annotation, error) {
+	return readDocuments[models.Annotation](als, als.annotations)
+}
+
+// PutAnnotations adds or replaces annotations in a single write
+func (als *AppendLogStorage) PutAnnotations(annotations ...models.Annotation) error {
+	return putDocuments(als, als.annotations, annotationKey, annotations...)
+}
+
+// DeleteAnnotations removes annotations
+func (als *AppendLogStorage) DeleteAnnotations(ids ...int64) error {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = strconv.FormatInt(id, 10)
+	}
+	return deleteDocuments(als, als.annotations, keys...)
+}
+
+func annotationKey(annotation models.Annotation) string {
+	return strconv.FormatInt(annotation.ID, 10)
+}
+
+// ============== REVISION METHODS ==============
+
+// ReadRevisions reads the revision logs of all bookmarks and sessions
+func (als *AppendLogStorage) ReadRevisions() ([]models.RevisionLog, error) {
+	return readDocuments[models.RevisionLog](als, als.revisions)
+}
+
+// GetRevisions reads the revision logs of the given items; items without
+// revisions are left out
+func (als *AppendLogStorage) GetRevisions(items ...models.ItemRef) ([]models.RevisionLog, error) {
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = itemKey(item.Type, item.ID)
+	}
+	return getDocuments[models.RevisionLog](als, als.revisions, keys...)
+}
+
+// PutRevisions adds or replaces revision logs in a single write
+func (als *AppendLogStorage) PutRevisions(logs ...models.RevisionLog) error {
+	return putDocuments(als, als.revisions, revisionKey, logs...)
+}
+
+// DeleteRevisions drops the revision logs of the given items
+func (als *AppendLogStorage) DeleteRevisions(items ...models.ItemRef) error {
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = itemKey(item.Type, item.ID)
+	}
+	return deleteDocuments(als, als.revisions, keys...)
+}
+
+func revisionKey(log models.RevisionLog) string {
+	return itemKey(log.ItemType, log.ItemID)
+}
+
+// ============== TRASH METHODS ==============
+
+// ReadTrash reads the deleted bookmarks and sessions still in the trash
+func (als *AppendLogStorage) ReadTrash() ([]models.TrashItem, error) {
+	return readDocuments[models.TrashItem](als, als.trash)
+}
+
+// PutTrash adds items to the trash in a single write
+func (als *AppendLogStorage) PutTrash(items ...models.TrashItem) error {
+	return putDocuments(als, als.trash, trashKey, items...)
+}
+
+// DeleteTrash takes items out of the trash
+func (als *AppendLogStorage) DeleteTrash(items ...models.ItemRef) error {
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = itemKey(item.Type, item.ID)
+	}
+	return deleteDocuments(als, als.trash, keys...)
+}
+
+func trashKey(item models.TrashItem) string {
+	return itemKey(item.Type, item.ID)
+}
+
+// ============== SESSION VERSION METHODS ==============
+
+// ReadSessionVersions reads the saved versions of every session
+func (als *AppendLogStorage) ReadSessionVersions() ([]models.SessionVersion, error) {
+	return readDocuments[models.SessionVersion](als, als.sessionVersions)
+}
+
+// PutSessionVersions adds or replaces session versions in a single write
+func (als *AppendLogStorage) PutSessionVersions(versions ...models.SessionVersion) error {
+	return putDocuments(als, als.sessionVersions, sessionVersionKey, versions...)
+}
+
+// DeleteSessionVersions removes session versions
+func (als *AppendLogStorage) DeleteSessionVersions(versions ...models.SessionVersion) error {
+	keys := make([]string, len(versions))
+	for i, version := range versions {
+		keys[i] = sessionVersionKey(version)
+	}
+	return deleteDocuments(als, als.sessionVersions, keys...)
+}
+
+func sessionVersionKey(version models.SessionVersion) string {
+	return strconv.FormatInt(version.SessionID, 10) + ":" + strconv.Itoa(version.Version)
+}
+
+// itemKey keys documents about a bookmark or session
+func itemKey(itemType string, id int64) string {
+	return itemType + ":" + strconv.FormatInt(id, 10)
+}
+
+// ============== COMPACTION ==============
+
+// Compact folds every delta log into its main file now, so that nothing
+// deleted or overwritten is left behind in the deltas. Privacy purges use it.
+func (als *AppendLogStorage) Compact() error {
+	if err := als.compactBookmarks(); err != nil {
+		return err
+	}
+	if err := als.compactSessions(); err != nil {
+		return err
+	}
+	for _, log := range als.documentLogs() {
+		if err := als.compactDocuments(log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ============== SETTINGS METHODS ==============
+// Settings are a single document, so they skip the delta log entirely
+
+// ReadSettings reads the stored settings, returning defaults if none were saved yet
+func (als *AppendLogStorage) ReadSettings() (models.Settings, error) {
+	als.mutex.RLock()
+	defer als.mutex.RUnlock()
+	
+	return als.readSettingsLocked()
+}
+
+// readSettingsLocked reads the settings file; caller must hold the mutex
+func (als *AppendLogStorage) readSettingsLocked() (models.Settings, error) {
+	settings := models.DefaultSettings()
+	
+	data, err := os.ReadFile(als.settingsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return settings, fmt.Errorf("failed to read settings: %w", err)
+	}
+	
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("failed to parse settings: %w", err)
+	}
+	
+	return settings, nil
+}
+
+// WriteSettings replaces the stored settings
+func (als *AppendLogStorage) WriteSettings(settings models.Settings) error {
+	als.mutex.Lock()
+	defer als.mutex.Unlock()
+	
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	
+	if err := writeFileAtomic(als.settingsFile, data); err != nil {
+		return err
+	}
+	// URL rules decide which bookmarks share a canonical URL
+	als.bookmarkIndex = nil
+	return nil
+}
+
 // ============== PRIVATE HELPER METHODS ==============
 
 func (als *AppendLogStorage) loadAllDeltaFiles() {
@@ -489,6 +1196,27 @@ func (als *AppendLogStorage) loadAllDeltaFiles() {
 	als.loadSessionDelta()
 	als.loadHistoryDelta()
 	als.loadLinkClickDelta()
+	
+	for _, log := range als.documentLogs() {
+		als.loadDocumentDelta(log)
+	}
+}
+
+// documentLogs lists every document collection, for startup and flushing
+func (als *AppendLogStorage) documentLogs() []*documentLog {
+	return []*documentLog{
+		als.collections,
+		als.tags,
+		als.jobs,
+		als.rules,
+		als.metadata,
+		als.archives,
+		als.linkHealth,
+		als.annotations,
+		als.revisions,
+		als.trash,
+		als.sessionVersions,
+	}
 }
 
 func (als *AppendLogStorage) loadBookmarkDelta() error {
@@ -613,12 +1341,29 @@ func (als *AppendLogStorage) appendSessionToDelta(session models.Session) error
 	return als.appendToDeltaFile(als.sessionDeltaFile, session)
 }
 
-func (als *AppendLogStorage) appendHistoryToDelta(entry models.HistoryEntry) error {
-	return als.appendToDeltaFile(als.historyDeltaFile, entry)
-}
-
-func (als *AppendLogStorage) appendLinkClickToDelta(click models.LinkClick) error {
-	return als.appendToDeltaFile(als.linkClickDeltaFile, click)
+// appendManyToDeltaFile appends every entry with a single write and sync
+func appendManyToDeltaFile[T any](filename string, entries []T) error {
+	var buf []byte
+	for _, entry := range entries {
+		jsonData, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, jsonData...)
+		buf = append(buf, '\n')
+	}
+	
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	
+	if _, err := file.Write(buf); err != nil {
+		return err
+	}
+	
+	return file.Sync()
 }
 
 func (als *AppendLogStorage) appendToDeltaFile(filename string, data interface{}) error {
@@ -649,7 +1394,10 @@ func (als *AppendLogStorage) compactBookmarks() error {
 	defer als.mutex.Unlock()
 	
 	// Read all current bookmarks (with deletes/updates applied)
-	allBookmarks, _ := als.ReadBookmarks()
+	allBookmarks, err := als.readBookmarksLocked()
+	if err != nil {
+		return fmt.Errorf("bookmark compaction failed: %w", err)
+	}
 	
 	// Write new main Parquet file
 	if err := als.parquetStorage.WriteBookmarks(allBookmarks); err != nil {
@@ -661,6 +1409,12 @@ func (als *AppendLogStorage) compactBookmarks() error {
 	als.bookmarkDeltaBuffer = make([]models.Bookmark, 0)
 	als.bookmarkDeltaCount = 0
 	
+	// Swap in a view built from what was just written
+	delete(als.viewSkipped, viewBookmarks)
+	if _, err := als.materializeBookmarksLocked(allBookmarks); err != nil {
+		return fmt.Errorf("bookmark compaction failed: %w", err)
+	}
+	
 	return nil
 }
 
@@ -669,7 +1423,10 @@ func (als *AppendLogStorage) compactSessions() error {
 	defer als.mutex.Unlock()
 	
 	// Read all current sessions (with deletes/updates applied)
-	allSessions, _ := als.ReadSessions()
+	allSessions, err := als.readSessionsLocked()
+	if err != nil {
+		return fmt.Errorf("session compaction failed: %w", err)
+	}
 	
 	// Write new main Parquet file
 	if err := als.parquetStorage.WriteSessions(allSessions); err != nil {
@@ -681,6 +1438,10 @@ func (als *AppendLogStorage) compactSessions() error {
 	als.sessionDeltaBuffer = make([]models.Session, 0)
 	als.sessionDeltaCount = 0
 	
+	// Swap in a view built from what was just written
+	delete(als.viewSkipped, viewSessions)
+	als.materializeSessionsLocked(allSessions)
+	
 	return nil
 }
 
@@ -688,15 +1449,11 @@ func (als *AppendLogStorage) compactHistory() error {
 	als.mutex.Lock()
 	defer als.mutex.Unlock()
 	
-	// Read all history directly (already have lock, no deduplication needed)
-	mainHistory, err := als.parquetStorage.ReadHistory()
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to read main history: %w", err)
+	allHistory, err := als.readHistoryLocked()
+	if err != nil {
+		return err
 	}
 	
-	// Append delta buffer (no deduplication needed for history)
-	allHistory := append(mainHistory, als.historyDeltaBuffer...)
-	
 	// Write new main Parquet file
 	if err := als.parquetStorage.WriteHistory(allHistory); err != nil {
 		return fmt.Errorf("history compaction failed: %w", err)
@@ -707,6 +1464,10 @@ func (als *AppendLogStorage) compactHistory() error {
 	als.historyDeltaBuffer = make([]models.HistoryEntry, 0)
 	als.historyDeltaCount = 0
 	
+	// Swap in a view built from what was just written
+	delete(als.viewSkipped, viewHistory)
+	als.materializeHistoryLocked(allHistory)
+	
 	return nil
 }
 
@@ -714,15 +1475,11 @@ func (als *AppendLogStorage) compactLinkClicks() error {
 	als.mutex.Lock()
 	defer als.mutex.Unlock()
 	
-	// Read all link clicks directly (already have lock, no deduplication needed)
-	mainClicks, err := als.parquetStorage.ReadLinkClicks()
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to read main link clicks: %w", err)
+	allClicks, err := als.readLinkClicksLocked()
+	if err != nil {
+		return err
 	}
 	
-	// Append delta buffer (no deduplication needed for link clicks)
-	allClicks := append(mainClicks, als.linkClickDeltaBuffer...)
-	
 	// Write new main Parquet file
 	if err := als.parquetStorage.WriteLinkClicks(allClicks); err != nil {
 		return fmt.Errorf("link click compaction failed: %w", err)
@@ -733,6 +1490,10 @@ func (als *AppendLogStorage) compactLinkClicks() error {
 	als.linkClickDeltaBuffer = make([]models.LinkClick, 0)
 	als.linkClickDeltaCount = 0
 	
+	// Swap in a view built from what was just written
+	delete(als.viewSkipped, viewLinkClicks)
+	als.materializeLinkClicksLocked(allClicks)
+	
 	return nil
 }
 
@@ -768,6 +1529,9 @@ func (als *AppendLogStorage) flushAllDelta() {
 		als.historyDeltaFile,
 		als.linkClickDeltaFile,
 	}
+	for _, log := range als.documentLogs() {
+		files = append(files, log.deltaFile)
+	}
 	
 	for _, filename := range files {
 		if _, err := os.Stat(filename); err == nil {
@@ -779,6 +1543,17 @@ func (als *AppendLogStorage) flushAllDelta() {
 	}
 }
 
+// writeFileAtomic writes to a temp file and renames it over the target,
+// so readers never observe a half-written file
+func writeFileAtomic(filename string, data []byte) error {
+	tmpFile := filename + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	
+	return os.Rename(tmpFile, filename)
+}
+
 // Helper function to split byte slice by newlines
 func splitLines(data []byte) [][]byte {
 	var lines [][]byte
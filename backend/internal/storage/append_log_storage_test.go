@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -184,6 +185,126 @@ func TestAppendLogStorage_Compaction(t *testing.T) {
 	}
 }
 
+func TestParquetStorage_ReadingState(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := NewParquetStorage(tempDir)
+
+	readAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	bookmarks := []models.Bookmark{
+		{ID: 1, URL: "https://example.com/queued", Tags: []string{}, Status: models.ReadingReading, Progress: 40},
+		{ID: 2, URL: "https://example.com/done", Tags: []string{}, Status: models.ReadingRead, Progress: 100, ReadAt: readAt},
+		{ID: 3, URL: "https://example.com/plain", Tags: []string{}},
+	}
+	if err := storage.WriteBookmarks(bookmarks); err != nil {
+		t.Fatalf("Failed to write bookmarks: %v", err)
+	}
+
+	read, err := storage.ReadBookmarks()
+	if err != nil {
+		t.Fatalf("Failed to read bookmarks: %v", err)
+	}
+	if len(read) != 3 {
+		t.Fatalf("Expected 3 bookmarks, got %d", len(read))
+	}
+	if read[0].Status != models.ReadingReading || read[0].Progress != 40 || !read[0].ReadAt.IsZero() {
+		t.Errorf("Unexpected reading state %q %d%% %v", read[0].Status, read[0].Progress, read[0].ReadAt)
+	}
+	if read[1].Status != models.ReadingRead || !read[1].ReadAt.Equal(readAt) {
+		t.Errorf("Expected read at %v, got %q at %v", readAt, read[1].Status, read[1].ReadAt)
+	}
+	if read[2].Status != "" || read[2].Progress != 0 || !read[2].ReadAt.IsZero() {
+		t.Errorf("Expected no reading state, got %q %d%% %v", read[2].Status, read[2].Progress, read[2].ReadAt)
+	}
+}
+
+func TestParquetStorage_SessionLayout(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := NewParquetStorage(tempDir)
+
+	sessions := []models.Session{
+		{
+			ID:   1,
+			Name: "work",
+			Tabs: []models.Tab{
+				{URL: "https://example.com/a", WindowID: 1, GroupID: 7},
+				{URL: "https://example.com/b", WindowID: 2, Active: true},
+			},
+			Windows: []models.Window{{ID: 1}, {ID: 2, Focused: true, State: models.WindowMaximized}},
+			Groups:  []models.TabGroup{{ID: 7, WindowID: 1, Title: "Docs", Color: models.GroupBlue, Collapsed: true}},
+		},
+		{ID: 2, Name: "flat", Tabs: []models.Tab{{URL: "https://example.com/c"}}},
+	}
+	if err := storage.WriteSessions(sessions); err != nil {
+		t.Fatalf("Failed to write sessions: %v", err)
+	}
+
+	read, err := storage.ReadSessions()
+	if err != nil {
+		t.Fatalf("Failed to read sessions: %v", err)
+	}
+	if len(read) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(read))
+	}
+	if !reflect.DeepEqual(read[0].Tabs, sessions[0].Tabs) || !reflect.DeepEqual(read[0].Windows, sessions[0].Windows) ||
+		!reflect.DeepEqual(read[0].Groups, sessions[0].Groups) {
+		t.Errorf("Layout didn't round-trip: %+v %+v %+v", read[0].Tabs, read[0].Windows, read[0].Groups)
+	}
+	if read[1].Windows != nil || read[1].Groups != nil || len(read[1].Tabs) != 1 {
+		t.Errorf("Expected a flat session, got %+v", read[1])
+	}
+}
+
+func TestAppendLogStorage_LookupIndex(t *testing.T) {
+	storage := NewAppendLogStorage(t.TempDir())
+	defer storage.Close()
+
+	bookmark := models.Bookmark{ID: 1, URL: "https://example.com/post", Title: "Post"}
+	if err := storage.AddBookmark(bookmark); err != nil {
+		t.Fatalf("Failed to add bookmark: %v", err)
+	}
+	if _, ok, err := storage.GetBookmark(1); err != nil || !ok {
+		t.Fatalf("Expected bookmark 1, got ok=%v err=%v", ok, err)
+	}
+
+	// Tracking parameters don't change the canonical URL
+	found, err := storage.FindBookmarksByURL("http://example.com/post?utm_source=feed")
+	if err != nil {
+		t.Fatalf("Failed to look up URL: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != 1 {
+		t.Fatalf("Expected bookmark 1 by URL, got %v", found)
+	}
+
+	bookmark.URL = "https://example.com/moved"
+	if err := storage.UpdateBookmark(bookmark); err != nil {
+		t.Fatalf("Failed to update bookmark: %v", err)
+	}
+	if found, _ := storage.FindBookmarksByURL("https://example.com/post"); len(found) != 0 {
+		t.Errorf("Expected the old URL to be gone, got %v", found)
+	}
+	if found, _ := storage.FindBookmarksByURL("https://example.com/moved"); len(found) != 1 {
+		t.Errorf("Expected the new URL to be indexed, got %v", found)
+	}
+
+	if err := storage.DeleteBookmarks(1); err != nil {
+		t.Fatalf("Failed to delete bookmark: %v", err)
+	}
+	if _, ok, _ := storage.GetBookmark(1); ok {
+		t.Error("Expected bookmark 1 to be gone after delete")
+	}
+
+	session := models.Session{ID: 7, Name: "Work"}
+	if err := storage.AddSession(session); err != nil {
+		t.Fatalf("Failed to add session: %v", err)
+	}
+	if err := storage.DeleteSession(7); err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	if _, ok, _ := storage.GetSession(7); ok {
+		t.Error("Expected session 7 to be gone after delete")
+	}
+}
+
 func BenchmarkAppendLogStorage_SingleWrites(b *testing.B) {
 	tempDir, _ := os.MkdirTemp("", "hyprlink_bench")
 	defer os.RemoveAll(tempDir)
@@ -230,4 +351,167 @@ func BenchmarkParquetStorage_SingleWrites(b *testing.B) {
 		bookmarks = append(bookmarks, bookmark)
 		storage.WriteBookmarks(bookmarks)
 	}
-}
\ No newline at end of file
+}
+
+func TestAppendLogStorage_Views(t *testing.T) {
+	for _, budget := range []int64{DefaultViewBudget, 1, 0} {
+		t.Run(fmt.Sprintf("budget %d", budget), func(t *testing.T) {
+			storage := NewAppendLogStorage(t.TempDir())
+			defer storage.Close()
+			storage.SetViewBudget(budget)
+
+			if err := storage.WriteBookmarks([]models.Bookmark{{ID: 1, URL: "https://a.example", Title: "A", Tags: []string{"one"}}}); err != nil {
+				t.Fatalf("Failed to write bookmarks: %v", err)
+			}
+			if err := storage.AddBookmark(models.Bookmark{ID: 2, URL: "https://b.example", Title: "B"}); err != nil {
+				t.Fatalf("Failed to add bookmark: %v", err)
+			}
+
+			// Changing a read copy must not change what is stored
+			bookmark, _, err := storage.GetBookmark(1)
+			if err != nil {
+				t.Fatalf("Failed to get bookmark: %v", err)
+			}
+			bookmark.Tags[0] = "changed"
+			if bookmark, _, _ := storage.GetBookmark(1); bookmark.Tags[0] != "one" {
+				t.Errorf("Expected the stored tag to stay 'one', got %q", bookmark.Tags[0])
+			}
+
+			if err := storage.DeleteBookmark(1); err != nil {
+				t.Fatalf("Failed to delete bookmark: %v", err)
+			}
+			if err := storage.compactBookmarks(); err != nil {
+				t.Fatalf("Failed to compact bookmarks: %v", err)
+			}
+			if err := storage.AddBookmark(models.Bookmark{ID: 3, URL: "https://c.example", Title: "C"}); err != nil {
+				t.Fatalf("Failed to add bookmark: %v", err)
+			}
+			bookmarks, err := storage.ReadBookmarks()
+			if err != nil {
+				t.Fatalf("Failed to read bookmarks: %v", err)
+			}
+			if len(bookmarks) != 2 {
+				t.Errorf("Expected bookmarks 2 and 3 after compaction, got %v", bookmarks)
+			}
+
+			// A later entry for a URL replaces the earlier one
+			visit := time.Now().Truncate(time.Second)
+			storage.WriteHistory([]models.HistoryEntry{{URL: "https://a.example", VisitCount: 1, LastVisitTime: visit}})
+			storage.ReadHistory()
+			storage.WriteHistory([]models.HistoryEntry{{URL: "https://a.example", VisitCount: 2, LastVisitTime: visit}})
+			history, err := storage.ReadHistory()
+			if err != nil {
+				t.Fatalf("Failed to read history: %v", err)
+			}
+			if len(history) != 1 || history[0].VisitCount != 2 {
+				t.Errorf("Expected one entry with 2 visits, got %v", history)
+			}
+
+			storage.WriteLinkClicks([]models.LinkClick{
+				{DestinationURL: "https://a.example", LinkText: "old", Timestamp: visit.Add(-time.Hour)},
+				{DestinationURL: "https://a.example", LinkText: "new", Timestamp: visit},
+			})
+			latest, err := storage.LatestLinkClicks([]string{"https://a.example", "https://b.example"})
+			if err != nil {
+				t.Fatalf("Failed to read link clicks: %v", err)
+			}
+			if len(latest) != 1 || latest["https://a.example"].LinkText != "new" {
+				t.Errorf("Expected the newer click to a.example only, got %v", latest)
+			}
+
+			// A document deleted and put back is listed once
+			storage.PutCollections(models.Collection{ID: 1, Name: "Reading"})
+			storage.ReadCollections()
+			storage.DeleteCollection(1)
+			storage.PutCollections(models.Collection{ID: 1, Name: "Reading"})
+			collections, err := storage.ReadCollections()
+			if err != nil {
+				t.Fatalf("Failed to read collections: %v", err)
+			}
+			if len(collections) != 1 {
+				t.Errorf("Expected 1 collection, got %v", collections)
+			}
+
+			// Revision logs can be read by item, skipping items without one
+			storage.PutRevisions(
+				models.RevisionLog{ItemType: models.ItemBookmark, ItemID: 1},
+				models.RevisionLog{ItemType: models.ItemSession, ItemID: 1},
+			)
+			logs, err := storage.GetRevisions(
+				models.ItemRef{Type: models.ItemSession, ID: 1},
+				models.ItemRef{Type: models.ItemBookmark, ID: 2},
+			)
+			if err != nil {
+				t.Fatalf("Failed to get revisions: %v", err)
+			}
+			if len(logs) != 1 || logs[0].ItemType != models.ItemSession {
+				t.Errorf("Expected the session's revision log only, got %v", logs)
+			}
+
+			storage.mutex.RLock()
+			built := storage.bookmarkIndex != nil
+			storage.mutex.RUnlock()
+			if built != (budget == DefaultViewBudget) {
+				t.Errorf("Bookmark view built = %v with a budget of %d bytes", built, budget)
+			}
+		})
+	}
+}
+
+func TestHistoryIndex_Size(t *testing.T) {
+	first := models.HistoryEntry{URL: "https://example.com", Title: "First visit"}
+	second := models.HistoryEntry{URL: "https://example.com", Title: "Second, longer visit title"}
+	third := models.HistoryEntry{URL: "https://example.com", Title: "Third"}
+
+	index := newHistoryIndex([]models.HistoryEntry{first})
+	index.apply(second)
+	// The superseded entry is still held, so it still counts
+	if want := sizeOf(first) + sizeOf(second); index.size != want {
+		t.Errorf("Expected both entries counted (%d bytes), got %d", want, index.size)
+	}
+	if history := index.all(); len(history) != 1 || history[0].Title != second.Title {
+		t.Errorf("Expected only the latest entry, got %+v", history)
+	}
+
+	// The third entry makes superseded entries the majority, which drops them
+	index.apply(third)
+	if len(index.entries) != 1 || index.size != sizeOf(third) {
+		t.Errorf("Expected the index compacted to the latest entry (%d bytes), got %d entries and %d bytes",
+			sizeOf(third), len(index.entries), index.size)
+	}
+}
+
+func TestAppendLogStorage_Favicons(t *testing.T) {
+	storage := NewAppendLogStorage(t.TempDir())
+	defer storage.Close()
+
+	if err := storage.WriteFavicon("example.com.au", "image/png", []byte("au")); err != nil {
+		t.Fatalf("Failed to write favicon: %v", err)
+	}
+	if err := storage.WriteFavicon("example.com", "image/png", []byte("png")); err != nil {
+		t.Fatalf("Failed to write favicon: %v", err)
+	}
+	// Replacing example.com's icon with another type drops its old file only
+	if err := storage.WriteFavicon("example.com", "image/svg+xml", []byte("svg")); err != nil {
+		t.Fatalf("Failed to write favicon: %v", err)
+	}
+
+	data, contentType, err := storage.ReadFavicon("example.com")
+	if err != nil || string(data) != "svg" || contentType != "image/svg+xml" {
+		t.Errorf("Expected the svg icon, got %q %q %v", data, contentType, err)
+	}
+	if _, err := os.Stat(filepath.Join(storage.faviconDir, "example.com.png")); !os.IsNotExist(err) {
+		t.Errorf("Expected the replaced png to be removed, got %v", err)
+	}
+	data, _, err = storage.ReadFavicon("example.com.au")
+	if err != nil || string(data) != "au" {
+		t.Errorf("Expected example.com.au's icon to survive, got %q %v", data, err)
+	}
+
+	if err := storage.WriteFavicon("../etc", "image/png", nil); err == nil {
+		t.Error("Expected an invalid host to be rejected")
+	}
+	if err := storage.WriteFavicon("example.com", "text/html", nil); err == nil {
+		t.Error("Expected an unsupported type to be rejected")
+	}
+}
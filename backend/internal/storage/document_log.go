@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// documentLog holds a small keyed collection (collections, tag metadata, ...)
+// using the same delta log + compaction scheme as bookmarks. Each entry is
+// kept as JSON, so new entity types don't need their own Parquet schema.
+type documentLog struct {
+	name        string
+	mainFile    string
+	deltaFile   string
+	deltaBuffer []documentEntry
+	deltaCount  int
+	index       *documentIndex // materialized view; nil until first read
+}
+
+// documentEntry is one line of a document delta log; Deleted marks a tombstone
+type documentEntry struct {
+	Key     string          `json:"key"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Deleted bool            `json:"deleted,omitempty"`
+}
+
+func newDocumentLog(dataDir, name string) *documentLog {
+	return &documentLog{
+		name:        name,
+		mainFile:    filepath.Join(dataDir, name+".parquet"),
+		deltaFile:   filepath.Join(dataDir, name+".delta.json"),
+		deltaBuffer: make([]documentEntry, 0),
+	}
+}
+
+// readDocuments decodes every live document in log, in first-written order
+func readDocuments[T any](als *AppendLogStorage, log *documentLog) ([]T, error) {
+	var entries []documentEntry
+	built := func() bool { return log.index != nil }
+	err := als.readView(log.name, built, func() error {
+		if !built() && !als.viewEnabledLocked(log.name) {
+			var err error
+			entries, err = als.readDocumentsLocked(log)
+			return err
+		}
+		index, err := als.documentIndexLocked(log)
+		if err != nil {
+			return err
+		}
+		entries = index.all()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocuments[T](log, entries), nil
+}
+
+// getDocuments decodes the live documents with the given keys, in the
+// order of keys; keys without a document are skipped
+func getDocuments[T any](als *AppendLogStorage, log *documentLog, keys ...string) ([]T, error) {
+	var entries []documentEntry
+	built := func() bool { return log.index != nil }
+	err := als.readView(log.name, built, func() error {
+		index, err := als.documentIndexLocked(log)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if entry, ok := index.entries[key]; ok {
+				entries = append(entries, entry)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocuments[T](log, entries), nil
+}
+
+// decodeDocuments decodes entries, skipping any that are corrupted
+func decodeDocuments[T any](log *documentLog, entries []documentEntry) []T {
+	result := make([]T, 0, len(entries))
+	for _, entry := range entries {
+		var doc T
+		if err := json.Unmarshal(entry.Data, &doc); err != nil {
+			fmt.Printf("Warning: corrupted %s document %s: %v\n", log.name, entry.Key, err)
+			continue
+		}
+		result = append(result, doc)
+	}
+	return result
+}
+
+// putDocuments adds or replaces docs, keyed by keyOf, in a single delta write
+func putDocuments[T any](als *AppendLogStorage, log *documentLog, keyOf func(T) string, docs ...T) error {
+	entries := make([]documentEntry, 0, len(docs))
+	for _, doc := range docs {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, documentEntry{Key: keyOf(doc), Data: data})
+	}
+
+	als.mutex.Lock()
+	defer als.mutex.Unlock()
+
+	return als.appendDocumentsLocked(log, entries)
+}
+
+// deleteDocuments writes tombstones for keys in a single delta write
+func deleteDocuments(als *AppendLogStorage, log *documentLog, keys ...string) error {
+	entries := make([]documentEntry, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, documentEntry{Key: key, Deleted: true})
+	}
+
+	als.mutex.Lock()
+	defer als.mutex.Unlock()
+
+	return als.appendDocumentsLocked(log, entries)
+}
+
+// readDocumentsLocked merges main file and delta from disk; caller must
+// hold the mutex
+func (als *AppendLogStorage) readDocumentsLocked(log *documentLog) ([]documentEntry, error) {
+	mainEntries, err := als.parquetStorage.ReadDocuments(log.mainFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read main %s: %w", log.name, err)
+	}
+
+	index := newDocumentIndex(mainEntries)
+	index.apply(log.deltaBuffer...)
+	return index.all(), nil
+}
+
+// appendDocumentsLocked persists entries to the delta log; caller must hold the mutex
+func (als *AppendLogStorage) appendDocumentsLocked(log *documentLog, entries []documentEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := appendManyToDeltaFile(log.deltaFile, entries); err != nil {
+		return fmt.Errorf("failed to append %s to delta: %w", log.name, err)
+	}
+
+	log.deltaBuffer = append(log.deltaBuffer, entries...)
+	als.indexDocumentsLocked(log, entries...)
+	log.deltaCount += len(entries)
+
+	if log.deltaCount >= als.compactThreshold {
+		go als.compactDocuments(log)
+	}
+
+	return nil
+}
+
+func (als *AppendLogStorage) loadDocumentDelta(log *documentLog) error {
+	data, err := os.ReadFile(log.deltaFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, line := range splitLines(data) {
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry documentEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			fmt.Printf("Warning: corrupted %s delta entry: %v\n", log.name, err)
+			continue
+		}
+
+		log.deltaBuffer = append(log.deltaBuffer, entry)
+		log.deltaCount++
+	}
+
+	return nil
+}
+
+func (als *AppendLogStorage) compactDocuments(log *documentLog) error {
+	als.mutex.Lock()
+	defer als.mutex.Unlock()
+
+	entries, err := als.readDocumentsLocked(log)
+	if err != nil {
+		return fmt.Errorf("%s compaction failed: %w", log.name, err)
+	}
+
+	if err := als.parquetStorage.WriteDocuments(log.mainFile, entries); err != nil {
+		return fmt.Errorf("%s compaction failed: %w", log.name, err)
+	}
+
+	os.Remove(log.deltaFile)
+	log.deltaBuffer = make([]documentEntry, 0)
+	log.deltaCount = 0
+
+	// Swap in a view built from what was just written
+	delete(als.viewSkipped, log.name)
+	als.materializeDocumentsLocked(log, entries)
+
+	return nil
+}
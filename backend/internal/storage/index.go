@@ -0,0 +1,316 @@
+package storage
+
+import (
+	"slices"
+	"sort"
+
+	"hyprlnk/internal/models"
+	"hyprlnk/internal/urlnorm"
+)
+
+// bookmarkIndex is the materialized view of the live bookmarks, by ID and
+// by canonical URL, so neither listing nor looking up bookmarks rebuilds
+// the whole set from Parquet and delta. It is built on first use and kept
+// current by every bookmark write.
+type bookmarkIndex struct {
+	normalizer *urlnorm.Normalizer
+	byID       map[int64]models.Bookmark
+	byURL      map[string][]int64 // canonical URL to the bookmarks saved under it
+	size       int64              // estimated bytes held
+}
+
+func newBookmarkIndex(bookmarks []models.Bookmark, normalizer *urlnorm.Normalizer) *bookmarkIndex {
+	index := &bookmarkIndex{
+		normalizer: normalizer,
+		byID:       make(map[int64]models.Bookmark, len(bookmarks)),
+		byURL:      make(map[string][]int64, len(bookmarks)),
+	}
+	for _, bookmark := range bookmarks {
+		index.put(bookmark)
+	}
+	return index
+}
+
+// apply updates the index with a delta entry, a tombstone or a new version
+func (index *bookmarkIndex) apply(bookmark models.Bookmark) {
+	if bookmark.Title == "__DELETED__" {
+		index.remove(bookmark.ID)
+		return
+	}
+	index.put(bookmark)
+}
+
+func (index *bookmarkIndex) put(bookmark models.Bookmark) {
+	index.remove(bookmark.ID)
+	// The caller keeps its copy of the tags, which it may still change
+	bookmark.Tags = slices.Clone(bookmark.Tags)
+	index.byID[bookmark.ID] = bookmark
+	key := index.normalizer.Normalize(bookmark.URL)
+	index.byURL[key] = append(index.byURL[key], bookmark.ID)
+	index.size += sizeOf(bookmark)
+}
+
+func (index *bookmarkIndex) remove(id int64) {
+	existing, ok := index.byID[id]
+	if !ok {
+		return
+	}
+	delete(index.byID, id)
+	index.size -= sizeOf(existing)
+
+	key := index.normalizer.Normalize(existing.URL)
+	ids := index.byURL[key]
+	for i, other := range ids {
+		if other == id {
+			ids = append(ids[:i:i], ids[i+1:]...)
+			break
+		}
+	}
+	if len(ids) == 0 {
+		delete(index.byURL, key)
+		return
+	}
+	index.byURL[key] = ids
+}
+
+// get returns a copy of the bookmark with the given ID
+func (index *bookmarkIndex) get(id int64) (models.Bookmark, bool) {
+	bookmark, ok := index.byID[id]
+	bookmark.Tags = slices.Clone(bookmark.Tags)
+	return bookmark, ok
+}
+
+// all returns a copy of every bookmark, in no particular order
+func (index *bookmarkIndex) all() []models.Bookmark {
+	bookmarks := make([]models.Bookmark, 0, len(index.byID))
+	for id := range index.byID {
+		bookmark, _ := index.get(id)
+		bookmarks = append(bookmarks, bookmark)
+	}
+	return bookmarks
+}
+
+// lookupURL returns the bookmarks sharing rawURL's canonical form, oldest
+// first
+func (index *bookmarkIndex) lookupURL(rawURL string) []models.Bookmark {
+	ids := index.byURL[index.normalizer.Normalize(rawURL)]
+	bookmarks := make([]models.Bookmark, 0, len(ids))
+	for _, id := range ids {
+		bookmark, _ := index.get(id)
+		bookmarks = append(bookmarks, bookmark)
+	}
+	sort.Slice(bookmarks, func(i, j int) bool {
+		if !bookmarks[i].CreatedAt.Equal(bookmarks[j].CreatedAt) {
+			return bookmarks[i].CreatedAt.Before(bookmarks[j].CreatedAt)
+		}
+		return bookmarks[i].ID < bookmarks[j].ID
+	})
+	return bookmarks
+}
+
+// bookmarkIndexLocked returns the bookmark view, building it if needed.
+// When the view is off or doesn't fit the memory budget it returns a
+// throwaway index read from disk. Caller must hold the mutex, and the
+// write lock unless the view is built or off.
+func (als *AppendLogStorage) bookmarkIndexLocked() (*bookmarkIndex, error) {
+	if als.bookmarkIndex != nil {
+		return als.bookmarkIndex, nil
+	}
+
+	bookmarks, err := als.readBookmarksLocked()
+	if err != nil {
+		return nil, err
+	}
+	index, err := als.materializeBookmarksLocked(bookmarks)
+	if err != nil {
+		return nil, err
+	}
+	if index == nil {
+		settings, err := als.readSettingsLocked()
+		if err != nil {
+			return nil, err
+		}
+		index = newBookmarkIndex(bookmarks, urlnorm.New(settings.URLRules))
+	}
+	return index, nil
+}
+
+// materializeBookmarksLocked makes bookmarks the bookmark view, unless the
+// view is turned off or too large for the memory budget, in which case it
+// returns nil. Caller must hold the write lock.
+func (als *AppendLogStorage) materializeBookmarksLocked(bookmarks []models.Bookmark) (*bookmarkIndex, error) {
+	if !als.viewEnabledLocked(viewBookmarks) {
+		return nil, nil
+	}
+
+	settings, err := als.readSettingsLocked()
+	if err != nil {
+		return nil, err
+	}
+	als.bookmarkIndex = newBookmarkIndex(bookmarks, urlnorm.New(settings.URLRules))
+	if !als.viewFitsLocked(viewBookmarks) {
+		als.bookmarkIndex = nil
+	}
+	return als.bookmarkIndex, nil
+}
+
+// indexBookmarksLocked brings a built view up to date with written delta
+// entries, dropping it if it outgrows the memory budget; caller must hold
+// the write lock
+func (als *AppendLogStorage) indexBookmarksLocked(bookmarks ...models.Bookmark) {
+	if als.bookmarkIndex == nil {
+		return
+	}
+	for _, bookmark := range bookmarks {
+		als.bookmarkIndex.apply(bookmark)
+	}
+	if !als.viewFitsLocked(viewBookmarks) {
+		als.bookmarkIndex = nil
+	}
+}
+
+// GetBookmark returns the bookmark with the given ID; ok is false when
+// there is none
+func (als *AppendLogStorage) GetBookmark(id int64) (bookmark models.Bookmark, ok bool, err error) {
+	err = als.readView(viewBookmarks, als.bookmarkViewBuilt, func() error {
+		index, err := als.bookmarkIndexLocked()
+		if err != nil {
+			return err
+		}
+		bookmark, ok = index.get(id)
+		return nil
+	})
+	return bookmark, ok, err
+}
+
+// FindBookmarksByURL returns the bookmarks whose URL has the same canonical
+// form as rawURL under the current URL rules, oldest first
+func (als *AppendLogStorage) FindBookmarksByURL(rawURL string) (bookmarks []models.Bookmark, err error) {
+	err = als.readView(viewBookmarks, als.bookmarkViewBuilt, func() error {
+		index, err := als.bookmarkIndexLocked()
+		if err != nil {
+			return err
+		}
+		bookmarks = index.lookupURL(rawURL)
+		return nil
+	})
+	return bookmarks, err
+}
+
+func (als *AppendLogStorage) bookmarkViewBuilt() bool {
+	return als.bookmarkIndex != nil
+}
+
+// sessionIndex is the materialized view of the live sessions by ID
+type sessionIndex struct {
+	byID map[int64]models.Session
+	size int64 // estimated bytes held
+}
+
+func newSessionIndex(sessions []models.Session) *sessionIndex {
+	index := &sessionIndex{byID: make(map[int64]models.Session, len(sessions))}
+	for _, session := range sessions {
+		index.apply(session)
+	}
+	return index
+}
+
+// apply updates the index with a delta entry, a tombstone or a new version
+func (index *sessionIndex) apply(session models.Session) {
+	if existing, ok := index.byID[session.ID]; ok {
+		delete(index.byID, session.ID)
+		index.size -= sizeOf(existing)
+	}
+	if session.Name == "__DELETED__" {
+		return
+	}
+	session.Tabs = slices.Clone(session.Tabs)
+	session.Windows = slices.Clone(session.Windows)
+	session.Groups = slices.Clone(session.Groups)
+	index.byID[session.ID] = session
+	index.size += sizeOf(session)
+}
+
+// get returns a copy of the session with the given ID
+func (index *sessionIndex) get(id int64) (models.Session, bool) {
+	session, ok := index.byID[id]
+	session.Tabs = slices.Clone(session.Tabs)
+	session.Windows = slices.Clone(session.Windows)
+	session.Groups = slices.Clone(session.Groups)
+	return session, ok
+}
+
+// all returns a copy of every session, in no particular order
+func (index *sessionIndex) all() []models.Session {
+	sessions := make([]models.Session, 0, len(index.byID))
+	for id := range index.byID {
+		session, _ := index.get(id)
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// sessionIndexLocked returns the session view, building it if needed, or
+// a throwaway index read from disk when the view is off or over budget;
+// locking as for bookmarkIndexLocked
+func (als *AppendLogStorage) sessionIndexLocked() (*sessionIndex, error) {
+	if als.sessionIndex != nil {
+		return als.sessionIndex, nil
+	}
+
+	sessions, err := als.readSessionsLocked()
+	if err != nil {
+		return nil, err
+	}
+	if index := als.materializeSessionsLocked(sessions); index != nil {
+		return index, nil
+	}
+	return newSessionIndex(sessions), nil
+}
+
+// materializeSessionsLocked makes sessions the session view, or returns
+// nil when the view is off or over budget; caller must hold the write lock
+func (als *AppendLogStorage) materializeSessionsLocked(sessions []models.Session) *sessionIndex {
+	if !als.viewEnabledLocked(viewSessions) {
+		return nil
+	}
+
+	als.sessionIndex = newSessionIndex(sessions)
+	if !als.viewFitsLocked(viewSessions) {
+		als.sessionIndex = nil
+	}
+	return als.sessionIndex
+}
+
+// indexSessionsLocked brings a built view up to date with written delta
+// entries, dropping it if it outgrows the memory budget; caller must hold
+// the write lock
+func (als *AppendLogStorage) indexSessionsLocked(sessions ...models.Session) {
+	if als.sessionIndex == nil {
+		return
+	}
+	for _, session := range sessions {
+		als.sessionIndex.apply(session)
+	}
+	if !als.viewFitsLocked(viewSessions) {
+		als.sessionIndex = nil
+	}
+}
+
+// GetSession returns the session with the given ID; ok is false when there
+// is none
+func (als *AppendLogStorage) GetSession(id int64) (session models.Session, ok bool, err error) {
+	err = als.readView(viewSessions, als.sessionViewBuilt, func() error {
+		index, err := als.sessionIndexLocked()
+		if err != nil {
+			return err
+		}
+		session, ok = index.get(id)
+		return nil
+	})
+	return session, ok, err
+}
+
+func (als *AppendLogStorage) sessionViewBuilt() bool {
+	return als.sessionIndex != nil
+}
@@ -36,6 +36,11 @@ func (ps *ParquetStorage) getBookmarkSchema() *arrow.Schema {
         {Name: "tags", Type: arrow.BinaryTypes.String},
         {Name: "created_at", Type: arrow.FixedWidthTypes.Timestamp_ms},
         {Name: "updated_at", Type: arrow.FixedWidthTypes.Timestamp_ms},
+        // Columns below were added later; readers must tolerate files without them
+        {Name: "collection_id", Type: arrow.PrimitiveTypes.Int64},
+        {Name: "status", Type: arrow.BinaryTypes.String},
+        {Name: "progress", Type: arrow.PrimitiveTypes.Int32},
+        {Name: "read_at", Type: arrow.FixedWidthTypes.Timestamp_ms},
     }, nil)
 }
 
@@ -48,6 +53,10 @@ func (ps *ParquetStorage) getSessionSchema() *arrow.Schema {
         {Name: "is_active", Type: arrow.FixedWidthTypes.Boolean},
         {Name: "created_at", Type: arrow.FixedWidthTypes.Timestamp_ms},
         {Name: "updated_at", Type: arrow.FixedWidthTypes.Timestamp_ms},
+        // Columns below were added later; readers must tolerate files without them
+        {Name: "auto_save", Type: arrow.FixedWidthTypes.Boolean},
+        {Name: "windows", Type: arrow.BinaryTypes.String}, // JSON, empty for flat sessions
+        {Name: "groups", Type: arrow.BinaryTypes.String},  // JSON, empty without tab groups
     }, nil)
 }
 
@@ -60,6 +69,13 @@ func (ps *ParquetStorage) getHistorySchema() *arrow.Schema {
     }, nil)
 }
 
+func (ps *ParquetStorage) getDocumentSchema() *arrow.Schema {
+    return arrow.NewSchema([]arrow.Field{
+        {Name: "key", Type: arrow.BinaryTypes.String},
+        {Name: "data", Type: arrow.BinaryTypes.String},
+    }, nil)
+}
+
 func (ps *ParquetStorage) getLinkClickSchema() *arrow.Schema {
     return arrow.NewSchema([]arrow.Field{
         {Name: "id", Type: arrow.PrimitiveTypes.Int64},
@@ -73,6 +89,8 @@ func (ps *ParquetStorage) getLinkClickSchema() *arrow.Schema {
         {Name: "is_new_tab", Type: arrow.FixedWidthTypes.Boolean},
         {Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_ms},
         {Name: "created_at", Type: arrow.FixedWidthTypes.Timestamp_ms},
+        // Columns below were added later; readers must tolerate files without them
+        {Name: "source", Type: arrow.BinaryTypes.String},
     }, nil)
 }
 
@@ -93,6 +111,10 @@ func (ps *ParquetStorage) WriteBookmarks(bookmarks []models.Bookmark) error {
         
         builder.Field(5).(*array.TimestampBuilder).Append(arrow.Timestamp(bookmark.CreatedAt.UnixMilli()))
         builder.Field(6).(*array.TimestampBuilder).Append(arrow.Timestamp(bookmark.UpdatedAt.UnixMilli()))
+        builder.Field(7).(*array.Int64Builder).Append(bookmark.CollectionID)
+        builder.Field(8).(*array.StringBuilder).Append(bookmark.Status)
+        builder.Field(9).(*array.Int32Builder).Append(int32(bookmark.Progress))
+        builder.Field(10).(*array.TimestampBuilder).Append(arrow.Timestamp(bookmark.ReadAt.UnixMilli()))
     }
 
     record := builder.NewRecord()
@@ -143,6 +165,11 @@ func (ps *ParquetStorage) ReadBookmarks() ([]models.Bookmark, error) {
         return bookmarks, nil
     }
 
+    collectionCol, _ := optionalColumn(table, "collection_id").(*array.Int64)
+    statusCol, _ := optionalColumn(table, "status").(*array.String)
+    progressCol, _ := optionalColumn(table, "progress").(*array.Int32)
+    readAtCol, _ := optionalColumn(table, "read_at").(*array.Timestamp)
+
     for i := 0; i < int(table.NumRows()); i++ {
         idCol := table.Column(0).Data().Chunk(0).(*array.Int64)
         urlCol := table.Column(1).Data().Chunk(0).(*array.String)
@@ -166,6 +193,20 @@ func (ps *ParquetStorage) ReadBookmarks() ([]models.Bookmark, error) {
             CreatedAt:   time.UnixMilli(int64(createdCol.Value(i))),
             UpdatedAt:   time.UnixMilli(int64(updatedCol.Value(i))),
         }
+        if collectionCol != nil {
+            bookmark.CollectionID = collectionCol.Value(i)
+        }
+        if statusCol != nil {
+            bookmark.Status = statusCol.Value(i)
+        }
+        if progressCol != nil {
+            bookmark.Progress = int(progressCol.Value(i))
+        }
+        if readAtCol != nil {
+            if readAt := time.UnixMilli(int64(readAtCol.Value(i))); !readAt.IsZero() {
+                bookmark.ReadAt = readAt
+            }
+        }
         bookmarks = append(bookmarks, bookmark)
     }
 
@@ -189,6 +230,17 @@ func (ps *ParquetStorage) WriteSessions(sessions []models.Session) error {
         builder.Field(4).(*array.BooleanBuilder).Append(session.IsActive)
         builder.Field(5).(*array.TimestampBuilder).Append(arrow.Timestamp(session.CreatedAt.UnixMilli()))
         builder.Field(6).(*array.TimestampBuilder).Append(arrow.Timestamp(session.UpdatedAt.UnixMilli()))
+        builder.Field(7).(*array.BooleanBuilder).Append(session.AutoSave)
+
+        var windowsJSON, groupsJSON []byte
+        if len(session.Windows) > 0 {
+            windowsJSON, _ = json.Marshal(session.Windows)
+        }
+        if len(session.Groups) > 0 {
+            groupsJSON, _ = json.Marshal(session.Groups)
+        }
+        builder.Field(8).(*array.StringBuilder).Append(string(windowsJSON))
+        builder.Field(9).(*array.StringBuilder).Append(string(groupsJSON))
     }
 
     record := builder.NewRecord()
@@ -239,6 +291,10 @@ func (ps *ParquetStorage) ReadSessions() ([]models.Session, error) {
         return sessions, nil
     }
 
+    autoSaveCol, _ := optionalColumn(table, "auto_save").(*array.Boolean)
+    windowsCol, _ := optionalColumn(table, "windows").(*array.String)
+    groupsCol, _ := optionalColumn(table, "groups").(*array.String)
+
     for i := 0; i < int(table.NumRows()); i++ {
         idCol := table.Column(0).Data().Chunk(0).(*array.Int64)
         nameCol := table.Column(1).Data().Chunk(0).(*array.String)
@@ -262,6 +318,15 @@ func (ps *ParquetStorage) ReadSessions() ([]models.Session, error) {
             CreatedAt:   time.UnixMilli(int64(createdCol.Value(i))),
             UpdatedAt:   time.UnixMilli(int64(updatedCol.Value(i))),
         }
+        if autoSaveCol != nil {
+            session.AutoSave = autoSaveCol.Value(i)
+        }
+        if windowsCol != nil && windowsCol.Value(i) != "" {
+            json.Unmarshal([]byte(windowsCol.Value(i)), &session.Windows)
+        }
+        if groupsCol != nil && groupsCol.Value(i) != "" {
+            json.Unmarshal([]byte(groupsCol.Value(i)), &session.Groups)
+        }
         sessions = append(sessions, session)
     }
 
@@ -365,6 +430,7 @@ func (ps *ParquetStorage) WriteLinkClicks(clicks []models.LinkClick) error {
         builder.Field(8).(*array.BooleanBuilder).Append(click.IsNewTab)
         builder.Field(9).(*array.TimestampBuilder).Append(arrow.Timestamp(click.Timestamp.UnixMilli()))
         builder.Field(10).(*array.TimestampBuilder).Append(arrow.Timestamp(click.CreatedAt.UnixMilli()))
+        builder.Field(11).(*array.StringBuilder).Append(click.Source)
     }
 
     record := builder.NewRecord()
@@ -415,6 +481,8 @@ func (ps *ParquetStorage) ReadLinkClicks() ([]models.LinkClick, error) {
         return clicks, nil
     }
 
+    sourceCol, _ := optionalColumn(table, "source").(*array.String)
+
     for i := 0; i < int(table.NumRows()); i++ {
         idCol := table.Column(0).Data().Chunk(0).(*array.Int64)
         destUrlCol := table.Column(1).Data().Chunk(0).(*array.String)
@@ -441,8 +509,92 @@ func (ps *ParquetStorage) ReadLinkClicks() ([]models.LinkClick, error) {
             Timestamp:        time.UnixMilli(int64(timestampCol.Value(i))),
             CreatedAt:        time.UnixMilli(int64(createdCol.Value(i))),
         }
+        if sourceCol != nil {
+            click.Source = sourceCol.Value(i)
+        }
         clicks = append(clicks, click)
     }
 
     return clicks, nil
-}
\ No newline at end of file
+}
+
+// WriteDocuments replaces a document file with the given key/JSON rows
+func (ps *ParquetStorage) WriteDocuments(filename string, entries []documentEntry) error {
+    schema := ps.getDocumentSchema()
+    mem := memory.NewGoAllocator()
+    builder := array.NewRecordBuilder(mem, schema)
+    defer builder.Release()
+
+    for _, entry := range entries {
+        builder.Field(0).(*array.StringBuilder).Append(entry.Key)
+        builder.Field(1).(*array.StringBuilder).Append(string(entry.Data))
+    }
+
+    record := builder.NewRecord()
+    defer record.Release()
+
+    file, err := os.Create(filename)
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+
+    writer, err := pqarrow.NewFileWriter(schema, file, parquet.NewWriterProperties(), pqarrow.DefaultWriterProps())
+    if err != nil {
+        return err
+    }
+    defer writer.Close()
+
+    return writer.Write(record)
+}
+
+func (ps *ParquetStorage) ReadDocuments(filename string) ([]documentEntry, error) {
+    if _, err := os.Stat(filename); os.IsNotExist(err) {
+        return []documentEntry{}, nil
+    }
+
+    fileReader, err := file.OpenParquetFile(filename, false)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open parquet file: %w", err)
+    }
+    defer fileReader.Close()
+
+    reader, err := pqarrow.NewFileReader(fileReader, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create parquet reader: %w", err)
+    }
+
+    table, err := reader.ReadTable(context.Background())
+    if err != nil {
+        return nil, fmt.Errorf("failed to read table: %w", err)
+    }
+    defer table.Release()
+
+    var entries []documentEntry
+
+    if table.NumRows() == 0 {
+        return entries, nil
+    }
+
+    keyCol := table.Column(0).Data().Chunk(0).(*array.String)
+    dataCol := table.Column(1).Data().Chunk(0).(*array.String)
+
+    for i := 0; i < int(table.NumRows()); i++ {
+        entries = append(entries, documentEntry{
+            Key:  keyCol.Value(i),
+            Data: json.RawMessage(dataCol.Value(i)),
+        })
+    }
+
+    return entries, nil
+}
+
+// optionalColumn returns the first chunk of the named column, or nil when the
+// file was written before that column existed
+func optionalColumn(table arrow.Table, name string) arrow.Array {
+    indices := table.Schema().FieldIndices(name)
+    if len(indices) == 0 {
+        return nil
+    }
+    return table.Column(indices[0]).Data().Chunk(0)
+}
@@ -0,0 +1,430 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+
+	"hyprlnk/internal/models"
+)
+
+// Materialized views keep the merged state of each data set (the main
+// Parquet file with its delta applied) in memory, so reads don't decode
+// Parquet. A view is built on its first read, kept current by every delta
+// write and rebuilt from the compacted data when its log is compacted.
+// Views share a memory budget: one that doesn't fit is dropped and its data
+// set is read from disk again until the next compaction.
+
+// DefaultViewBudget is the memory the views may use unless SetViewBudget
+// changes it
+const DefaultViewBudget = 256 << 20
+
+// Names of the views other than the document logs', which go by log name
+const (
+	viewBookmarks  = "bookmarks"
+	viewSessions   = "sessions"
+	viewHistory    = "history"
+	viewLinkClicks = "link_clicks"
+)
+
+// viewEntryOverhead is added to each entry's size for the map and slice
+// bookkeeping around it
+const viewEntryOverhead = 64
+
+// SetViewBudget sets how many bytes the materialized views may hold in
+// total; 0 turns them off. Views built so far are dropped.
+func (als *AppendLogStorage) SetViewBudget(bytes int64) {
+	als.mutex.Lock()
+	defer als.mutex.Unlock()
+
+	als.viewBudget = bytes
+	als.viewSkipped = make(map[string]bool)
+	als.bookmarkIndex = nil
+	als.sessionIndex = nil
+	als.historyIndex = nil
+	als.linkClickIndex = nil
+	for _, log := range als.documentLogs() {
+		log.index = nil
+	}
+}
+
+// readView runs read under the read lock when the named view is built or
+// won't be, and under the write lock when read is going to build it
+func (als *AppendLogStorage) readView(name string, built func() bool, read func() error) error {
+	als.mutex.RLock()
+	if built() || !als.viewEnabledLocked(name) {
+		defer als.mutex.RUnlock()
+		return read()
+	}
+	als.mutex.RUnlock()
+
+	als.mutex.Lock()
+	defer als.mutex.Unlock()
+	return read()
+}
+
+// viewEnabledLocked reports whether the named view may be built
+func (als *AppendLogStorage) viewEnabledLocked(name string) bool {
+	return als.viewBudget > 0 && !als.viewSkipped[name]
+}
+
+// viewFitsLocked reports whether the views, including the named one as it
+// now stands, fit the budget. If not, the named view is skipped until its
+// log is next compacted and the caller drops it. Caller must hold the
+// write lock.
+func (als *AppendLogStorage) viewFitsLocked(name string) bool {
+	used := als.viewBytesLocked()
+	if used <= als.viewBudget {
+		return true
+	}
+	als.viewSkipped[name] = true
+	fmt.Printf("Warning: %s view would take the views to %.1f MB, over the %.1f MB budget; reading %s from disk until the next compaction\n",
+		name, float64(used)/(1<<20), float64(als.viewBudget)/(1<<20), name)
+	return false
+}
+
+// viewBytesLocked estimates the memory held by all built views
+func (als *AppendLogStorage) viewBytesLocked() int64 {
+	var used int64
+	if als.bookmarkIndex != nil {
+		used += als.bookmarkIndex.size
+	}
+	if als.sessionIndex != nil {
+		used += als.sessionIndex.size
+	}
+	if als.historyIndex != nil {
+		used += als.historyIndex.size
+	}
+	if als.linkClickIndex != nil {
+		used += als.linkClickIndex.size
+	}
+	for _, log := range als.documentLogs() {
+		if log.index != nil {
+			used += log.index.size
+		}
+	}
+	return used
+}
+
+// sizeOf estimates the bytes held by an entry: its own size plus the
+// strings and slices it points to
+func sizeOf(entry any) int64 {
+	value := reflect.ValueOf(entry)
+	return int64(value.Type().Size()) + referencedSize(value) + viewEntryOverhead
+}
+
+func referencedSize(value reflect.Value) int64 {
+	switch value.Kind() {
+	case reflect.String:
+		return int64(value.Len())
+	case reflect.Slice:
+		size := int64(value.Len()) * int64(value.Type().Elem().Size())
+		for i := 0; i < value.Len(); i++ {
+			size += referencedSize(value.Index(i))
+		}
+		return size
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < value.NumField(); i++ {
+			size += referencedSize(value.Field(i))
+		}
+		return size
+	case reflect.Pointer:
+		if value.IsNil() {
+			return 0
+		}
+		return int64(value.Elem().Type().Size()) + referencedSize(value.Elem())
+	}
+	return 0
+}
+
+// ============== HISTORY ==============
+
+// historyIndex is the materialized view of the history, one entry per URL.
+// A new entry for a URL supersedes the old one, which stays in entries,
+// and in size, until superseded entries make up half of them.
+type historyIndex struct {
+	entries []models.HistoryEntry
+	latest  map[string]int // URL to the position of its current entry
+	size    int64          // estimated bytes held
+}
+
+func newHistoryIndex(history []models.HistoryEntry) *historyIndex {
+	index := &historyIndex{latest: make(map[string]int, len(history))}
+	index.apply(history...)
+	return index
+}
+
+func (index *historyIndex) apply(history ...models.HistoryEntry) {
+	for _, entry := range history {
+		index.latest[entry.URL] = len(index.entries)
+		index.entries = append(index.entries, entry)
+		index.size += sizeOf(entry)
+	}
+
+	if len(index.entries) > 2*len(index.latest) {
+		index.entries = index.all()
+		index.size = 0
+		for i, entry := range index.entries {
+			index.latest[entry.URL] = i
+			index.size += sizeOf(entry)
+		}
+	}
+}
+
+// all returns a copy of the current entries, in the order they were
+// written
+func (index *historyIndex) all() []models.HistoryEntry {
+	history := make([]models.HistoryEntry, 0, len(index.latest))
+	for i, entry := range index.entries {
+		if index.latest[entry.URL] == i {
+			history = append(history, entry)
+		}
+	}
+	return history
+}
+
+// historyIndexLocked returns the history view, building it if needed, or
+// a throwaway index read from disk when the view is off or over budget;
+// locking as for bookmarkIndexLocked
+func (als *AppendLogStorage) historyIndexLocked() (*historyIndex, error) {
+	if als.historyIndex != nil {
+		return als.historyIndex, nil
+	}
+
+	history, err := als.readHistoryLocked()
+	if err != nil {
+		return nil, err
+	}
+	if index := als.materializeHistoryLocked(history); index != nil {
+		return index, nil
+	}
+	return newHistoryIndex(history), nil
+}
+
+// materializeHistoryLocked makes history the history view, or returns nil
+// when the view is off or over budget; caller must hold the write lock
+func (als *AppendLogStorage) materializeHistoryLocked(history []models.HistoryEntry) *historyIndex {
+	if !als.viewEnabledLocked(viewHistory) {
+		return nil
+	}
+
+	als.historyIndex = newHistoryIndex(history)
+	if !als.viewFitsLocked(viewHistory) {
+		als.historyIndex = nil
+	}
+	return als.historyIndex
+}
+
+// indexHistoryLocked brings a built view up to date with written entries;
+// caller must hold the write lock
+func (als *AppendLogStorage) indexHistoryLocked(history ...models.HistoryEntry) {
+	if als.historyIndex == nil {
+		return
+	}
+	als.historyIndex.apply(history...)
+	if !als.viewFitsLocked(viewHistory) {
+		als.historyIndex = nil
+	}
+}
+
+func (als *AppendLogStorage) historyViewBuilt() bool {
+	return als.historyIndex != nil
+}
+
+// ============== LINK CLICKS ==============
+
+// linkClickIndex is the materialized view of the link clicks, with the
+// most recent click to each destination
+type linkClickIndex struct {
+	clicks []models.LinkClick
+	latest map[string]int // destination URL to the position of its latest click
+	size   int64          // estimated bytes held
+}
+
+func newLinkClickIndex(clicks []models.LinkClick) *linkClickIndex {
+	index := &linkClickIndex{latest: make(map[string]int)}
+	index.apply(clicks...)
+	return index
+}
+
+func (index *linkClickIndex) apply(clicks ...models.LinkClick) {
+	for _, click := range clicks {
+		i, ok := index.latest[click.DestinationURL]
+		if !ok || click.Timestamp.After(index.clicks[i].Timestamp) {
+			index.latest[click.DestinationURL] = len(index.clicks)
+		}
+		index.clicks = append(index.clicks, click)
+		index.size += sizeOf(click)
+	}
+}
+
+// linkClickIndexLocked returns the link click view, building it if needed,
+// or a throwaway index read from disk when the view is off or over budget;
+// locking as for bookmarkIndexLocked
+func (als *AppendLogStorage) linkClickIndexLocked() (*linkClickIndex, error) {
+	if als.linkClickIndex != nil {
+		return als.linkClickIndex, nil
+	}
+
+	clicks, err := als.readLinkClicksLocked()
+	if err != nil {
+		return nil, err
+	}
+	if index := als.materializeLinkClicksLocked(clicks); index != nil {
+		return index, nil
+	}
+	return newLinkClickIndex(clicks), nil
+}
+
+// materializeLinkClicksLocked makes clicks the link click view, or returns
+// nil when the view is off or over budget; caller must hold the write lock
+func (als *AppendLogStorage) materializeLinkClicksLocked(clicks []models.LinkClick) *linkClickIndex {
+	if !als.viewEnabledLocked(viewLinkClicks) {
+		return nil
+	}
+
+	als.linkClickIndex = newLinkClickIndex(clicks)
+	if !als.viewFitsLocked(viewLinkClicks) {
+		als.linkClickIndex = nil
+	}
+	return als.linkClickIndex
+}
+
+// indexLinkClicksLocked brings a built view up to date with written
+// clicks; caller must hold the write lock
+func (als *AppendLogStorage) indexLinkClicksLocked(clicks ...models.LinkClick) {
+	if als.linkClickIndex == nil {
+		return
+	}
+	als.linkClickIndex.apply(clicks...)
+	if !als.viewFitsLocked(viewLinkClicks) {
+		als.linkClickIndex = nil
+	}
+}
+
+func (als *AppendLogStorage) linkClickViewBuilt() bool {
+	return als.linkClickIndex != nil
+}
+
+// LatestLinkClicks returns the most recent click to each of the given
+// destination URLs that was ever clicked
+func (als *AppendLogStorage) LatestLinkClicks(urls []string) (latest map[string]models.LinkClick, err error) {
+	err = als.readView(viewLinkClicks, als.linkClickViewBuilt, func() error {
+		index, err := als.linkClickIndexLocked()
+		if err != nil {
+			return err
+		}
+		latest = make(map[string]models.LinkClick)
+		for _, url := range urls {
+			if i, ok := index.latest[url]; ok {
+				latest[url] = index.clicks[i]
+			}
+		}
+		return nil
+	})
+	return latest, err
+}
+
+// ============== DOCUMENT LOGS ==============
+
+// documentIndex is the materialized view of a document log: its live
+// entries in first-written order. Deleted keys stay in order until they
+// make up half of it.
+type documentIndex struct {
+	order    []string
+	position map[string]int // key to its position in order, for live keys
+	entries  map[string]documentEntry
+	size     int64 // estimated bytes held
+}
+
+func newDocumentIndex(entries []documentEntry) *documentIndex {
+	index := &documentIndex{
+		position: make(map[string]int, len(entries)),
+		entries:  make(map[string]documentEntry, len(entries)),
+	}
+	index.apply(entries...)
+	return index
+}
+
+func (index *documentIndex) apply(entries ...documentEntry) {
+	for _, entry := range entries {
+		if existing, ok := index.entries[entry.Key]; ok {
+			index.size -= sizeOf(existing)
+		}
+		if entry.Deleted {
+			delete(index.entries, entry.Key)
+			delete(index.position, entry.Key)
+			continue
+		}
+		if _, ok := index.position[entry.Key]; !ok {
+			index.position[entry.Key] = len(index.order)
+			index.order = append(index.order, entry.Key)
+		}
+		index.entries[entry.Key] = entry
+		index.size += sizeOf(entry)
+	}
+
+	if len(index.order) > 2*len(index.entries) {
+		live := index.all()
+		index.order = index.order[:0]
+		for i, entry := range live {
+			index.position[entry.Key] = i
+			index.order = append(index.order, entry.Key)
+		}
+	}
+}
+
+// all returns the live entries in first-written order
+func (index *documentIndex) all() []documentEntry {
+	entries := make([]documentEntry, 0, len(index.entries))
+	for i, key := range index.order {
+		if position, ok := index.position[key]; ok && position == i {
+			entries = append(entries, index.entries[key])
+		}
+	}
+	return entries
+}
+
+// documentIndexLocked returns log's view, building it if needed, or a
+// throwaway index read from disk when the view is off or over budget;
+// locking as for bookmarkIndexLocked
+func (als *AppendLogStorage) documentIndexLocked(log *documentLog) (*documentIndex, error) {
+	if log.index != nil {
+		return log.index, nil
+	}
+
+	entries, err := als.readDocumentsLocked(log)
+	if err != nil {
+		return nil, err
+	}
+	if index := als.materializeDocumentsLocked(log, entries); index != nil {
+		return index, nil
+	}
+	return newDocumentIndex(entries), nil
+}
+
+// materializeDocumentsLocked makes entries log's view, or returns nil when
+// the view is off or over budget; caller must hold the write lock
+func (als *AppendLogStorage) materializeDocumentsLocked(log *documentLog, entries []documentEntry) *documentIndex {
+	if !als.viewEnabledLocked(log.name) {
+		return nil
+	}
+
+	log.index = newDocumentIndex(entries)
+	if !als.viewFitsLocked(log.name) {
+		log.index = nil
+	}
+	return log.index
+}
+
+// indexDocumentsLocked brings log's view, if built, up to date with
+// written entries; caller must hold the write lock
+func (als *AppendLogStorage) indexDocumentsLocked(log *documentLog, entries ...documentEntry) {
+	if log.index == nil {
+		return
+	}
+	log.index.apply(entries...)
+	if !als.viewFitsLocked(log.name) {
+		log.index = nil
+	}
+}
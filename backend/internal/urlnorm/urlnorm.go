@@ -0,0 +1,175 @@
+// Package urlnorm reduces URLs to a canonical form so the same page saved
+// with different tracking parameters, fragments or schemes compares equal.
+package urlnorm
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"hyprlnk/internal/models"
+)
+
+// trackingParams are dropped from every URL; entries ending in "*" are prefixes
+var trackingParams = []string{
+	"utm_*",
+	"fbclid",
+	"gclid",
+	"dclid",
+	"msclkid",
+	"yclid",
+	"igshid",
+	"mc_cid",
+	"mc_eid",
+	"_hsenc",
+	"_hsmi",
+}
+
+// Normalizer canonicalizes URLs using the default rules plus any per-domain overrides
+type Normalizer struct {
+	rules []models.URLRule
+}
+
+// New creates a Normalizer; rules are matched against the URL host in order
+func New(rules []models.URLRule) *Normalizer {
+	return &Normalizer{rules: rules}
+}
+
+// Normalize returns the canonical form of raw. Strings that don't parse as
+// absolute http(s) URLs are returned trimmed but otherwise untouched.
+func (n *Normalizer) Normalize(raw string) string {
+	raw = strings.TrimSpace(raw)
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return raw
+	}
+
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	rule := n.ruleFor(host)
+
+	if !rule.KeepScheme {
+		scheme = "https"
+	}
+	if !rule.KeepWWW {
+		host = strings.TrimPrefix(host, "www.")
+	}
+	if port == "80" || port == "443" {
+		port = ""
+	}
+	if port != "" {
+		host = host + ":" + port
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if !rule.KeepTrailingSlash && len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+		if path == "" {
+			path = "/"
+		}
+	}
+
+	result := scheme + "://" + host + path
+	if query := n.filterQuery(u.Query(), rule); query != "" {
+		result += "?" + query
+	}
+	if rule.KeepFragment && u.Fragment != "" {
+		result += "#" + u.EscapedFragment()
+	}
+
+	return result
+}
+
+// Equal reports whether a and b normalize to the same URL
+func (n *Normalizer) Equal(a, b string) bool {
+	return n.Normalize(a) == n.Normalize(b)
+}
+
+// ruleFor merges every rule whose domain matches host; later rules win on
+// the flags they set, and parameter lists accumulate
+func (n *Normalizer) ruleFor(host string) models.URLRule {
+	var merged models.URLRule
+	for _, rule := range n.rules {
+		if !domainMatches(rule.Domain, host) {
+			continue
+		}
+		merged.StripParams = append(merged.StripParams, rule.StripParams...)
+		merged.KeepParams = append(merged.KeepParams, rule.KeepParams...)
+		merged.KeepFragment = merged.KeepFragment || rule.KeepFragment
+		merged.KeepTrailingSlash = merged.KeepTrailingSlash || rule.KeepTrailingSlash
+		merged.KeepScheme = merged.KeepScheme || rule.KeepScheme
+		merged.KeepWWW = merged.KeepWWW || rule.KeepWWW
+	}
+	return merged
+}
+
+// filterQuery drops tracking and rule-stripped parameters and sorts the rest
+func (n *Normalizer) filterQuery(values url.Values, rule models.URLRule) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if matchesAny(key, trackingParams) || matchesAny(key, rule.StripParams) {
+			continue
+		}
+		if len(rule.KeepParams) > 0 && !matchesAny(key, rule.KeepParams) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		vals := values[key]
+		sort.Strings(vals)
+		for _, val := range vals {
+			parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(val))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// domainMatches reports whether host is pattern or one of its subdomains.
+// A leading "*." on the pattern is accepted and means the same thing.
+func domainMatches(pattern, host string) bool {
+	pattern = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(pattern)), "*.")
+	if pattern == "" {
+		return false
+	}
+	host = strings.TrimPrefix(host, "www.")
+	return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
+
+// matchesAny reports whether key equals one of patterns; "*" matches every
+// key and a trailing "*" makes a prefix match
+func matchesAny(key string, patterns []string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if pattern == "*" {
+			return true
+		}
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(key, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+			continue
+		}
+		if key == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package urlnorm
+
+import (
+	"testing"
+
+	"hyprlnk/internal/models"
+)
+
+func TestNormalize(t *testing.T) {
+	normalizer := New(models.DefaultSettings().URLRules)
+
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"upgrades the scheme", "http://example.com/a", "https://example.com/a"},
+		{"lowercases the host", "https://Example.COM/Path", "https://example.com/Path"},
+		{"strips www", "https://www.example.com/a", "https://example.com/a"},
+		{"drops default ports", "https://example.com:443/a", "https://example.com/a"},
+		{"keeps other ports", "https://example.com:8080/a", "https://example.com:8080/a"},
+		{"drops the fragment", "https://example.com/a#section", "https://example.com/a"},
+		{"drops a trailing slash", "https://example.com/a/", "https://example.com/a"},
+		{"adds a root path", "https://example.com", "https://example.com/"},
+		{"drops tracking params", "https://example.com/a?utm_source=x&id=1&fbclid=y", "https://example.com/a?id=1"},
+		{"sorts params", "https://example.com/a?b=2&a=1", "https://example.com/a?a=1&b=2"},
+		{"applies keep params", "https://www.youtube.com/watch?v=abc&t=30&feature=share", "https://youtube.com/watch?v=abc"},
+		{"trims whitespace", "  https://example.com/a  ", "https://example.com/a"},
+		{"leaves other schemes", "ftp://example.com/a/", "ftp://example.com/a/"},
+		{"leaves relative URLs", "not a url", "not a url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizer.Normalize(tt.raw); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize_Rules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule models.URLRule
+		raw  string
+		want string
+	}{
+		{"keep fragment", models.URLRule{Domain: "app.example", KeepFragment: true}, "https://app.example/#/route", "https://app.example/#/route"},
+		{"keep trailing slash", models.URLRule{Domain: "example.com", KeepTrailingSlash: true}, "https://example.com/dir/", "https://example.com/dir/"},
+		{"keep scheme", models.URLRule{Domain: "example.com", KeepScheme: true}, "http://example.com/a", "http://example.com/a"},
+		{"keep www", models.URLRule{Domain: "example.com", KeepWWW: true}, "https://www.example.com/a", "https://www.example.com/a"},
+		{"strip params", models.URLRule{Domain: "example.com", StripParams: []string{"ref*"}}, "https://example.com/a?ref_src=x&id=1", "https://example.com/a?id=1"},
+		{"strip all params", models.URLRule{Domain: "example.com", StripParams: []string{"*"}}, "https://example.com/a?id=1", "https://example.com/a"},
+		{"matches subdomains", models.URLRule{Domain: "*.example.com", KeepScheme: true}, "http://blog.example.com/a", "http://blog.example.com/a"},
+		{"ignores other domains", models.URLRule{Domain: "example.com", KeepScheme: true}, "http://example.com.au/a", "https://example.com.au/a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalizer := New([]models.URLRule{tt.rule})
+			if got := normalizer.Normalize(tt.raw); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	normalizer := New(nil)
+
+	if !normalizer.Equal("http://www.example.com/a/?utm_medium=x", "https://example.com/a") {
+		t.Error("Expected URLs differing only in scheme, www, slash and tracking to be equal")
+	}
+	if normalizer.Equal("https://example.com/a", "https://example.com/b") {
+		t.Error("Expected different paths to differ")
+	}
+}
@@ -6,12 +6,18 @@ import (
     "log"
     "net/http"
     "os"
+    "path/filepath"
+    "strconv"
     "time"
+    _ "time/tzdata" // the alpine runtime image ships without a zoneinfo database
 
     "github.com/gorilla/mux"
     "github.com/rs/cors"
 
+    "hyprlnk/internal/classify"
+    "hyprlnk/internal/fetch"
     "hyprlnk/internal/handlers"
+    "hyprlnk/internal/linkcheck"
     "hyprlnk/internal/repositories"
     "hyprlnk/internal/services"
     "hyprlnk/internal/storage"
@@ -19,37 +25,124 @@ import (
 
 type App struct {
     storage          *storage.AppendLogStorage
+    service          services.HyprLinkService
     bookmarkHandler   *handlers.BookmarkHandler
+    collectionHandler *handlers.CollectionHandler
+    tagHandler        *handlers.TagHandler
     sessionHandler    *handlers.SessionHandler
     historyHandler    *handlers.HistoryHandler
     linkClickHandler  *handlers.LinkClickHandler
     importHandler     *handlers.ImportHandler
+    exportHandler     *handlers.ExportHandler
+    settingsHandler   *handlers.SettingsHandler
+    jobHandler        *handlers.JobHandler
+    ruleHandler       *handlers.RuleHandler
+    metadataHandler   *handlers.MetadataHandler
+    archiveHandler    *handlers.ArchiveHandler
+    linkHealthHandler *handlers.LinkHealthHandler
+    readingHandler    *handlers.ReadingHandler
+    annotationHandler *handlers.AnnotationHandler
+    revisionHandler   *handlers.RevisionHandler
+    trashHandler      *handlers.TrashHandler
 }
 
 func NewApp(dataDir string) *App {
     appendLogStorage := storage.NewAppendLogStorage(dataDir)
+    if value := os.Getenv("VIEW_CACHE_MB"); value != "" {
+        megabytes, err := strconv.Atoi(value)
+        if err != nil || megabytes < 0 {
+            log.Fatalf("invalid VIEW_CACHE_MB %q", value)
+        }
+        appendLogStorage.SetViewBudget(int64(megabytes) << 20)
+    }
 
     bookmarkRepo := repositories.NewBookmarkRepository(appendLogStorage)
+    collectionRepo := repositories.NewCollectionRepository(appendLogStorage)
+    tagRepo := repositories.NewTagRepository(appendLogStorage)
     sessionRepo := repositories.NewSessionRepository(appendLogStorage)
     historyRepo := repositories.NewHistoryRepository(appendLogStorage)
     linkClickRepo := repositories.NewLinkClickRepository(appendLogStorage)
     importRepo := repositories.NewImportRepository(appendLogStorage)
+    settingsRepo := repositories.NewSettingsRepository(appendLogStorage)
+    jobRepo := repositories.NewJobRepository(appendLogStorage)
+    ruleRepo := repositories.NewRuleRepository(appendLogStorage)
+    metadataRepo := repositories.NewMetadataRepository(appendLogStorage)
+    archiveRepo := repositories.NewArchiveRepository(appendLogStorage)
+    linkHealthRepo := repositories.NewLinkHealthRepository(appendLogStorage)
+    annotationRepo := repositories.NewAnnotationRepository(appendLogStorage)
+    revisionRepo := repositories.NewRevisionRepository(appendLogStorage)
+    trashRepo := repositories.NewTrashRepository(appendLogStorage)
+    versionRepo := repositories.NewSessionVersionRepository(appendLogStorage)
+
+    // Trained lazily from the stored bookmarks on first use
+    suggester := classify.NewBayes(bookmarkRepo.GetAll)
+
+    config, err := classifierConfig(dataDir)
+    if err != nil {
+        log.Fatal(err)
+    }
+    config.Bayes = suggester
+    classifier, err := classify.New(config)
+    if err != nil {
+        log.Fatalf("Failed to set up the bookmark classifier: %v", err)
+    }
 
-    hyprLinkService := services.NewHyprLinkService(
-        bookmarkRepo,
-        sessionRepo,
-        historyRepo,
-        linkClickRepo,
-        importRepo,
-    )
+    fetcher := pageFetcher()
+    checker, err := linkChecker()
+    if err != nil {
+        log.Fatal(err)
+    }
+    archivePages := os.Getenv("ARCHIVE_PAGES") == "true"
+    if archivePages && fetcher == nil {
+        log.Printf("ARCHIVE_PAGES is ignored while FETCH_PAGES=false")
+    }
+
+    hyprLinkService := services.NewHyprLinkService(services.Config{
+        BookmarkRepo:   bookmarkRepo,
+        CollectionRepo: collectionRepo,
+        TagRepo:        tagRepo,
+        SessionRepo:    sessionRepo,
+        HistoryRepo:    historyRepo,
+        LinkClickRepo:  linkClickRepo,
+        ImportRepo:     importRepo,
+        SettingsRepo:   settingsRepo,
+        JobRepo:        jobRepo,
+        RuleRepo:       ruleRepo,
+        MetadataRepo:   metadataRepo,
+        ArchiveRepo:    archiveRepo,
+        LinkHealthRepo: linkHealthRepo,
+        AnnotationRepo: annotationRepo,
+        RevisionRepo:   revisionRepo,
+        TrashRepo:      trashRepo,
+        VersionRepo:    versionRepo,
+        Classifier:     classifier,
+        Suggester:      suggester,
+        Fetcher:        fetcher,
+        ArchivePages:   archivePages,
+        LinkChecker:    checker,
+    })
 
     return &App{
-        storage:          appendLogStorage,
-        bookmarkHandler:  handlers.NewBookmarkHandler(hyprLinkService),
-        sessionHandler:   handlers.NewSessionHandler(hyprLinkService),
-        historyHandler:   handlers.NewHistoryHandler(hyprLinkService),
-        linkClickHandler: handlers.NewLinkClickHandler(hyprLinkService),
-        importHandler:    handlers.NewImportHandler(hyprLinkService),
+        storage:           appendLogStorage,
+        service:           hyprLinkService,
+        bookmarkHandler:   handlers.NewBookmarkHandler(hyprLinkService),
+        collectionHandler: handlers.NewCollectionHandler(hyprLinkService),
+        tagHandler:        handlers.NewTagHandler(hyprLinkService),
+        sessionHandler:    handlers.NewSessionHandler(hyprLinkService),
+        historyHandler:    handlers.NewHistoryHandler(hyprLinkService),
+        linkClickHandler:  handlers.NewLinkClickHandler(hyprLinkService),
+        importHandler:     handlers.NewImportHandler(hyprLinkService),
+        exportHandler:     handlers.NewExportHandler(hyprLinkService),
+        settingsHandler:   handlers.NewSettingsHandler(hyprLinkService),
+        jobHandler:        handlers.NewJobHandler(hyprLinkService),
+        ruleHandler:       handlers.NewRuleHandler(hyprLinkService),
+        metadataHandler:   handlers.NewMetadataHandler(hyprLinkService),
+        archiveHandler:    handlers.NewArchiveHandler(hyprLinkService),
+        linkHealthHandler: handlers.NewLinkHealthHandler(hyprLinkService),
+        readingHandler:    handlers.NewReadingHandler(hyprLinkService),
+        annotationHandler: handlers.NewAnnotationHandler(hyprLinkService),
+        revisionHandler:   handlers.NewRevisionHandler(hyprLinkService),
+        trashHandler:      handlers.NewTrashHandler(hyprLinkService),
     }
 }
 
@@ -58,14 +151,77 @@ func (app *App) setupRoutes() *mux.Router {
 
     router.HandleFunc("/api/bookmarks", app.bookmarkHandler.GetAll).Methods("GET")
     router.HandleFunc("/api/bookmarks", app.bookmarkHandler.Create).Methods("POST")
+    router.HandleFunc("/api/bookmarks/{id:[0-9]+}", app.bookmarkHandler.Get).Methods("GET")
+    router.HandleFunc("/api/bookmarks/lookup", app.bookmarkHandler.Lookup).Methods("GET")
     router.HandleFunc("/api/bookmarks/{id}", app.bookmarkHandler.Update).Methods("PUT")
     router.HandleFunc("/api/bookmarks/{id}", app.bookmarkHandler.Delete).Methods("DELETE")
     router.HandleFunc("/api/bookmarks/search", app.bookmarkHandler.Search).Methods("GET")
+    router.HandleFunc("/api/bookmarks/duplicates", app.bookmarkHandler.GetDuplicates).Methods("GET")
+    router.HandleFunc("/api/bookmarks/health", app.linkHealthHandler.GetAll).Methods("GET")
+    router.HandleFunc("/api/bookmarks/health/apply-redirects", app.linkHealthHandler.ApplyRedirects).Methods("POST")
+    router.HandleFunc("/api/bookmarks/{id}/health", app.linkHealthHandler.Get).Methods("GET")
+    router.HandleFunc("/api/bookmarks/{id}/health/check", app.linkHealthHandler.Check).Methods("POST")
+    router.HandleFunc("/api/bookmarks/merge", app.bookmarkHandler.Merge).Methods("POST")
+    router.HandleFunc("/api/bookmarks/bulk", app.bookmarkHandler.Bulk).Methods("POST")
+    router.HandleFunc("/api/bookmarks/{id}/move", app.bookmarkHandler.Move).Methods("POST")
+    router.HandleFunc("/api/bookmarks/{id}/suggest-tags", app.bookmarkHandler.SuggestTags).Methods("GET")
+    router.HandleFunc("/api/suggest-tags", app.bookmarkHandler.SuggestTagsForNew).Methods("POST")
+    router.HandleFunc("/api/bookmarks/{id}/metadata", app.metadataHandler.Get).Methods("GET")
+    router.HandleFunc("/api/bookmarks/{id}/metadata/refresh", app.metadataHandler.Refresh).Methods("POST")
+    router.HandleFunc("/api/bookmarks/{id}/archive", app.archiveHandler.Get).Methods("GET")
+    router.HandleFunc("/api/bookmarks/{id}/archive", app.archiveHandler.Create).Methods("POST")
+    router.HandleFunc("/api/bookmarks/{id}/reading", app.readingHandler.Update).Methods("PUT")
+    router.HandleFunc("/api/bookmarks/{id}/reading", app.readingHandler.Delete).Methods("DELETE")
+    router.HandleFunc("/api/reading-list", app.readingHandler.GetAll).Methods("GET")
+    router.HandleFunc("/api/bookmarks/{id}/annotations", app.annotationHandler.GetForBookmark).Methods("GET")
+    router.HandleFunc("/api/annotations", app.annotationHandler.GetAll).Methods("GET")
+    router.HandleFunc("/api/annotations", app.annotationHandler.Create).Methods("POST")
+    router.HandleFunc("/api/annotations/{id}", app.annotationHandler.Get).Methods("GET")
+    router.HandleFunc("/api/annotations/{id}", app.annotationHandler.Update).Methods("PUT")
+    router.HandleFunc("/api/annotations/{id}", app.annotationHandler.Delete).Methods("DELETE")
+    router.HandleFunc("/api/bookmarks/{id}/revisions", app.revisionHandler.GetBookmarkRevisions).Methods("GET")
+    router.HandleFunc("/api/bookmarks/{id}/revisions/{rev}/restore", app.revisionHandler.RestoreBookmarkRevision).Methods("POST")
+    router.HandleFunc("/api/favicons/{host}", app.metadataHandler.Favicon).Methods("GET")
+    
+    router.HandleFunc("/api/collections", app.collectionHandler.GetAll).Methods("GET")
+    router.HandleFunc("/api/collections", app.collectionHandler.Create).Methods("POST")
+    router.HandleFunc("/api/collections/tree", app.collectionHandler.GetTree).Methods("GET")
+    router.HandleFunc("/api/collections/{id}", app.collectionHandler.Update).Methods("PUT")
+    router.HandleFunc("/api/collections/{id}", app.collectionHandler.Delete).Methods("DELETE")
+    router.HandleFunc("/api/collections/{id}/move", app.collectionHandler.Move).Methods("POST")
+    router.HandleFunc("/api/collections/{id}/bookmarks", app.collectionHandler.GetBookmarks).Methods("GET")
+
+    router.HandleFunc("/api/tags", app.tagHandler.GetAll).Methods("GET")
+    router.HandleFunc("/api/tags/rename", app.tagHandler.Rename).Methods("POST")
+    router.HandleFunc("/api/tags/merge", app.tagHandler.Merge).Methods("POST")
+    router.HandleFunc("/api/tags/{name:.+}", app.tagHandler.Update).Methods("PUT")
+    router.HandleFunc("/api/tags/{name:.+}", app.tagHandler.Delete).Methods("DELETE")
+    
+    router.HandleFunc("/api/rules", app.ruleHandler.GetAll).Methods("GET")
+    router.HandleFunc("/api/rules", app.ruleHandler.Create).Methods("POST")
+    router.HandleFunc("/api/rules/apply", app.ruleHandler.Apply).Methods("POST")
+    router.HandleFunc("/api/rules/{id}", app.ruleHandler.Update).Methods("PUT")
+    router.HandleFunc("/api/rules/{id}", app.ruleHandler.Delete).Methods("DELETE")
 
     router.HandleFunc("/api/sessions", app.sessionHandler.GetAll).Methods("GET")
     router.HandleFunc("/api/sessions", app.sessionHandler.Create).Methods("POST")
+    router.HandleFunc("/api/sessions/autosave", app.sessionHandler.AutoSave).Methods("POST")
     router.HandleFunc("/api/sessions/{id}", app.sessionHandler.Update).Methods("PUT")
     router.HandleFunc("/api/sessions/{id}", app.sessionHandler.Delete).Methods("DELETE")
+    router.HandleFunc("/api/sessions/{id}/revisions", app.revisionHandler.GetSessionRevisions).Methods("GET")
+    router.HandleFunc("/api/sessions/{id}/revisions/{rev}/restore", app.revisionHandler.RestoreSessionRevision).Methods("POST")
+    router.HandleFunc("/api/sessions/{id}/versions", app.sessionHandler.GetVersions).Methods("GET")
+    router.HandleFunc("/api/sessions/{id}/versions/at", app.sessionHandler.GetVersionAt).Methods("GET")
+    router.HandleFunc("/api/sessions/{id}/versions/{version:[0-9]+}", app.sessionHandler.GetVersion).Methods("GET")
+    router.HandleFunc("/api/sessions/{id}/versions/{version}/restore", app.sessionHandler.RestoreVersion).Methods("POST")
+
+    router.HandleFunc("/api/operations", app.revisionHandler.GetOperations).Methods("GET")
+    router.HandleFunc("/api/operations/undo", app.revisionHandler.Undo).Methods("POST")
+
+    router.HandleFunc("/api/trash", app.trashHandler.GetAll).Methods("GET")
+    router.HandleFunc("/api/trash", app.trashHandler.Empty).Methods("DELETE")
+    router.HandleFunc("/api/trash/{type}/{id}/restore", app.trashHandler.Restore).Methods("POST")
+    router.HandleFunc("/api/trash/{type}/{id}", app.trashHandler.Delete).Methods("DELETE")
     
     router.HandleFunc("/api/history", app.historyHandler.GetAll).Methods("GET")
     router.HandleFunc("/api/history/today", app.historyHandler.GetToday).Methods("GET")
@@ -78,8 +234,19 @@ func (app *App) setupRoutes() *mux.Router {
     router.HandleFunc("/api/link-clicks/sync", app.linkClickHandler.Sync).Methods("POST")
     
     router.HandleFunc("/api/import/browser", app.importHandler.ImportBrowserData).Methods("POST")
+    router.HandleFunc("/api/import/browser-db", app.importHandler.ImportBrowserDB).Methods("POST")
+    router.HandleFunc("/api/import/{format}", app.importHandler.ImportFile).Methods("POST")
+    router.HandleFunc("/api/export/bookmarks.html", app.exportHandler.ExportBookmarksHTML).Methods("GET")
+    router.HandleFunc("/api/export/annotations.md", app.exportHandler.ExportAnnotationsMarkdown).Methods("GET")
+    router.HandleFunc("/api/export/annotations.json", app.exportHandler.ExportAnnotationsJSON).Methods("GET")
+    router.HandleFunc("/api/jobs", app.jobHandler.GetAll).Methods("GET")
+    router.HandleFunc("/api/jobs/{id}", app.jobHandler.Get).Methods("GET")
+    router.HandleFunc("/api/jobs/{id}/cancel", app.jobHandler.Cancel).Methods("POST")
     router.HandleFunc("/api/segment", app.importHandler.BulkSegmentBookmarks).Methods("POST")
     
+    router.HandleFunc("/api/settings", app.settingsHandler.Get).Methods("GET")
+    router.HandleFunc("/api/settings", app.settingsHandler.Update).Methods("PUT")
+    
     router.HandleFunc("/api/ping", func(w http.ResponseWriter, r *http.Request) {
         w.Header().Set("Content-Type", "application/json")
         json.NewEncoder(w).Encode(map[string]string{
@@ -96,6 +263,80 @@ func (app *App) setupRoutes() *mux.Router {
     return router
 }
 
+// pageFetcher builds the fetcher for bookmarked pages, or returns nil when
+// FETCH_PAGES=false keeps the server from making any requests of its own
+func pageFetcher() *fetch.Fetcher {
+    if os.Getenv("FETCH_PAGES") == "false" {
+        return nil
+    }
+    return fetch.New(fetch.Config{UserAgent: os.Getenv("FETCH_USER_AGENT")})
+}
+
+// linkChecker builds the checker for dead and moved links from the
+// environment, or returns nil when LINK_CHECK=false or FETCH_PAGES=false
+func linkChecker() (*linkcheck.Checker, error) {
+    if os.Getenv("LINK_CHECK") == "false" || os.Getenv("FETCH_PAGES") == "false" {
+        return nil, nil
+    }
+
+    config := linkcheck.Config{UserAgent: os.Getenv("FETCH_USER_AGENT")}
+    var err error
+    if value := os.Getenv("LINK_CHECK_WORKERS"); value != "" {
+        if config.Workers, err = strconv.Atoi(value); err != nil {
+            return nil, fmt.Errorf("invalid LINK_CHECK_WORKERS: %w", err)
+        }
+    }
+    for name, target := range map[string]*time.Duration{
+        "LINK_CHECK_INTERVAL":      &config.Interval,
+        "LINK_CHECK_HOST_INTERVAL": &config.HostInterval,
+        "LINK_CHECK_TIMEOUT":       &config.Timeout,
+    } {
+        if value := os.Getenv(name); value != "" {
+            if *target, err = time.ParseDuration(value); err != nil {
+                return nil, fmt.Errorf("invalid %s: %w", name, err)
+            }
+        }
+    }
+    return linkcheck.New(config), nil
+}
+
+// classifierConfig reads the bookmark classifier settings from the
+// environment. CLASSIFIER picks keywords (default), rules, llm or bayes.
+func classifierConfig(dataDir string) (classify.Config, error) {
+    rulesFile := os.Getenv("CLASSIFIER_RULES_FILE")
+    if rulesFile == "" {
+        rulesFile = filepath.Join(dataDir, "classifier_rules.json")
+    }
+
+    config := classify.Config{
+        Kind:      os.Getenv("CLASSIFIER"),
+        RulesFile: rulesFile,
+        LLM: classify.LLMConfig{
+            BaseURL: os.Getenv("LLM_BASE_URL"),
+            Model:   os.Getenv("LLM_MODEL"),
+            APIKey:  os.Getenv("LLM_API_KEY"),
+        },
+    }
+
+    var err error
+    if value := os.Getenv("LLM_BATCH_SIZE"); value != "" {
+        if config.LLM.BatchSize, err = strconv.Atoi(value); err != nil {
+            return config, fmt.Errorf("invalid LLM_BATCH_SIZE: %w", err)
+        }
+    }
+    if value := os.Getenv("LLM_MAX_RETRIES"); value != "" {
+        if config.LLM.MaxRetries, err = strconv.Atoi(value); err != nil {
+            return config, fmt.Errorf("invalid LLM_MAX_RETRIES: %w", err)
+        }
+    }
+    if value := os.Getenv("LLM_TIMEOUT"); value != "" {
+        if config.LLM.Timeout, err = time.ParseDuration(value); err != nil {
+            return config, fmt.Errorf("invalid LLM_TIMEOUT: %w", err)
+        }
+    }
+    return config, nil
+}
+
 func main() {
     dataDir := os.Getenv("DATA_DIR")
     if dataDir == "" {
@@ -111,6 +352,19 @@ func main() {
         }
     }()
     
+    if len(os.Args) > 1 {
+        if err := app.runCommand(os.Args[1], os.Args[2:]); err != nil {
+            app.storage.Close()
+            log.Fatal(err)
+        }
+        return
+    }
+    
+    // Resume imports interrupted by the last shutdown
+    if err := app.service.StartJobs(); err != nil {
+        log.Fatalf("Failed to start background jobs: %v", err)
+    }
+    
     router := app.setupRoutes()
 
     c := cors.New(cors.Options{
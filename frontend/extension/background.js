@@ -8,6 +8,8 @@ function debugLog(message, ...args) {
   }
 }
 
+importScripts('session-state.js');
+
 debugLog('[HyprLnk] Background script loaded, API_BASE:', API_BASE);
 
 // Context menu setup
@@ -20,6 +22,12 @@ chrome.runtime.onInstalled.addListener(() => {
     contexts: ['page']
   });
 
+  chrome.contextMenus.create({
+    id: 'readLater',
+    title: 'Read Later with HyprLnk',
+    contexts: ['page']
+  });
+
   chrome.contextMenus.create({
     id: 'saveSession',
     title: 'Save Session to HyprLnk',
@@ -35,17 +43,20 @@ chrome.runtime.onInstalled.addListener(() => {
 chrome.contextMenus.onClicked.addListener(async (info, tab) => {
   if (info.menuItemId === 'saveBookmark') {
     await saveBookmark(tab);
+  } else if (info.menuItemId === 'readLater') {
+    await saveBookmark(tab, 'unread');
   } else if (info.menuItemId === 'saveSession') {
     await saveCurrentSession();
   }
 });
 
-async function saveBookmark(tab) {
+async function saveBookmark(tab, status) {
   const bookmark = {
     url: tab.url,
     title: tab.title,
     description: '',
-    tags: []
+    tags: [],
+    ...(status && { status })
   };
 
   try {
@@ -67,18 +78,14 @@ async function saveBookmark(tab) {
 
 async function saveCurrentSession() {
   try {
-    const tabs = await chrome.tabs.query({});
-    const sessionTabs = tabs.map((tab, index) => ({
-      url: tab.url,
-      title: tab.title,
-      active: tab.active,
-      index: index
-    }));
+    const { tabs: sessionTabs, windows, groups } = await captureBrowserState();
 
     const session = {
       name: `Session ${new Date().toLocaleString()}`,
       description: `${sessionTabs.length} tabs saved`,
       tabs: sessionTabs,
+      windows,
+      groups,
       is_active: true
     };
 
@@ -100,18 +107,14 @@ async function saveCurrentSession() {
 
 async function saveCurrentSessionWithName(sessionName) {
   try {
-    const tabs = await chrome.tabs.query({});
-    const sessionTabs = tabs.map((tab, index) => ({
-      url: tab.url,
-      title: tab.title,
-      active: tab.active,
-      index: index
-    }));
+    const { tabs: sessionTabs, windows, groups } = await captureBrowserState();
 
     const session = {
       name: sessionName,
       description: `${sessionTabs.length} tabs saved`,
       tabs: sessionTabs,
+      windows,
+      groups,
       is_active: true
     };
 
@@ -184,6 +187,79 @@ function startHistorySync() {
   debugLog('[HyprLnk] All sync listeners set up successfully');
 }
 
+// Seconds spent on each page since the last history sync; the backend
+// marks reading-list bookmarks read after a long enough visit. Kept in
+// session storage, as the service worker can be stopped between events:
+// { pages: { url: seconds }, active: { url, since } }
+const DWELL_KEY = 'pageDwell';
+let dwellUpdates = Promise.resolve();
+
+async function loadDwell() {
+  const { [DWELL_KEY]: dwell } = await chrome.storage.session.get(DWELL_KEY);
+  return dwell || { pages: {}, active: null };
+}
+
+// Applies change to the stored dwell state, one update at a time so
+// events arriving together don't overwrite each other
+function updateDwell(change) {
+  dwellUpdates = dwellUpdates
+    .then(async () => {
+      const dwell = await loadDwell();
+      change(dwell);
+      await chrome.storage.session.set({ [DWELL_KEY]: dwell });
+    })
+    .catch(error => debugLog('[HyprLnk] Could not update page dwell:', error));
+  return dwellUpdates;
+}
+
+function closeActivePage(dwell) {
+  if (dwell.active) {
+    const seconds = (Date.now() - dwell.active.since) / 1000;
+    dwell.pages[dwell.active.url] = (dwell.pages[dwell.active.url] || 0) + seconds;
+    dwell.active = null;
+  }
+}
+
+function recordDwell() {
+  return updateDwell(closeActivePage);
+}
+
+async function trackActivePage() {
+  let tab;
+  try {
+    [tab] = await chrome.tabs.query({ active: true, lastFocusedWindow: true });
+  } catch (error) {
+    debugLog('[HyprLnk] Could not read the active tab:', error);
+  }
+  return updateDwell(dwell => {
+    closeActivePage(dwell);
+    if (tab && tab.url && /^https?:/.test(tab.url)) {
+      dwell.active = { url: tab.url, since: Date.now() };
+    }
+  });
+}
+
+chrome.tabs.onActivated.addListener(trackActivePage);
+chrome.tabs.onUpdated.addListener((tabId, changeInfo, tab) => {
+  if (changeInfo.url && tab.active) {
+    trackActivePage();
+  }
+});
+chrome.windows.onFocusChanged.addListener((windowId) => {
+  if (windowId === chrome.windows.WINDOW_ID_NONE) {
+    recordDwell();
+  } else {
+    trackActivePage();
+  }
+});
+chrome.idle.onStateChanged.addListener((newState) => {
+  if (newState === 'active') {
+    trackActivePage();
+  } else {
+    recordDwell();
+  }
+});
+
 // Debounced sync to prevent multiple rapid syncs
 function debouncedSync() {
   // Clear any pending sync
@@ -222,6 +298,9 @@ async function syncTodaysHistory() {
 
     debugLog(`[HyprLnk] Found ${historyItems.length} history items from Chrome`);
 
+    await dwellUpdates;
+    const { pages: pageDwell } = await loadDwell();
+
     // Filter and format history for today
     const todaysHistory = historyItems
       .filter(item => item.lastVisitTime >= startOfDay)
@@ -229,7 +308,8 @@ async function syncTodaysHistory() {
         url: item.url,
         title: item.title || 'Untitled',
         visit_count: item.visitCount || 1,
-        last_visit_time: new Date(item.lastVisitTime).toISOString()
+        last_visit_time: new Date(item.lastVisitTime).toISOString(),
+        ...(pageDwell[item.url] && { dwell_seconds: Math.round(pageDwell[item.url]) })
       }));
 
     debugLog(`[HyprLnk] Filtered to ${todaysHistory.length} entries for today`);
@@ -249,6 +329,12 @@ async function syncTodaysHistory() {
       if (response.ok) {
         const result = await response.json();
         debugLog(`[HyprLnk] History synced successfully: ${result.synced_count} entries`);
+        // Each visit's dwell is reported once
+        await updateDwell(dwell => {
+          todaysHistory
+            .filter(entry => entry.dwell_seconds)
+            .forEach(entry => delete dwell.pages[entry.url]);
+        });
       } else {
         console.error('[HyprLnk] History sync failed:', response.status, response.statusText);
       }
@@ -305,6 +391,12 @@ chrome.runtime.onMessage.addListener((request, sender, sendResponse) => {
 });
 
 async function restoreSession(session) {
+  // Sessions saved with windows get their windows and tab groups back;
+  // flat ones are restored into the current window
+  if (session.windows && session.windows.length > 0) {
+    return restoreSessionLayout(session);
+  }
+
   try {
     // Get current window and all its tabs
     const currentWindow = await chrome.windows.getCurrent({ populate: true });
@@ -325,13 +417,7 @@ async function restoreSession(session) {
     }
     
     // Create new tabs from session
-    const validTabs = session.tabs.filter(tab => 
-      tab.url && 
-      !tab.url.startsWith('chrome://') && 
-      !tab.url.startsWith('chrome-extension://') &&
-      !tab.url.startsWith('about:') &&
-      !tab.url.startsWith('moz-extension://')
-    );
+    const validTabs = session.tabs.filter(tab => isRestorableURL(tab.url));
     
     const createdTabs = [];
     for (let i = 0; i < validTabs.length; i++) {
@@ -370,6 +456,125 @@ async function restoreSession(session) {
   }
 }
 
+function isRestorableURL(url) {
+  return url &&
+    !url.startsWith('chrome://') &&
+    !url.startsWith('chrome-extension://') &&
+    !url.startsWith('about:') &&
+    !url.startsWith('moz-extension://');
+}
+
+// Recreates a session's windows and tab groups. The first window replaces
+// the tabs of the current one, keeping the HyprLnk tab like a flat
+// restore; the others open as new windows.
+async function restoreSessionLayout(session) {
+  try {
+    const currentWindow = await chrome.windows.getCurrent({ populate: true });
+    const hyprLinkTab = currentWindow.tabs.find(tab =>
+      tab.url && (tab.url.includes('localhost:4381') || tab.url.includes('127.0.0.1:4381'))
+    );
+    const tabsToClose = currentWindow.tabs.filter(tab => tab.id !== hyprLinkTab?.id);
+
+    const createdTabs = new Map(); // session tab to the tab opened for it
+    const browserWindowIds = new Map(); // session window ID to browser window ID
+
+    for (const sessionWindow of session.windows) {
+      const windowTabs = session.tabs
+        .filter(tab => tab.window_id === sessionWindow.id && isRestorableURL(tab.url))
+        .sort((a, b) => a.index - b.index);
+      if (windowTabs.length === 0) {
+        continue;
+      }
+
+      let windowId;
+      let remaining = windowTabs;
+      if (browserWindowIds.size === 0) {
+        windowId = currentWindow.id;
+      } else {
+        const created = await chrome.windows.create({ url: windowTabs[0].url, focused: false });
+        windowId = created.id;
+        await chrome.tabs.update(created.tabs[0].id, { pinned: windowTabs[0].pinned });
+        createdTabs.set(windowTabs[0], created.tabs[0]);
+        remaining = windowTabs.slice(1);
+      }
+      browserWindowIds.set(sessionWindow.id, windowId);
+
+      for (const sessionTab of remaining) {
+        try {
+          const newTab = await chrome.tabs.create({
+            url: sessionTab.url,
+            active: false,
+            pinned: sessionTab.pinned,
+            windowId
+          });
+          createdTabs.set(sessionTab, newTab);
+
+          // Small delay between tab creation to avoid overwhelming the browser
+          await new Promise(resolve => setTimeout(resolve, 100));
+        } catch (error) {
+          console.error(`Failed to create tab for ${sessionTab.url}:`, error);
+        }
+      }
+
+      const activeTab = windowTabs.find(tab => tab.active && createdTabs.has(tab));
+      if (activeTab) {
+        await chrome.tabs.update(createdTabs.get(activeTab).id, { active: true });
+      }
+      if (windowId !== currentWindow.id && sessionWindow.state && sessionWindow.state !== 'normal') {
+        await chrome.windows.update(windowId, { state: sessionWindow.state });
+      }
+    }
+
+    // Closed only now, so the current window never runs out of tabs
+    if (createdTabs.size > 0 && tabsToClose.length > 0) {
+      await chrome.tabs.remove(tabsToClose.map(tab => tab.id));
+    }
+
+    // Pinned tabs can't be grouped
+    let groupsCreated = 0;
+    for (const group of session.groups || []) {
+      const windowId = browserWindowIds.get(group.window_id);
+      const tabIds = session.tabs
+        .filter(tab => tab.group_id === group.id && !tab.pinned && createdTabs.has(tab))
+        .map(tab => createdTabs.get(tab).id);
+      if (!windowId || tabIds.length === 0 || !chrome.tabGroups) {
+        continue;
+      }
+      try {
+        const groupId = await chrome.tabs.group({ tabIds, createProperties: { windowId } });
+        await chrome.tabGroups.update(groupId, {
+          title: group.title,
+          color: group.color || 'grey',
+          collapsed: group.collapsed
+        });
+        groupsCreated++;
+      } catch (error) {
+        console.error(`Failed to recreate tab group "${group.title}":`, error);
+      }
+    }
+
+    const focused = session.windows.find(win => win.focused);
+    if (focused && browserWindowIds.has(focused.id)) {
+      await chrome.windows.update(browserWindowIds.get(focused.id), { focused: true });
+    }
+    if (hyprLinkTab) {
+      await chrome.tabs.update(hyprLinkTab.id, { active: true });
+    }
+
+    return {
+      message: `Restored session "${session.name}" with ${createdTabs.size} tabs in ${browserWindowIds.size} windows`,
+      tabsCreated: createdTabs.size,
+      tabsClosed: createdTabs.size > 0 ? tabsToClose.length : 0,
+      windowsCreated: Math.max(browserWindowIds.size - 1, 0),
+      groupsCreated
+    };
+
+  } catch (error) {
+    console.error('Error restoring session:', error);
+    throw error;
+  }
+}
+
 // Link click tracking functionality
 function handleLinkClicks(clicks) {
   // Add clicks to buffer
@@ -426,4 +631,68 @@ setInterval(() => {
 chrome.runtime.onStartup.addListener(() => {
   debugLog('[HyprLnk] Extension startup - syncing any buffered link clicks');
   syncLinkClicks();
-});
\ No newline at end of file
+});
+
+// Session autosave: post the open windows and tabs every few minutes so the backend
+// keeps a versioned "Autosave" session. The interval comes from the
+// autosave_minutes setting; 0 uses the default, negative turns it off.
+// An alarm rather than a timer, as the service worker is stopped when idle.
+const DEFAULT_AUTOSAVE_MINUTES = 5;
+const AUTOSAVE_ALARM = 'autosave';
+
+async function autoSaveSession() {
+  try {
+    const state = await captureBrowserState();
+
+    const response = await fetch(`${API_BASE}/sessions/autosave`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json'
+      },
+      body: JSON.stringify(state)
+    });
+
+    if (response.ok) {
+      const result = await response.json();
+      debugLog('[HyprLnk] Autosave done, changed:', result.changed);
+    } else {
+      console.error(`[HyprLnk] Failed to autosave session: ${response.status}`);
+    }
+  } catch (error) {
+    console.error('[HyprLnk] Error autosaving session:', error);
+  }
+}
+
+async function startAutoSave() {
+  let minutes = DEFAULT_AUTOSAVE_MINUTES;
+  try {
+    const response = await fetch(`${API_BASE}/settings`);
+    if (response.ok) {
+      const settings = await response.json();
+      if (settings.autosave_minutes) {
+        minutes = settings.autosave_minutes;
+      }
+    }
+  } catch (error) {
+    console.error('[HyprLnk] Error loading autosave settings:', error);
+  }
+
+  await chrome.alarms.clear(AUTOSAVE_ALARM);
+  if (minutes < 0) {
+    debugLog('[HyprLnk] Session autosave is off');
+    return;
+  }
+
+  debugLog(`[HyprLnk] Autosaving the session every ${minutes} minutes`);
+  chrome.alarms.create(AUTOSAVE_ALARM, { periodInMinutes: minutes });
+}
+
+chrome.alarms.onAlarm.addListener((alarm) => {
+  if (alarm.name === AUTOSAVE_ALARM) {
+    autoSaveSession();
+  }
+});
+
+// Alarms don't always survive a browser restart or an update
+chrome.runtime.onStartup.addListener(startAutoSave);
+chrome.runtime.onInstalled.addListener(startAutoSave);
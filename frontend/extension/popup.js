@@ -89,18 +89,14 @@ async function saveNewSession() {
   const sessionName = document.getElementById('sessionName').value || `Session ${new Date().toLocaleString()}`;
   
   try {
-    const tabs = await chrome.tabs.query({});
-    const sessionTabs = tabs.map((tab, index) => ({
-      url: tab.url,
-      title: tab.title,
-      active: tab.active,
-      index: index
-    }));
+    const { tabs: sessionTabs, windows, groups } = await captureBrowserState();
     
     const session = {
       name: sessionName,
       description: `${sessionTabs.length} tabs saved`,
       tabs: sessionTabs,
+      windows,
+      groups,
       is_active: true
     };
     
@@ -131,18 +127,14 @@ async function updateLatestSession() {
   }
   
   try {
-    const tabs = await chrome.tabs.query({});
-    const sessionTabs = tabs.map((tab, index) => ({
-      url: tab.url,
-      title: tab.title,
-      active: tab.active,
-      index: index
-    }));
+    const { tabs: sessionTabs, windows, groups } = await captureBrowserState();
     
     const updatedSession = {
       ...latestSession,
       description: `${sessionTabs.length} tabs updated`,
       tabs: sessionTabs,
+      windows,
+      groups,
       updated_at: new Date().toISOString()
     };
     
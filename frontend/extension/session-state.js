@@ -0,0 +1,53 @@
+// Shared by the background script and the popup: reads the open windows,
+// tabs and tab groups into the session shape the backend stores.
+
+const WINDOW_STATES = ['normal', 'minimized', 'maximized', 'fullscreen'];
+
+// Browser window and group IDs change between runs, so they are renumbered
+// from 1 within the session.
+async function captureBrowserState() {
+  const browserWindows = await chrome.windows.getAll({ populate: true, windowTypes: ['normal'] });
+  const browserGroups = chrome.tabGroups ? await chrome.tabGroups.query({}) : [];
+
+  const windowIds = new Map();
+  const windows = browserWindows.map((win, i) => {
+    windowIds.set(win.id, i + 1);
+    return {
+      id: i + 1,
+      focused: win.focused,
+      state: WINDOW_STATES.includes(win.state) ? win.state : 'normal'
+    };
+  });
+
+  const groupIds = new Map();
+  const groups = browserGroups
+    .filter(group => windowIds.has(group.windowId))
+    .map((group, i) => {
+      groupIds.set(group.id, i + 1);
+      return {
+        id: i + 1,
+        window_id: windowIds.get(group.windowId),
+        title: group.title || '',
+        color: group.color,
+        collapsed: group.collapsed
+      };
+    });
+
+  const tabs = [];
+  for (const win of browserWindows) {
+    for (const tab of win.tabs) {
+      tabs.push({
+        url: tab.url,
+        title: tab.title,
+        active: tab.active,
+        index: tab.index,
+        favIconUrl: tab.favIconUrl || '',
+        pinned: tab.pinned,
+        window_id: windowIds.get(win.id),
+        group_id: groupIds.get(tab.groupId) || 0
+      });
+    }
+  }
+
+  return { tabs, windows, groups };
+}